@@ -4,14 +4,13 @@ set -e
 
 PLUS=""
 if [ -z "${BUILD_OS##*plus*}" ]; then
-    mkdir -p /etc/nginx/oidc/
-    cp -a /code/internal/configs/oidc/* /etc/nginx/oidc/
     mkdir -p /etc/nginx/state_files/
     mkdir -p /etc/nginx/reporting/
     mkdir -p /etc/nginx/secrets/mgmt/
     PLUS=-plus
 fi
 
+mkdir -p /etc/nginx/oidc/ && cp -a /code/internal/configs/oidc/* /etc/nginx/oidc/
 mkdir -p /etc/nginx/njs/ && cp -a /code/internal/configs/njs/* /etc/nginx/njs/
 mkdir -p /var/lib/nginx /etc/nginx/secrets /etc/nginx/stream-conf.d
 setcap 'cap_net_bind_service=+eip' /usr/sbin/nginx 'cap_net_bind_service=+eip' /usr/sbin/nginx-debug
@@ -23,6 +23,7 @@ type ConfigParams struct {
 	HSTSIncludeSubdomains                  bool
 	HSTSMaxAge                             int64
 	HTTP2                                  bool
+	HTTP3                                  bool
 	Keepalive                              int
 	LBMethod                               string
 	LocationSnippets                       []string
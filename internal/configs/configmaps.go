@@ -129,6 +129,16 @@ func ParseConfigMap(ctx context.Context, cfgm *v1.ConfigMap, nginxPlus bool, has
 		}
 	}
 
+	if HTTP3, exists, err := GetMapKeyAsBool(cfgm.Data, "http3", cfgm); exists {
+		if err != nil {
+			nl.Error(l, err)
+			eventLog.Event(cfgm, v1.EventTypeWarning, nl.EventReasonInvalidValue, err.Error())
+			configOk = false
+		} else {
+			cfgParams.HTTP3 = HTTP3
+		}
+	}
+
 	if redirectToHTTPS, exists, err := GetMapKeyAsBool(cfgm.Data, "redirect-to-https", cfgm); exists {
 		if err != nil {
 			nl.Error(l, err)
@@ -1012,6 +1022,7 @@ func GenerateNginxMainConfig(staticCfgParams *StaticConfigParams, config *Config
 		HealthStatus:                       staticCfgParams.HealthStatus,
 		HealthStatusURI:                    staticCfgParams.HealthStatusURI,
 		HTTP2:                              config.HTTP2,
+		HTTP3:                              config.HTTP3,
 		HTTPSnippets:                       config.MainHTTPSnippets,
 		KeepaliveRequests:                  config.MainKeepaliveRequests,
 		KeepaliveTimeout:                   config.MainKeepaliveTimeout,
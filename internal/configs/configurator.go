@@ -65,6 +65,9 @@ const JWTKeyKey = "jwk"
 // HtpasswdFileKey is the key of the data field of a Secret where the HTTP basic authorization list must be stored
 const HtpasswdFileKey = "htpasswd"
 
+// HMACKeyKey is the key of the data field of a Secret where the HMAC key for signature validation must be stored.
+const HMACKeyKey = "key"
+
 // CACrtKey is the key of the data field of a Secret where the cert must be stored.
 const CACrtKey = "ca.crt"
 
@@ -74,6 +77,9 @@ const CACrlKey = "ca.crl"
 // ClientSecretKey is the key of the data field of a Secret where the OIDC client secret must be stored.
 const ClientSecretKey = "client-secret"
 
+// SessionKeyKey is the key of the data field of an OIDC Secret where the key used to encrypt the session cookies in NGINX must be stored.
+const SessionKeyKey = "session-key"
+
 // SPIFFE filenames and modes
 const (
 	spiffeCertFileName   = "spiffe_cert.pem"
@@ -755,30 +761,42 @@ func (cnf *Configurator) deleteTransportServerMetricsLabels(key string) {
 // AddOrUpdateTransportServer adds or updates NGINX configuration for the TransportServer resource.
 // It is a responsibility of the caller to check that the TransportServer references an existing listener.
 func (cnf *Configurator) AddOrUpdateTransportServer(transportServerEx *TransportServerEx) (Warnings, error) {
-	_, warnings, err := cnf.addOrUpdateTransportServer(transportServerEx)
+	_, warnings, weightUpdates, err := cnf.addOrUpdateTransportServer(transportServerEx)
 	if err != nil {
 		return nil, fmt.Errorf("error adding or updating TransportServer %v/%v: %w", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
 	}
+
+	if len(weightUpdates) > 0 {
+		cnf.EnableReloads()
+	}
+
 	if err := cnf.Reload(nginx.ReloadForOtherUpdate); err != nil {
 		return nil, fmt.Errorf("error reloading NGINX for TransportServer %v/%v: %w", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name, err)
 	}
+
+	for _, weightUpdate := range weightUpdates {
+		cnf.nginxManager.UpsertStreamSplitClientsKeyVal(weightUpdate.Zone, weightUpdate.Key, weightUpdate.Value)
+	}
+
 	return warnings, nil
 }
 
-func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *TransportServerEx) (bool, Warnings, error) {
+func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *TransportServerEx) (bool, Warnings, []WeightUpdate, error) {
+	var weightUpdates []WeightUpdate
 	name := getFileNameForTransportServer(transportServerEx.TransportServer)
 	tsCfg, warnings := generateTransportServerConfig(transportServerConfigParams{
-		transportServerEx:      transportServerEx,
-		listenerPort:           transportServerEx.ListenerPort,
-		isPlus:                 cnf.isPlus,
-		isResolverConfigured:   cnf.IsResolverConfigured(),
-		isDynamicReloadEnabled: cnf.staticCfgParams.DynamicSSLReload,
-		staticSSLPath:          cnf.staticCfgParams.StaticSSLPath,
+		transportServerEx:          transportServerEx,
+		listenerPort:               transportServerEx.ListenerPort,
+		isPlus:                     cnf.isPlus,
+		isResolverConfigured:       cnf.IsResolverConfigured(),
+		isDynamicReloadEnabled:     cnf.staticCfgParams.DynamicSSLReload,
+		staticSSLPath:              cnf.staticCfgParams.StaticSSLPath,
+		weightChangesDynamicReload: cnf.staticCfgParams.DynamicWeightChangesReload,
 	})
 
 	content, err := cnf.templateExecutorV2.ExecuteTransportServerTemplate(tsCfg)
 	if err != nil {
-		return false, nil, fmt.Errorf("error generating TransportServer config %v: %w", name, err)
+		return false, nil, weightUpdates, fmt.Errorf("error generating TransportServer config %v: %w", name, err)
 	}
 	if cnf.isPlus && cnf.isPrometheusEnabled {
 		cnf.updateTransportServerMetricsLabels(transportServerEx, tsCfg.Upstreams)
@@ -787,6 +805,14 @@ func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *Transport
 
 	cnf.transportServers[name] = transportServerEx
 
+	if cnf.staticCfgParams.DynamicWeightChangesReload && len(tsCfg.TwoWaySplitClients) > 0 {
+		variableNamer := NewTSVariableNamer(transportServerEx.TransportServer)
+		for _, splitClient := range tsCfg.TwoWaySplitClients {
+			value := variableNamer.GetNameOfKeyOfMapForWeights(splitClient.SplitClientsIndex, splitClient.Weights[0], splitClient.Weights[1])
+			weightUpdates = append(weightUpdates, WeightUpdate{Zone: splitClient.ZoneName, Key: splitClient.Key, Value: value})
+		}
+	}
+
 	// update TLS Passthrough Hosts config in case we have a TLS Passthrough TransportServer
 	// A non empty Host, may be a TLS Passthrough TransportServer but we have to check for the existence of the TLS Passthrough listener also, as TransportServers that terminate at the NGINX level can have non empty Hosts now too
 	isTLSPassthrough := transportServerEx.TransportServer.Spec.Listener.Name == conf_v1.TLSPassthroughListenerName
@@ -798,11 +824,11 @@ func (cnf *Configurator) addOrUpdateTransportServer(transportServerEx *Transport
 		}
 		ptChanged, err := cnf.updateTLSPassthroughHostsConfig()
 		if err != nil {
-			return false, nil, err
+			return false, nil, weightUpdates, err
 		}
-		return changed || ptChanged, warnings, nil
+		return changed || ptChanged, warnings, weightUpdates, nil
 	}
-	return changed, warnings, nil
+	return changed, warnings, weightUpdates, nil
 }
 
 // GetVirtualServerRoutesForVirtualServer returns the virtualServerRoutes that a virtualServer
@@ -856,10 +882,17 @@ func (cnf *Configurator) addOrUpdateHtpasswdSecret(secret *api_v1.Secret) string
 	return cnf.nginxManager.CreateSecret(name, data, nginx.HtpasswdSecretFileMode)
 }
 
+func (cnf *Configurator) addOrUpdateHMACSecret(secret *api_v1.Secret) string {
+	name := objectMetaToFileName(&secret.ObjectMeta)
+	data := secret.Data[HMACKeyKey]
+	return cnf.nginxManager.CreateSecret(name, data, nginx.HMACSecretFileMode)
+}
+
 // AddOrUpdateResources adds or updates configuration for resources.
 func (cnf *Configurator) AddOrUpdateResources(resources ExtendedResources, reloadIfUnchanged bool) (Warnings, error) {
 	allWarnings := newWarnings()
 	allWeightUpdates := []WeightUpdate{}
+	var allStreamWeightUpdates []WeightUpdate
 	configsChanged := false
 
 	updateResource := func(updateFunc func() (bool, Warnings, error), namespace, name string) error {
@@ -917,7 +950,9 @@ func (cnf *Configurator) AddOrUpdateResources(resources ExtendedResources, reloa
 
 	for _, tsEx := range resources.TransportServerExes {
 		err := updateResource(func() (bool, Warnings, error) {
-			return cnf.addOrUpdateTransportServer(tsEx)
+			changed, warnings, weightUpdates, err := cnf.addOrUpdateTransportServer(tsEx)
+			allStreamWeightUpdates = append(allStreamWeightUpdates, weightUpdates...)
+			return changed, warnings, err
 		}, tsEx.TransportServer.Namespace, tsEx.TransportServer.Name)
 		if err != nil {
 			return nil, err
@@ -929,6 +964,15 @@ func (cnf *Configurator) AddOrUpdateResources(resources ExtendedResources, reloa
 			return nil, fmt.Errorf("error when reloading NGINX when updating resources: %w", err)
 		}
 	}
+
+	for _, weightUpdate := range allWeightUpdates {
+		cnf.nginxManager.UpsertSplitClientsKeyVal(weightUpdate.Zone, weightUpdate.Key, weightUpdate.Value)
+	}
+
+	for _, weightUpdate := range allStreamWeightUpdates {
+		cnf.nginxManager.UpsertStreamSplitClientsKeyVal(weightUpdate.Zone, weightUpdate.Key, weightUpdate.Value)
+	}
+
 	return allWarnings, nil
 }
 
@@ -1071,6 +1115,10 @@ func (cnf *Configurator) deleteTransportServer(key string) error {
 	name := getFileNameForTransportServerFromKey(key)
 	cnf.nginxManager.DeleteStreamConfig(name)
 
+	if cnf.isPlus {
+		cnf.nginxManager.DeleteKeyValStateFiles(name)
+	}
+
 	delete(cnf.transportServers, name)
 	// update TLS Passthrough Hosts config in case we have a TLS Passthrough TransportServer
 	if _, exists := cnf.tlsPassthroughPairs[key]; exists {
@@ -1206,7 +1254,7 @@ func (cnf *Configurator) UpdateEndpointsForTransportServers(transportServerExes
 
 	for _, tsEx := range transportServerExes {
 		// Ignore warnings here as no new warnings should appear when updating Endpoints for TransportServers
-		_, _, err := cnf.addOrUpdateTransportServer(tsEx)
+		_, _, _, err := cnf.addOrUpdateTransportServer(tsEx)
 		if err != nil {
 			return fmt.Errorf("error adding or updating TransportServer %v/%v: %w", tsEx.TransportServer.Namespace, tsEx.TransportServer.Name, err)
 		}
@@ -1421,12 +1469,14 @@ func (cnf *Configurator) UpdateConfig(resources ExtendedResources) (Warnings, er
 		allWeightUpdates = append(allWeightUpdates, weightUpdates...)
 	}
 
+	var allStreamWeightUpdates []WeightUpdate
 	for _, tsEx := range resources.TransportServerExes {
-		_, warnings, err := cnf.addOrUpdateTransportServer(tsEx)
+		_, warnings, weightUpdates, err := cnf.addOrUpdateTransportServer(tsEx)
 		if err != nil {
 			return allWarnings, err
 		}
 		allWarnings.Add(warnings)
+		allStreamWeightUpdates = append(allStreamWeightUpdates, weightUpdates...)
 	}
 
 	if err := cnf.Reload(nginx.ReloadForOtherUpdate); err != nil {
@@ -1437,6 +1487,10 @@ func (cnf *Configurator) UpdateConfig(resources ExtendedResources) (Warnings, er
 		cnf.nginxManager.UpsertSplitClientsKeyVal(weightUpdate.Zone, weightUpdate.Key, weightUpdate.Value)
 	}
 
+	for _, weightUpdate := range allStreamWeightUpdates {
+		cnf.nginxManager.UpsertStreamSplitClientsKeyVal(weightUpdate.Zone, weightUpdate.Key, weightUpdate.Value)
+	}
+
 	return allWarnings, nil
 }
 
@@ -1484,11 +1538,13 @@ func (cnf *Configurator) UpdateVirtualServers(updatedVSExes []*VirtualServerEx,
 // UpdateTransportServers updates TransportServers.
 func (cnf *Configurator) UpdateTransportServers(updatedTSExes []*TransportServerEx, deletedKeys []string) []error {
 	var errList []error
+	var allWeightUpdates []WeightUpdate
 	for _, tsEx := range updatedTSExes {
-		_, _, err := cnf.addOrUpdateTransportServer(tsEx)
+		_, _, weightUpdates, err := cnf.addOrUpdateTransportServer(tsEx)
 		if err != nil {
 			errList = append(errList, fmt.Errorf("error adding or updating TransportServer %v/%v: %w", tsEx.TransportServer.Namespace, tsEx.TransportServer.Name, err))
 		}
+		allWeightUpdates = append(allWeightUpdates, weightUpdates...)
 	}
 
 	for _, key := range deletedKeys {
@@ -1502,6 +1558,10 @@ func (cnf *Configurator) UpdateTransportServers(updatedTSExes []*TransportServer
 		errList = append(errList, fmt.Errorf("error when updating TransportServers: %w", err))
 	}
 
+	for _, weightUpdate := range allWeightUpdates {
+		cnf.nginxManager.UpsertStreamSplitClientsKeyVal(weightUpdate.Zone, weightUpdate.Key, weightUpdate.Value)
+	}
+
 	return errList
 }
 
@@ -2014,6 +2074,8 @@ func (cnf *Configurator) AddOrUpdateSecret(secret *api_v1.Secret) string {
 		return cnf.addOrUpdateJWKSecret(secret)
 	case secrets.SecretTypeHtpasswd:
 		return cnf.addOrUpdateHtpasswdSecret(secret)
+	case secrets.SecretTypeHMAC:
+		return cnf.addOrUpdateHMACSecret(secret)
 	case secrets.SecretTypeOIDC:
 		// OIDC ClientSecret is not required on the filesystem, it is written directly to the config file.
 		return ""
@@ -2042,6 +2104,11 @@ func (cnf *Configurator) UpsertSplitClientsKeyVal(zoneName, key, value string) {
 	cnf.nginxManager.UpsertSplitClientsKeyVal(zoneName, key, value)
 }
 
+// UpsertStreamSplitClientsKeyVal upserts a key-value pair in a stream keyval zone for weight changes without reloads.
+func (cnf *Configurator) UpsertStreamSplitClientsKeyVal(zoneName, key, value string) {
+	cnf.nginxManager.UpsertStreamSplitClientsKeyVal(zoneName, key, value)
+}
+
 // GetIngressControllerReplicas returns the number of ingresscontroller-replicas (previously stored via SetIngressControllerReplicas)
 func (cnf *Configurator) GetIngressControllerReplicas() int {
 	return cnf.ingressControllerReplicas
@@ -0,0 +1,118 @@
+// The identity of the client certificate is only forwarded to the upstreams if the certificate was verified.
+function verified(r) {
+    return r.variables.ssl_client_verify === 'SUCCESS';
+}
+
+function subjectDN(r) {
+    return verified(r) ? r.variables.ssl_client_s_dn : '';
+}
+
+function fingerprint(r) {
+    return verified(r) ? r.variables.ssl_client_fingerprint : '';
+}
+
+function escapedCert(r) {
+    return verified(r) ? r.variables.ssl_client_escaped_cert : '';
+}
+
+// readTLV reads the DER-encoded tag-length-value at pos.
+function readTLV(der, pos) {
+    const tag = der[pos];
+    let len = der[pos + 1];
+    let start = pos + 2;
+
+    if (len & 0x80) {
+        const octets = len & 0x7f;
+        len = 0;
+        for (let i = 0; i < octets; i++) {
+            len = len * 256 + der[start + i];
+        }
+        start += octets;
+    }
+
+    if (start + len > der.length) {
+        throw new Error('truncated DER value');
+    }
+
+    return { tag: tag, start: start, end: start + len };
+}
+
+function children(der, tlv) {
+    const result = [];
+    for (let pos = tlv.start; pos < tlv.end;) {
+        const child = readTLV(der, pos);
+        result.push(child);
+        pos = child.end;
+    }
+    return result;
+}
+
+function formatIP(bytes) {
+    if (bytes.length === 4) {
+        return Array.prototype.join.call(bytes, '.');
+    }
+
+    const groups = [];
+    for (let i = 0; i < bytes.length; i += 2) {
+        groups.push(((bytes[i] << 8) | bytes[i + 1]).toString(16));
+    }
+    return groups.join(':');
+}
+
+const sanOID = '551d11';
+
+const generalNames = {
+    0x81: 'email',
+    0x82: 'DNS',
+    0x86: 'URI',
+    0x87: 'IP',
+};
+
+// parseSANs returns the subject alternative names of a DER-encoded X.509 certificate.
+function parseSANs(der) {
+    const cert = readTLV(der, 0);
+    const tbs = children(der, cert)[0];
+    // the extensions are the only explicitly tagged [3] field of the TBSCertificate
+    const extensions = children(der, tbs).filter(function (field) { return field.tag === 0xa3; })[0];
+    if (!extensions) {
+        return [];
+    }
+
+    const sans = [];
+    children(der, children(der, extensions)[0]).forEach(function (ext) {
+        const fields = children(der, ext);
+        if (der.slice(fields[0].start, fields[0].end).toString('hex') !== sanOID) {
+            return;
+        }
+
+        const value = fields[fields.length - 1];
+        const names = readTLV(der, value.start);
+        children(der, names).forEach(function (name) {
+            const type = generalNames[name.tag];
+            if (!type) {
+                return;
+            }
+            const bytes = der.slice(name.start, name.end);
+            sans.push(type + ':' + (type === 'IP' ? formatIP(bytes) : bytes.toString()));
+        });
+    });
+
+    return sans;
+}
+
+function sans(r) {
+    if (!verified(r)) {
+        return '';
+    }
+
+    const pem = r.variables.ssl_client_raw_cert;
+    const base64 = pem.replace(/-----(BEGIN|END) CERTIFICATE-----/g, '').replace(/\s+/g, '');
+    try {
+        return parseSANs(Buffer.from(base64, 'base64')).join(',');
+    } catch (e) {
+        r.warn('failed to parse the subject alternative names of the client certificate: ' + e);
+        return '';
+    }
+}
+
+export default { subjectDN, sans, fingerprint, escapedCert };
@@ -0,0 +1,12 @@
+function delay(r) {
+    const delayMs = Number(r.variables.fault_injection_delay);
+
+    setTimeout(function () {
+        // mark the request so that the fault is not injected again after the redirect
+        r.variables.fault_injection_delayed = '1';
+        const args = r.variables.args;
+        r.internalRedirect(args ? r.uri + '?' + args : r.uri);
+    }, delayMs);
+}
+
+export default { delay };
@@ -0,0 +1,276 @@
+const fs = require('fs');
+
+const algorithms = {
+    HS256: {
+        kty: 'oct',
+        params: { name: 'HMAC', hash: 'SHA-256' },
+    },
+    RS256: {
+        kty: 'RSA',
+        params: { name: 'RSASSA-PKCS1-v1_5', hash: 'SHA-256' },
+    },
+    ES256: {
+        kty: 'EC',
+        params: { name: 'ECDSA', namedCurve: 'P-256', hash: 'SHA-256' },
+    },
+};
+
+function deny(r, reason) {
+    r.warn('JWT validation failed: ' + reason);
+    r.headersOut['WWW-Authenticate'] = 'Bearer realm="' + r.variables.jwt_auth_realm + '"';
+    r.return(401);
+}
+
+function parseSegment(segment) {
+    return JSON.parse(Buffer.from(segment, 'base64url').toString());
+}
+
+async function loadKeys(r) {
+    if (r.variables.jwt_auth_jwks_uri) {
+        // the JWKS is cached by the location the subrequest is sent to
+        const reply = await r.subrequest(r.variables.jwt_auth_jwks_uri);
+        if (reply.status !== 200) {
+            throw new Error('failed to fetch the JWKS: status ' + reply.status);
+        }
+        return JSON.parse(reply.responseText).keys || [];
+    }
+
+    return JSON.parse(fs.readFileSync(r.variables.jwt_auth_key_file)).keys || [];
+}
+
+// publicJWK strips a JWK down to the members required to import it for verification.
+function publicJWK(jwk) {
+    switch (jwk.kty) {
+    case 'oct':
+        return { kty: jwk.kty, k: jwk.k };
+    case 'RSA':
+        return { kty: jwk.kty, n: jwk.n, e: jwk.e };
+    case 'EC':
+        return { kty: jwk.kty, crv: jwk.crv, x: jwk.x, y: jwk.y };
+    }
+}
+
+async function verifySignature(r, header, segments) {
+    const alg = algorithms[header.alg];
+    if (!alg) {
+        return false;
+    }
+
+    const keys = (await loadKeys(r)).filter(function (jwk) {
+        return jwk.kty === alg.kty
+            && (!jwk.alg || jwk.alg === header.alg)
+            && (!header.kid || jwk.kid === header.kid);
+    });
+
+    const data = Buffer.from(segments[0] + '.' + segments[1]);
+    const signature = Buffer.from(segments[2], 'base64url');
+
+    for (let i = 0; i < keys.length; i++) {
+        const key = await crypto.subtle.importKey('jwk', publicJWK(keys[i]), alg.params, false, ['verify']);
+        if (await crypto.subtle.verify(alg.params, key, signature, data)) {
+            return true;
+        }
+    }
+
+    return false;
+}
+
+function checkClaims(r, payload) {
+    const now = Math.floor(Date.now() / 1000);
+
+    // a non-numeric exp or nbf would fail both comparisons below
+    if (payload.exp !== undefined && !Number.isFinite(payload.exp)) {
+        return 'the exp claim is not a number';
+    }
+    if (payload.nbf !== undefined && !Number.isFinite(payload.nbf)) {
+        return 'the nbf claim is not a number';
+    }
+
+    if (payload.exp !== undefined && now >= payload.exp) {
+        return 'the token has expired';
+    }
+    if (payload.nbf !== undefined && now < payload.nbf) {
+        return 'the token is not valid yet';
+    }
+
+    const issuer = r.variables.jwt_auth_issuer;
+    if (issuer && payload.iss !== issuer) {
+        return 'unexpected iss claim ' + payload.iss;
+    }
+
+    const audience = r.variables.jwt_auth_audience;
+    if (audience) {
+        const accepted = audience.split(' ');
+        const tokenAudience = Array.isArray(payload.aud) ? payload.aud : [payload.aud];
+        if (!tokenAudience.some(function (aud) { return accepted.indexOf(aud) !== -1; })) {
+            return 'unexpected aud claim ' + payload.aud;
+        }
+    }
+
+    return '';
+}
+
+function claimValues(payload, claim) {
+    let value = payload;
+    const parts = claim.split('.');
+    for (let i = 0; i < parts.length; i++) {
+        if (value === null || typeof value !== 'object') {
+            return [];
+        }
+        value = value[parts[i]];
+    }
+
+    if (value === undefined || value === null) {
+        return [];
+    }
+    if (Array.isArray(value)) {
+        return value.map(String);
+    }
+    // claims such as scope hold several values separated by spaces
+    return String(value).split(' ');
+}
+
+function meets(payload, requirement) {
+    const values = claimValues(payload, requirement.claim);
+    return requirement.values.some(function (v) {
+        return values.indexOf(v) !== -1;
+    });
+}
+
+// authorize checks the claims of the verified token against the requirements of an authorization policy, if any.
+function authorize(r, payload) {
+    if (!r.variables.authorization_requirements) {
+        return true;
+    }
+
+    let requirements;
+    try {
+        requirements = JSON.parse(r.variables.authorization_requirements);
+    } catch (e) {
+        r.error('failed to parse the authorization requirements: ' + e);
+        return false;
+    }
+
+    const allOf = requirements.allOf || [];
+    const anyOf = requirements.anyOf || [];
+
+    const allMet = allOf.every(function (req) {
+        return meets(payload, req);
+    });
+    const anyMet = anyOf.length === 0 || anyOf.some(function (req) {
+        return meets(payload, req);
+    });
+
+    return allMet && anyMet;
+}
+
+async function validate(r) {
+    let token = r.variables.jwt_auth_token;
+    if (!token) {
+        deny(r, 'no token');
+        return;
+    }
+    if (token.startsWith('Bearer ')) {
+        token = token.slice(7);
+    }
+
+    const segments = token.split('.');
+    if (segments.length !== 3) {
+        deny(r, 'malformed token');
+        return;
+    }
+
+    let header;
+    let payload;
+    try {
+        header = parseSegment(segments[0]);
+        payload = parseSegment(segments[1]);
+    } catch (e) {
+        deny(r, 'malformed token: ' + e);
+        return;
+    }
+
+    try {
+        if (!(await verifySignature(r, header, segments))) {
+            deny(r, 'invalid signature or unsupported alg ' + header.alg);
+            return;
+        }
+    } catch (e) {
+        r.error('failed to verify the JWT signature: ' + e);
+        r.return(500);
+        return;
+    }
+
+    const reason = checkClaims(r, payload);
+    if (reason) {
+        deny(r, reason);
+        return;
+    }
+
+    r.variables.jwt_payload = JSON.stringify(payload);
+    setClaimVariables(r, payload);
+
+    if (!authorize(r, payload)) {
+        r.warn('JWT authorization failed: the claims do not meet the requirements');
+        r.return(Number(r.variables.authorization_reject_code) || 403);
+        return;
+    }
+
+    r.return(204);
+}
+
+// formatClaim formats the value of a claim like the $jwt_claim_ variables of NGINX Plus.
+function formatClaim(value) {
+    if (value === undefined || value === null) {
+        return '';
+    }
+    if (Array.isArray(value)) {
+        return value.join(',');
+    }
+    if (typeof value === 'object') {
+        return JSON.stringify(value);
+    }
+    return String(value);
+}
+
+const claimsSetByJS = ['sub', 'iss', 'aud', 'scope'];
+
+// setClaimVariables sets the $jwt_claim_ variables declared by js_var for the claims of the token.
+function setClaimVariables(r, payload) {
+    Object.keys(payload).forEach(function (name) {
+        if (claimsSetByJS.indexOf(name) !== -1 || !/^[A-Za-z0-9_]+$/.test(name)) {
+            return;
+        }
+        try {
+            r.variables['jwt_claim_' + name] = formatClaim(payload[name]);
+        } catch (e) {
+            // the variable of the claim is not declared because it is not referenced
+        }
+    });
+}
+
+function claim(r, name) {
+    if (!r.variables.jwt_payload) {
+        return '';
+    }
+
+    return formatClaim(JSON.parse(r.variables.jwt_payload)[name]);
+}
+
+function sub(r) {
+    return claim(r, 'sub');
+}
+
+function iss(r) {
+    return claim(r, 'iss');
+}
+
+function aud(r) {
+    return claim(r, 'aud');
+}
+
+function scope(r) {
+    return claim(r, 'scope');
+}
+
+export default { validate, sub, iss, aud, scope };
@@ -0,0 +1,140 @@
+const fs = require('fs');
+
+const algorithms = {
+    sha1: 'SHA-1',
+    sha256: 'SHA-256',
+    sha512: 'SHA-512',
+};
+
+const slackTimestampHeader = 'X-Slack-Request-Timestamp';
+
+function deny(r, reason) {
+    r.warn('signature validation failed: ' + reason);
+    r.return(Number(r.variables.signature_validation_reject_code));
+}
+
+// parseSignatureHeader returns the timestamp and the hex-encoded signatures of the signature header.
+function parseSignatureHeader(r, format, header) {
+    switch (format) {
+    case 'stripe': {
+        const parsed = { timestamp: '', signatures: [] };
+        header.split(',').forEach(function (item) {
+            const i = item.indexOf('=');
+            const name = item.slice(0, i).trim();
+            const value = item.slice(i + 1).trim();
+            if (name === 't') {
+                parsed.timestamp = value;
+            } else if (name === 'v1') {
+                parsed.signatures.push(value);
+            }
+        });
+        return parsed;
+    }
+    case 'slack':
+        return {
+            timestamp: r.headersIn[slackTimestampHeader] || '',
+            signatures: header.startsWith('v0=') ? [header.slice(3)] : [],
+        };
+    default: {
+        // GitHub prefixes the signature with the algorithm, for example sha256=
+        const prefix = r.variables.signature_validation_algorithm + '=';
+        return {
+            timestamp: '',
+            signatures: [header.startsWith(prefix) ? header.slice(prefix.length) : header],
+        };
+    }
+    }
+}
+
+function signedPayload(format, timestamp, body) {
+    switch (format) {
+    case 'stripe':
+        return Buffer.concat([Buffer.from(timestamp + '.'), body]);
+    case 'slack':
+        return Buffer.concat([Buffer.from('v0:' + timestamp + ':'), body]);
+    default:
+        return body;
+    }
+}
+
+function checkTimestamp(r, timestamp) {
+    if (!/^[0-9]+$/.test(timestamp)) {
+        return 'missing or invalid timestamp';
+    }
+
+    const tolerance = Number(r.variables.signature_validation_timestamp_tolerance);
+    const now = Math.floor(Date.now() / 1000);
+    if (tolerance > 0 && Math.abs(now - Number(timestamp)) > tolerance) {
+        return 'the timestamp ' + timestamp + ' is outside of the tolerance';
+    }
+
+    return '';
+}
+
+async function verifySignatures(r, payload, signatures) {
+    const params = { name: 'HMAC', hash: algorithms[r.variables.signature_validation_algorithm] };
+    const keyData = fs.readFileSync(r.variables.signature_validation_key_file);
+    const key = await crypto.subtle.importKey('raw', keyData, params, false, ['verify']);
+
+    for (let i = 0; i < signatures.length; i++) {
+        if (!/^([0-9a-fA-F]{2})+$/.test(signatures[i])) {
+            continue;
+        }
+        // crypto.subtle.verify compares the signatures in constant time
+        if (await crypto.subtle.verify(params, key, Buffer.from(signatures[i], 'hex'), payload)) {
+            return true;
+        }
+    }
+
+    return false;
+}
+
+async function validate(r) {
+    const header = r.headersIn[r.variables.signature_validation_header];
+    if (!header) {
+        deny(r, 'no signature header ' + r.variables.signature_validation_header);
+        return;
+    }
+
+    let body = r.requestBuffer;
+    if (body === undefined) {
+        if (r.headersIn['Content-Length'] || r.headersIn['Transfer-Encoding']) {
+            // the body was written to a temporary file, because it does not fit in the client body buffer
+            r.error('the request body is too large for signature validation');
+            r.return(413);
+            return;
+        }
+        body = Buffer.from('');
+    }
+
+    const format = r.variables.signature_validation_format;
+    const parsed = parseSignatureHeader(r, format, header);
+    if (parsed.signatures.length === 0) {
+        deny(r, 'malformed signature header');
+        return;
+    }
+
+    if (format !== 'plain') {
+        const reason = checkTimestamp(r, parsed.timestamp);
+        if (reason) {
+            deny(r, reason);
+            return;
+        }
+    }
+
+    try {
+        const payload = signedPayload(format, parsed.timestamp, body);
+        if (!(await verifySignatures(r, payload, parsed.signatures))) {
+            deny(r, 'invalid signature');
+            return;
+        }
+    } catch (e) {
+        r.error('failed to verify the request signature: ' + e);
+        r.return(500);
+        return;
+    }
+
+    r.internalRedirect(r.variables.signature_validation_location);
+}
+
+export default { validate };
@@ -8,9 +8,14 @@ export default {
     auth,
     codeExchange,
     extractTokenClaims,
+    validSession,
+    backchannelLogout,
     logout
 };
 
+// The event of the logout tokens sent by the IdP for back-channel logout.
+const BACKCHANNEL_LOGOUT_EVENT = "http://schemas.openid.net/event/backchannel-logout";
+
 // The main authentication flow, called before serving a protected resource.
 async function auth(r, afterSyncCheck) {
     // If there's a session cookie but session not synced, wait for sync
@@ -20,12 +25,22 @@ async function auth(r, afterSyncCheck) {
         return;
     }
 
+    // The session was terminated by back-channel logout, do not refresh it
+    const sessionClaims = parseClaims(r.variables.session_jwt);
+    if (sessionClaims && isLoggedOut(r, sessionClaims)) {
+        r.variables.session_jwt = '-';
+        r.variables.access_token = '-';
+        r.variables.refresh_token = '-';
+        initiateNewAuth(r);
+        return;
+    }
+
     if (isNewSession(r)) {
         initiateNewAuth(r);
         return;
     }
 
-    // No or expired ID token, but refresh token present, attempt to refresh
+    // No, expired or soon to expire ID token, but refresh token present, attempt to refresh
     const tokenset = await refreshTokens(r);
     if (!tokenset) {
         return;
@@ -88,6 +103,136 @@ async function codeExchange(r) {
     r.return(302, r.variables.redirect_base + decodeURIComponent(r.variables.cookie_auth_redir));
 }
 
+// Checks the session of a request with a valid ID token, used with auth_jwt_require.
+// A session is not valid if it was terminated by back-channel logout, or if its tokens
+// expire within $oidc_refresh_leeway seconds and can be refreshed.
+function validSession(r) {
+    const claims = parseClaims(r.variables.session_jwt);
+    if (!claims || isLoggedOut(r, claims)) {
+        return '0';
+    }
+
+    if (!isNewSession(r)) {
+        // The access token expires before the ID token if it is a JWT with an earlier exp
+        const accessClaims = parseClaims(r.variables.access_token);
+        const exp = accessClaims && accessClaims.exp < claims.exp ? accessClaims.exp : claims.exp;
+        if (refreshDue(r, exp, claims.iat)) {
+            return '0';
+        }
+    }
+
+    return '1';
+}
+
+// Checks if tokens expire within $oidc_refresh_leeway seconds. The leeway is capped at half
+// the lifetime of the tokens, so that tokens which were just refreshed are never due.
+function refreshDue(r, exp, iat) {
+    const leeway = Number(r.variables.oidc_refresh_leeway) || 0;
+    if (!leeway || !exp || !iat) {
+        return false;
+    }
+
+    const now = Math.floor(Date.now() / 1000);
+    return exp - now <= Math.min(leeway, (exp - iat) / 2);
+}
+
+// Checks if the session was terminated by back-channel logout, either by its sid or
+// by a logout token for its sub issued after the session.
+function isLoggedOut(r, claims) {
+    if (claims.sid) {
+        r.variables.oidc_logout_key = "sid:" + claims.sid;
+        if (r.variables.oidc_logout) {
+            return true;
+        }
+    }
+
+    r.variables.oidc_logout_key = "sub:" + claims.sub;
+    const loggedOutAt = Number(r.variables.oidc_logout);
+    return loggedOutAt > 0 && Number(claims.iat) < loggedOutAt;
+}
+
+// The back-channel logout handler, called by the IdP with a logout token to terminate sessions, as per:
+//  https://openid.net/specs/openid-connect-backchannel-1_0.html
+async function backchannelLogout(r) {
+    r.headersOut["Cache-Control"] = "no-store";
+    if (r.method !== "POST") {
+        r.return(405);
+        return;
+    }
+
+    const token = require('querystring').parse(r.requestText || "").logout_token;
+    if (!token) {
+        rejectLogoutToken(r, "missing logout_token");
+        return;
+    }
+
+    // The signature is verified by the internal endpoint, the claims are then read from the token
+    if (!(await getTokenClaims(r, token))) {
+        rejectLogoutToken(r, "invalid logout_token");
+        return;
+    }
+
+    const claims = parseClaims(token);
+    const error = validateLogoutTokenClaims(r, claims);
+    if (error) {
+        rejectLogoutToken(r, error);
+        return;
+    }
+
+    // Terminate the session with the sid, otherwise all the sessions of the sub
+    if (claims.sid) {
+        r.variables.oidc_logout_key = "sid:" + claims.sid;
+        r.variables.oidc_logout = "1";
+    } else {
+        r.variables.oidc_logout_key = "sub:" + claims.sub;
+        r.variables.oidc_logout = String(claims.iat);
+    }
+
+    r.log("OIDC back-channel logout for " + (claims.sid ? "sid " + claims.sid : "sub " + claims.sub));
+    r.return(200);
+}
+
+// Validates the claims of a logout token as per the back-channel logout spec.
+function validateLogoutTokenClaims(r, claims) {
+    if (!claims || !claims.iss || !claims.aud || !claims.iat) {
+        return "missing claim(s) in logout_token";
+    }
+
+    const aud = Array.isArray(claims.aud) ? claims.aud : [claims.aud];
+    if (!aud.includes(r.variables.oidc_client)) {
+        return "aud claim does not include $oidc_client";
+    }
+    if (!claims.events || typeof claims.events[BACKCHANNEL_LOGOUT_EVENT] !== 'object') {
+        return "missing back-channel logout event";
+    }
+    if (claims.nonce) {
+        return "logout_token must not contain a nonce";
+    }
+    if (!claims.sid && !claims.sub) {
+        return "logout_token must contain a sid or a sub claim";
+    }
+
+    return "";
+}
+
+function rejectLogoutToken(r, error) {
+    r.error("OIDC back-channel logout error: " + error);
+    r.headersOut["Content-Type"] = "application/json";
+    r.return(400, JSON.stringify({ error: "invalid_request", error_description: error }));
+}
+
+// Decodes the claims of a JWT. The token must have been validated.
+function parseClaims(token) {
+    if (!token || token === '-') {
+        return null;
+    }
+    try {
+        return JSON.parse(Buffer.from(token.split('.')[1], 'base64url').toString());
+    } catch (e) {
+        return null;
+    }
+}
+
 // Extracts claims from token by calling the internal endpoint.
 function getTokenClaims(r, token) {
     return new Promise((resolve) => {
@@ -0,0 +1,485 @@
+/*
+ * JavaScript functions for providing OpenID Connect with NGINX
+ *
+ * The tokens are stored in the session cookies, encrypted with AES-GCM
+ * using a key derived from $oidc_session_key.
+ *
+ * Copyright (C) 2024 Nginx, Inc.
+ */
+
+const c = require('crypto');
+
+export default {
+    session,
+    auth,
+    codeExchange,
+    backchannelLogout,
+    logout
+};
+
+// The prefix of the session cookies. The encrypted session is split into
+// chunks stored in the cookies auth_session_0, auth_session_1 and so on.
+const SESSION_COOKIE = "auth_session_";
+
+// Browsers limit the size of a cookie to 4096 bytes, including its name and attributes.
+const SESSION_CHUNK_SIZE = 3800;
+
+// The event of the logout tokens sent by the IdP for back-channel logout.
+const BACKCHANNEL_LOGOUT_EVENT = "http://schemas.openid.net/event/backchannel-logout";
+
+// The auth_request handler, called before serving a protected resource.
+// Exposes the tokens of a valid session, otherwise triggers the authentication flow.
+async function session(r) {
+    const session = await readSession(r);
+    if (!session || sessionExpired(session) || isLoggedOut(session.claims) || refreshDue(r, session)) {
+        r.return(401);
+        return;
+    }
+
+    // the variables are shared with the protected request
+    r.variables.session_jwt = session.id_token;
+    r.variables.access_token = session.access_token || "";
+    r.variables.jwt_payload = JSON.stringify(session.claims);
+
+    // Expose the claims passed to the upstream in request headers
+    if (r.variables.oidc_claims) {
+        r.variables.oidc_claims.split(' ').forEach((claim) => {
+            r.variables["oidc_claim_" + claim.replace(/[.-]/g, '_')] = claimValue(session.claims, claim);
+        });
+    }
+
+    r.return(204);
+}
+
+// The main authentication flow, called when the session is missing or expired.
+async function auth(r) {
+    const current = await readSession(r);
+
+    // The session was terminated by back-channel logout, do not refresh it
+    if (current && isLoggedOut(current.claims)) {
+        r.headersOut["Set-Cookie"] = clearSessionCookies(r);
+        initiateNewAuth(r);
+        return;
+    }
+
+    if (!current || !current.refresh_token) {
+        initiateNewAuth(r);
+        return;
+    }
+
+    // Expired or soon to expire tokens, but refresh token present, attempt to refresh
+    const tokenset = await requestTokens(r, "/_refresh", generateTokenRequestParams(r, "refresh_token", current.refresh_token));
+    if (!tokenset) {
+        r.headersOut["Set-Cookie"] = clearSessionCookies(r);
+        initiateNewAuth(r);
+        return;
+    }
+
+    const claims = await validateIdToken(r, tokenset.id_token, false);
+    if (!claims) {
+        r.headersOut["Set-Cookie"] = clearSessionCookies(r);
+        initiateNewAuth(r);
+        return;
+    }
+
+    // The refresh token is only returned by some IdPs, keep the current one otherwise
+    tokenset.refresh_token = tokenset.refresh_token || current.refresh_token;
+
+    r.log("OIDC success, refreshing session for " + claims.sub);
+
+    // Set the session cookies and retry the original request
+    r.headersOut["Set-Cookie"] = await writeSession(r, tokenset, claims);
+    r.return(302, r.variables.request_uri);
+}
+
+// The code exchange handler, called after IdP redirects back with a authorization code.
+async function codeExchange(r) {
+    // Check authorization code presence
+    if (!r.variables.arg_code || r.variables.arg_code.length == 0) {
+        if (r.variables.arg_error) {
+            r.error("OIDC error receiving authorization code: " +
+                    r.variables.arg_error_description);
+        } else {
+            r.error("OIDC expected authorization code but received: " + r.uri);
+        }
+        r.return(502);
+        return;
+    }
+
+    // Exchange authorization code for tokens
+    const tokenset = await requestTokens(r, "/_token", generateTokenRequestParams(r, "authorization_code"));
+    if (!tokenset) {
+        r.return(502);
+        return;
+    }
+
+    const claims = await validateIdToken(r, tokenset.id_token, true);
+    if (!claims) {
+        r.return(500);
+        return;
+    }
+
+    r.log("OIDC success, creating session for " + claims.sub);
+
+    // Set the session cookies and redirect to the originally requested URI
+    const cookies = await writeSession(r, tokenset, claims);
+    cookies.push("auth_nonce=; " + r.variables.oidc_cookie_flags);
+    r.headersOut["Set-Cookie"] = cookies;
+    r.return(302, r.variables.redirect_base + decodeURIComponent(r.variables.cookie_auth_redir));
+}
+
+// The back-channel logout handler, called by the IdP with a logout token to terminate sessions, as per:
+//  https://openid.net/specs/openid-connect-backchannel-1_0.html
+async function backchannelLogout(r) {
+    r.headersOut["Cache-Control"] = "no-store";
+    if (r.method !== "POST") {
+        r.return(405);
+        return;
+    }
+
+    const token = require('querystring').parse(r.requestText || "").logout_token;
+    if (!token) {
+        rejectLogoutToken(r, "missing logout_token");
+        return;
+    }
+
+    // The internal endpoint verifies the signature and stores the claims in $jwt_payload
+    const reply = await r.subrequest("/_token_validation", "token=" + encodeURIComponent(token));
+    if (reply.status !== 204) {
+        rejectLogoutToken(r, "invalid logout_token");
+        return;
+    }
+
+    const claims = JSON.parse(r.variables.jwt_payload);
+    const error = validateLogoutTokenClaims(claims);
+    if (error) {
+        rejectLogoutToken(r, error);
+        return;
+    }
+
+    // Terminate the session with the sid, otherwise all the sessions of the sub
+    if (claims.sid) {
+        ngx.shared.oidc_logouts.set("sid:" + claims.sid, "1");
+    } else {
+        ngx.shared.oidc_logouts.set("sub:" + claims.sub, String(claims.iat));
+    }
+
+    r.log("OIDC back-channel logout for " + (claims.sid ? "sid " + claims.sid : "sub " + claims.sub));
+    r.return(200);
+}
+
+// Validates the claims of a logout token as per the back-channel logout spec.
+function validateLogoutTokenClaims(claims) {
+    if (!claims.iss || !claims.aud || !claims.iat) {
+        return "missing claim(s) in logout_token";
+    }
+    if (!claims.events || typeof claims.events[BACKCHANNEL_LOGOUT_EVENT] !== 'object') {
+        return "missing back-channel logout event";
+    }
+    if (claims.nonce) {
+        return "logout_token must not contain a nonce";
+    }
+    if (!claims.sid && !claims.sub) {
+        return "logout_token must contain a sid or a sub claim";
+    }
+
+    return "";
+}
+
+function rejectLogoutToken(r, error) {
+    r.error("OIDC back-channel logout error: " + error);
+    r.headersOut["Content-Type"] = "application/json";
+    r.return(400, JSON.stringify({ error: "invalid_request", error_description: error }));
+}
+
+// Checks if the session was terminated by back-channel logout, either by its sid or
+// by a logout token for its sub issued after the session.
+function isLoggedOut(claims) {
+    if (claims.sid && ngx.shared.oidc_logouts.get("sid:" + claims.sid)) {
+        return true;
+    }
+
+    const loggedOutAt = Number(ngx.shared.oidc_logouts.get("sub:" + claims.sub));
+    return loggedOutAt > 0 && Number(claims.iat) < loggedOutAt;
+}
+
+// Logout handler
+async function logout(r) {
+    const current = await readSession(r);
+
+    function getLogoutRedirectUrl(base, redirect) {
+        return redirect.match(/^(http|https):\/\//) ? redirect : base + redirect;
+    }
+
+    const logoutRedirectUrl = getLogoutRedirectUrl(r.variables.redirect_base,
+                              r.variables.oidc_logout_redirect);
+
+    const cookies = clearSessionCookies(r);
+    cookies.push("auth_nonce=; " + r.variables.oidc_cookie_flags);
+    cookies.push("auth_redir=; " + r.variables.oidc_cookie_flags);
+    r.headersOut["Set-Cookie"] = cookies;
+
+    if (r.variables.oidc_end_session_endpoint && current) {
+        r.log("RP-Initiated Logout for " + current.claims.sub);
+        const logoutArgs = "?post_logout_redirect_uri=" + encodeURIComponent(logoutRedirectUrl) +
+                           "&id_token_hint=" + encodeURIComponent(current.id_token);
+        r.return(302, r.variables.oidc_end_session_endpoint + logoutArgs);
+        return;
+    }
+
+    r.return(302, logoutRedirectUrl);
+}
+
+// Derives the AES-256 key used to encrypt the session cookies.
+function sessionKey(r) {
+    const raw = c.createHash('sha256').update(r.variables.oidc_session_key).digest();
+    return crypto.subtle.importKey('raw', raw, 'AES-GCM', false, ['encrypt', 'decrypt']);
+}
+
+// Reads and decrypts the session stored in the session cookies.
+async function readSession(r) {
+    let encoded = "";
+    for (let i = 0; r.variables["cookie_" + SESSION_COOKIE + i]; i++) {
+        encoded += r.variables["cookie_" + SESSION_COOKIE + i];
+    }
+    if (!encoded) {
+        return null;
+    }
+
+    try {
+        const data = Buffer.from(encoded, 'base64url');
+        const plaintext = await crypto.subtle.decrypt({
+            name: 'AES-GCM',
+            iv: data.subarray(0, 12),
+            additionalData: Buffer.from(r.variables.oidc_client)
+        }, await sessionKey(r), data.subarray(12));
+        return JSON.parse(Buffer.from(plaintext).toString());
+    } catch (e) {
+        r.warn("OIDC session cookie could not be decrypted: " + e);
+        return null;
+    }
+}
+
+// Encrypts the session and returns the Set-Cookie values that store it.
+async function writeSession(r, tokenset, claims) {
+    const session = {
+        id_token: tokenset.id_token,
+        access_token: tokenset.access_token || "",
+        access_token_exp: tokenset.expires_in ? Math.floor(Date.now() / 1000) + Number(tokenset.expires_in) : 0,
+        refresh_token: tokenset.refresh_token || "",
+        claims: claims
+    };
+
+    const iv = crypto.getRandomValues(new Uint8Array(12));
+    const ciphertext = await crypto.subtle.encrypt({
+        name: 'AES-GCM',
+        iv: iv,
+        additionalData: Buffer.from(r.variables.oidc_client)
+    }, await sessionKey(r), Buffer.from(JSON.stringify(session)));
+    const encoded = Buffer.concat([Buffer.from(iv), Buffer.from(ciphertext)]).toString('base64url');
+
+    const cookies = [];
+    let i = 0;
+    for (; i * SESSION_CHUNK_SIZE < encoded.length; i++) {
+        const chunk = encoded.substring(i * SESSION_CHUNK_SIZE, (i + 1) * SESSION_CHUNK_SIZE);
+        cookies.push(SESSION_COOKIE + i + "=" + chunk + "; " + r.variables.oidc_cookie_flags);
+    }
+
+    // Remove the chunks left over from a larger previous session
+    return cookies.concat(clearSessionCookies(r, i));
+}
+
+// Returns the Set-Cookie values that remove the session cookies starting from the chunk first.
+function clearSessionCookies(r, first) {
+    const cookies = [];
+    for (let i = first || 0; r.variables["cookie_" + SESSION_COOKIE + i]; i++) {
+        cookies.push(SESSION_COOKIE + i + "=; Max-Age=0; " + r.variables.oidc_cookie_flags);
+    }
+    return cookies;
+}
+
+function sessionExpired(session) {
+    return !session.claims.exp || Math.floor(Date.now() / 1000) >= session.claims.exp;
+}
+
+// Checks if the tokens of a session with a refresh token expire within $oidc_refresh_leeway seconds.
+// The leeway is capped at half the lifetime of the tokens, so that tokens which were just refreshed are never due.
+function refreshDue(r, session) {
+    const leeway = Number(r.variables.oidc_refresh_leeway) || 0;
+    if (!leeway || !session.refresh_token) {
+        return false;
+    }
+
+    const exp = session.access_token_exp && session.access_token_exp < session.claims.exp
+        ? session.access_token_exp
+        : session.claims.exp;
+    const now = Math.floor(Date.now() / 1000);
+    return exp - now <= Math.min(leeway, (exp - session.claims.iat) / 2);
+}
+
+// Returns the value of a claim passed in a request header. Nested claims are separated by '.',
+// and the elements of array claims are separated by commas.
+function claimValue(claims, claim) {
+    let value = claims;
+    const parts = claim.split('.');
+    for (let i = 0; i < parts.length; i++) {
+        if (value === null || typeof value !== 'object') {
+            return "";
+        }
+        value = value[parts[i]];
+    }
+
+    if (value === undefined || value === null) {
+        return "";
+    }
+    if (Array.isArray(value)) {
+        return value.join(',');
+    }
+    if (typeof value === 'object') {
+        return JSON.stringify(value);
+    }
+    return String(value);
+}
+
+// Validates the ID Token as per the OpenID Connect spec, see
+//  https://openid.net/specs/openid-connect-core-1_0.html#IDTokenValidation
+// The signature is verified with the keys of $oidc_jwt_keyfile by the internal /_token_validation endpoint.
+async function validateIdToken(r, idToken, isNewSession) {
+    // The internal endpoint verifies the signature and stores the claims in $jwt_payload
+    const reply = await r.subrequest("/_token_validation", "token=" + encodeURIComponent(idToken));
+    if (reply.status !== 204) {
+        r.error("OIDC ID Token validation error: invalid signature or claims");
+        return null;
+    }
+
+    const claims = JSON.parse(r.variables.jwt_payload);
+
+    const requiredClaims = ["iat", "iss", "sub", "aud", "exp"];
+    const missingClaims = requiredClaims.filter((claim) => !claims[claim]);
+    if (missingClaims.length > 0) {
+        r.error(`OIDC ID Token validation error: missing claim(s) ${missingClaims.join(' ')}`);
+        return null;
+    }
+
+    if (sessionExpired({ claims: claims })) {
+        r.error("OIDC ID Token validation error: token has expired");
+        return null;
+    }
+
+    // Audience must include the configured client
+    const aud = Array.isArray(claims.aud) ? claims.aud : [claims.aud];
+    if (!aud.includes(r.variables.oidc_client)) {
+        r.error(`OIDC ID Token validation error: aud claim (${claims.aud}) ` +
+                `does not include $oidc_client (${r.variables.oidc_client})`);
+        return null;
+    }
+
+    // Nonce validation for initial authentication
+    if (isNewSession) {
+        const clientNonceHash = r.variables.cookie_auth_nonce
+            ? c.createHmac('sha256', r.variables.oidc_hmac_key)
+                  .update(r.variables.cookie_auth_nonce)
+                  .digest('base64url')
+            : '';
+
+        if (!claims.nonce || claims.nonce !== clientNonceHash) {
+            r.error(`OIDC ID Token validation error: nonce from token (${claims.nonce}) ` +
+                    `does not match client (${clientNonceHash})`);
+            return null;
+        }
+    }
+
+    return claims;
+}
+
+// Requests tokens from the IdP using the internal /_token or /_refresh endpoints.
+async function requestTokens(r, uri, params) {
+    const reply = await r.subrequest(uri, params);
+
+    if (reply.status === 504) {
+        r.error("OIDC timeout connecting to IdP");
+        return null;
+    }
+
+    let tokenset;
+    try {
+        tokenset = JSON.parse(reply.responseText);
+    } catch (e) {
+        r.error("OIDC unexpected response from IdP (HTTP " + reply.status + "). " + reply.responseText);
+        return null;
+    }
+
+    if (reply.status !== 200 || tokenset.error) {
+        r.error("OIDC error from IdP (HTTP " + reply.status + "): " +
+                tokenset.error + ", " + tokenset.error_description);
+        return null;
+    }
+    if (!tokenset.id_token) {
+        r.error("OIDC token response did not include id_token");
+        return null;
+    }
+
+    return tokenset;
+}
+
+// Initiate a new authentication flow by redirecting to the IdP's authorization endpoint
+function initiateNewAuth(r) {
+    const oidcConfigurables = ["authz_endpoint", "scopes", "hmac_key", "cookie_flags", "session_key"];
+    const missingConfig = oidcConfigurables.filter(key =>
+        !r.variables["oidc_" + key] || r.variables["oidc_" + key] == ""
+    );
+
+    if (missingConfig.length) {
+        r.error("OIDC missing configuration variables: $oidc_" + missingConfig.join(" $oidc_"));
+        r.return(500, r.variables.internal_error_message);
+        return;
+    }
+
+    const noncePlain = r.variables.request_id;
+    const nonceHash = c.createHmac('sha256', r.variables.oidc_hmac_key).update(noncePlain).digest('base64url');
+
+    let authZArgs = "?response_type=code&scope=" + r.variables.oidc_scopes +
+                    "&client_id=" + r.variables.oidc_client +
+                    "&redirect_uri=" + r.variables.redirect_base + r.variables.redir_location +
+                    "&nonce=" + nonceHash + "&state=0";
+
+    if (r.variables.oidc_authz_extra_args) {
+        authZArgs += "&" + r.variables.oidc_authz_extra_args;
+    }
+
+    // Keep the cookies set by auth(), if any
+    const cookies = [].concat(r.headersOut["Set-Cookie"] || []);
+    cookies.push("auth_redir=" + encodeURIComponent(r.variables.request_uri) + "; " + r.variables.oidc_cookie_flags);
+    cookies.push("auth_nonce=" + noncePlain + "; " + r.variables.oidc_cookie_flags);
+    r.headersOut["Set-Cookie"] = cookies;
+
+    // Redirect to IdP authorization endpoint with the cookies set for state and nonce
+    r.return(302, r.variables.oidc_authz_endpoint + authZArgs);
+}
+
+// Generate the token request parameters
+function generateTokenRequestParams(r, grantType, refreshToken) {
+    let body = "grant_type=" + grantType + "&client_id=" + r.variables.oidc_client;
+
+    if (grantType === "authorization_code") {
+        body += "&code=" + r.variables.arg_code +
+                "&redirect_uri=" + r.variables.redirect_base + r.variables.redir_location;
+    } else {
+        body += "&refresh_token=" + refreshToken;
+    }
+
+    const options = {
+        body: body,
+        method: "POST"
+    };
+
+    if (r.variables.oidc_client_auth_method === "client_secret_basic") {
+        const authBasic = "Basic " + Buffer.from(r.variables.oidc_client + ":" +
+                          r.variables.oidc_client_secret).toString('base64');
+        options.args = "secret_basic=" + authBasic;
+    } else {
+        options.body += "&client_secret=" + r.variables.oidc_client_secret;
+    }
+
+    return options;
+}
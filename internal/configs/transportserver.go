@@ -2,6 +2,7 @@ package configs
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -23,8 +24,11 @@ type TransportServerEx struct {
 	ExternalNameSvcs map[string]bool
 	DisableIPV6      bool
 	SecretRefs       map[string]*secrets.SecretReference
+	Policies         map[string]*conf_v1.Policy
 	IPv4             string
 	IPv6             string
+	ProxyProtocol    bool
+	SetRealIPFrom    []string
 }
 
 func (tsEx *TransportServerEx) String() string {
@@ -43,6 +47,63 @@ func newUpstreamNamerForTransportServer(transportServer *conf_v1.TransportServer
 	}
 }
 
+// TransportServerVariableNamer is a namer which generates unique variable names for a TransportServer.
+type TransportServerVariableNamer struct {
+	safeNsName string
+}
+
+// NewTSVariableNamer creates a new namer for a TransportServer.
+func NewTSVariableNamer(transportServer *conf_v1.TransportServer) *TransportServerVariableNamer {
+	return &TransportServerVariableNamer{
+		safeNsName: strings.ReplaceAll(fmt.Sprintf("%s_%s", transportServer.Namespace, transportServer.Name), "-", "_"),
+	}
+}
+
+// GetNameForMatchConditionVariable gets the name of the variable of the map of a condition of a match.
+func (namer *TransportServerVariableNamer) GetNameForMatchConditionVariable(matchIndex int, conditionIndex int) string {
+	return fmt.Sprintf("$ts_%s_match_%d_cond_%d", namer.safeNsName, matchIndex, conditionIndex)
+}
+
+// GetNameForMatchesVariable gets the name of the variable of the map that selects the upstream for the matches.
+func (namer *TransportServerVariableNamer) GetNameForMatchesVariable() string {
+	return fmt.Sprintf("$ts_%s_matches", namer.safeNsName)
+}
+
+// GetNameForSplitClientVariable gets the name of a split client variable for a particular scIndex.
+func (namer *TransportServerVariableNamer) GetNameForSplitClientVariable(index int) string {
+	return fmt.Sprintf("$ts_%s_splits_%d", namer.safeNsName, index)
+}
+
+// GetNameOfKeyvalZoneForSplitClientIndex returns a unique name for a keyval zone for split clients.
+func (namer *TransportServerVariableNamer) GetNameOfKeyvalZoneForSplitClientIndex(index int) string {
+	return fmt.Sprintf("ts_%s_keyval_zone_split_clients_%d", namer.safeNsName, index)
+}
+
+// GetNameOfKeyvalForSplitClientIndex returns a unique name for a keyval for split clients.
+func (namer *TransportServerVariableNamer) GetNameOfKeyvalForSplitClientIndex(index int) string {
+	return fmt.Sprintf("$ts_%s_keyval_split_clients_%d", namer.safeNsName, index)
+}
+
+// GetNameOfKeyvalKeyForSplitClientIndex returns a unique name for a keyval key for split clients.
+func (namer *TransportServerVariableNamer) GetNameOfKeyvalKeyForSplitClientIndex(index int) string {
+	return fmt.Sprintf("\"ts_%s_keyval_key_split_clients_%d\"", namer.safeNsName, index)
+}
+
+// GetNameOfMapForSplitClientIndex returns a unique name for a map for split clients.
+func (namer *TransportServerVariableNamer) GetNameOfMapForSplitClientIndex(index int) string {
+	return fmt.Sprintf("$ts_%s_map_split_clients_%d", namer.safeNsName, index)
+}
+
+// GetNameOfKeyOfMapForWeights returns a unique name for a key of a map for split clients.
+func (namer *TransportServerVariableNamer) GetNameOfKeyOfMapForWeights(index int, i int, j int) string {
+	return fmt.Sprintf("\"ts_%s_split_clients_%d_%d_%d\"", namer.safeNsName, index, i, j)
+}
+
+// GetNameOfSplitClientsForWeights gets the name of the split clients for a particular combination of weights and scIndex.
+func (namer *TransportServerVariableNamer) GetNameOfSplitClientsForWeights(index int, i int, j int) string {
+	return fmt.Sprintf("$ts_%s_split_clients_%d_%d_%d", namer.safeNsName, index, i, j)
+}
+
 type transportServerConfigParams struct {
 	transportServerEx      *TransportServerEx
 	listenerPort           int
@@ -50,6 +111,8 @@ type transportServerConfigParams struct {
 	isResolverConfigured   bool
 	isDynamicReloadEnabled bool
 	staticSSLPath          string
+
+	weightChangesDynamicReload bool
 }
 
 // generateTransportServerConfig generates a full configuration for a TransportServer.
@@ -68,9 +131,12 @@ func generateTransportServerConfig(p transportServerConfigParams) (*version2.Tra
 	sslConfig, w := generateSSLConfig(p.transportServerEx.TransportServer, p.transportServerEx.TransportServer.Spec.TLS, p.transportServerEx.TransportServer.Namespace, p.transportServerEx.SecretRefs)
 	warnings.Add(w)
 
+	proxySSL, proxySSLInvalid, w := generateStreamProxySSL(p.transportServerEx)
+	warnings.Add(w)
+
 	var proxyRequests, proxyResponses *int
 	var connectTimeout, nextUpstreamTimeout string
-	var nextUpstream bool
+	var nextUpstream, upstreamProxyProtocol bool
 	var nextUpstreamTries int
 	if p.transportServerEx.TransportServer.Spec.UpstreamParameters != nil {
 		proxyRequests = p.transportServerEx.TransportServer.Spec.UpstreamParameters.UDPRequests
@@ -83,6 +149,7 @@ func generateTransportServerConfig(p transportServerConfigParams) (*version2.Tra
 		}
 
 		connectTimeout = p.transportServerEx.TransportServer.Spec.UpstreamParameters.ConnectTimeout
+		upstreamProxyProtocol = p.transportServerEx.TransportServer.Spec.UpstreamParameters.ProxyProtocol
 	}
 
 	var proxyTimeout string
@@ -90,6 +157,9 @@ func generateTransportServerConfig(p transportServerConfigParams) (*version2.Tra
 		proxyTimeout = p.transportServerEx.TransportServer.Spec.SessionParameters.Timeout
 	}
 
+	policiesCfg, w := generateTransportServerPolicies(p.transportServerEx)
+	warnings.Add(w)
+
 	serverSnippets := generateSnippets(true, p.transportServerEx.TransportServer.Spec.ServerSnippets, []string{})
 
 	streamSnippets := generateSnippets(true, p.transportServerEx.TransportServer.Spec.StreamSnippets, []string{})
@@ -103,6 +173,24 @@ func generateTransportServerConfig(p transportServerConfigParams) (*version2.Tra
 	serverName := generateServerName(host, isTLSPassthrough)
 	isUDP := p.transportServerEx.TransportServer.Spec.Listener.Protocol == "UDP"
 
+	variableNamer := NewTSVariableNamer(p.transportServerEx.TransportServer)
+	splitsCfg := &transportServerSplitsConfig{}
+
+	var matchPasses []string
+	for _, m := range p.transportServerEx.TransportServer.Spec.Matches {
+		matchPasses = append(matchPasses, generateTransportServerPass(m.Action, upstreamNamer, variableNamer, p.weightChangesDynamicReload, splitsCfg))
+	}
+	proxyPass := generateTransportServerPass(p.transportServerEx.TransportServer.Spec.Action, upstreamNamer, variableNamer, p.weightChangesDynamicReload, splitsCfg)
+
+	maps := splitsCfg.maps
+	var geos []version2.Geo
+	var sslPreread bool
+	if len(p.transportServerEx.TransportServer.Spec.Matches) > 0 {
+		var matchMaps []version2.Map
+		proxyPass, matchMaps, geos, sslPreread = generateTransportServerMatches(p.transportServerEx.TransportServer, variableNamer, matchPasses, proxyPass)
+		maps = append(maps, matchMaps...)
+	}
+
 	tsConfig := &version2.TransportServerConfig{
 		Server: version2.StreamServer{
 			ServerName:               serverName,
@@ -113,7 +201,7 @@ func generateTransportServerConfig(p transportServerConfigParams) (*version2.Tra
 			StatusZone:               statusZone,
 			ProxyRequests:            proxyRequests,
 			ProxyResponses:           proxyResponses,
-			ProxyPass:                upstreamNamer.GetNameForUpstream(p.transportServerEx.TransportServer.Spec.Action.Pass),
+			ProxyPass:                proxyPass,
 			Name:                     p.transportServerEx.TransportServer.Name,
 			Namespace:                p.transportServerEx.TransportServer.Namespace,
 			ProxyConnectTimeout:      generateTimeWithDefault(connectTimeout, "60s"),
@@ -127,8 +215,25 @@ func generateTransportServerConfig(p transportServerConfigParams) (*version2.Tra
 			SSL:                      sslConfig,
 			IPv4:                     p.transportServerEx.IPv4,
 			IPv6:                     p.transportServerEx.IPv6,
+			ProxyProtocol:            p.transportServerEx.ProxyProtocol,
+			SetRealIPFrom:            p.transportServerEx.SetRealIPFrom,
+			UpstreamProxyProtocol:    upstreamProxyProtocol,
+			Allow:                    policiesCfg.Allow,
+			Deny:                     policiesCfg.Deny,
+			DenyAll:                  policiesCfg.DenyAll || proxySSLInvalid,
+			IngressMTLS:              policiesCfg.IngressMTLS,
+			LimitConn:                policiesCfg.LimitConn,
+			SSLPreread:               sslPreread,
+			ProxySSL:                 proxySSL,
 		},
 		Match:                   match,
+		LimitConnZones:          policiesCfg.LimitConnZones,
+		Maps:                    maps,
+		Geos:                    geos,
+		SplitClients:            splitsCfg.splitClients,
+		KeyValZones:             splitsCfg.keyValZones,
+		KeyVals:                 splitsCfg.keyVals,
+		TwoWaySplitClients:      splitsCfg.twoWaySplitClients,
 		Upstreams:               upstreams,
 		StreamSnippets:          streamSnippets,
 		DynamicSSLReloadEnabled: p.isDynamicReloadEnabled,
@@ -137,6 +242,316 @@ func generateTransportServerConfig(p transportServerConfigParams) (*version2.Tra
 	return tsConfig, warnings
 }
 
+// generateTransportServerMatches generates the maps that select the upstream of a connection according to the matches
+// of a TransportServer, the same way as the matches of a VirtualServer route, and returns the variable with the name
+// of the selected upstream. It also reports whether the server name or the ALPN protocols of the ClientHello are used.
+// matchPasses and defaultPass are the values of proxy_pass for the actions of the matches and the default action.
+func generateTransportServerMatches(ts *conf_v1.TransportServer, variableNamer *TransportServerVariableNamer, matchPasses []string, defaultPass string) (string, []version2.Map, []version2.Geo, bool) {
+	var maps []version2.Map
+	var geos []version2.Geo
+	sslPreread := false
+
+	source := ""
+	var params []version2.Parameter
+	for i, m := range ts.Spec.Matches {
+		for j, c := range m.Conditions {
+			variable := variableNamer.GetNameForMatchConditionVariable(i, j)
+			successfulResult := "1"
+			if j < len(m.Conditions)-1 {
+				successfulResult = variableNamer.GetNameForMatchConditionVariable(i, j+1)
+			}
+
+			matchMap, geo := generateTransportServerConditionConfig(c, variable, successfulResult)
+			maps = append(maps, matchMap)
+			if geo != nil {
+				geos = append(geos, *geo)
+			}
+			if c.ServerName != "" || c.ALPN != "" {
+				sslPreread = true
+			}
+		}
+
+		source += variableNamer.GetNameForMatchConditionVariable(i, 0)
+		params = append(params, version2.Parameter{
+			Value:  fmt.Sprintf("~^%s1", strings.Repeat("0", i)),
+			Result: matchPasses[i],
+		})
+	}
+	params = append(params, version2.Parameter{
+		Value:  "default",
+		Result: defaultPass,
+	})
+
+	variable := variableNamer.GetNameForMatchesVariable()
+	maps = append(maps, version2.Map{
+		Source:     source,
+		Variable:   variable,
+		Parameters: params,
+	})
+
+	return variable, maps, geos, sslPreread
+}
+
+// transportServerSplitsConfig holds the configuration generated for the splits of the actions of a TransportServer.
+type transportServerSplitsConfig struct {
+	scIndex            int
+	splitClients       []version2.SplitClient
+	maps               []version2.Map
+	keyValZones        []version2.KeyValZone
+	keyVals            []version2.KeyVal
+	twoWaySplitClients []version2.TwoWaySplitClients
+}
+
+// generateTransportServerPass returns the value of proxy_pass for an action: the name of the upstream or, for splits,
+// the variable with the name of the upstream selected by the split clients, whose configuration is added to splitsCfg.
+func generateTransportServerPass(action *conf_v1.TransportServerAction, upstreamNamer *upstreamNamer, variableNamer *TransportServerVariableNamer,
+	weightChangesDynamicReload bool, splitsCfg *transportServerSplitsConfig,
+) string {
+	if len(action.Splits) == 0 {
+		return upstreamNamer.GetNameForUpstream(action.Pass)
+	}
+
+	scIndex := splitsCfg.scIndex
+	// the connections are distributed by the client address, so that the datagrams of a UDP session go to the same upstream
+	source := "$remote_addr"
+
+	if weightChangesDynamicReload && len(action.Splits) == 2 {
+		scs, weightMap := generateTransportServerSplitsForWeightChangesDynamicReload(action.Splits, scIndex, source, upstreamNamer, variableNamer)
+		kvZoneName := variableNamer.GetNameOfKeyvalZoneForSplitClientIndex(scIndex)
+		splitsCfg.keyValZones = append(splitsCfg.keyValZones, version2.KeyValZone{
+			Name:  kvZoneName,
+			Size:  splitClientsKeyValZoneSize,
+			State: fmt.Sprintf("%s/%s.json", keyvalZoneBasePath, kvZoneName),
+		})
+		splitsCfg.keyVals = append(splitsCfg.keyVals, version2.KeyVal{
+			Key:      variableNamer.GetNameOfKeyvalKeyForSplitClientIndex(scIndex),
+			Variable: variableNamer.GetNameOfKeyvalForSplitClientIndex(scIndex),
+			ZoneName: kvZoneName,
+		})
+		splitsCfg.twoWaySplitClients = append(splitsCfg.twoWaySplitClients, version2.TwoWaySplitClients{
+			Key:               variableNamer.GetNameOfKeyvalKeyForSplitClientIndex(scIndex),
+			Variable:          variableNamer.GetNameOfKeyvalForSplitClientIndex(scIndex),
+			ZoneName:          kvZoneName,
+			Weights:           []int{action.Splits[0].Weight, action.Splits[1].Weight},
+			SplitClientsIndex: scIndex,
+		})
+		splitsCfg.splitClients = append(splitsCfg.splitClients, scs...)
+		splitsCfg.maps = append(splitsCfg.maps, weightMap)
+		splitsCfg.scIndex += splitClientAmountWhenWeightChangesDynamicReload
+
+		return variableNamer.GetNameOfMapForSplitClientIndex(scIndex)
+	}
+
+	var distributions []version2.Distribution
+	for _, s := range action.Splits {
+		if s.Weight == 0 {
+			continue
+		}
+		distributions = append(distributions, version2.Distribution{
+			Weight: fmt.Sprintf("%d%%", s.Weight),
+			Value:  upstreamNamer.GetNameForUpstream(s.Pass),
+		})
+	}
+
+	variable := variableNamer.GetNameForSplitClientVariable(scIndex)
+	splitsCfg.splitClients = append(splitsCfg.splitClients, version2.SplitClient{
+		Source:        source,
+		Variable:      variable,
+		Distributions: distributions,
+	})
+	splitsCfg.scIndex++
+
+	return variable
+}
+
+// generateTransportServerSplitsForWeightChangesDynamicReload generates the split clients for all combinations of the weights
+// of two splits and the map that selects the split clients by the weights stored in a keyval.
+func generateTransportServerSplitsForWeightChangesDynamicReload(splits []conf_v1.TransportServerSplit, scIndex int, source string,
+	upstreamNamer *upstreamNamer, variableNamer *TransportServerVariableNamer,
+) ([]version2.SplitClient, version2.Map) {
+	var splitClients []version2.SplitClient
+	var mapParameters []version2.Parameter
+	for i := 0; i <= 100; i++ {
+		j := 100 - i
+		var distributions []version2.Distribution
+		if i > 0 {
+			distributions = append(distributions, version2.Distribution{
+				Weight: fmt.Sprintf("%d%%", i),
+				Value:  upstreamNamer.GetNameForUpstream(splits[0].Pass),
+			})
+		}
+		if j > 0 {
+			distributions = append(distributions, version2.Distribution{
+				Weight: fmt.Sprintf("%d%%", j),
+				Value:  upstreamNamer.GetNameForUpstream(splits[1].Pass),
+			})
+		}
+		splitClients = append(splitClients, version2.SplitClient{
+			Source:        source,
+			Variable:      variableNamer.GetNameOfSplitClientsForWeights(scIndex, i, j),
+			Distributions: distributions,
+		})
+		mapParameters = append(mapParameters, version2.Parameter{
+			Value:  variableNamer.GetNameOfKeyOfMapForWeights(scIndex, i, j),
+			Result: variableNamer.GetNameOfSplitClientsForWeights(scIndex, i, j),
+		})
+	}
+
+	result := variableNamer.GetNameOfSplitClientsForWeights(scIndex, 100, 0)
+	if splits[0].Weight < splits[1].Weight {
+		result = variableNamer.GetNameOfSplitClientsForWeights(scIndex, 0, 100)
+	}
+	mapParameters = append(mapParameters, version2.Parameter{Value: "default", Result: result})
+
+	return splitClients, version2.Map{
+		Source:     variableNamer.GetNameOfKeyvalForSplitClientIndex(scIndex),
+		Variable:   variableNamer.GetNameOfMapForSplitClientIndex(scIndex),
+		Parameters: mapParameters,
+	}
+}
+
+// generateTransportServerConditionConfig generates the map that sets the variable to successfulResult when the
+// condition is satisfied. For a condition on the client IP address, it also generates the geo block the map is based on.
+func generateTransportServerConditionConfig(condition conf_v1.TransportServerCondition, variable string, successfulResult string) (version2.Map, *version2.Geo) {
+	var source, value string
+	var geo *version2.Geo
+
+	switch {
+	case condition.ServerName != "":
+		source = "$ssl_preread_server_name"
+		value = generateValueForServerNameCondition(condition.ServerName)
+	case condition.ALPN != "":
+		// the variable holds the comma-separated list of the protocols offered by the client
+		source = "$ssl_preread_alpn_protocols"
+		value = fmt.Sprintf(`"~(^|,)%s(,|$)"`, regexp.QuoteMeta(condition.ALPN))
+	default:
+		geo = &version2.Geo{
+			Source:   "$remote_addr",
+			Variable: fmt.Sprintf("%s_geo", variable),
+			Parameters: []version2.Parameter{
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		}
+		for _, cidr := range condition.SourceCIDRs {
+			geo.Parameters = append(geo.Parameters, version2.Parameter{
+				Value:  cidr,
+				Result: "1",
+			})
+		}
+		source = geo.Variable
+		value = `"1"`
+	}
+
+	return version2.Map{
+		Source:   source,
+		Variable: variable,
+		Parameters: []version2.Parameter{
+			{
+				Value:  value,
+				Result: successfulResult,
+			},
+			{
+				Value:  "default",
+				Result: "0",
+			},
+		},
+	}, geo
+}
+
+// generateValueForServerNameCondition generates the map value for a server name, which can be a wildcard like *.example.com.
+func generateValueForServerNameCondition(serverName string) string {
+	if suffix, ok := strings.CutPrefix(serverName, "*"); ok {
+		return fmt.Sprintf(`"~^.+%s$"`, regexp.QuoteMeta(suffix))
+	}
+	return fmt.Sprintf(`"%s"`, serverName)
+}
+
+// streamPoliciesCfg holds the configuration generated from the policies of a TransportServer.
+type streamPoliciesCfg struct {
+	LimitConnZones []version2.LimitConnZone
+	LimitConn      *version2.LimitConn
+	Allow          []string
+	Deny           []string
+	IngressMTLS    *version2.IngressMTLS
+	DenyAll        bool
+}
+
+// generateTransportServerPolicies generates the configuration for the policies referenced by a TransportServer.
+// Only the accessControl, ingressMTLS and connectionLimit policies are supported in the stream context,
+// other policies are ignored with a warning. A missing or invalid policy denies all connections.
+func generateTransportServerPolicies(transportServerEx *TransportServerEx) (streamPoliciesCfg, Warnings) {
+	warnings := newWarnings()
+	var cfg streamPoliciesCfg
+
+	// accessControl and ingressMTLS are generated the same way as for VirtualServers.
+	httpCfg := newPoliciesConfig(nil)
+
+	ts := transportServerEx.TransportServer
+	for _, p := range ts.Spec.Policies {
+		polNamespace := p.Namespace
+		if polNamespace == "" {
+			polNamespace = ts.Namespace
+		}
+
+		key := fmt.Sprintf("%s/%s", polNamespace, p.Name)
+
+		pol, exists := transportServerEx.Policies[key]
+		if !exists {
+			warnings.AddWarningf(ts, "Policy %s is missing or invalid", key)
+			return streamPoliciesCfg{DenyAll: true}, warnings
+		}
+
+		var res *validationResults
+		switch {
+		case pol.Spec.AccessControl != nil:
+			res = httpCfg.addAccessControlConfig(pol.Spec.AccessControl)
+		case pol.Spec.IngressMTLS != nil:
+			if ts.Spec.TLS == nil {
+				res = newValidationResults()
+				res.addWarningf("TLS must be enabled in TransportServer for IngressMTLS policy %s", key)
+				res.isError = true
+				break
+			}
+			res = httpCfg.addIngressMTLSConfig(pol.Spec.IngressMTLS, key, polNamespace, specContext, true, transportServerEx.SecretRefs)
+			if pol.Spec.IngressMTLS.ForwardCertificate != nil {
+				res.addWarningf("forwardCertificate of IngressMTLS policy %s is not supported in TransportServers and will be ignored", key)
+			}
+			if pol.Spec.IngressMTLS.OCSP != nil {
+				res.addWarningf("ocsp of IngressMTLS policy %s is not supported in TransportServers and will be ignored", key)
+			}
+		case pol.Spec.ConnectionLimit != nil:
+			if cfg.LimitConn != nil {
+				warnings.AddWarningf(ts, "Multiple connectionLimit policies in the same context is not valid. ConnectionLimit policy %s will be ignored", key)
+				continue
+			}
+			zoneName := rfc1123ToSnake(fmt.Sprintf("pol_cl_%v_%v_%v_%v", pol.Namespace, pol.Name, ts.Namespace, ts.Name))
+			cfg.LimitConnZones = append(cfg.LimitConnZones, generateLimitConnZone(zoneName, pol.Spec.ConnectionLimit))
+			limitConn := generateLimitConn(zoneName, pol.Spec.ConnectionLimit, 0)
+			cfg.LimitConn = &limitConn
+			continue
+		default:
+			warnings.AddWarningf(ts, "Policy %s is not supported in TransportServers and will be ignored", key)
+			continue
+		}
+
+		for _, w := range res.warnings {
+			warnings.AddWarning(ts, w)
+		}
+		if res.isError {
+			return streamPoliciesCfg{DenyAll: true}, warnings
+		}
+	}
+
+	cfg.Allow = httpCfg.Allow
+	cfg.Deny = httpCfg.Deny
+	cfg.IngressMTLS = httpCfg.IngressMTLS
+
+	return cfg, warnings
+}
+
 func generateUnixSocket(transportServerEx *TransportServerEx) string {
 	if transportServerEx.TransportServer.Spec.Listener.Name == conf_v1.TLSPassthroughListenerName {
 		return fmt.Sprintf("unix:/var/lib/nginx/passthrough-%s_%s.sock", transportServerEx.TransportServer.Namespace, transportServerEx.TransportServer.Name)
@@ -177,6 +592,87 @@ func generateSSLConfig(ts *conf_v1.TransportServer, tls *conf_v1.TransportServer
 	return &ssl, warnings
 }
 
+// generateStreamProxySSL generates the TLS configuration for the connections to the upstream servers. It is taken from
+// the upstream of the default action: validateTransportServerUpstreamsTLS in the validation package rejects matches or
+// splits that pass to upstreams with a different TLS configuration.
+// It also reports whether a secret is invalid, in which case the connections must be rejected rather than sent in plaintext.
+func generateStreamProxySSL(transportServerEx *TransportServerEx) (*version2.StreamProxySSL, bool, Warnings) {
+	ts := transportServerEx.TransportServer
+	warnings := newWarnings()
+
+	pass := ts.Spec.Action.Pass
+	if len(ts.Spec.Action.Splits) > 0 {
+		pass = ts.Spec.Action.Splits[0].Pass
+	}
+
+	var upstream *conf_v1.TransportServerUpstream
+	for i := range ts.Spec.Upstreams {
+		if ts.Spec.Upstreams[i].Name == pass {
+			upstream = &ts.Spec.Upstreams[i]
+			break
+		}
+	}
+	if upstream == nil || upstream.TLS == nil || !upstream.TLS.Enable {
+		return nil, false, warnings
+	}
+	tls := upstream.TLS
+
+	var tlsSecretPath string
+	if tls.TLSSecret != "" {
+		path, err := getUpstreamTLSSecretPath(transportServerEx.SecretRefs, ts.Namespace, tls.TLSSecret, api_v1.SecretTypeTLS)
+		if err != nil {
+			warnings.AddWarningf(ts, "TLS of upstream %s: %v. Connections will be rejected.", upstream.Name, err)
+			return nil, true, warnings
+		}
+		tlsSecretPath = path
+	}
+
+	var trustedSecretPath string
+	if tls.TrustedCertSecret != "" {
+		path, err := getUpstreamTLSSecretPath(transportServerEx.SecretRefs, ts.Namespace, tls.TrustedCertSecret, secrets.SecretTypeCA)
+		if err != nil {
+			warnings.AddWarningf(ts, "TLS of upstream %s: %v. Connections will be rejected.", upstream.Name, err)
+			return nil, true, warnings
+		}
+		if caFields := strings.Fields(path); len(caFields) > 0 {
+			trustedSecretPath = caFields[0]
+		}
+	}
+
+	return &version2.StreamProxySSL{
+		Certificate:    tlsSecretPath,
+		CertificateKey: tlsSecretPath,
+		TrustedCert:    trustedSecretPath,
+		VerifyServer:   tls.VerifyServer,
+		VerifyDepth:    generateIntFromPointer(tls.VerifyDepth, 1),
+		ServerName:     tls.ServerName,
+		SSLName:        generateString(tls.SSLName, fmt.Sprintf("%s.%s.svc", upstream.Service, ts.Namespace)),
+	}, false, warnings
+}
+
+// getUpstreamTLSSecretPath returns the path of a secret referenced by the TLS of an upstream.
+func getUpstreamTLSSecretPath(secretRefs map[string]*secrets.SecretReference, namespace string, name string, expectedType api_v1.SecretType) (string, error) {
+	key := fmt.Sprintf("%s/%s", namespace, name)
+
+	secretRef, exists := secretRefs[key]
+	if !exists {
+		return "", fmt.Errorf("secret %s is missing", key)
+	}
+
+	var secretType api_v1.SecretType
+	if secretRef.Secret != nil {
+		secretType = secretRef.Secret.Type
+	}
+	if secretType != "" && secretType != expectedType {
+		return "", fmt.Errorf("secret %s is of a wrong type '%s', must be '%s'", key, secretType, expectedType)
+	}
+	if secretRef.Error != nil {
+		return "", fmt.Errorf("secret %s is invalid: %w", key, secretRef.Error)
+	}
+
+	return secretRef.Path, nil
+}
+
 func generateStreamUpstreams(transportServerEx *TransportServerEx, upstreamNamer *upstreamNamer, isPlus bool, isResolverConfigured bool) ([]version2.StreamUpstream, Warnings) {
 	warnings := newWarnings()
 	var upstreams []version2.StreamUpstream
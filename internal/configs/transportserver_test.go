@@ -353,6 +353,595 @@ func TestGenerateTransportServerConfigForTCP(t *testing.T) {
 	}
 }
 
+func TestGenerateTransportServerConfigForTCPWithProxyProtocol(t *testing.T) {
+	t.Parallel()
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1.TransportServerSpec{
+				Listener: conf_v1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1.TransportServerUpstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+				},
+				UpstreamParameters: &conf_v1.UpstreamParameters{
+					ProxyProtocol: true,
+				},
+				Action: &conf_v1.TransportServerAction{
+					Pass: "tcp-app",
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tcp-app-svc:5001": {
+				"10.0.0.20:5001",
+			},
+		},
+		ProxyProtocol: true,
+		SetRealIPFrom: []string{"192.168.0.0/16"},
+	}
+
+	listenerPort := 2020
+
+	expected := &version2.TransportServerConfig{
+		Upstreams: []version2.StreamUpstream{
+			{
+				Name: "ts_default_tcp-server_tcp-app",
+				Servers: []version2.StreamUpstreamServer{
+					{
+						Address:     "10.0.0.20:5001",
+						MaxFails:    1,
+						FailTimeout: "10s",
+					},
+				},
+				UpstreamLabels: version2.UpstreamLabels{
+					ResourceName:      "tcp-server",
+					ResourceType:      "transportserver",
+					ResourceNamespace: "default",
+					Service:           "tcp-app-svc",
+				},
+				LoadBalancingMethod: "random two least_conn",
+			},
+		},
+		Server: version2.StreamServer{
+			Port:                     2020,
+			UDP:                      false,
+			StatusZone:               "tcp-listener",
+			ProxyPass:                "ts_default_tcp-server_tcp-app",
+			Name:                     "tcp-server",
+			Namespace:                "default",
+			ProxyConnectTimeout:      "60s",
+			ProxyNextUpstream:        false,
+			ProxyNextUpstreamTries:   0,
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyTimeout:             "10m",
+			HealthCheck:              nil,
+			ServerSnippets:           []string{},
+			SSL:                      &version2.StreamSSL{},
+			ProxyProtocol:            true,
+			SetRealIPFrom:            []string{"192.168.0.0/16"},
+			UpstreamProxyProtocol:    true,
+		},
+		StreamSnippets: []string{},
+		StaticSSLPath:  "/etc/nginx/secret",
+	}
+
+	result, warnings := generateTransportServerConfig(transportServerConfigParams{
+		transportServerEx:      &transportServerEx,
+		listenerPort:           listenerPort,
+		isPlus:                 true,
+		isResolverConfigured:   false,
+		isDynamicReloadEnabled: false,
+		staticSSLPath:          "/etc/nginx/secret",
+	})
+	if len(warnings) != 0 {
+		t.Errorf("want no warnings, got %v", warnings)
+	}
+	if !cmp.Equal(expected, result) {
+		t.Errorf("generateTransportServerConfig() mismatch (-want +got):\n%s", cmp.Diff(expected, result))
+	}
+}
+
+func TestGenerateTransportServerConfigForTCPWithMatches(t *testing.T) {
+	t.Parallel()
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1.TransportServerSpec{
+				Listener: conf_v1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1.TransportServerUpstream{
+					{
+						Name:    "tcp-app",
+						Service: "tcp-app-svc",
+						Port:    5001,
+					},
+					{
+						Name:    "postgres",
+						Service: "postgres-svc",
+						Port:    5432,
+					},
+				},
+				Matches: []conf_v1.TransportServerConnectionMatch{
+					{
+						Conditions: []conf_v1.TransportServerCondition{
+							{
+								ServerName: "db.example.com",
+							},
+							{
+								SourceCIDRs: []string{"10.0.0.0/8"},
+							},
+						},
+						Action: &conf_v1.TransportServerAction{
+							Pass: "postgres",
+						},
+					},
+					{
+						Conditions: []conf_v1.TransportServerCondition{
+							{
+								ALPN: "postgresql",
+							},
+						},
+						Action: &conf_v1.TransportServerAction{
+							Pass: "postgres",
+						},
+					},
+				},
+				Action: &conf_v1.TransportServerAction{
+					Pass: "tcp-app",
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tcp-app-svc:5001": {
+				"10.0.0.20:5001",
+			},
+			"default/postgres-svc:5432": {
+				"10.0.0.30:5432",
+			},
+		},
+	}
+
+	listenerPort := 2020
+
+	expected := &version2.TransportServerConfig{
+		Upstreams: []version2.StreamUpstream{
+			{
+				Name: "ts_default_tcp-server_postgres",
+				Servers: []version2.StreamUpstreamServer{
+					{
+						Address:     "10.0.0.30:5432",
+						MaxFails:    1,
+						FailTimeout: "10s",
+					},
+				},
+				UpstreamLabels: version2.UpstreamLabels{
+					ResourceName:      "tcp-server",
+					ResourceType:      "transportserver",
+					ResourceNamespace: "default",
+					Service:           "postgres-svc",
+				},
+				LoadBalancingMethod: "random two least_conn",
+			},
+			{
+				Name: "ts_default_tcp-server_tcp-app",
+				Servers: []version2.StreamUpstreamServer{
+					{
+						Address:     "10.0.0.20:5001",
+						MaxFails:    1,
+						FailTimeout: "10s",
+					},
+				},
+				UpstreamLabels: version2.UpstreamLabels{
+					ResourceName:      "tcp-server",
+					ResourceType:      "transportserver",
+					ResourceNamespace: "default",
+					Service:           "tcp-app-svc",
+				},
+				LoadBalancingMethod: "random two least_conn",
+			},
+		},
+		Server: version2.StreamServer{
+			Port:                     2020,
+			UDP:                      false,
+			StatusZone:               "tcp-listener",
+			ProxyPass:                "$ts_default_tcp_server_matches",
+			Name:                     "tcp-server",
+			Namespace:                "default",
+			ProxyConnectTimeout:      "60s",
+			ProxyNextUpstream:        false,
+			ProxyNextUpstreamTries:   0,
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyTimeout:             "10m",
+			HealthCheck:              nil,
+			ServerSnippets:           []string{},
+			SSL:                      &version2.StreamSSL{},
+			SSLPreread:               true,
+		},
+		Maps: []version2.Map{
+			{
+				Source:   "$ssl_preread_server_name",
+				Variable: "$ts_default_tcp_server_match_0_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"db.example.com"`,
+						Result: "$ts_default_tcp_server_match_0_cond_1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$ts_default_tcp_server_match_0_cond_1_geo",
+				Variable: "$ts_default_tcp_server_match_0_cond_1",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"1"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$ssl_preread_alpn_protocols",
+				Variable: "$ts_default_tcp_server_match_1_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"~(^|,)postgresql(,|$)"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$ts_default_tcp_server_match_0_cond_0$ts_default_tcp_server_match_1_cond_0",
+				Variable: "$ts_default_tcp_server_matches",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "~^1",
+						Result: "ts_default_tcp-server_postgres",
+					},
+					{
+						Value:  "~^01",
+						Result: "ts_default_tcp-server_postgres",
+					},
+					{
+						Value:  "default",
+						Result: "ts_default_tcp-server_tcp-app",
+					},
+				},
+			},
+		},
+		Geos: []version2.Geo{
+			{
+				Source:   "$remote_addr",
+				Variable: "$ts_default_tcp_server_match_0_cond_1_geo",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "default",
+						Result: "0",
+					},
+					{
+						Value:  "10.0.0.0/8",
+						Result: "1",
+					},
+				},
+			},
+		},
+		StreamSnippets: []string{},
+		StaticSSLPath:  "/etc/nginx/secret",
+	}
+
+	result, warnings := generateTransportServerConfig(transportServerConfigParams{
+		transportServerEx:      &transportServerEx,
+		listenerPort:           listenerPort,
+		isPlus:                 true,
+		isResolverConfigured:   false,
+		isDynamicReloadEnabled: false,
+		staticSSLPath:          "/etc/nginx/secret",
+	})
+	if len(warnings) != 0 {
+		t.Errorf("want no warnings, got %v", warnings)
+	}
+	if !cmp.Equal(expected, result) {
+		t.Errorf("generateTransportServerConfig() mismatch (-want +got):\n%s", cmp.Diff(expected, result))
+	}
+}
+
+func TestGenerateTransportServerConfigForTCPWithSplits(t *testing.T) {
+	t.Parallel()
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1.TransportServerSpec{
+				Listener: conf_v1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1.TransportServerUpstream{
+					{
+						Name:    "tcp-app-v1",
+						Service: "tcp-app-v1-svc",
+						Port:    5001,
+					},
+					{
+						Name:    "tcp-app-v2",
+						Service: "tcp-app-v2-svc",
+						Port:    5001,
+					},
+				},
+				Matches: []conf_v1.TransportServerConnectionMatch{
+					{
+						Conditions: []conf_v1.TransportServerCondition{
+							{
+								SourceCIDRs: []string{"10.0.0.0/8"},
+							},
+						},
+						Action: &conf_v1.TransportServerAction{
+							Splits: []conf_v1.TransportServerSplit{
+								{
+									Weight: 100,
+									Pass:   "tcp-app-v2",
+								},
+								{
+									Weight: 0,
+									Pass:   "tcp-app-v1",
+								},
+							},
+						},
+					},
+				},
+				Action: &conf_v1.TransportServerAction{
+					Splits: []conf_v1.TransportServerSplit{
+						{
+							Weight: 90,
+							Pass:   "tcp-app-v1",
+						},
+						{
+							Weight: 10,
+							Pass:   "tcp-app-v2",
+						},
+					},
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tcp-app-v1-svc:5001": {
+				"10.0.0.20:5001",
+			},
+			"default/tcp-app-v2-svc:5001": {
+				"10.0.0.30:5001",
+			},
+		},
+	}
+
+	expectedSplitClients := []version2.SplitClient{
+		{
+			Source:   "$remote_addr",
+			Variable: "$ts_default_tcp_server_splits_0",
+			Distributions: []version2.Distribution{
+				{
+					Weight: "100%",
+					Value:  "ts_default_tcp-server_tcp-app-v2",
+				},
+			},
+		},
+		{
+			Source:   "$remote_addr",
+			Variable: "$ts_default_tcp_server_splits_1",
+			Distributions: []version2.Distribution{
+				{
+					Weight: "90%",
+					Value:  "ts_default_tcp-server_tcp-app-v1",
+				},
+				{
+					Weight: "10%",
+					Value:  "ts_default_tcp-server_tcp-app-v2",
+				},
+			},
+		},
+	}
+	expectedMatchesMap := version2.Map{
+		Source:   "$ts_default_tcp_server_match_0_cond_0",
+		Variable: "$ts_default_tcp_server_matches",
+		Parameters: []version2.Parameter{
+			{
+				Value:  "~^1",
+				Result: "$ts_default_tcp_server_splits_0",
+			},
+			{
+				Value:  "default",
+				Result: "$ts_default_tcp_server_splits_1",
+			},
+		},
+	}
+
+	result, warnings := generateTransportServerConfig(transportServerConfigParams{
+		transportServerEx: &transportServerEx,
+		listenerPort:      2020,
+		isPlus:            false,
+		staticSSLPath:     "/etc/nginx/secret",
+	})
+	if len(warnings) != 0 {
+		t.Errorf("want no warnings, got %v", warnings)
+	}
+	if !cmp.Equal(expectedSplitClients, result.SplitClients) {
+		t.Errorf("generateTransportServerConfig() split clients mismatch (-want +got):\n%s", cmp.Diff(expectedSplitClients, result.SplitClients))
+	}
+	if len(result.Maps) == 0 || !cmp.Equal(expectedMatchesMap, result.Maps[len(result.Maps)-1]) {
+		t.Errorf("generateTransportServerConfig() returned maps %v, expected the last one to be %v", result.Maps, expectedMatchesMap)
+	}
+	if result.Server.ProxyPass != "$ts_default_tcp_server_matches" {
+		t.Errorf("generateTransportServerConfig() returned proxy pass %q but expected %q", result.Server.ProxyPass, "$ts_default_tcp_server_matches")
+	}
+	if result.KeyValZones != nil || result.KeyVals != nil || result.TwoWaySplitClients != nil {
+		t.Errorf("generateTransportServerConfig() returned keyvals for splits without weightChangesDynamicReload")
+	}
+}
+
+func TestGenerateTransportServerConfigForTCPWithSplitsWeightChangesDynamicReload(t *testing.T) {
+	t.Parallel()
+	transportServerEx := TransportServerEx{
+		TransportServer: &conf_v1.TransportServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "tcp-server",
+				Namespace: "default",
+			},
+			Spec: conf_v1.TransportServerSpec{
+				Listener: conf_v1.TransportServerListener{
+					Name:     "tcp-listener",
+					Protocol: "TCP",
+				},
+				Upstreams: []conf_v1.TransportServerUpstream{
+					{
+						Name:    "tcp-app-v1",
+						Service: "tcp-app-v1-svc",
+						Port:    5001,
+					},
+					{
+						Name:    "tcp-app-v2",
+						Service: "tcp-app-v2-svc",
+						Port:    5001,
+					},
+				},
+				Action: &conf_v1.TransportServerAction{
+					Splits: []conf_v1.TransportServerSplit{
+						{
+							Weight: 90,
+							Pass:   "tcp-app-v1",
+						},
+						{
+							Weight: 10,
+							Pass:   "tcp-app-v2",
+						},
+					},
+				},
+			},
+		},
+		Endpoints: map[string][]string{},
+	}
+
+	expectedKeyValZones := []version2.KeyValZone{
+		{
+			Name:  "ts_default_tcp_server_keyval_zone_split_clients_0",
+			Size:  "100k",
+			State: "/etc/nginx/state_files/ts_default_tcp_server_keyval_zone_split_clients_0.json",
+		},
+	}
+	expectedKeyVals := []version2.KeyVal{
+		{
+			Key:      `"ts_default_tcp_server_keyval_key_split_clients_0"`,
+			Variable: "$ts_default_tcp_server_keyval_split_clients_0",
+			ZoneName: "ts_default_tcp_server_keyval_zone_split_clients_0",
+		},
+	}
+	expectedTwoWaySplitClients := []version2.TwoWaySplitClients{
+		{
+			Key:               `"ts_default_tcp_server_keyval_key_split_clients_0"`,
+			Variable:          "$ts_default_tcp_server_keyval_split_clients_0",
+			ZoneName:          "ts_default_tcp_server_keyval_zone_split_clients_0",
+			Weights:           []int{90, 10},
+			SplitClientsIndex: 0,
+		},
+	}
+	expectedSplitClient := version2.SplitClient{
+		Source:   "$remote_addr",
+		Variable: "$ts_default_tcp_server_split_clients_0_90_10",
+		Distributions: []version2.Distribution{
+			{
+				Weight: "90%",
+				Value:  "ts_default_tcp-server_tcp-app-v1",
+			},
+			{
+				Weight: "10%",
+				Value:  "ts_default_tcp-server_tcp-app-v2",
+			},
+		},
+	}
+
+	result, warnings := generateTransportServerConfig(transportServerConfigParams{
+		transportServerEx:          &transportServerEx,
+		listenerPort:               2020,
+		isPlus:                     true,
+		staticSSLPath:              "/etc/nginx/secret",
+		weightChangesDynamicReload: true,
+	})
+	if len(warnings) != 0 {
+		t.Errorf("want no warnings, got %v", warnings)
+	}
+	if !cmp.Equal(expectedKeyValZones, result.KeyValZones) {
+		t.Errorf("generateTransportServerConfig() keyval zones mismatch (-want +got):\n%s", cmp.Diff(expectedKeyValZones, result.KeyValZones))
+	}
+	if !cmp.Equal(expectedKeyVals, result.KeyVals) {
+		t.Errorf("generateTransportServerConfig() keyvals mismatch (-want +got):\n%s", cmp.Diff(expectedKeyVals, result.KeyVals))
+	}
+	if !cmp.Equal(expectedTwoWaySplitClients, result.TwoWaySplitClients) {
+		t.Errorf("generateTransportServerConfig() two way split clients mismatch (-want +got):\n%s", cmp.Diff(expectedTwoWaySplitClients, result.TwoWaySplitClients))
+	}
+	if len(result.SplitClients) != 101 {
+		t.Fatalf("generateTransportServerConfig() returned %d split clients but expected 101", len(result.SplitClients))
+	}
+	if !cmp.Equal(expectedSplitClient, result.SplitClients[90]) {
+		t.Errorf("generateTransportServerConfig() split client mismatch (-want +got):\n%s", cmp.Diff(expectedSplitClient, result.SplitClients[90]))
+	}
+	if len(result.Maps) != 1 || len(result.Maps[0].Parameters) != 102 {
+		t.Errorf("generateTransportServerConfig() returned maps %v, expected one map with 102 parameters", result.Maps)
+	}
+	if result.Server.ProxyPass != "$ts_default_tcp_server_map_split_clients_0" {
+		t.Errorf("generateTransportServerConfig() returned proxy pass %q but expected %q", result.Server.ProxyPass, "$ts_default_tcp_server_map_split_clients_0")
+	}
+}
+
+func TestGenerateValueForServerNameCondition(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		serverName string
+		expected   string
+	}{
+		{
+			serverName: "db.example.com",
+			expected:   `"db.example.com"`,
+		},
+		{
+			serverName: "*.example.com",
+			expected:   `"~^.+\.example\.com$"`,
+		},
+	}
+
+	for _, test := range tests {
+		result := generateValueForServerNameCondition(test.serverName)
+		if result != test.expected {
+			t.Errorf("generateValueForServerNameCondition(%q) returned %q but expected %q", test.serverName, result, test.expected)
+		}
+	}
+}
+
 func TestGenerateTransportServerConfigForTCPMaxConnections(t *testing.T) {
 	t.Parallel()
 	transportServerEx := TransportServerEx{
@@ -1522,6 +2111,449 @@ func TestGenerateTransportServerConfigForTCPWithTLS(t *testing.T) {
 	}
 }
 
+func TestGenerateStreamProxySSL(t *testing.T) {
+	t.Parallel()
+
+	secretRefs := map[string]*secrets.SecretReference{
+		"default/mtls-secret": {
+			Secret: &api_v1.Secret{
+				Type: api_v1.SecretTypeTLS,
+			},
+			Path: "/etc/nginx/secrets/default-mtls-secret",
+		},
+		"default/ca-secret": {
+			Secret: &api_v1.Secret{
+				Type: secrets.SecretTypeCA,
+			},
+			Path: "/etc/nginx/secrets/default-ca-secret-ca.crt",
+		},
+		"default/invalid-secret": {
+			Secret: &api_v1.Secret{
+				Type: api_v1.SecretTypeTLS,
+			},
+			Error: errors.New("secret is invalid"),
+		},
+	}
+
+	tests := []struct {
+		upstreamTLS     *conf_v1.TransportServerUpstreamTLS
+		splits          bool
+		expected        *version2.StreamProxySSL
+		expectedInvalid bool
+		msg             string
+	}{
+		{
+			upstreamTLS: nil,
+			expected:    nil,
+			msg:         "no tls",
+		},
+		{
+			upstreamTLS: &conf_v1.TransportServerUpstreamTLS{
+				Enable:    false,
+				TLSSecret: "mtls-secret",
+			},
+			expected: nil,
+			msg:      "disabled tls",
+		},
+		{
+			upstreamTLS: &conf_v1.TransportServerUpstreamTLS{
+				Enable: true,
+			},
+			expected: &version2.StreamProxySSL{
+				VerifyDepth: 1,
+				SSLName:     "tcp-app-svc.default.svc",
+			},
+			msg: "tls with defaults",
+		},
+		{
+			upstreamTLS: &conf_v1.TransportServerUpstreamTLS{
+				Enable:            true,
+				TLSSecret:         "mtls-secret",
+				TrustedCertSecret: "ca-secret",
+				VerifyServer:      true,
+				VerifyDepth:       createPointerFromInt(2),
+				ServerName:        true,
+				SSLName:           "tcp-app.example.com",
+			},
+			splits: true,
+			expected: &version2.StreamProxySSL{
+				Certificate:    "/etc/nginx/secrets/default-mtls-secret",
+				CertificateKey: "/etc/nginx/secrets/default-mtls-secret",
+				TrustedCert:    "/etc/nginx/secrets/default-ca-secret-ca.crt",
+				VerifyServer:   true,
+				VerifyDepth:    2,
+				ServerName:     true,
+				SSLName:        "tcp-app.example.com",
+			},
+			msg: "tls with client certificate and verification of the upstream of the splits",
+		},
+		{
+			upstreamTLS: &conf_v1.TransportServerUpstreamTLS{
+				Enable:    true,
+				TLSSecret: "ca-secret",
+			},
+			expected:        nil,
+			expectedInvalid: true,
+			msg:             "tls secret of a wrong type",
+		},
+		{
+			upstreamTLS: &conf_v1.TransportServerUpstreamTLS{
+				Enable:            true,
+				TrustedCertSecret: "invalid-secret",
+			},
+			expected:        nil,
+			expectedInvalid: true,
+			msg:             "invalid trusted cert secret",
+		},
+		{
+			upstreamTLS: &conf_v1.TransportServerUpstreamTLS{
+				Enable:    true,
+				TLSSecret: "missing-secret",
+			},
+			expected:        nil,
+			expectedInvalid: true,
+			msg:             "missing tls secret",
+		},
+	}
+
+	for _, test := range tests {
+		transportServerEx := &TransportServerEx{
+			TransportServer: &conf_v1.TransportServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "tcp-server",
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					Upstreams: []conf_v1.TransportServerUpstream{
+						{
+							Name:    "tcp-app",
+							Service: "tcp-app-svc",
+							Port:    5001,
+							TLS:     test.upstreamTLS,
+						},
+					},
+					Action: &conf_v1.TransportServerAction{
+						Pass: "tcp-app",
+					},
+				},
+			},
+			SecretRefs: secretRefs,
+		}
+		if test.splits {
+			transportServerEx.TransportServer.Spec.Action = &conf_v1.TransportServerAction{
+				Splits: []conf_v1.TransportServerSplit{
+					{Weight: 50, Pass: "tcp-app"},
+					{Weight: 50, Pass: "tcp-app"},
+				},
+			}
+		}
+
+		result, invalid, warnings := generateStreamProxySSL(transportServerEx)
+		if !cmp.Equal(test.expected, result) {
+			t.Errorf("generateStreamProxySSL() mismatch for the case of %s (-want +got):\n%s", test.msg, cmp.Diff(test.expected, result))
+		}
+		if invalid != test.expectedInvalid {
+			t.Errorf("generateStreamProxySSL() returned %v but expected %v for the case of %s", invalid, test.expectedInvalid, test.msg)
+		}
+		if invalid != (len(warnings) > 0) {
+			t.Errorf("generateStreamProxySSL() returned warnings %v for the case of %s", warnings, test.msg)
+		}
+	}
+}
+
+func TestGenerateTransportServerPolicies(t *testing.T) {
+	t.Parallel()
+	connLimitPolicy := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "conn-limit",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			ConnectionLimit: &conf_v1.ConnectionLimit{
+				Key:         "${binary_remote_addr}",
+				ZoneSize:    "10M",
+				Connections: 20,
+				DryRun:      createPointerFromBool(true),
+			},
+		},
+	}
+	connLimitPolicy2 := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "conn-limit2",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			ConnectionLimit: &conf_v1.ConnectionLimit{
+				Key:         "${server_addr}",
+				ZoneSize:    "1M",
+				Connections: 100,
+			},
+		},
+	}
+	allowPolicy := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "allow",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			AccessControl: &conf_v1.AccessControl{
+				Allow: []string{"10.0.0.0/8"},
+			},
+		},
+	}
+	ingressMTLSPolicy := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "ingress-mtls",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			IngressMTLS: &conf_v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				VerifyClient:     "optional",
+			},
+		},
+	}
+	ingressMTLSForwardPolicy := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "ingress-mtls-forward",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			IngressMTLS: &conf_v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				VerifyClient:     "optional",
+				ForwardCertificate: &conf_v1.ForwardCertificate{
+					SubjectHeader: "X-Client-Subject-DN",
+				},
+				OCSP: &conf_v1.IngressMTLSOCSP{},
+			},
+		},
+	}
+	rateLimitPolicy := &conf_v1.Policy{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "rate-limit",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			RateLimit: &conf_v1.RateLimit{
+				Key:      "${binary_remote_addr}",
+				ZoneSize: "10M",
+				Rate:     "10r/s",
+			},
+		},
+	}
+	secretRefs := map[string]*secrets.SecretReference{
+		"default/ingress-mtls-secret": {
+			Secret: &api_v1.Secret{
+				Type: secrets.SecretTypeCA,
+			},
+			Path: "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt",
+		},
+	}
+
+	expectedLimitConnZones := []version2.LimitConnZone{
+		{
+			Key:      "${binary_remote_addr}",
+			ZoneName: "pol_cl_default_conn_limit_default_tcp_server",
+			ZoneSize: "10M",
+		},
+	}
+	expectedLimitConn := &version2.LimitConn{
+		ZoneName:    "pol_cl_default_conn_limit_default_tcp_server",
+		Connections: 20,
+		DryRun:      true,
+	}
+
+	tests := []struct {
+		policyRefs       []conf_v1.PolicyReference
+		policies         map[string]*conf_v1.Policy
+		tls              *conf_v1.TransportServerTLS
+		expected         streamPoliciesCfg
+		expectedWarnings []string
+		msg              string
+	}{
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "conn-limit",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/conn-limit": connLimitPolicy,
+			},
+			expected: streamPoliciesCfg{
+				LimitConnZones: expectedLimitConnZones,
+				LimitConn:      expectedLimitConn,
+			},
+			msg: "connection limit reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "conn-limit",
+				},
+				{
+					Name: "conn-limit2",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/conn-limit":  connLimitPolicy,
+				"default/conn-limit2": connLimitPolicy2,
+			},
+			expected: streamPoliciesCfg{
+				LimitConnZones: expectedLimitConnZones,
+				LimitConn:      expectedLimitConn,
+			},
+			expectedWarnings: []string{
+				"Multiple connectionLimit policies in the same context is not valid. ConnectionLimit policy default/conn-limit2 will be ignored",
+			},
+			msg: "multiple connection limit references",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "allow",
+				},
+				{
+					Name: "conn-limit",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/allow":      allowPolicy,
+				"default/conn-limit": connLimitPolicy,
+			},
+			expected: streamPoliciesCfg{
+				Allow:          []string{"10.0.0.0/8"},
+				LimitConnZones: expectedLimitConnZones,
+				LimitConn:      expectedLimitConn,
+			},
+			msg: "access control and connection limit references",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "ingress-mtls",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls": ingressMTLSPolicy,
+			},
+			tls: &conf_v1.TransportServerTLS{
+				Secret: "tls-secret",
+			},
+			expected: streamPoliciesCfg{
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:   "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt",
+					VerifyClient: "optional",
+					VerifyDepth:  1,
+				},
+			},
+			msg: "ingress mtls reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "ingress-mtls-forward",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-forward": ingressMTLSForwardPolicy,
+			},
+			tls: &conf_v1.TransportServerTLS{
+				Secret: "tls-secret",
+			},
+			expected: streamPoliciesCfg{
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:   "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt",
+					VerifyClient: "optional",
+					VerifyDepth:  1,
+					ForwardHeaders: []version2.Header{
+						{Name: "X-Client-Subject-DN", Value: "$ingress_mtls_client_s_dn"},
+					},
+					OCSP: "on",
+				},
+			},
+			expectedWarnings: []string{
+				"forwardCertificate of IngressMTLS policy default/ingress-mtls-forward is not supported in TransportServers and will be ignored",
+				"ocsp of IngressMTLS policy default/ingress-mtls-forward is not supported in TransportServers and will be ignored",
+			},
+			msg: "ingress mtls reference with unsupported fields",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "ingress-mtls",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls": ingressMTLSPolicy,
+			},
+			expected: streamPoliciesCfg{
+				DenyAll: true,
+			},
+			expectedWarnings: []string{
+				"TLS must be enabled in TransportServer for IngressMTLS policy default/ingress-mtls",
+			},
+			msg: "ingress mtls reference without tls",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "conn-limit",
+					Namespace: "nginx-ingress",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{},
+			expected: streamPoliciesCfg{
+				DenyAll: true,
+			},
+			expectedWarnings: []string{
+				"Policy nginx-ingress/conn-limit is missing or invalid",
+			},
+			msg: "missing policy",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "rate-limit",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/rate-limit": rateLimitPolicy,
+			},
+			expectedWarnings: []string{
+				"Policy default/rate-limit is not supported in TransportServers and will be ignored",
+			},
+			msg: "unsupported policy",
+		},
+	}
+
+	for _, test := range tests {
+		transportServerEx := &TransportServerEx{
+			TransportServer: &conf_v1.TransportServer{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "tcp-server",
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					TLS:      test.tls,
+					Policies: test.policyRefs,
+				},
+			},
+			Policies:   test.policies,
+			SecretRefs: secretRefs,
+		}
+
+		result, warnings := generateTransportServerPolicies(transportServerEx)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateTransportServerPolicies() mismatch for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+		if !cmp.Equal(test.expectedWarnings, warnings[transportServerEx.TransportServer]) {
+			t.Errorf("generateTransportServerPolicies() returned warnings %v but expected %v for the case of %s", warnings[transportServerEx.TransportServer], test.expectedWarnings, test.msg)
+		}
+	}
+}
+
 func TestGenerateUnixSocket(t *testing.T) {
 	t.Parallel()
 	transportServerEx := &TransportServerEx{
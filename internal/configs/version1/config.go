@@ -230,6 +230,7 @@ type MainConfig struct {
 	HealthStatus                       bool
 	HealthStatusURI                    string
 	HTTP2                              bool
+	HTTP3                              bool
 	HTTPSnippets                       []string
 	KeepaliveRequests                  int64
 	KeepaliveTimeout                   string
@@ -3,6 +3,7 @@ package version1
 import (
 	"bytes"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -131,6 +132,66 @@ func TestExecuteMainTemplateForNGINX(t *testing.T) {
 	t.Log(buf.String())
 }
 
+func TestExecuteMainTemplate_CORSPreflightMapMatchesOnlyPreflightRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		request  string
+		expected bool
+		msg      string
+	}{
+		{
+			request:  "OPTIONS https://example.com GET",
+			expected: true,
+			msg:      "preflight request",
+		},
+		{
+			request:  "OPTIONS https://example.com:8443 PUT",
+			expected: true,
+			msg:      "preflight request from an origin with a port",
+		},
+		{
+			request:  "OPTIONS  ",
+			expected: false,
+			msg:      "plain OPTIONS request",
+		},
+		{
+			request:  "OPTIONS https://example.com ",
+			expected: false,
+			msg:      "OPTIONS request without Access-Control-Request-Method",
+		},
+		{
+			request:  "OPTIONS  GET",
+			expected: false,
+			msg:      "OPTIONS request without Origin",
+		},
+		{
+			request:  "GET https://example.com GET",
+			expected: false,
+			msg:      "GET request",
+		},
+	}
+
+	for _, tmpl := range []*template.Template{newNGINXMainTmpl(t), newNGINXPlusMainTmpl(t)} {
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, mainCfg); err != nil {
+			t.Fatal(err)
+		}
+
+		m := regexp.MustCompile(`\$cors_preflight \{\s+default\s+0;\s+"~([^"]+)" 1;`).FindStringSubmatch(buf.String())
+		if m == nil {
+			t.Fatalf("want the map of $cors_preflight in the generated template")
+		}
+		preflight := regexp.MustCompile(m[1])
+
+		for _, test := range tests {
+			if got := preflight.MatchString(test.request); got != test.expected {
+				t.Errorf("the map of $cors_preflight matched %q: %v, expected %v for the case of %s", test.request, got, test.expected, test.msg)
+			}
+		}
+	}
+}
+
 func TestExecuteTemplate_ForIngressForNGINXPlus(t *testing.T) {
 	t.Parallel()
 
@@ -868,6 +929,38 @@ func TestExecuteTemplate_ForMainForNGINXPlusWithHTTP2On(t *testing.T) {
 	snaps.MatchSnapshot(t, buf.String())
 }
 
+func TestExecuteTemplate_ForMainWithHTTP3On(t *testing.T) {
+	t.Parallel()
+
+	mainCfg := mainCfgHTTP2On
+	mainCfg.HTTP3 = true
+
+	for _, tmpl := range []*template.Template{newNGINXMainTmpl(t), newNGINXPlusMainTmpl(t)} {
+		buf := &bytes.Buffer{}
+
+		err := tmpl.Execute(buf, mainCfg)
+		t.Log(buf.String())
+
+		if err != nil {
+			t.Fatalf("Failed to write template %v", err)
+		}
+
+		wantDirectives := []string{
+			"listen 443 ssl default_server;",
+			"listen [::]:443 ssl default_server;",
+			"listen 443 quic reuseport default_server;",
+			"listen [::]:443 quic reuseport default_server;",
+		}
+
+		mainConf := buf.String()
+		for _, want := range wantDirectives {
+			if !strings.Contains(mainConf, want) {
+				t.Errorf("want %q in generated config", want)
+			}
+		}
+	}
+}
+
 func TestExecuteTemplate_ForMainForNGINXWithHTTP2Off(t *testing.T) {
 	t.Parallel()
 
@@ -20,6 +20,9 @@ type VirtualServerConfig struct {
 	KeyValZones             []KeyValZone
 	KeyVals                 []KeyVal
 	LimitReqZones           []LimitReqZone
+	LimitConnZones          []LimitConnZone
+	CacheZones              []CacheZone
+	Geos                    []Geo
 	Maps                    []Map
 	AuthJWTClaimSets        []AuthJWTClaimSet
 	Server                  Server
@@ -64,65 +67,95 @@ type UpstreamServer struct {
 
 // Server defines a server.
 type Server struct {
-	ServerName                string
-	StatusZone                string
-	CustomListeners           bool
-	HTTPIPv4                  string
-	HTTPIPv6                  string
-	HTTPSIPv4                 string
-	HTTPSIPv6                 string
-	HTTPPort                  int
-	HTTPSPort                 int
-	ProxyProtocol             bool
-	SSL                       *SSL
-	ServerTokens              string
-	RealIPHeader              string
-	SetRealIPFrom             []string
-	RealIPRecursive           bool
-	Snippets                  []string
-	InternalRedirectLocations []InternalRedirectLocation
-	Locations                 []Location
-	ErrorPageLocations        []ErrorPageLocation
-	ReturnLocations           []ReturnLocation
-	HealthChecks              []HealthCheck
-	TLSRedirect               *TLSRedirect
-	TLSPassthrough            bool
-	Allow                     []string
-	Deny                      []string
-	LimitReqOptions           LimitReqOptions
-	LimitReqs                 []LimitReq
-	JWTAuth                   *JWTAuth
-	JWTAuthList               map[string]*JWTAuth
-	JWKSAuthEnabled           bool
-	BasicAuth                 *BasicAuth
-	IngressMTLS               *IngressMTLS
-	EgressMTLS                *EgressMTLS
-	OIDC                      *OIDC
-	APIKey                    *APIKey
-	APIKeyEnabled             bool
-	WAF                       *WAF
-	Dos                       *Dos
-	PoliciesErrorReturn       *Return
-	VSNamespace               string
-	VSName                    string
-	DisableIPV6               bool
-	Gunzip                    bool
+	ServerName                   string
+	StatusZone                   string
+	CustomListeners              bool
+	HTTPIPv4                     string
+	HTTPIPv6                     string
+	HTTPSIPv4                    string
+	HTTPSIPv6                    string
+	HTTPPort                     int
+	HTTPSPort                    int
+	ProxyProtocol                bool
+	HTTPProxyProtocol            *bool
+	HTTPSProxyProtocol           *bool
+	HTTP3                        bool
+	HTTP3ReusePort               bool
+	SSL                          *SSL
+	ServerTokens                 string
+	RealIPHeader                 string
+	SetRealIPFrom                []string
+	RealIPRecursive              bool
+	Snippets                     []string
+	InternalRedirectLocations    []InternalRedirectLocation
+	SignatureValidationLocations []SignatureValidationLocation
+	Locations                    []Location
+	ErrorPageLocations           []ErrorPageLocation
+	ReturnLocations              []ReturnLocation
+	MirrorLocations              []MirrorLocation
+	ExternalAuthLocations        []ExternalAuthLocation
+	FaultInjectionLocations      []FaultInjectionLocation
+	HealthChecks                 []HealthCheck
+	TLSRedirect                  *TLSRedirect
+	TLSPassthrough               bool
+	Allow                        []string
+	Deny                         []string
+	LimitReqOptions              LimitReqOptions
+	LimitReqs                    []LimitReq
+	LimitConn                    *LimitConn
+	Authorization                *Authorization
+	JWTAuth                      *JWTAuth
+	JWTAuthList                  map[string]*JWTAuth
+	JWKSAuthEnabled              bool
+	JWTAuthEnabled               bool
+	BasicAuth                    *BasicAuth
+	IngressMTLS                  *IngressMTLS
+	EgressMTLS                   *EgressMTLS
+	OIDC                         *OIDC
+	JWTClaimVariables            []string
+	APIKey                       *APIKey
+	APIKeyEnabled                bool
+	CORS                         *CORS
+	ExternalAuth                 *ExternalAuth
+	Cache                        *Cache
+	FaultInjection               *FaultInjection
+	WAF                          *WAF
+	Dos                          *Dos
+	PoliciesErrorReturn          *Return
+	VSNamespace                  string
+	VSName                       string
+	DisableIPV6                  bool
+	Gunzip                       bool
 }
 
 // SSL defines SSL configuration for a server.
 type SSL struct {
-	HTTP2           bool
-	Certificate     string
-	CertificateKey  string
-	RejectHandshake bool
+	HTTP2                 bool
+	Certificate           string
+	CertificateKey        string
+	RejectHandshake       bool
+	OCSPStapling          bool
+	OCSPStaplingResponder string
+	OCSPResolver          *Resolver
+}
+
+// Resolver defines a DNS resolver for a server.
+type Resolver struct {
+	Addresses []string
+	Valid     string
+	Timeout   string
+	IPv6      bool
 }
 
 // IngressMTLS defines TLS configuration for a server. This is a subset of TLS specifically for clients auth.
 type IngressMTLS struct {
-	ClientCert   string
-	ClientCrl    string
-	VerifyClient string
-	VerifyDepth  int
+	ClientCert     string
+	ClientCrl      string
+	VerifyClient   string
+	VerifyDepth    int
+	ForwardHeaders []Header
+	OCSP           string
+	OCSPResponder  string
 }
 
 // EgressMTLS defines TLS configuration for a location.
@@ -154,13 +187,25 @@ type OIDC struct {
 	AuthExtraArgs         string
 	AccessTokenEnable     bool
 	PKCEEnable            bool
+	SessionKey            string
+	ClaimHeaders          []OIDCClaimHeader
+	RefreshLeeway         int
+	BackchannelLogoutURI  string
+}
+
+// OIDCClaimHeader defines a request header set from an ID token claim.
+type OIDCClaimHeader struct {
+	Header   string
+	Claim    string
+	Variable string
 }
 
 // APIKey holds API key configuration.
 type APIKey struct {
-	Header  []string
-	Query   []string
-	MapName string
+	Header      []string
+	Query       []string
+	MapName     string
+	TierMapName string
 }
 
 // WAF defines WAF configuration.
@@ -220,6 +265,8 @@ type Location struct {
 	Deny                     []string
 	LimitReqOptions          LimitReqOptions
 	LimitReqs                []LimitReq
+	LimitConn                *LimitConn
+	Authorization            *Authorization
 	JWTAuth                  *JWTAuth
 	BasicAuth                *BasicAuth
 	EgressMTLS               *EgressMTLS
@@ -233,6 +280,110 @@ type Location struct {
 	VSRName                  string
 	VSRNamespace             string
 	GRPCPass                 string
+	Mirror                   *Mirror
+	CORS                     *CORS
+	ExternalAuth             *ExternalAuth
+	Cache                    *Cache
+	FaultInjection           *FaultInjection
+	SignatureValidation      *SignatureValidation
+}
+
+// CORS defines the Cross-Origin Resource Sharing headers of a location.
+type CORS struct {
+	AllowOrigin      string
+	AllowMethods     string
+	AllowHeaders     string
+	ExposeHeaders    string
+	AllowCredentials bool
+	MaxAge           int
+}
+
+// Mirror defines the mirroring of requests of a location.
+type Mirror struct {
+	Path         string
+	RequestBody  bool
+	UpstreamName string
+	Percentage   int
+}
+
+// MirrorLocation defines an internal location for sending mirrored requests to an upstream.
+type MirrorLocation struct {
+	Path                string
+	ProxyPass           string
+	ProxyConnectTimeout string
+	ProxyReadTimeout    string
+	ProxySendTimeout    string
+	ProxySSLName        string
+	HasKeepalive        bool
+	Variable            string
+}
+
+// ExternalAuth defines the authorization of the requests of a location by an external auth service.
+type ExternalAuth struct {
+	URI             string
+	SigninURL       string
+	ResponseHeaders []ExternalAuthResponseHeader
+}
+
+// ExternalAuthResponseHeader defines a header of the auth service response that is added to the proxied request.
+type ExternalAuthResponseHeader struct {
+	Name     string
+	Variable string
+	Value    string
+}
+
+// ExternalAuthLocation defines an internal location for sending auth subrequests to an external auth service.
+type ExternalAuthLocation struct {
+	Path           string
+	ProxyPass      string
+	ForwardHeaders []Header
+}
+
+// CacheZone defines a cache zone created by the proxy_cache_path directive.
+type CacheZone struct {
+	Name              string
+	Path              string
+	Size              string
+	MaxSize           string
+	Inactive          string
+	PurgeAllowedCIDRs []string
+	PurgeAllowedVar   string
+	PurgeVar          string
+	PurgeDeniedVar    string
+}
+
+// Cache defines the caching of the responses of a location.
+type Cache struct {
+	ZoneName           string
+	Key                string
+	Methods            string
+	Valid              []CacheValid
+	Bypass             string
+	IgnoreCacheControl bool
+	PurgeVar           string
+	PurgeDeniedVar     string
+}
+
+// CacheValid defines the caching time for response codes.
+type CacheValid struct {
+	Codes string
+	Time  string
+}
+
+// FaultInjection defines a fault injected into the requests of a location.
+// The fault is injected when the value of Check is equal to Value.
+type FaultInjection struct {
+	Check         string
+	Value         string
+	Status        int
+	DelayLocation string
+}
+
+// FaultInjectionLocation defines a named location that delays requests
+// before redirecting them back to their original location.
+type FaultInjectionLocation struct {
+	Name  string
+	Delay int64
 }
 
 // ReturnLocation defines a location for returning a fixed response.
@@ -354,6 +505,13 @@ func (m *Map) String() string {
 	return buf.String()
 }
 
+// Geo defines a geo block.
+type Geo struct {
+	Source     string
+	Variable   string
+	Parameters []Parameter
+}
+
 // Parameter defines a Parameter in a Map.
 type Parameter struct {
 	Value  string
@@ -426,6 +584,57 @@ func (rl LimitReqOptions) String() string {
 	return fmt.Sprintf("{DryRun %v, LogLevel %q, RejectCode %q}", rl.DryRun, rl.LogLevel, rl.RejectCode)
 }
 
+// LimitConnZone defines a connection limit shared memory zone.
+type LimitConnZone struct {
+	Key      string
+	ZoneName string
+	ZoneSize string
+}
+
+// LimitConn defines a connection limit.
+type LimitConn struct {
+	ZoneName    string
+	Connections int
+	DryRun      bool
+	RejectCode  int
+}
+
+// Authorization defines the claims-based authorization of requests.
+// Variable holds the authorization decision in NGINX Plus, while Requirements holds the claim requirements in JSON
+// for the njs authorization in NGINX.
+type Authorization struct {
+	Variable     string
+	Requirements string
+	RejectCode   int
+}
+
+// SignatureValidation holds the HMAC signature validation configuration of a location.
+type SignatureValidation struct {
+	KeyFile            string
+	Format             string
+	Algorithm          string
+	Header             string
+	TimestampTolerance int
+	RejectCode         int
+}
+
+// SignatureValidationLocation defines a location which validates the signature of requests with njs before
+// redirecting them to the named location with the configuration of the route. The access and rate limits of
+// the route also apply to the location, so that they are checked before the request body is read.
+type SignatureValidationLocation struct {
+	Path              string
+	Internal          bool
+	NamedLocation     string
+	ClientMaxBodySize string
+	BodyBufferSize    string
+	Validation        *SignatureValidation
+	Allow             []string
+	Deny              []string
+	LimitReqOptions   LimitReqOptions
+	LimitReqs         []LimitReq
+	LimitConn         *LimitConn
+}
+
 // JWTAuth holds JWT authentication configuration.
 type JWTAuth struct {
 	Key      string
@@ -434,6 +643,9 @@ type JWTAuth struct {
 	Token    string
 	KeyCache string
 	JwksURI  JwksURI
+	Issuer   string
+	Audience string
+	Require  []string
 }
 
 // JwksURI defines the components of a JwksURI
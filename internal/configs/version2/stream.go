@@ -6,6 +6,13 @@ type TransportServerConfig struct {
 	Upstreams               []StreamUpstream
 	StreamSnippets          []string
 	Match                   *Match
+	LimitConnZones          []LimitConnZone
+	Maps                    []Map
+	Geos                    []Geo
+	SplitClients            []SplitClient
+	KeyValZones             []KeyValZone
+	KeyVals                 []KeyVal
+	TwoWaySplitClients      []TwoWaySplitClients
 	DisableIPV6             bool
 	DynamicSSLReloadEnabled bool
 	StaticSSLPath           string
@@ -59,6 +66,16 @@ type StreamServer struct {
 	SSL                      *StreamSSL
 	IPv4                     string
 	IPv6                     string
+	ProxyProtocol            bool
+	SetRealIPFrom            []string
+	UpstreamProxyProtocol    bool
+	Allow                    []string
+	Deny                     []string
+	DenyAll                  bool
+	IngressMTLS              *IngressMTLS
+	LimitConn                *LimitConn
+	SSLPreread               bool
+	ProxySSL                 *StreamProxySSL
 }
 
 // StreamSSL defines SSL configuration for a server.
@@ -68,6 +85,17 @@ type StreamSSL struct {
 	CertificateKey string
 }
 
+// StreamProxySSL defines the TLS configuration for the connections to the upstream servers of a StreamServer.
+type StreamProxySSL struct {
+	Certificate    string
+	CertificateKey string
+	TrustedCert    string
+	VerifyServer   bool
+	VerifyDepth    int
+	ServerName     bool
+	SSLName        string
+}
+
 // StreamHealthCheck defines a health check for a StreamUpstream in a StreamServer.
 type StreamHealthCheck struct {
 	Enabled  bool
@@ -27,6 +27,8 @@ type listen struct {
 	ipAddress     string
 	port          string
 	tls           bool
+	quic          bool
+	reusePort     bool
 	proxyProtocol bool
 	udp           bool
 	ipType        ipType
@@ -76,13 +78,14 @@ func buildCustomListenerDirectives(listenerType protocol, s Server) string {
 
 func buildListenerDirectives(listenerType protocol, s Server, port string) string {
 	var directives string
+	proxyProtocol := getProxyProtocol(listenerType, s)
 
 	if listenerType == http {
 		directives += buildListenDirective(listen{
 			ipAddress:     s.HTTPIPv4,
 			port:          port,
 			tls:           false,
-			proxyProtocol: s.ProxyProtocol,
+			proxyProtocol: proxyProtocol,
 			udp:           false,
 			ipType:        ipv4,
 		})
@@ -92,7 +95,7 @@ func buildListenerDirectives(listenerType protocol, s Server, port string) strin
 				ipAddress:     s.HTTPIPv6,
 				port:          port,
 				tls:           false,
-				proxyProtocol: s.ProxyProtocol,
+				proxyProtocol: proxyProtocol,
 				udp:           false,
 				ipType:        ipv6,
 			})
@@ -102,7 +105,7 @@ func buildListenerDirectives(listenerType protocol, s Server, port string) strin
 			ipAddress:     s.HTTPSIPv4,
 			port:          port,
 			tls:           true,
-			proxyProtocol: s.ProxyProtocol,
+			proxyProtocol: proxyProtocol,
 			udp:           false,
 			ipType:        ipv4,
 		})
@@ -112,16 +115,65 @@ func buildListenerDirectives(listenerType protocol, s Server, port string) strin
 				ipAddress:     s.HTTPSIPv6,
 				port:          port,
 				tls:           true,
-				proxyProtocol: s.ProxyProtocol,
+				proxyProtocol: proxyProtocol,
 				udp:           false,
 				ipType:        ipv6,
 			})
 		}
+		if s.HTTP3 {
+			directives += buildQUICListenerDirectives(s, port)
+		}
 	}
 
 	return directives
 }
 
+// buildQUICListenerDirectives builds the listen directives that accept HTTP/3 connections on the port of the HTTPS listener.
+// The reuseport parameter can only be specified once per address and port, so only one server of a listener sets it.
+func buildQUICListenerDirectives(s Server, port string) string {
+	directives := spacing
+	directives += buildListenDirective(listen{
+		ipAddress: s.HTTPSIPv4,
+		port:      port,
+		quic:      true,
+		reusePort: s.HTTP3ReusePort,
+		ipType:    ipv4,
+	})
+	if !s.DisableIPV6 {
+		directives += spacing
+		directives += buildListenDirective(listen{
+			ipAddress: s.HTTPSIPv6,
+			port:      port,
+			quic:      true,
+			reusePort: s.HTTP3ReusePort,
+			ipType:    ipv6,
+		})
+	}
+	return directives
+}
+
+// makeAltSvcHeader returns the value of the Alt-Svc header that advertises HTTP/3 on the port of the HTTPS listener.
+func makeAltSvcHeader(s Server) string {
+	port := getDefaultPort(https)
+	if s.CustomListeners {
+		port = getCustomPort(https, s)
+	}
+	return fmt.Sprintf(`h3=":%s"; ma=86400`, port)
+}
+
+// getProxyProtocol returns the PROXY protocol setting of a listener.
+// The setting of a custom listener, if present, overrides the global setting.
+func getProxyProtocol(listenerType protocol, s Server) bool {
+	override := s.HTTPProxyProtocol
+	if listenerType == https {
+		override = s.HTTPSProxyProtocol
+	}
+	if s.CustomListeners && override != nil {
+		return *override
+	}
+	return s.ProxyProtocol
+}
+
 func getDefaultPort(listenerType protocol) string {
 	s := Server{
 		HTTPPort:  80,
@@ -159,6 +211,14 @@ func buildListenDirective(l listen) string {
 		directive += " ssl"
 	}
 
+	if l.quic {
+		directive += " quic"
+	}
+
+	if l.reusePort {
+		directive += " reuseport"
+	}
+
 	if l.proxyProtocol {
 		directive += " proxy_protocol"
 	}
@@ -187,7 +247,7 @@ func makeTransportListener(s StreamServer) string {
 		ipAddress:     s.IPv4,
 		port:          port,
 		tls:           s.SSL.Enabled,
-		proxyProtocol: false,
+		proxyProtocol: s.ProxyProtocol,
 		udp:           s.UDP,
 		ipType:        ipv4,
 	})
@@ -198,7 +258,7 @@ func makeTransportListener(s StreamServer) string {
 			ipAddress:     s.IPv6,
 			port:          port,
 			tls:           s.SSL.Enabled,
-			proxyProtocol: false,
+			proxyProtocol: s.ProxyProtocol,
 			udp:           s.UDP,
 			ipType:        ipv6,
 		})
@@ -259,6 +319,7 @@ var helperFunctions = template.FuncMap{
 	"replaceAll":            strings.ReplaceAll,
 	"makeHTTPListener":      makeHTTPListener,
 	"makeHTTPSListener":     makeHTTPSListener,
+	"makeAltSvcHeader":      makeAltSvcHeader,
 	"makeSecretPath":        commonhelpers.MakeSecretPath,
 	"makeHeaderQueryValue":  makeHeaderQueryValue,
 	"makeTransportListener": makeTransportListener,
@@ -147,6 +147,11 @@ func TestToUpperInputString(t *testing.T) {
 	}
 }
 
+var (
+	proxyProtocolOn  = true
+	proxyProtocolOff = false
+)
+
 func TestMakeHTTPListener(t *testing.T) {
 	t.Parallel()
 
@@ -198,6 +203,33 @@ func TestMakeHTTPListener(t *testing.T) {
 			DisableIPV6:     false,
 			ProxyProtocol:   true,
 		}, expected: "listen 81 proxy_protocol;\n    listen [::]:81 proxy_protocol;\n"},
+		{server: Server{
+			CustomListeners:   true,
+			HTTPPort:          81,
+			DisableIPV6:       true,
+			ProxyProtocol:     true,
+			HTTPProxyProtocol: &proxyProtocolOff,
+		}, expected: "listen 81;\n"},
+		{server: Server{
+			CustomListeners:   true,
+			HTTPPort:          81,
+			DisableIPV6:       true,
+			ProxyProtocol:     false,
+			HTTPProxyProtocol: &proxyProtocolOn,
+		}, expected: "listen 81 proxy_protocol;\n"},
+		{server: Server{
+			CustomListeners:    true,
+			HTTPPort:           81,
+			DisableIPV6:        true,
+			ProxyProtocol:      false,
+			HTTPSProxyProtocol: &proxyProtocolOn,
+		}, expected: "listen 81;\n"},
+		{server: Server{
+			CustomListeners:   false,
+			DisableIPV6:       true,
+			ProxyProtocol:     false,
+			HTTPProxyProtocol: &proxyProtocolOn,
+		}, expected: "listen 80;\n"},
 	}
 
 	for _, tc := range testCases {
@@ -259,6 +291,40 @@ func TestMakeHTTPSListener(t *testing.T) {
 			DisableIPV6:     false,
 			ProxyProtocol:   true,
 		}, expected: "listen 444 ssl proxy_protocol;\n    listen [::]:444 ssl proxy_protocol;\n"},
+		{server: Server{
+			CustomListeners:    true,
+			HTTPSPort:          444,
+			DisableIPV6:        true,
+			ProxyProtocol:      true,
+			HTTPSProxyProtocol: &proxyProtocolOff,
+		}, expected: "listen 444 ssl;\n"},
+		{server: Server{
+			CustomListeners:    true,
+			HTTPSPort:          444,
+			DisableIPV6:        true,
+			ProxyProtocol:      false,
+			HTTPSProxyProtocol: &proxyProtocolOn,
+		}, expected: "listen 444 ssl proxy_protocol;\n"},
+		{server: Server{
+			CustomListeners: false,
+			DisableIPV6:     false,
+			ProxyProtocol:   true,
+			HTTP3:           true,
+		}, expected: "listen 443 ssl proxy_protocol;\n    listen [::]:443 ssl proxy_protocol;\n    listen 443 quic;\n    listen [::]:443 quic;\n"},
+		{server: Server{
+			CustomListeners: true,
+			HTTPSPort:       444,
+			DisableIPV6:     true,
+			HTTP3:           true,
+		}, expected: "listen 444 ssl;\n    listen 444 quic;\n"},
+		{server: Server{
+			CustomListeners: true,
+			HTTPSPort:       444,
+			HTTPSIPv4:       "192.168.0.2",
+			HTTPSIPv6:       "::1",
+			HTTP3:           true,
+			HTTP3ReusePort:  true,
+		}, expected: "listen 192.168.0.2:444 ssl;\n    listen [::1]:444 ssl;\n    listen 192.168.0.2:444 quic reuseport;\n    listen [::1]:444 quic reuseport;\n"},
 	}
 	for _, tc := range testCases {
 		got := makeHTTPSListener(tc.server)
@@ -268,6 +334,31 @@ func TestMakeHTTPSListener(t *testing.T) {
 	}
 }
 
+func TestMakeAltSvcHeader(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		server   Server
+		expected string
+	}{
+		{server: Server{
+			CustomListeners: false,
+			HTTP3:           true,
+		}, expected: `h3=":443"; ma=86400`},
+		{server: Server{
+			CustomListeners: true,
+			HTTPSPort:       8443,
+			HTTP3:           true,
+		}, expected: `h3=":8443"; ma=86400`},
+	}
+	for _, tc := range testCases {
+		got := makeAltSvcHeader(tc.server)
+		if got != tc.expected {
+			t.Errorf("makeAltSvcHeader() returned %v but expected %v.", got, tc.expected)
+		}
+	}
+}
+
 func TestMakeHTTPListenerAndHTTPSListenerWithCustomIPs(t *testing.T) {
 	t.Parallel()
 
@@ -529,6 +620,15 @@ func TestMakeTransportListener(t *testing.T) {
 			DisableIPV6: false,
 			Port:        5353,
 		}, expected: "listen 5353 ssl udp;\n    listen [::]:5353 ssl udp;\n"},
+		{server: StreamServer{
+			UDP: false,
+			SSL: &StreamSSL{
+				Enabled: true,
+			},
+			DisableIPV6:   false,
+			ProxyProtocol: true,
+			Port:          5353,
+		}, expected: "listen 5353 ssl proxy_protocol;\n    listen [::]:5353 ssl proxy_protocol;\n"},
 	}
 
 	for _, tc := range testCases {
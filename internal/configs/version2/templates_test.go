@@ -65,6 +65,29 @@ func TestExecuteVirtualServerTemplate_RendersTemplateWithServerGunzipOn(t *testi
 	t.Log(string(got))
 }
 
+func TestExecuteVirtualServerTemplate_RendersCORSPreflightOnlyForPreflightRequests(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		corsVirtualServerCfg := virtualServerCfg
+		corsVirtualServerCfg.Server.CORS = &CORS{
+			AllowOrigin:  "https://example.com",
+			AllowMethods: "GET, POST",
+		}
+
+		got, err := executor.ExecuteVirtualServerTemplate(&corsVirtualServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(got, []byte("if ($cors_preflight) {")) {
+			t.Error("want the preflight response to depend on `$cors_preflight`")
+		}
+		// a plain OPTIONS request must reach the upstream
+		if bytes.Contains(got, []byte("if ($request_method = OPTIONS) {")) {
+			t.Error("want no preflight response for every OPTIONS request")
+		}
+	}
+}
+
 func TestExecuteVirtualServerTemplate_RendersTemplateWithServerGunzipOff(t *testing.T) {
 	t.Parallel()
 	executor := newTmplExecutorNGINXPlus(t)
@@ -578,6 +601,278 @@ func TestTransportServerForNginx(t *testing.T) {
 	t.Log(string(data))
 }
 
+func TestExecuteTemplateForTransportServerWithConnectionLimit(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		connLimitTransportServerCfg := transportServerCfg
+		connLimitTransportServerCfg.LimitConnZones = []LimitConnZone{
+			{
+				Key:      "${binary_remote_addr}",
+				ZoneName: "pol_cl_default_conn_limit_default_tcp_server",
+				ZoneSize: "10M",
+			},
+		}
+		connLimitTransportServerCfg.Server.LimitConn = &LimitConn{
+			ZoneName:    "pol_cl_default_conn_limit_default_tcp_server",
+			Connections: 20,
+			DryRun:      true,
+		}
+
+		got, err := executor.ExecuteTransportServerTemplate(&connLimitTransportServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStrings := []string{
+			"limit_conn_zone ${binary_remote_addr} zone=pol_cl_default_conn_limit_default_tcp_server:10M;",
+			"limit_conn pol_cl_default_conn_limit_default_tcp_server 20;",
+			"limit_conn_dry_run on;",
+		}
+		for _, want := range wantStrings {
+			if !bytes.Contains(got, []byte(want)) {
+				t.Errorf("want `%s` in generated template", want)
+			}
+		}
+	}
+}
+
+func TestExecuteTemplateForTransportServerWithAccessControlAndIngressMTLS(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		policiesTransportServerCfg := transportServerCfgWithSSL
+		policiesTransportServerCfg.Server.Allow = []string{"10.0.0.0/8"}
+		policiesTransportServerCfg.Server.IngressMTLS = &IngressMTLS{
+			ClientCert:   "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt",
+			VerifyClient: "on",
+			VerifyDepth:  2,
+		}
+
+		got, err := executor.ExecuteTransportServerTemplate(&policiesTransportServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStrings := []string{
+			"ssl_client_certificate /etc/nginx/secrets/default-ingress-mtls-secret-ca.crt;",
+			"ssl_verify_client on;",
+			"ssl_verify_depth 2;",
+			"allow 10.0.0.0/8;",
+			"deny all;",
+		}
+		for _, want := range wantStrings {
+			if !bytes.Contains(got, []byte(want)) {
+				t.Errorf("want `%s` in generated template", want)
+			}
+		}
+	}
+}
+
+func TestExecuteTemplateForTransportServerWithDenyAll(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		denyAllTransportServerCfg := transportServerCfg
+		denyAllTransportServerCfg.Server.DenyAll = true
+
+		got, err := executor.ExecuteTransportServerTemplate(&denyAllTransportServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(got, []byte("deny all;")) {
+			t.Error("want `deny all;` in generated template")
+		}
+	}
+}
+
+func TestExecuteTemplateForTransportServerWithProxyProtocol(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		proxyProtocolTransportServerCfg := transportServerCfg
+		proxyProtocolTransportServerCfg.Server.UDP = false
+		proxyProtocolTransportServerCfg.Server.SSL = &StreamSSL{}
+		proxyProtocolTransportServerCfg.Server.ProxyProtocol = true
+		proxyProtocolTransportServerCfg.Server.SetRealIPFrom = []string{"192.168.0.0/16"}
+		proxyProtocolTransportServerCfg.Server.UpstreamProxyProtocol = true
+
+		got, err := executor.ExecuteTransportServerTemplate(&proxyProtocolTransportServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStrings := []string{
+			"listen 1234 proxy_protocol;",
+			"set_real_ip_from 192.168.0.0/16;",
+			"proxy_protocol on;",
+		}
+		for _, want := range wantStrings {
+			if !bytes.Contains(got, []byte(want)) {
+				t.Errorf("want `%s` in generated template", want)
+			}
+		}
+	}
+}
+
+func TestExecuteTemplateForTransportServerWithMatches(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		matchesTransportServerCfg := transportServerCfg
+		matchesTransportServerCfg.Server.UDP = false
+		matchesTransportServerCfg.Server.SSL = &StreamSSL{}
+		matchesTransportServerCfg.Server.SSLPreread = true
+		matchesTransportServerCfg.Server.ProxyPass = "$ts_default_tcp_server_matches"
+		matchesTransportServerCfg.Geos = []Geo{
+			{
+				Source:   "$remote_addr",
+				Variable: "$ts_default_tcp_server_match_0_cond_1_geo",
+				Parameters: []Parameter{
+					{Value: "default", Result: "0"},
+					{Value: "10.0.0.0/8", Result: "1"},
+				},
+			},
+		}
+		matchesTransportServerCfg.Maps = []Map{
+			{
+				Source:   "$ssl_preread_server_name",
+				Variable: "$ts_default_tcp_server_match_0_cond_0",
+				Parameters: []Parameter{
+					{Value: `"db.example.com"`, Result: "1"},
+					{Value: "default", Result: "0"},
+				},
+			},
+			{
+				Source:   "$ts_default_tcp_server_match_0_cond_0",
+				Variable: "$ts_default_tcp_server_matches",
+				Parameters: []Parameter{
+					{Value: "~^1", Result: "ts_default_tcp-server_postgres"},
+					{Value: "default", Result: "ts_default_tcp-server_tcp-app"},
+				},
+			},
+		}
+
+		got, err := executor.ExecuteTransportServerTemplate(&matchesTransportServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStrings := []string{
+			"geo $remote_addr $ts_default_tcp_server_match_0_cond_1_geo {",
+			"10.0.0.0/8 1;",
+			"map $ssl_preread_server_name $ts_default_tcp_server_match_0_cond_0 {",
+			`"db.example.com" 1;`,
+			"map $ts_default_tcp_server_match_0_cond_0 $ts_default_tcp_server_matches {",
+			"~^1 ts_default_tcp-server_postgres;",
+			"default ts_default_tcp-server_tcp-app;",
+			"ssl_preread on;",
+			"proxy_pass $ts_default_tcp_server_matches;",
+		}
+		for _, want := range wantStrings {
+			if !bytes.Contains(got, []byte(want)) {
+				t.Errorf("want `%s` in generated template", want)
+			}
+		}
+	}
+}
+
+func TestExecuteTemplateForTransportServerWithSplits(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		splitsTransportServerCfg := transportServerCfg
+		splitsTransportServerCfg.Server.ProxyPass = "$ts_default_tcp_server_splits_0"
+		splitsTransportServerCfg.SplitClients = []SplitClient{
+			{
+				Source:   "$remote_addr",
+				Variable: "$ts_default_tcp_server_splits_0",
+				Distributions: []Distribution{
+					{Weight: "90%", Value: "ts_default_tcp-server_tcp-app-v1"},
+					{Weight: "10%", Value: "ts_default_tcp-server_tcp-app-v2"},
+				},
+			},
+		}
+
+		got, err := executor.ExecuteTransportServerTemplate(&splitsTransportServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStrings := []string{
+			"split_clients $remote_addr $ts_default_tcp_server_splits_0 {",
+			"90% ts_default_tcp-server_tcp-app-v1;",
+			"10% ts_default_tcp-server_tcp-app-v2;",
+			"proxy_pass $ts_default_tcp_server_splits_0;",
+		}
+		for _, want := range wantStrings {
+			if !bytes.Contains(got, []byte(want)) {
+				t.Errorf("want `%s` in generated template", want)
+			}
+		}
+	}
+}
+
+func TestExecuteTemplateForNGINXPlusTransportServerWithSplitsWeightChangesDynamicReload(t *testing.T) {
+	t.Parallel()
+	executor := newTmplExecutorNGINXPlus(t)
+	splitsTransportServerCfg := transportServerCfg
+	splitsTransportServerCfg.Server.ProxyPass = "$ts_default_tcp_server_map_split_clients_0"
+	splitsTransportServerCfg.KeyValZones = []KeyValZone{
+		{
+			Name:  "ts_default_tcp_server_keyval_zone_split_clients_0",
+			Size:  "100k",
+			State: "/etc/nginx/state_files/ts_default_tcp_server_keyval_zone_split_clients_0.json",
+		},
+	}
+	splitsTransportServerCfg.KeyVals = []KeyVal{
+		{
+			Key:      `"ts_default_tcp_server_keyval_key_split_clients_0"`,
+			Variable: "$ts_default_tcp_server_keyval_split_clients_0",
+			ZoneName: "ts_default_tcp_server_keyval_zone_split_clients_0",
+		},
+	}
+
+	got, err := executor.ExecuteTransportServerTemplate(&splitsTransportServerCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStrings := []string{
+		"keyval_zone zone=ts_default_tcp_server_keyval_zone_split_clients_0:100k state=/etc/nginx/state_files/ts_default_tcp_server_keyval_zone_split_clients_0.json;",
+		`keyval "ts_default_tcp_server_keyval_key_split_clients_0" $ts_default_tcp_server_keyval_split_clients_0 zone=ts_default_tcp_server_keyval_zone_split_clients_0;`,
+	}
+	for _, want := range wantStrings {
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("want `%s` in generated template", want)
+		}
+	}
+}
+
+func TestExecuteTemplateForTransportServerWithUpstreamTLS(t *testing.T) {
+	t.Parallel()
+	for _, executor := range []*TemplateExecutor{newTmplExecutorNGINX(t), newTmplExecutorNGINXPlus(t)} {
+		upstreamTLSTransportServerCfg := transportServerCfg
+		upstreamTLSTransportServerCfg.Server.ProxySSL = &StreamProxySSL{
+			Certificate:    "/etc/nginx/secrets/default-mtls-secret",
+			CertificateKey: "/etc/nginx/secrets/default-mtls-secret",
+			TrustedCert:    "/etc/nginx/secrets/default-ca-secret-ca.crt",
+			VerifyServer:   true,
+			VerifyDepth:    2,
+			ServerName:     true,
+			SSLName:        "tcp-app-svc.default.svc",
+		}
+
+		got, err := executor.ExecuteTransportServerTemplate(&upstreamTLSTransportServerCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantStrings := []string{
+			"proxy_ssl on;",
+			"proxy_ssl_certificate /etc/nginx/secrets/default-mtls-secret;",
+			"proxy_ssl_certificate_key /etc/nginx/secrets/default-mtls-secret;",
+			"proxy_ssl_trusted_certificate /etc/nginx/secrets/default-ca-secret-ca.crt;",
+			"proxy_ssl_verify on;",
+			"proxy_ssl_verify_depth 2;",
+			"proxy_ssl_server_name on;",
+			"proxy_ssl_name tcp-app-svc.default.svc;",
+		}
+		for _, want := range wantStrings {
+			if !bytes.Contains(got, []byte(want)) {
+				t.Errorf("want `%s` in generated template", want)
+			}
+		}
+	}
+}
+
 func TestExecuteTemplateForTransportServerWithTCPIPListener(t *testing.T) {
 	t.Parallel()
 	executor := newTmplExecutorNGINXPlus(t)
@@ -820,6 +1115,316 @@ func TestExecuteVirtualServerTemplateWithAPIKeyPolicyNGINXPlus(t *testing.T) {
 	t.Log(string(got))
 }
 
+func TestExecuteVirtualServerTemplate_WithAPIKeyTiers(t *testing.T) {
+	t.Parallel()
+	executors := []*TemplateExecutor{newTmplExecutorNGINXPlus(t), newTmplExecutorNGINX(t)}
+
+	for _, e := range executors {
+		vscfg := vsConfig()
+		vscfg.Server.APIKey = &APIKey{
+			Header:      []string{"X-header-name"},
+			MapName:     "apikey_auth_client_name_default_cafe_apikey_policy",
+			TierMapName: "apikey_auth_client_name_default_cafe_apikey_policy_tier",
+		}
+
+		got, err := e.ExecuteVirtualServerTemplate(&vscfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := "js_var $apikey_tier $apikey_auth_client_name_default_cafe_apikey_policy_tier;"
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("want %q in generated template", want)
+		}
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithIngressMTLSForwardHeaders(t *testing.T) {
+	t.Parallel()
+	executors := []*TemplateExecutor{newTmplExecutorNGINXPlus(t), newTmplExecutorNGINX(t)}
+
+	for _, e := range executors {
+		vscfg := vsConfig()
+		vscfg.Server.IngressMTLS = &IngressMTLS{
+			ClientCert:   "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt",
+			VerifyClient: "on",
+			VerifyDepth:  1,
+			ForwardHeaders: []Header{
+				{Name: "X-Client-Subject-DN", Value: "$ingress_mtls_client_s_dn"},
+				{Name: "X-Client-SANs", Value: "$ingress_mtls_client_sans"},
+			},
+		}
+
+		got, err := e.ExecuteVirtualServerTemplate(&vscfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"proxy_set_header X-Client-Subject-DN $ingress_mtls_client_s_dn;",
+			"proxy_set_header X-Client-SANs $ingress_mtls_client_sans;",
+		}
+		for _, w := range want {
+			if !bytes.Contains(got, []byte(w)) {
+				t.Errorf("want %q in generated template", w)
+			}
+		}
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithHTTP3(t *testing.T) {
+	t.Parallel()
+	executors := []*TemplateExecutor{newTmplExecutorNGINXPlus(t), newTmplExecutorNGINX(t)}
+
+	for _, e := range executors {
+		vscfg := vsConfig()
+		vscfg.Server.CustomListeners = true
+		vscfg.Server.HTTPSPort = 8443
+		vscfg.Server.HTTP3 = true
+		vscfg.Server.HTTP3ReusePort = true
+		vscfg.Server.SSL = &SSL{
+			Certificate:    "/etc/nginx/secrets/default-tls-secret",
+			CertificateKey: "/etc/nginx/secrets/default-tls-secret",
+		}
+
+		got, err := e.ExecuteVirtualServerTemplate(&vscfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"listen 8443 ssl proxy_protocol;",
+			"listen 8443 quic reuseport;",
+			"listen [::]:8443 quic reuseport;",
+			`add_header Alt-Svc 'h3=":8443"; ma=86400' always;`,
+		}
+		for _, w := range want {
+			if !bytes.Contains(got, []byte(w)) {
+				t.Errorf("want %q in generated template", w)
+			}
+		}
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithHTTP3AndResponseHeaders(t *testing.T) {
+	t.Parallel()
+	executors := []*TemplateExecutor{newTmplExecutorNGINXPlus(t), newTmplExecutorNGINX(t)}
+
+	for _, e := range executors {
+		vscfg := vsConfig()
+		vscfg.Server.HTTP3 = true
+		vscfg.Server.SSL = &SSL{
+			Certificate:    "/etc/nginx/secrets/default-tls-secret",
+			CertificateKey: "/etc/nginx/secrets/default-tls-secret",
+		}
+		vscfg.Server.Locations = []Location{
+			{
+				Path:      "/latte",
+				ProxyPass: "http://test-upstream",
+				AddHeaders: []AddHeader{
+					{
+						Header: Header{
+							Name:  "X-Coffee",
+							Value: "espresso",
+						},
+					},
+				},
+			},
+		}
+
+		got, err := e.ExecuteVirtualServerTemplate(&vscfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the add_header directives of a location replace the ones of the server
+		start := bytes.Index(got, []byte("location /latte {"))
+		if start == -1 {
+			t.Fatal("want location /latte in generated template")
+		}
+		location := got[start:]
+		location = location[:bytes.Index(location, []byte("\n    }"))]
+
+		want := []string{
+			`add_header X-Coffee "espresso" ;`,
+			`add_header Alt-Svc 'h3=":443"; ma=86400' always;`,
+		}
+		for _, w := range want {
+			if !bytes.Contains(location, []byte(w)) {
+				t.Errorf("want %q in location /latte", w)
+			}
+		}
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithOCSP(t *testing.T) {
+	t.Parallel()
+	executors := []*TemplateExecutor{newTmplExecutorNGINXPlus(t), newTmplExecutorNGINX(t)}
+
+	for _, e := range executors {
+		vscfg := vsConfig()
+		vscfg.Server.SSL = &SSL{
+			Certificate:           "/etc/nginx/secrets/default-tls-secret",
+			CertificateKey:        "/etc/nginx/secrets/default-tls-secret",
+			OCSPStapling:          true,
+			OCSPStaplingResponder: "http://ocsp.example.com",
+			OCSPResolver: &Resolver{
+				Addresses: []string{"10.0.0.10", "10.0.0.11:53"},
+				Valid:     "30s",
+				Timeout:   "5s",
+			},
+		}
+		vscfg.Server.IngressMTLS = &IngressMTLS{
+			ClientCert:    "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt",
+			VerifyClient:  "on",
+			VerifyDepth:   1,
+			OCSP:          "leaf",
+			OCSPResponder: "http://ocsp.example.com",
+		}
+
+		got, err := e.ExecuteVirtualServerTemplate(&vscfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"ssl_stapling on;",
+			"ssl_stapling_responder http://ocsp.example.com;",
+			"resolver 10.0.0.10 10.0.0.11:53 valid=30s ipv6=off;",
+			"resolver_timeout 5s;",
+			"ssl_ocsp leaf;",
+			"ssl_ocsp_responder http://ocsp.example.com;",
+		}
+		for _, w := range want {
+			if !bytes.Contains(got, []byte(w)) {
+				t.Errorf("want %q in generated template", w)
+			}
+		}
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithSignatureValidation(t *testing.T) {
+	t.Parallel()
+	executors := []*TemplateExecutor{newTmplExecutorNGINXPlus(t), newTmplExecutorNGINX(t)}
+
+	for _, e := range executors {
+		vscfg := vsConfig()
+		vscfg.Server.SignatureValidationLocations = []SignatureValidationLocation{
+			{
+				Path:              "/webhooks",
+				NamedLocation:     "@signature_validation_0",
+				ClientMaxBodySize: "2m",
+				BodyBufferSize:    "2m",
+				Validation: &SignatureValidation{
+					KeyFile:            "/etc/nginx/secrets/default-hmac-secret",
+					Format:             "plain",
+					Algorithm:          "sha256",
+					Header:             "X-Hub-Signature-256",
+					TimestampTolerance: 300,
+					RejectCode:         401,
+				},
+				Deny:      []string{"10.0.0.1"},
+				LimitReqs: []LimitReq{{ZoneName: "pol_rl_default_rate-limit_default_cafe", Burst: 5}},
+				LimitConn: &LimitConn{ZoneName: "pol_cl_default_conn-limit_default_cafe", Connections: 10},
+			},
+		}
+
+		got, err := e.ExecuteVirtualServerTemplate(&vscfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			"location /webhooks {\n        deny 10.0.0.1;\n        allow all;\n" +
+				"        limit_req zone=pol_rl_default_rate-limit_default_cafe burst=5;\n" +
+				"        limit_conn pol_cl_default_conn-limit_default_cafe 10;\n",
+			`set $signature_validation_key_file "/etc/nginx/secrets/default-hmac-secret";`,
+			`set $signature_validation_header "X-Hub-Signature-256";`,
+			`set $signature_validation_location "@signature_validation_0";`,
+			"client_body_buffer_size 2m;",
+			"js_content signature_validation.validate;",
+		}
+		for _, w := range want {
+			if !bytes.Contains(got, []byte(w)) {
+				t.Errorf("want %q in generated template", w)
+			}
+		}
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithJWTRequireForPlus(t *testing.T) {
+	t.Parallel()
+	executor := newTmplExecutorNGINXPlus(t)
+
+	vscfg := vsConfig()
+	vscfg.Server.Locations[0].JWTAuth = &JWTAuth{
+		Secret:  "/etc/nginx/secrets/default-jwt-secret",
+		Realm:   "My Api",
+		Require: []string{"$jwt_default_jwt_policy_default_test_iss", "$jwt_default_jwt_policy_default_test_aud"},
+	}
+
+	got, err := executor.ExecuteVirtualServerTemplate(&vscfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "auth_jwt_require $jwt_default_jwt_policy_default_test_iss $jwt_default_jwt_policy_default_test_aud;"
+	if !bytes.Contains(got, []byte(want)) {
+		t.Errorf("want %q in generated template", want)
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithJWTAuthForNGINX(t *testing.T) {
+	t.Parallel()
+	executor := newTmplExecutorNGINX(t)
+
+	vscfg := vsConfig()
+	vscfg.Server.JWTAuthEnabled = true
+	vscfg.Server.JWTAuth = &JWTAuth{
+		Secret: "/etc/nginx/secrets/default-jwt-secret",
+		Realm:  "My Api",
+	}
+
+	got, err := executor.ExecuteVirtualServerTemplate(&vscfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"location = /_validate_jwt_njs {",
+		"subrequest_output_buffer_size 64k;",
+		"js_content jwt_auth.validate;",
+	}
+	for _, w := range want {
+		if !bytes.Contains(got, []byte(w)) {
+			t.Errorf("want %q in generated template", w)
+		}
+	}
+}
+
+func TestExecuteVirtualServerTemplate_WithJWTClaimVariables(t *testing.T) {
+	t.Parallel()
+	executor := newTmplExecutorNGINX(t)
+
+	vscfg := vsConfig()
+	vscfg.Server.JWTClaimVariables = []string{"$jwt_claim_email", "$jwt_claim_tenant"}
+
+	got, err := executor.ExecuteVirtualServerTemplate(&vscfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"js_var $jwt_claim_email;",
+		"js_var $jwt_claim_tenant;",
+	}
+	for _, w := range want {
+		if !bytes.Contains(got, []byte(w)) {
+			t.Errorf("want %q in generated template", w)
+		}
+	}
+}
+
 func TestExecuteVirtualServerTemplate_WithCustomOIDCRedirectLocation(t *testing.T) {
 	t.Parallel()
 	executor := newTmplExecutorNGINXPlus(t)
@@ -851,6 +1456,69 @@ func TestExecuteVirtualServerTemplate_WithCustomOIDCRedirectLocation(t *testing.
 	}
 }
 
+func TestExecuteVirtualServerTemplate_WithOIDCClaimHeadersAndBackchannelLogout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		executor *TemplateExecutor
+		variable string
+		want     []string
+		msg      string
+	}{
+		{
+			executor: newTmplExecutorNGINXPlus(t),
+			variable: "$jwt_default_cafe_email",
+			want: []string{
+				"set $oidc_refresh_leeway 60;",
+				"auth_jwt_require $oidc_session_valid;",
+				"proxy_set_header X-User-Email $jwt_default_cafe_email;",
+			},
+			msg: "nginx plus",
+		},
+		{
+			executor: newTmplExecutorNGINX(t),
+			variable: "$oidc_claim_email",
+			want: []string{
+				"set $oidc_refresh_leeway 60;",
+				`set $oidc_claims "email";`,
+				"js_var $oidc_claim_email;",
+				"proxy_set_header X-User-Email $oidc_claim_email;",
+			},
+			msg: "nginx",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.msg, func(t *testing.T) {
+			t.Parallel()
+			cfg := virtualServerCfg
+			oidc := *cfg.Server.OIDC
+			oidc.ClaimHeaders = []OIDCClaimHeader{{Header: "X-User-Email", Claim: "email", Variable: tc.variable}}
+			oidc.RefreshLeeway = 60
+			oidc.BackchannelLogoutURI = "/back_channel_logout"
+			cfg.Server.OIDC = &oidc
+			cfg.Server.Locations = []Location{
+				{
+					Path:      "/",
+					ProxyPass: "http://test-upstream",
+					OIDC:      true,
+				},
+			}
+
+			got, err := tc.executor.ExecuteVirtualServerTemplate(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := append(tc.want, "location = /back_channel_logout {", "js_content oidc.backchannelLogout;")
+			for _, w := range want {
+				if !bytes.Contains(got, []byte(w)) {
+					t.Errorf("want %q in generated template", w)
+				}
+			}
+		})
+	}
+}
+
 func TestExecuteVirtualServerTemplateWithOIDCAndPKCEPolicyNGINXPlus(t *testing.T) {
 	t.Parallel()
 
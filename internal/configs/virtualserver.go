@@ -2,16 +2,19 @@ package configs
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/base64"
-	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nginx/kubernetes-ingress/internal/configs/version2"
 	"github.com/nginx/kubernetes-ingress/internal/k8s/secrets"
@@ -93,6 +96,14 @@ type VirtualServerEx struct {
 	HTTPIPv6            string
 	HTTPSIPv4           string
 	HTTPSIPv6           string
+	HTTPProxyProtocol   *bool
+	HTTPSProxyProtocol  *bool
+	HTTPSetRealIPFrom   []string
+	HTTPSSetRealIPFrom  []string
+	HTTPRealIPHeader    string
+	HTTPSRealIPHeader   string
+	HTTP3               bool
+	HTTP3ReusePort      bool
 	Endpoints           map[string][]string
 	VirtualServerRoutes []*conf_v1.VirtualServerRoute
 	ExternalNameSvcs    map[string]bool
@@ -186,20 +197,32 @@ func (namer *upstreamNamer) GetNameForUpstreamFromAction(action *conf_v1.Action)
 	return fmt.Sprintf("%s_%s", namer.prefix, upstream)
 }
 
+func (namer *upstreamNamer) GetNameForMirrorUpstreamFromAction(action *conf_v1.Action) string {
+	if action.Proxy == nil || action.Proxy.Mirror == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s_%s", namer.prefix, action.Proxy.Mirror.Upstream)
+}
+
 func (namer *upstreamNamer) GetNameForUpstream(upstream string) string {
 	return fmt.Sprintf("%s_%s", namer.prefix, upstream)
 }
 
 // VariableNamer is a namer which generates unique variable names for a VirtualServer.
 type VariableNamer struct {
-	safeNsName string
+	safeNsName  string
+	vsNamespace string
+	vsName      string
 }
 
 // NewVSVariableNamer creates a new namer for a VirtualServer.
 func NewVSVariableNamer(virtualServer *conf_v1.VirtualServer) *VariableNamer {
 	safeNsName := strings.ReplaceAll(fmt.Sprintf("%s_%s", virtualServer.Namespace, virtualServer.Name), "-", "_")
 	return &VariableNamer{
-		safeNsName: safeNsName,
+		safeNsName:  safeNsName,
+		vsNamespace: virtualServer.Namespace,
+		vsName:      virtualServer.Name,
 	}
 }
 
@@ -238,6 +261,16 @@ func (namer *VariableNamer) GetNameForSplitClientVariable(index int) string {
 	return fmt.Sprintf("$vs_%s_splits_%d", namer.safeNsName, index)
 }
 
+// GetNameForSplitKeyVariable gets the name of a variable which holds the key of split clients for a particular source.
+func (namer *VariableNamer) GetNameForSplitKeyVariable(source string) string {
+	return fmt.Sprintf("$vs_%s_split_key_%s", namer.safeNsName, strings.TrimPrefix(source, "$"))
+}
+
+// GetNameForMirrorVariable gets the name of a split client variable for mirroring a particular percentage of requests.
+func (namer *VariableNamer) GetNameForMirrorVariable(percentage int) string {
+	return fmt.Sprintf("$vs_%s_mirror_%d", namer.safeNsName, percentage)
+}
+
 // GetNameForVariableForMatchesRouteMap gets the name of a matches route map
 func (namer *VariableNamer) GetNameForVariableForMatchesRouteMap(
 	matchesIndex int,
@@ -352,6 +385,14 @@ func (vsc *virtualServerConfigurator) generateEndpointsForUpstream(
 	upstream conf_v1.Upstream,
 	virtualServerEx *VirtualServerEx,
 ) []string {
+	if isStaticUpstream(upstream) {
+		if upstream.Resolve && !vsc.isResolverConfigured {
+			msgFmt := "Re-resolving of the servers in upstream %v will be disabled. To re-resolve the servers, a resolver must be configured in the ConfigMap"
+			vsc.addWarningf(owner, msgFmt, upstream.Name)
+		}
+		return upstream.Servers
+	}
+
 	endpointsKey := GenerateEndpointsKey(namespace, upstream.Service, upstream.Subselector, upstream.Port)
 	externalNameSvcKey := GenerateExternalNameSvcKey(namespace, upstream.Service)
 	endpoints := virtualServerEx.Endpoints[endpointsKey]
@@ -403,6 +444,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 	vsc.clearWarnings()
 
 	var maps []version2.Map
+	var geos []version2.Geo
 	useCustomListeners := false
 
 	if vsEx.VirtualServer.Spec.Listener != nil {
@@ -427,6 +469,12 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		vsName:         vsEx.VirtualServer.Name,
 	}
 	policiesCfg := vsc.generatePolicies(ownerDetails, vsEx.VirtualServer.Spec.Policies, vsEx.Policies, specContext, "/", policyOpts)
+	policyOpts.authenticated = policiesCfg.hasAuthentication(vsc.isPlus)
+	policyOpts.authRequestPolicies = policiesCfg.authRequestPolicies(vsc.isPlus)
+
+	if isOCSPResolverRequired(sslConfig, policiesCfg.IngressMTLS) && !vsc.isResolverConfigured {
+		vsc.addWarningf(vsEx.VirtualServer, "OCSP requires a resolver for the hostnames of the OCSP responders. Configure the resolver in tls.ocsp of the VirtualServer or in the ConfigMap")
+	}
 
 	if policiesCfg.JWTAuth.JWKSEnabled {
 		jwtAuthKey := policiesCfg.JWTAuth.Auth.Key
@@ -448,6 +496,20 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		maps = append(maps, policiesCfg.RateLimit.PolicyGroupMaps...)
 	}
 
+	if policiesCfg.CORS.OriginMap != nil {
+		maps = append(maps, *policiesCfg.CORS.OriginMap)
+	}
+
+	externalAuths := make(map[string]externalAuth)
+	if policiesCfg.ExternalAuth.Auth != nil {
+		externalAuths[policiesCfg.ExternalAuth.Location.Path] = policiesCfg.ExternalAuth
+	}
+
+	faultInjections := make(map[string]faultInjection)
+	if policiesCfg.FaultInjection.Fault != nil {
+		faultInjections[policiesCfg.FaultInjection.SplitClient.Variable] = policiesCfg.FaultInjection
+	}
+
 	dosCfg := generateDosCfg(dosResources[""])
 
 	// enabledInternalRoutes controls if a virtual server is configured as an internal route.
@@ -466,10 +528,21 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 	var statusMatches []version2.StatusMatch
 	var healthChecks []version2.HealthCheck
 	var limitReqZones []version2.LimitReqZone
+	var cacheZones []version2.CacheZone
+	var limitConnZones []version2.LimitConnZone
 	var authJWTClaimSets []version2.AuthJWTClaimSet
 
 	limitReqZones = append(limitReqZones, policiesCfg.RateLimit.Zones...)
+	if policiesCfg.Cache.Zone != nil {
+		cacheZones = append(cacheZones, *policiesCfg.Cache.Zone)
+	}
+	if policiesCfg.ConnectionLimit.Zone != nil {
+		limitConnZones = append(limitConnZones, *policiesCfg.ConnectionLimit.Zone)
+	}
 	authJWTClaimSets = append(authJWTClaimSets, policiesCfg.RateLimit.AuthJWTClaimSets...)
+	authJWTClaimSets = append(authJWTClaimSets, policiesCfg.Authorization.AuthJWTClaimSets...)
+	maps = append(maps, policiesCfg.Authorization.Maps...)
+	maps = append(maps, policiesCfg.JWTAuth.Maps...)
 
 	// generate upstreams for VirtualServer
 	for _, u := range vsEx.VirtualServer.Spec.Upstreams {
@@ -526,6 +599,9 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 
 	// generates config for VirtualServer routes
 	for _, r := range vsEx.VirtualServer.Spec.Routes {
+		dosRouteCfg := generateDosCfg(dosResources[r.Path])
+		r.Path = GenerateRoutePath(r)
+
 		errorPages := generateErrorPageDetails(r.ErrorPages, errorPageLocations, vsEx.VirtualServer)
 		errorPageLocations = append(errorPageLocations, generateErrorPageLocations(errorPages.index, errorPages.pages)...)
 
@@ -567,6 +643,9 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		if policiesCfg.OIDC {
 			routePoliciesCfg.OIDC = policiesCfg.OIDC
 		}
+		if routePoliciesCfg.JWTAuth.Enabled {
+			policiesCfg.JWTAuth.Enabled = routePoliciesCfg.JWTAuth.Enabled
+		}
 		if routePoliciesCfg.JWTAuth.JWKSEnabled {
 			policiesCfg.JWTAuth.JWKSEnabled = routePoliciesCfg.JWTAuth.JWKSEnabled
 
@@ -598,11 +677,30 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 			maps = append(maps, routePoliciesCfg.RateLimit.PolicyGroupMaps...)
 		}
 
+		if routePoliciesCfg.CORS.OriginMap != nil {
+			maps = append(maps, *routePoliciesCfg.CORS.OriginMap)
+		}
+
+		if routePoliciesCfg.ExternalAuth.Auth != nil {
+			externalAuths[routePoliciesCfg.ExternalAuth.Location.Path] = routePoliciesCfg.ExternalAuth
+		}
+		if routePoliciesCfg.FaultInjection.Fault != nil {
+			faultInjections[routePoliciesCfg.FaultInjection.SplitClient.Variable] = routePoliciesCfg.FaultInjection
+		}
+
 		limitReqZones = append(limitReqZones, routePoliciesCfg.RateLimit.Zones...)
+		if routePoliciesCfg.Cache.Zone != nil {
+			cacheZones = append(cacheZones, *routePoliciesCfg.Cache.Zone)
+		}
+		if routePoliciesCfg.ConnectionLimit.Zone != nil {
+			limitConnZones = append(limitConnZones, *routePoliciesCfg.ConnectionLimit.Zone)
+		}
 
 		authJWTClaimSets = append(authJWTClaimSets, routePoliciesCfg.RateLimit.AuthJWTClaimSets...)
-
-		dosRouteCfg := generateDosCfg(dosResources[r.Path])
+		authJWTClaimSets = append(authJWTClaimSets, routePoliciesCfg.Authorization.AuthJWTClaimSets...)
+		maps = append(maps, routePoliciesCfg.Authorization.Maps...)
+		maps = append(maps, routePoliciesCfg.JWTAuth.Maps...)
+		authJWTClaimSets = append(authJWTClaimSets, generateSplitKeyAuthJwtClaimSets(r, ownerDetails)...)
 
 		if len(r.Matches) > 0 {
 			cfg := generateMatchesConfig(
@@ -626,6 +724,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 			addDosConfigToLocations(dosRouteCfg, cfg.Locations)
 
 			maps = append(maps, cfg.Maps...)
+			geos = append(geos, cfg.Geos...)
 			locations = append(locations, cfg.Locations...)
 			internalRedirectLocations = append(internalRedirectLocations, cfg.InternalRedirectLocation)
 			returnLocations = append(returnLocations, cfg.ReturnLocations...)
@@ -650,10 +749,11 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		} else {
 			upstreamName := virtualServerUpstreamNamer.GetNameForUpstreamFromAction(r.Action)
 			upstream := crUpstreams[upstreamName]
+			mirrorUpstreamName := virtualServerUpstreamNamer.GetNameForMirrorUpstreamFromAction(r.Action)
 
 			proxySSLName := generateProxySSLName(upstream.Service, vsEx.VirtualServer.Namespace)
 
-			loc, returnLoc := generateLocation(r.Path, upstreamName, upstream, r.Action, vsc.cfgParams, errorPages, false,
+			loc, returnLoc := generateLocation(r.Path, upstreamName, upstream, r.Action, mirrorUpstreamName, vsc.cfgParams, errorPages, false,
 				proxySSLName, r.Path, vsLocSnippets, vsc.enableSnippets, len(returnLocations), isVSR, "", "", vsc.warnings)
 			addPoliciesCfgToLocation(routePoliciesCfg, &loc)
 			loc.Dos = dosRouteCfg
@@ -670,6 +770,9 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		isVSR := true
 		upstreamNamer := NewUpstreamNamerForVirtualServerRoute(vsEx.VirtualServer, vsr)
 		for _, r := range vsr.Spec.Subroutes {
+			dosRouteCfg := generateDosCfg(dosResources[r.Path])
+			r.Path = GenerateRoutePath(r)
+
 			errorPages := generateErrorPageDetails(r.ErrorPages, errorPageLocations, vsr)
 			errorPageLocations = append(errorPageLocations, generateErrorPageLocations(errorPages.index, errorPages.pages)...)
 			vsrNamespaceName := fmt.Sprintf("%v/%v", vsr.Namespace, vsr.Name)
@@ -716,6 +819,9 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 			if policiesCfg.OIDC {
 				routePoliciesCfg.OIDC = policiesCfg.OIDC
 			}
+			if routePoliciesCfg.JWTAuth.Enabled {
+				policiesCfg.JWTAuth.Enabled = routePoliciesCfg.JWTAuth.Enabled
+			}
 			if routePoliciesCfg.JWTAuth.JWKSEnabled {
 				policiesCfg.JWTAuth.JWKSEnabled = routePoliciesCfg.JWTAuth.JWKSEnabled
 
@@ -747,11 +853,30 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 				maps = append(maps, routePoliciesCfg.RateLimit.PolicyGroupMaps...)
 			}
 
+			if routePoliciesCfg.CORS.OriginMap != nil {
+				maps = append(maps, *routePoliciesCfg.CORS.OriginMap)
+			}
+
+			if routePoliciesCfg.ExternalAuth.Auth != nil {
+				externalAuths[routePoliciesCfg.ExternalAuth.Location.Path] = routePoliciesCfg.ExternalAuth
+			}
+			if routePoliciesCfg.FaultInjection.Fault != nil {
+				faultInjections[routePoliciesCfg.FaultInjection.SplitClient.Variable] = routePoliciesCfg.FaultInjection
+			}
+
 			limitReqZones = append(limitReqZones, routePoliciesCfg.RateLimit.Zones...)
+			if routePoliciesCfg.Cache.Zone != nil {
+				cacheZones = append(cacheZones, *routePoliciesCfg.Cache.Zone)
+			}
+			if routePoliciesCfg.ConnectionLimit.Zone != nil {
+				limitConnZones = append(limitConnZones, *routePoliciesCfg.ConnectionLimit.Zone)
+			}
 
 			authJWTClaimSets = append(authJWTClaimSets, routePoliciesCfg.RateLimit.AuthJWTClaimSets...)
-
-			dosRouteCfg := generateDosCfg(dosResources[r.Path])
+			authJWTClaimSets = append(authJWTClaimSets, routePoliciesCfg.Authorization.AuthJWTClaimSets...)
+			maps = append(maps, routePoliciesCfg.Authorization.Maps...)
+			maps = append(maps, routePoliciesCfg.JWTAuth.Maps...)
+			authJWTClaimSets = append(authJWTClaimSets, generateSplitKeyAuthJwtClaimSets(r, ownerDetails)...)
 
 			if len(r.Matches) > 0 {
 				cfg := generateMatchesConfig(
@@ -776,6 +901,7 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 				addDosConfigToLocations(dosRouteCfg, cfg.Locations)
 
 				maps = append(maps, cfg.Maps...)
+				geos = append(geos, cfg.Geos...)
 				locations = append(locations, cfg.Locations...)
 				internalRedirectLocations = append(internalRedirectLocations, cfg.InternalRedirectLocation)
 				returnLocations = append(returnLocations, cfg.ReturnLocations...)
@@ -801,9 +927,10 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 			} else {
 				upstreamName := upstreamNamer.GetNameForUpstreamFromAction(r.Action)
 				upstream := crUpstreams[upstreamName]
+				mirrorUpstreamName := upstreamNamer.GetNameForMirrorUpstreamFromAction(r.Action)
 				proxySSLName := generateProxySSLName(upstream.Service, vsr.Namespace)
 
-				loc, returnLoc := generateLocation(r.Path, upstreamName, upstream, r.Action, vsc.cfgParams, errorPages, false,
+				loc, returnLoc := generateLocation(r.Path, upstreamName, upstream, r.Action, mirrorUpstreamName, vsc.cfgParams, errorPages, false,
 					proxySSLName, r.Path, locSnippets, vsc.enableSnippets, len(returnLocations), isVSR, vsr.Name, vsr.Namespace, vsc.warnings)
 				addPoliciesCfgToLocation(routePoliciesCfg, &loc)
 				loc.Dos = dosRouteCfg
@@ -818,6 +945,45 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 
 	for mapName, apiKeyClients := range policiesCfg.APIKey.ClientMap {
 		maps = append(maps, *generateAPIKeyClientMap(mapName, apiKeyClients))
+		if tierMap := generateAPIKeyTierMap(mapName, apiKeyClients); tierMap != nil {
+			maps = append(maps, *tierMap)
+		}
+	}
+
+	addCacheIgnoreHeaders(locations, policiesCfg.Cache.Cache)
+
+	mirrorLocations, mirrorSplitClients := vsc.generateMirrorLocations(vsEx.VirtualServer, locations, crUpstreams, VariableNamer)
+	splitClients = append(splitClients, mirrorSplitClients...)
+
+	signatureValidationLocations := generateSignatureValidationLocations(locations, policiesCfg.SignatureValidation, vsc.cfgParams.ClientMaxBodySize)
+
+	externalAuthUpstreams, externalAuthLocations := vsc.generateExternalAuthConfigs(vsEx, externalAuths)
+	upstreams = append(upstreams, externalAuthUpstreams...)
+
+	faultInjectionSplitClients, faultInjectionMaps, faultInjectionGeos, faultInjectionLocations := generateFaultInjectionConfigs(faultInjections)
+	splitClients = append(splitClients, faultInjectionSplitClients...)
+	maps = append(maps, faultInjectionMaps...)
+	geos = append(geos, faultInjectionGeos...)
+
+	if vsc.isPlus && vsc.oidcPolCfg.oidc != nil {
+		for _, h := range vsc.oidcPolCfg.oidc.ClaimHeaders {
+			authJWTClaimSets = append(authJWTClaimSets, version2.AuthJWTClaimSet{
+				Variable: h.Variable,
+				Claim:    generateAuthJwtClaimSetClaim(h.Claim),
+			})
+		}
+	}
+
+	var jwtClaimVariables []string
+	if !vsc.isPlus {
+		jwtClaimVariables = generateJWTClaimVariables(vsEx, vsc.enableSnippets)
+	}
+
+	setRealIPFrom, realIPHeader := vsc.generateRealIPConfig(vsEx, useCustomListeners)
+
+	http3 := vsc.cfgParams.HTTP3
+	if useCustomListeners {
+		http3 = vsEx.HTTP3
 	}
 
 	httpSnippets := generateSnippets(vsc.enableSnippets, vsEx.VirtualServer.Spec.HTTPSnippets, []string{})
@@ -835,54 +1001,73 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 		Upstreams:        upstreams,
 		SplitClients:     splitClients,
 		Maps:             removeDuplicateMaps(maps),
+		Geos:             geos,
 		StatusMatches:    statusMatches,
 		LimitReqZones:    removeDuplicateLimitReqZones(limitReqZones),
+		LimitConnZones:   removeDuplicateLimitConnZones(limitConnZones),
+		CacheZones:       removeDuplicateCacheZones(cacheZones),
 		AuthJWTClaimSets: removeDuplicateAuthJWTClaimSets(authJWTClaimSets),
 		HTTPSnippets:     httpSnippets,
 		Server: version2.Server{
-			ServerName:                vsEx.VirtualServer.Spec.Host,
-			Gunzip:                    vsEx.VirtualServer.Spec.Gunzip,
-			StatusZone:                vsEx.VirtualServer.Spec.Host,
-			HTTPPort:                  vsEx.HTTPPort,
-			HTTPSPort:                 vsEx.HTTPSPort,
-			HTTPIPv4:                  vsEx.HTTPIPv4,
-			HTTPIPv6:                  vsEx.HTTPIPv6,
-			HTTPSIPv4:                 vsEx.HTTPSIPv4,
-			HTTPSIPv6:                 vsEx.HTTPSIPv6,
-			CustomListeners:           useCustomListeners,
-			ProxyProtocol:             vsc.cfgParams.ProxyProtocol,
-			SSL:                       sslConfig,
-			ServerTokens:              vsc.cfgParams.ServerTokens,
-			SetRealIPFrom:             vsc.cfgParams.SetRealIPFrom,
-			RealIPHeader:              vsc.cfgParams.RealIPHeader,
-			RealIPRecursive:           vsc.cfgParams.RealIPRecursive,
-			Snippets:                  serverSnippets,
-			InternalRedirectLocations: internalRedirectLocations,
-			Locations:                 locations,
-			ReturnLocations:           returnLocations,
-			HealthChecks:              healthChecks,
-			TLSRedirect:               tlsRedirectConfig,
-			ErrorPageLocations:        errorPageLocations,
-			TLSPassthrough:            vsc.isTLSPassthrough,
-			Allow:                     policiesCfg.Allow,
-			Deny:                      policiesCfg.Deny,
-			LimitReqOptions:           policiesCfg.RateLimit.Options,
-			LimitReqs:                 policiesCfg.RateLimit.Reqs,
-			JWTAuth:                   policiesCfg.JWTAuth.Auth,
-			BasicAuth:                 policiesCfg.BasicAuth,
-			JWTAuthList:               policiesCfg.JWTAuth.List,
-			JWKSAuthEnabled:           policiesCfg.JWTAuth.JWKSEnabled,
-			IngressMTLS:               policiesCfg.IngressMTLS,
-			EgressMTLS:                policiesCfg.EgressMTLS,
-			APIKey:                    policiesCfg.APIKey.Key,
-			APIKeyEnabled:             policiesCfg.APIKey.Enabled,
-			OIDC:                      vsc.oidcPolCfg.oidc,
-			WAF:                       policiesCfg.WAF,
-			Dos:                       dosCfg,
-			PoliciesErrorReturn:       policiesCfg.ErrorReturn,
-			VSNamespace:               vsEx.VirtualServer.Namespace,
-			VSName:                    vsEx.VirtualServer.Name,
-			DisableIPV6:               vsc.isIPV6Disabled,
+			ServerName:                   vsEx.VirtualServer.Spec.Host,
+			Gunzip:                       vsEx.VirtualServer.Spec.Gunzip,
+			StatusZone:                   vsEx.VirtualServer.Spec.Host,
+			HTTPPort:                     vsEx.HTTPPort,
+			HTTPSPort:                    vsEx.HTTPSPort,
+			HTTPIPv4:                     vsEx.HTTPIPv4,
+			HTTPIPv6:                     vsEx.HTTPIPv6,
+			HTTPSIPv4:                    vsEx.HTTPSIPv4,
+			HTTPSIPv6:                    vsEx.HTTPSIPv6,
+			CustomListeners:              useCustomListeners,
+			ProxyProtocol:                vsc.cfgParams.ProxyProtocol,
+			HTTPProxyProtocol:            vsEx.HTTPProxyProtocol,
+			HTTPSProxyProtocol:           vsEx.HTTPSProxyProtocol,
+			HTTP3:                        http3,
+			HTTP3ReusePort:               useCustomListeners && vsEx.HTTP3ReusePort,
+			SSL:                          sslConfig,
+			ServerTokens:                 vsc.cfgParams.ServerTokens,
+			SetRealIPFrom:                setRealIPFrom,
+			RealIPHeader:                 realIPHeader,
+			RealIPRecursive:              vsc.cfgParams.RealIPRecursive,
+			Snippets:                     serverSnippets,
+			InternalRedirectLocations:    internalRedirectLocations,
+			SignatureValidationLocations: signatureValidationLocations,
+			Locations:                    locations,
+			ReturnLocations:              returnLocations,
+			MirrorLocations:              mirrorLocations,
+			ExternalAuthLocations:        externalAuthLocations,
+			FaultInjectionLocations:      faultInjectionLocations,
+			HealthChecks:                 healthChecks,
+			TLSRedirect:                  tlsRedirectConfig,
+			ErrorPageLocations:           errorPageLocations,
+			TLSPassthrough:               vsc.isTLSPassthrough,
+			Allow:                        policiesCfg.Allow,
+			Deny:                         policiesCfg.Deny,
+			LimitReqOptions:              policiesCfg.RateLimit.Options,
+			LimitReqs:                    policiesCfg.RateLimit.Reqs,
+			LimitConn:                    policiesCfg.ConnectionLimit.Conn,
+			Authorization:                policiesCfg.Authorization.Auth,
+			JWTAuth:                      policiesCfg.JWTAuth.Auth,
+			BasicAuth:                    policiesCfg.BasicAuth,
+			JWTAuthList:                  policiesCfg.JWTAuth.List,
+			JWKSAuthEnabled:              policiesCfg.JWTAuth.JWKSEnabled,
+			JWTAuthEnabled:               policiesCfg.JWTAuth.Enabled,
+			IngressMTLS:                  policiesCfg.IngressMTLS,
+			EgressMTLS:                   policiesCfg.EgressMTLS,
+			APIKey:                       policiesCfg.APIKey.Key,
+			APIKeyEnabled:                policiesCfg.APIKey.Enabled,
+			CORS:                         policiesCfg.CORS.Headers,
+			ExternalAuth:                 policiesCfg.ExternalAuth.Auth,
+			Cache:                        policiesCfg.Cache.Cache,
+			FaultInjection:               policiesCfg.FaultInjection.Fault,
+			OIDC:                         vsc.oidcPolCfg.oidc,
+			JWTClaimVariables:            jwtClaimVariables,
+			WAF:                          policiesCfg.WAF,
+			Dos:                          dosCfg,
+			PoliciesErrorReturn:          policiesCfg.ErrorReturn,
+			VSNamespace:                  vsEx.VirtualServer.Namespace,
+			VSName:                       vsEx.VirtualServer.Name,
+			DisableIPV6:                  vsc.isIPV6Disabled,
 		},
 		SpiffeCerts:             enabledInternalRoutes,
 		SpiffeClientCerts:       vsc.spiffeCerts && !enabledInternalRoutes,
@@ -896,6 +1081,63 @@ func (vsc *virtualServerConfigurator) GenerateVirtualServerConfig(
 	return vsCfg, vsc.warnings
 }
 
+// generateExternalAuthConfigs generates the upstreams and the internal locations for the auth services
+// referenced by the ExternalAuth policies of a VirtualServer and its VirtualServerRoutes.
+func (vsc *virtualServerConfigurator) generateExternalAuthConfigs(
+	vsEx *VirtualServerEx,
+	externalAuths map[string]externalAuth,
+) ([]version2.Upstream, []version2.ExternalAuthLocation) {
+	var upstreams []version2.Upstream
+	var locations []version2.ExternalAuthLocation
+
+	paths := make([]string, 0, len(externalAuths))
+	for p := range externalAuths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		extAuth := externalAuths[p]
+		locations = append(locations, *extAuth.Location)
+
+		endpoints := vsc.generateEndpointsForUpstream(vsEx.VirtualServer, extAuth.Namespace, extAuth.Upstream, vsEx)
+		_, isExternalNameSvc := vsEx.ExternalNameSvcs[GenerateExternalNameSvcKey(extAuth.Namespace, extAuth.Upstream.Service)]
+		upstreams = append(upstreams, vsc.generateUpstream(vsEx.VirtualServer, extAuth.UpstreamName, extAuth.Upstream, isExternalNameSvc, endpoints, nil))
+	}
+
+	return upstreams, locations
+}
+
+// generateFaultInjectionConfigs returns the split clients, maps, geo blocks and delay locations of the FaultInjection policies of a VirtualServer.
+func generateFaultInjectionConfigs(faultInjections map[string]faultInjection) ([]version2.SplitClient, []version2.Map, []version2.Geo, []version2.FaultInjectionLocation) {
+	var splitClients []version2.SplitClient
+	var maps []version2.Map
+	var geos []version2.Geo
+	var locations []version2.FaultInjectionLocation
+
+	variables := make([]string, 0, len(faultInjections))
+	for v := range faultInjections {
+		variables = append(variables, v)
+	}
+	sort.Strings(variables)
+
+	for _, v := range variables {
+		fault := faultInjections[v]
+		splitClients = append(splitClients, *fault.SplitClient)
+		if fault.ConditionMap != nil {
+			maps = append(maps, *fault.ConditionMap)
+		}
+		if fault.ConditionGeo != nil {
+			geos = append(geos, *fault.ConditionGeo)
+		}
+		if fault.Location != nil {
+			locations = append(locations, *fault.Location)
+		}
+	}
+
+	return splitClients, maps, geos, locations
+}
+
 func generateUpstreams(
 	sslConfig *version2.SSL,
 	vsc *virtualServerConfigurator,
@@ -919,7 +1161,8 @@ func generateUpstreams(
 
 	// isExternalNameSvc is always false for OSS
 	_, isExternalNameSvc := vsEx.ExternalNameSvcs[GenerateExternalNameSvcKey(ownerNamespace, u.Service)]
-	ups := vsc.generateUpstream(owner, upstreamName, u, isExternalNameSvc, endpoints, backup)
+	resolve := isExternalNameSvc || (isStaticUpstream(u) && u.Resolve && vsc.isResolverConfigured)
+	ups := vsc.generateUpstream(owner, upstreamName, u, resolve, endpoints, backup)
 	upstreams = append(upstreams, ups)
 	u.TLS.Enable = isTLSEnabled(u, vsc.spiffeCerts, vsEx.VirtualServer.Spec.InternalRoute)
 	crUpstreams[upstreamName] = u
@@ -948,9 +1191,11 @@ type rateLimit struct {
 
 // jwtAuth hold the configuration for the JWTAuth & JWKSAuth Policies
 type jwtAuth struct {
+	Enabled     bool
 	Auth        *version2.JWTAuth
 	List        map[string]*version2.JWTAuth
 	JWKSEnabled bool
+	Maps        []version2.Map
 }
 
 // apiKeyAuth hold the configuration for the APIKey Policy
@@ -961,20 +1206,69 @@ type apiKeyAuth struct {
 	ClientMap map[string][]apiKeyClient
 }
 
+// cors holds the configuration for the CORS Policy
+type cors struct {
+	Headers   *version2.CORS
+	OriginMap *version2.Map
+}
+
+// externalAuth holds the configuration for the ExternalAuth Policy
+type externalAuth struct {
+	Auth         *version2.ExternalAuth
+	Location     *version2.ExternalAuthLocation
+	UpstreamName string
+	Upstream     conf_v1.Upstream
+	Namespace    string
+}
+
+// cache holds the configuration for the Cache Policy
+type cache struct {
+	Cache *version2.Cache
+	Zone  *version2.CacheZone
+}
+
+// faultInjection holds the configuration for the FaultInjection Policy
+type faultInjection struct {
+	Fault        *version2.FaultInjection
+	Location     *version2.FaultInjectionLocation
+	SplitClient  *version2.SplitClient
+	ConditionMap *version2.Map
+	ConditionGeo *version2.Geo
+}
+
+// authorization holds the configuration for the Authorization Policy
+type authorization struct {
+	Auth             *version2.Authorization
+	Maps             []version2.Map
+	AuthJWTClaimSets []version2.AuthJWTClaimSet
+}
+
+type connectionLimit struct {
+	Zone *version2.LimitConnZone
+	Conn *version2.LimitConn
+}
+
 type policiesCfg struct {
-	Allow           []string
-	Context         context.Context
-	Deny            []string
-	RateLimit       rateLimit
-	JWTAuth         jwtAuth
-	BasicAuth       *version2.BasicAuth
-	IngressMTLS     *version2.IngressMTLS
-	EgressMTLS      *version2.EgressMTLS
-	OIDC            bool
-	APIKey          apiKeyAuth
-	WAF             *version2.WAF
-	ErrorReturn     *version2.Return
-	BundleValidator bundleValidator
+	Allow               []string
+	Context             context.Context
+	Deny                []string
+	RateLimit           rateLimit
+	JWTAuth             jwtAuth
+	BasicAuth           *version2.BasicAuth
+	IngressMTLS         *version2.IngressMTLS
+	EgressMTLS          *version2.EgressMTLS
+	OIDC                bool
+	APIKey              apiKeyAuth
+	CORS                cors
+	ExternalAuth        externalAuth
+	Cache               cache
+	FaultInjection      faultInjection
+	ConnectionLimit     connectionLimit
+	Authorization       authorization
+	SignatureValidation *version2.SignatureValidation
+	WAF                 *version2.WAF
+	ErrorReturn         *version2.Return
+	BundleValidator     bundleValidator
 }
 
 type bundleValidator interface {
@@ -989,6 +1283,7 @@ type internalBundleValidator struct {
 type apiKeyClient struct {
 	ClientID  string
 	HashedKey string
+	Tier      string
 }
 
 func (i internalBundleValidator) validate(bundle string) (string, error) {
@@ -1018,10 +1313,13 @@ type policyOwnerDetails struct {
 }
 
 type policyOptions struct {
-	tls         bool
-	zoneSync    bool
-	secretRefs  map[string]*secrets.SecretReference
-	apResources *appProtectResourcesForVS
+	tls           bool
+	zoneSync      bool
+	secretRefs    map[string]*secrets.SecretReference
+	apResources   *appProtectResourcesForVS
+	authenticated bool
+	// authRequestPolicies are the types of the policies of the VirtualServer spec that authenticate requests with auth_request.
+	authRequestPolicies []string
 }
 
 type validationResults struct {
@@ -1142,12 +1440,20 @@ func (p *policiesCfg) addJWTAuthConfig(
 	polKey string,
 	polNamespace string,
 	secretRefs map[string]*secrets.SecretReference,
+	ownerDetails policyOwnerDetails,
+	isPlus bool,
 ) *validationResults {
 	res := newValidationResults()
 	if p.JWTAuth.Auth != nil {
 		res.addWarningf("Multiple jwt policies in the same context is not valid. JWT policy %s will be ignored", polKey)
 		return res
 	}
+
+	// in NGINX, the iss and aud claims are checked by jwt_auth.js
+	var require []string
+	if isPlus {
+		p.JWTAuth.Maps, require = generateJWTRequireMaps(jwtAuth, polKey, ownerDetails)
+	}
 	if jwtAuth.Secret != "" {
 		jwtSecretKey := fmt.Sprintf("%v/%v", polNamespace, jwtAuth.Secret)
 		secretRef := secretRefs[jwtSecretKey]
@@ -1166,10 +1472,14 @@ func (p *policiesCfg) addJWTAuthConfig(
 		}
 
 		p.JWTAuth.Auth = &version2.JWTAuth{
-			Secret: secretRef.Path,
-			Realm:  jwtAuth.Realm,
-			Token:  jwtAuth.Token,
+			Secret:   secretRef.Path,
+			Realm:    jwtAuth.Realm,
+			Token:    jwtAuth.Token,
+			Issuer:   jwtAuth.Issuer,
+			Audience: strings.Join(jwtAuth.Audience, " "),
+			Require:  require,
 		}
+		p.JWTAuth.Enabled = true
 		return res
 	} else if jwtAuth.JwksURI != "" {
 		uri, _ := url.Parse(jwtAuth.JwksURI)
@@ -1189,13 +1499,138 @@ func (p *policiesCfg) addJWTAuthConfig(
 			Realm:    jwtAuth.Realm,
 			Token:    jwtAuth.Token,
 			KeyCache: jwtAuth.KeyCache,
+			Issuer:   jwtAuth.Issuer,
+			Audience: strings.Join(jwtAuth.Audience, " "),
+			Require:  require,
 		}
+		p.JWTAuth.Enabled = true
 		p.JWTAuth.JWKSEnabled = true
 		return res
 	}
 	return res
 }
 
+// generateJWTRequireMaps generates the maps of the variables checked by auth_jwt_require for the iss and aud claims
+// required by a JWT policy in NGINX Plus. The values of array claims such as aud are separated by commas.
+func generateJWTRequireMaps(jwtAuth *conf_v1.JWTAuth, polKey string, ownerDetails policyOwnerDetails) ([]version2.Map, []string) {
+	var maps []version2.Map
+	var variables []string
+	name := rfc1123ToSnake(fmt.Sprintf("jwt_%v_%v_%v", strings.ReplaceAll(polKey, "/", "_"), ownerDetails.vsNamespace, ownerDetails.vsName))
+
+	if jwtAuth.Issuer != "" {
+		variable := fmt.Sprintf("$%s_iss", name)
+		maps = append(maps, version2.Map{
+			Source:   "$jwt_claim_iss",
+			Variable: variable,
+			Parameters: []version2.Parameter{
+				{
+					Value:  "default",
+					Result: "0",
+				},
+				{
+					Value:  fmt.Sprintf(`"~^%s$"`, regexp.QuoteMeta(jwtAuth.Issuer)),
+					Result: "1",
+				},
+			},
+		})
+		variables = append(variables, variable)
+	}
+
+	if len(jwtAuth.Audience) > 0 {
+		variable := fmt.Sprintf("$%s_aud", name)
+		params := []version2.Parameter{
+			{
+				Value:  "default",
+				Result: "0",
+			},
+		}
+		for _, aud := range jwtAuth.Audience {
+			params = append(params, version2.Parameter{
+				Value:  fmt.Sprintf(`"~(^|,)%s(,|$)"`, regexp.QuoteMeta(aud)),
+				Result: "1",
+			})
+		}
+		maps = append(maps, version2.Map{
+			Source:     "$jwt_claim_aud",
+			Variable:   variable,
+			Parameters: params,
+		})
+		variables = append(variables, variable)
+	}
+
+	return maps, variables
+}
+
+var jwtClaimVariableRegexp = regexp.MustCompile(`\$\{?jwt_claim_([A-Za-z0-9_]+)`)
+
+// jwtClaimsSetByNJS are the claims whose variables are defined by js_set in the main NGINX configuration.
+var jwtClaimsSetByNJS = map[string]bool{"sub": true, "iss": true, "aud": true, "scope": true}
+
+// generateJWTClaimVariables generates the $jwt_claim_ variables referenced by the proxy headers and the snippets of
+// the VirtualServer and its VirtualServerRoutes, which are declared by js_var and set by njs from the claims of
+// a validated JWT in NGINX.
+func generateJWTClaimVariables(vsEx *VirtualServerEx, enableSnippets bool) []string {
+	var values []string
+	addAction := func(action *conf_v1.Action) {
+		if action == nil || action.Proxy == nil {
+			return
+		}
+		if action.Proxy.RequestHeaders != nil {
+			for _, h := range action.Proxy.RequestHeaders.Set {
+				values = append(values, h.Value)
+			}
+		}
+		if action.Proxy.ResponseHeaders != nil {
+			for _, h := range action.Proxy.ResponseHeaders.Add {
+				values = append(values, h.Value)
+			}
+		}
+	}
+	addRoute := func(r conf_v1.Route) {
+		addAction(r.Action)
+		for _, s := range r.Splits {
+			addAction(s.Action)
+		}
+		for _, m := range r.Matches {
+			addAction(m.Action)
+			for _, s := range m.Splits {
+				addAction(s.Action)
+			}
+		}
+		if enableSnippets {
+			values = append(values, r.LocationSnippets)
+		}
+	}
+
+	for _, r := range vsEx.VirtualServer.Spec.Routes {
+		addRoute(r)
+	}
+	for _, vsr := range vsEx.VirtualServerRoutes {
+		for _, sr := range vsr.Spec.Subroutes {
+			addRoute(sr)
+		}
+	}
+	if enableSnippets {
+		values = append(values, vsEx.VirtualServer.Spec.ServerSnippets)
+	}
+
+	seen := make(map[string]bool)
+	var variables []string
+	for _, v := range values {
+		for _, m := range jwtClaimVariableRegexp.FindAllStringSubmatch(v, -1) {
+			claim := m[1]
+			if jwtClaimsSetByNJS[claim] || seen[claim] {
+				continue
+			}
+			seen[claim] = true
+			variables = append(variables, "$jwt_claim_"+claim)
+		}
+	}
+
+	sort.Strings(variables)
+	return variables
+}
+
 func (p *policiesCfg) addIngressMTLSConfig(
 	ingressMTLS *conf_v1.IngressMTLS,
 	polKey string,
@@ -1272,9 +1707,38 @@ func (p *policiesCfg) addIngressMTLSConfig(
 			VerifyDepth:  verifyDepth,
 		}
 	}
+	p.IngressMTLS.ForwardHeaders = generateIngressMTLSForwardHeaders(ingressMTLS.ForwardCertificate)
+	if ingressMTLS.OCSP != nil {
+		p.IngressMTLS.OCSP = "on"
+		if ingressMTLS.OCSP.LeafOnly {
+			p.IngressMTLS.OCSP = "leaf"
+		}
+		p.IngressMTLS.OCSPResponder = ingressMTLS.OCSP.Responder
+	}
 	return res
 }
 
+// generateIngressMTLSForwardHeaders generates the request headers with the identity of the client certificate.
+// The variables are set by client_cert.js only for successfully verified certificates.
+func generateIngressMTLSForwardHeaders(fc *conf_v1.ForwardCertificate) []version2.Header {
+	if fc == nil {
+		return nil
+	}
+
+	var headers []version2.Header
+	for _, h := range []version2.Header{
+		{Name: fc.SubjectHeader, Value: "$ingress_mtls_client_s_dn"},
+		{Name: fc.SANsHeader, Value: "$ingress_mtls_client_sans"},
+		{Name: fc.FingerprintHeader, Value: "$ingress_mtls_client_fingerprint"},
+		{Name: fc.CertificateHeader, Value: "$ingress_mtls_client_cert"},
+	} {
+		if h.Name != "" {
+			headers = append(headers, h)
+		}
+	}
+	return headers
+}
+
 func (p *policiesCfg) addEgressMTLSConfig(
 	egressMTLS *conf_v1.EgressMTLS,
 	polKey string,
@@ -1362,6 +1826,8 @@ func (p *policiesCfg) addOIDCConfig(
 	polNamespace string,
 	secretRefs map[string]*secrets.SecretReference,
 	oidcPolCfg *oidcPolicyCfg,
+	ownerDetails policyOwnerDetails,
+	isPlus bool,
 ) *validationResults {
 	res := newValidationResults()
 	if p.OIDC {
@@ -1386,6 +1852,7 @@ func (p *policiesCfg) addOIDCConfig(
 		secretKey := fmt.Sprintf("%v/%v", polNamespace, oidc.ClientSecret)
 		secretRef, ok := secretRefs[secretKey]
 		clientSecret := []byte("")
+		sessionKey := []byte("")
 
 		if ok {
 			var secretType api_v1.SecretType
@@ -1407,6 +1874,12 @@ func (p *policiesCfg) addOIDCConfig(
 			}
 
 			clientSecret = secretRef.Secret.Data[ClientSecretKey]
+			sessionKey = secretRef.Secret.Data[SessionKeyKey]
+			if !isPlus && len(sessionKey) == 0 {
+				res.addWarningf("OIDC policy %s references a secret %s without the %s key, which is required to encrypt the session cookies in NGINX", polKey, secretKey, SessionKeyKey)
+				res.isError = true
+				return res
+			}
 		} else if !oidc.PKCEEnable {
 			res.addWarningf("Client secret is required for OIDC policy %s when not using PKCE", polKey)
 			res.isError = true
@@ -1430,6 +1903,15 @@ func (p *policiesCfg) addOIDCConfig(
 			authExtraArgs = strings.Join(oidc.AuthExtraArgs, "&")
 		}
 
+		var claimHeaders []version2.OIDCClaimHeader
+		for _, h := range oidc.ClaimHeaders {
+			claimHeaders = append(claimHeaders, version2.OIDCClaimHeader{
+				Header:   h.Header,
+				Claim:    h.Claim,
+				Variable: generateOIDCClaimVariable(h.Claim, ownerDetails, isPlus),
+			})
+		}
+
 		oidcPolCfg.oidc = &version2.OIDC{
 			AuthEndpoint:          oidc.AuthEndpoint,
 			AuthExtraArgs:         authExtraArgs,
@@ -1444,6 +1926,10 @@ func (p *policiesCfg) addOIDCConfig(
 			ZoneSyncLeeway:        generateIntFromPointer(oidc.ZoneSyncLeeway, 200),
 			AccessTokenEnable:     oidc.AccessTokenEnable,
 			PKCEEnable:            oidc.PKCEEnable,
+			SessionKey:            string(sessionKey),
+			ClaimHeaders:          claimHeaders,
+			RefreshLeeway:         generateIntFromPointer(oidc.RefreshLeeway, 0),
+			BackchannelLogoutURI:  oidc.BackchannelLogoutURI,
 		}
 		oidcPolCfg.key = polKey
 	}
@@ -1453,6 +1939,158 @@ func (p *policiesCfg) addOIDCConfig(
 	return res
 }
 
+// generateOIDCClaimVariable generates the variable holding an ID token claim.
+// In NGINX Plus, the claim is extracted by auth_jwt_claim_set, while in NGINX it is set by njs from the session.
+func generateOIDCClaimVariable(claim string, ownerDetails policyOwnerDetails, isPlus bool) string {
+	if isPlus {
+		return generateAuthJwtClaimSetVariable(claim, ownerDetails.vsNamespace, ownerDetails.vsName)
+	}
+	return "$oidc_claim_" + strings.NewReplacer(".", "_", "-", "_").Replace(claim)
+}
+
+const (
+	defaultSignatureValidationTimestampTolerance = 300
+	// signatureValidationMaxBodyBufferSize limits the client body buffer of locations with unlimited request bodies.
+	signatureValidationMaxBodyBufferSize = "1m"
+)
+
+var defaultSignatureValidationHeaders = map[string]string{
+	"plain":  "X-Hub-Signature-256",
+	"stripe": "Stripe-Signature",
+	"slack":  "X-Slack-Signature",
+}
+
+func (p *policiesCfg) addSignatureValidationConfig(
+	sv *conf_v1.SignatureValidation,
+	polKey string,
+	polNamespace string,
+	secretRefs map[string]*secrets.SecretReference,
+) *validationResults {
+	res := newValidationResults()
+	if p.SignatureValidation != nil {
+		res.addWarningf("Multiple Signature Validation policies in the same context is not valid. Signature Validation policy %s will be ignored", polKey)
+		return res
+	}
+
+	secretKey := fmt.Sprintf("%v/%v", polNamespace, sv.Secret)
+	secretRef := secretRefs[secretKey]
+	var secretType api_v1.SecretType
+	if secretRef.Secret != nil {
+		secretType = secretRef.Secret.Type
+	}
+	if secretType != "" && secretType != secrets.SecretTypeHMAC {
+		res.addWarningf("Signature Validation policy %s references a secret %s of a wrong type '%s', must be '%s'", polKey, secretKey, secretType, secrets.SecretTypeHMAC)
+		res.isError = true
+		return res
+	} else if secretRef.Error != nil {
+		res.addWarningf("Signature Validation policy %s references an invalid secret %s: %v", polKey, secretKey, secretRef.Error)
+		res.isError = true
+		return res
+	}
+
+	format := sv.Format
+	if format == "" {
+		format = "plain"
+	}
+	algorithm := sv.Algorithm
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+	header := sv.Header
+	if header == "" {
+		header = defaultSignatureValidationHeaders[format]
+	}
+	tolerance := defaultSignatureValidationTimestampTolerance
+	if sv.TimestampTolerance != nil {
+		tolerance = *sv.TimestampTolerance
+	}
+	rejectCode := 401
+	if sv.RejectCode != nil {
+		rejectCode = *sv.RejectCode
+	}
+
+	p.SignatureValidation = &version2.SignatureValidation{
+		KeyFile:            secretRef.Path,
+		Format:             format,
+		Algorithm:          algorithm,
+		Header:             header,
+		TimestampTolerance: tolerance,
+		RejectCode:         rejectCode,
+	}
+	return res
+}
+
+// generateSignatureValidationLocations moves the configuration of the locations with a Signature Validation policy,
+// either their own or the one of the VirtualServer spec, to named locations. The returned locations validate the
+// signature of the requests with njs, which needs to read the whole request body, before redirecting them to the named locations.
+// The access and rate limits of the locations are also checked by the returned locations, before the request body is read,
+// while the other policies, such as JWT authentication, are only applied in the named locations.
+// Locations without a client max body size of their own, such as the ones of return and redirect actions, use the one of the ConfigMap.
+func generateSignatureValidationLocations(
+	locations []version2.Location,
+	specValidation *version2.SignatureValidation,
+	defaultClientMaxBodySize string,
+) []version2.SignatureValidationLocation {
+	var svLocations []version2.SignatureValidationLocation
+
+	for i := range locations {
+		loc := &locations[i]
+
+		validation := loc.SignatureValidation
+		if validation == nil {
+			validation = specValidation
+		}
+		if validation == nil || loc.PoliciesErrorReturn != nil {
+			continue
+		}
+
+		clientMaxBodySize := loc.ClientMaxBodySize
+		if clientMaxBodySize == "" {
+			clientMaxBodySize = defaultClientMaxBodySize
+		}
+
+		bodyBufferSize := clientMaxBodySize
+		if bodyBufferSize == "" || bodyBufferSize == "0" {
+			bodyBufferSize = signatureValidationMaxBodyBufferSize
+		}
+
+		namedLocation := fmt.Sprintf("@signature_validation_%d", len(svLocations))
+		svLocations = append(svLocations, version2.SignatureValidationLocation{
+			Path:              loc.Path,
+			Internal:          loc.Internal,
+			NamedLocation:     namedLocation,
+			ClientMaxBodySize: clientMaxBodySize,
+			BodyBufferSize:    bodyBufferSize,
+			Validation:        validation,
+			Allow:             loc.Allow,
+			Deny:              loc.Deny,
+			LimitReqOptions:   loc.LimitReqOptions,
+			LimitReqs:         loc.LimitReqs,
+			LimitConn:         loc.LimitConn,
+		})
+
+		// proxy_pass cannot have a URI part in a named location
+		if loc.ProxyPassRewrite != "" {
+			loc.Rewrites = append(loc.Rewrites, generateNamedLocationRewrite(loc.Path, loc.ProxyPassRewrite))
+			loc.ProxyPassRewrite = ""
+		}
+		loc.Path = namedLocation
+		loc.Internal = false
+	}
+
+	return svLocations
+}
+
+// generateNamedLocationRewrite generates the rewrite that replaces the path of a prefix or exact match location
+// with the rewritePath of its proxy action, the same way the proxy_pass URI does in the original location.
+func generateNamedLocationRewrite(path string, rewritePath string) string {
+	if strings.HasPrefix(path, "=") {
+		exactPath := strings.TrimSpace(strings.TrimPrefix(path, "="))
+		return fmt.Sprintf(`"^%v$" "%v" break`, regexp.QuoteMeta(exactPath), rewritePath)
+	}
+	return fmt.Sprintf(`"^%v(.*)$" "%v$1" break`, regexp.QuoteMeta(path), rewritePath)
+}
+
 func (p *policiesCfg) addAPIKeyConfig(
 	apiKey *conf_v1.APIKey,
 	polKey string,
@@ -1487,7 +2125,15 @@ func (p *policiesCfg) addAPIKeyConfig(
 		return res
 	}
 
-	p.APIKey.Clients = generateAPIKeyClients(secretRef.Secret.Data)
+	for _, tier := range apiKey.Tiers {
+		for _, clientID := range tier.ClientIDs {
+			if _, exists := secretRef.Secret.Data[clientID]; !exists {
+				res.addWarningf("API Key policy %s assigns the client %s to the tier %s, but the secret %s has no API Key for the client", polKey, clientID, tier.Name, secretKey)
+			}
+		}
+	}
+
+	p.APIKey.Clients = generateAPIKeyClients(secretRef.Secret.Data, apiKey.Tiers)
 
 	mapName := fmt.Sprintf(
 		"apikey_auth_client_name_%s_%s_%s",
@@ -1500,26 +2146,484 @@ func (p *policiesCfg) addAPIKeyConfig(
 		Query:   apiKey.SuppliedIn.Query,
 		MapName: mapName,
 	}
-	p.APIKey.Enabled = true
-	return res
+	for _, client := range p.APIKey.Clients {
+		if client.Tier != "" {
+			p.APIKey.Key.TierMapName = generateAPIKeyTierMapName(mapName)
+			break
+		}
+	}
+	p.APIKey.Enabled = true
+	return res
+}
+
+func (p *policiesCfg) addCORSConfig(policy *conf_v1.Policy, ownerDetails policyOwnerDetails) *validationResults {
+	res := newValidationResults()
+	polKey := fmt.Sprintf("%v/%v", policy.Namespace, policy.Name)
+	if p.CORS.Headers != nil {
+		res.addWarningf("Multiple cors policies in the same context is not valid. CORS policy %s will be ignored", polKey)
+		return res
+	}
+
+	corsPol := policy.Spec.CORS
+	allowOrigin := "*"
+	if !(len(corsPol.AllowOrigins) == 1 && corsPol.AllowOrigins[0] == "*") {
+		originMap := generateCORSOriginMap(corsPol.AllowOrigins,
+			rfc1123ToSnake(fmt.Sprintf("$pol_cors_%v_%v_%v_%v_origin", policy.Namespace, policy.Name, ownerDetails.vsNamespace, ownerDetails.vsName)))
+		allowOrigin = originMap.Variable
+		p.CORS.OriginMap = originMap
+	}
+
+	allowMethods := []string{"GET", "HEAD", "POST"}
+	if len(corsPol.AllowMethods) > 0 {
+		allowMethods = corsPol.AllowMethods
+	}
+
+	p.CORS.Headers = &version2.CORS{
+		AllowOrigin:      allowOrigin,
+		AllowMethods:     strings.Join(allowMethods, ", "),
+		AllowHeaders:     strings.Join(corsPol.AllowHeaders, ", "),
+		ExposeHeaders:    strings.Join(corsPol.ExposeHeaders, ", "),
+		AllowCredentials: corsPol.AllowCredentials,
+		MaxAge:           generateIntFromPointer(corsPol.MaxAge, 0),
+	}
+
+	return res
+}
+
+func (p *policiesCfg) addExternalAuthConfig(policy *conf_v1.Policy, ownerDetails policyOwnerDetails) *validationResults {
+	res := newValidationResults()
+	polKey := fmt.Sprintf("%v/%v", policy.Namespace, policy.Name)
+	if p.ExternalAuth.Auth != nil {
+		res.addWarningf("Multiple externalAuth policies in the same context is not valid. ExternalAuth policy %s will be ignored", polKey)
+		return res
+	}
+
+	extAuth := policy.Spec.ExternalAuth
+	svcName, svcPort, err := ParseExternalAuthService(extAuth.AuthService)
+	if err != nil {
+		res.addWarningf("ExternalAuth policy %s references an invalid auth service %s: %v", polKey, extAuth.AuthService, err)
+		res.isError = true
+		return res
+	}
+
+	var forwardHeaders []version2.Header
+	for _, h := range extAuth.ForwardRequestHeaders {
+		forwardHeaders = append(forwardHeaders, version2.Header{
+			Name:  h,
+			Value: generateHeaderVariable("$http_", h),
+		})
+	}
+
+	var responseHeaders []version2.ExternalAuthResponseHeader
+	for _, h := range extAuth.ResponseHeaders {
+		responseHeaders = append(responseHeaders, version2.ExternalAuthResponseHeader{
+			Name:     h,
+			Variable: generateHeaderVariable("$ext_auth_resp_", h),
+			Value:    generateHeaderVariable("$upstream_http_", h),
+		})
+	}
+
+	upstreamName := fmt.Sprintf("vs_%s_%s_ext_auth_%s_%s", ownerDetails.vsNamespace, ownerDetails.vsName, policy.Namespace, policy.Name)
+	authPath := generateString(extAuth.AuthPath, "/")
+	locationPath := fmt.Sprintf("/%vext_auth_%s_%s", internalLocationPrefix, policy.Namespace, policy.Name)
+
+	p.ExternalAuth = externalAuth{
+		Auth: &version2.ExternalAuth{
+			URI:             locationPath,
+			SigninURL:       extAuth.SigninURL,
+			ResponseHeaders: responseHeaders,
+		},
+		Location: &version2.ExternalAuthLocation{
+			Path:           locationPath,
+			ProxyPass:      generateProxyPass(false, upstreamName, false, nil) + authPath,
+			ForwardHeaders: forwardHeaders,
+		},
+		UpstreamName: upstreamName,
+		Upstream: conf_v1.Upstream{
+			Name:    upstreamName,
+			Service: svcName,
+			Port:    svcPort,
+		},
+		Namespace: policy.Namespace,
+	}
+
+	return res
+}
+
+func (p *policiesCfg) addCacheConfig(policy *conf_v1.Policy, ownerDetails policyOwnerDetails, isPlus bool) *validationResults {
+	res := newValidationResults()
+	polKey := fmt.Sprintf("%v/%v", policy.Namespace, policy.Name)
+	if p.Cache.Cache != nil {
+		res.addWarningf("Multiple cache policies in the same context is not valid. Cache policy %s will be ignored", polKey)
+		return res
+	}
+
+	cachePol := policy.Spec.Cache
+	zoneName := rfc1123ToSnake(fmt.Sprintf("pol_cache_%v_%v_%v_%v", policy.Namespace, policy.Name, ownerDetails.vsNamespace, ownerDetails.vsName))
+
+	methods := []string{"GET", "HEAD"}
+	if len(cachePol.AllowedMethods) > 0 {
+		methods = cachePol.AllowedMethods
+	}
+
+	var valid []version2.CacheValid
+	for _, v := range cachePol.Valid {
+		valid = append(valid, version2.CacheValid{
+			Codes: strings.Join(v.Codes, " "),
+			Time:  v.Time,
+		})
+	}
+
+	p.Cache.Zone = &version2.CacheZone{
+		Name:     zoneName,
+		Path:     fmt.Sprintf("/var/cache/nginx/%s", zoneName),
+		Size:     generateString(cachePol.CacheZoneSize, "10m"),
+		MaxSize:  cachePol.MaxSize,
+		Inactive: cachePol.Inactive,
+	}
+	p.Cache.Cache = &version2.Cache{
+		ZoneName:           zoneName,
+		Key:                cachePol.Key,
+		Methods:            strings.Join(methods, " "),
+		Valid:              valid,
+		Bypass:             strings.Join(cachePol.Bypass, " "),
+		IgnoreCacheControl: cachePol.IgnoreCacheControl,
+	}
+
+	if isPlus && len(cachePol.PurgeAllowedCIDRs) > 0 {
+		p.Cache.Zone.PurgeAllowedCIDRs = cachePol.PurgeAllowedCIDRs
+		p.Cache.Zone.PurgeAllowedVar = fmt.Sprintf("$%s_purge_allowed", zoneName)
+		p.Cache.Zone.PurgeVar = fmt.Sprintf("$%s_purge", zoneName)
+		p.Cache.Zone.PurgeDeniedVar = fmt.Sprintf("$%s_purge_denied", zoneName)
+		p.Cache.Cache.PurgeVar = p.Cache.Zone.PurgeVar
+		p.Cache.Cache.PurgeDeniedVar = p.Cache.Zone.PurgeDeniedVar
+	}
+
+	return res
+}
+
+func (p *policiesCfg) addFaultInjectionConfig(policy *conf_v1.Policy, ownerDetails policyOwnerDetails) *validationResults {
+	res := newValidationResults()
+	polKey := fmt.Sprintf("%v/%v", policy.Namespace, policy.Name)
+	if p.FaultInjection.Fault != nil {
+		res.addWarningf("Multiple faultInjection policies in the same context is not valid. FaultInjection policy %s will be ignored", polKey)
+		return res
+	}
+
+	faultPol := policy.Spec.FaultInjection
+	name := rfc1123ToSnake(fmt.Sprintf("pol_fault_injection_%v_%v_%v_%v", policy.Namespace, policy.Name, ownerDetails.vsNamespace, ownerDetails.vsName))
+
+	splitVariable := fmt.Sprintf("$%s_split", name)
+	distributions := []version2.Distribution{
+		{
+			Weight: fmt.Sprintf("%d%%", faultPol.Percentage),
+			Value:  "1",
+		},
+	}
+	if faultPol.Percentage < 100 {
+		distributions = append(distributions, version2.Distribution{
+			Weight: "*",
+			Value:  "0",
+		})
+	}
+	p.FaultInjection.SplitClient = &version2.SplitClient{
+		Source:        "$request_id",
+		Variable:      splitVariable,
+		Distributions: distributions,
+	}
+
+	check := fmt.Sprintf("${%s}", splitVariable[1:])
+	value := "1"
+
+	if faultPol.Condition != nil {
+		conditionVariable := fmt.Sprintf("$%s_condition", name)
+		conditionMap, conditionGeo := generateConditionConfig(*faultPol.Condition, conditionVariable, "1")
+		p.FaultInjection.ConditionMap = &conditionMap
+		p.FaultInjection.ConditionGeo = conditionGeo
+		check += fmt.Sprintf("${%s}", conditionVariable[1:])
+		value += "1"
+	}
+
+	p.FaultInjection.Fault = &version2.FaultInjection{
+		Status: faultPol.Status,
+	}
+
+	if faultPol.Delay != "" {
+		// the delayed requests are redirected back to their location with $fault_injection_delayed set,
+		// so that the fault is not injected into them again.
+		check += "${fault_injection_delayed}"
+		p.FaultInjection.Location = &version2.FaultInjectionLocation{
+			Name:  fmt.Sprintf("@%s", name),
+			Delay: parseFaultInjectionDelay(faultPol.Delay),
+		}
+		p.FaultInjection.Fault.DelayLocation = p.FaultInjection.Location.Name
+	}
+
+	p.FaultInjection.Fault.Check = check
+	p.FaultInjection.Fault.Value = value
+
+	return res
+}
+
+// parseFaultInjectionDelay converts the delay of a FaultInjection policy to milliseconds.
+func parseFaultInjectionDelay(delay string) int64 {
+	t, err := ParseTime(delay)
+	if err != nil {
+		return 0
+	}
+
+	d, err := time.ParseDuration(t)
+	if err != nil {
+		return 0
+	}
+
+	return d.Milliseconds()
+}
+
+func (p *policiesCfg) addConnectionLimitConfig(policy *conf_v1.Policy, ownerDetails policyOwnerDetails) *validationResults {
+	res := newValidationResults()
+	polKey := fmt.Sprintf("%v/%v", policy.Namespace, policy.Name)
+	if p.ConnectionLimit.Conn != nil {
+		res.addWarningf("Multiple connectionLimit policies in the same context is not valid. ConnectionLimit policy %s will be ignored", polKey)
+		return res
+	}
+
+	zoneName := rfc1123ToSnake(fmt.Sprintf("pol_cl_%v_%v_%v_%v", policy.Namespace, policy.Name, ownerDetails.vsNamespace, ownerDetails.vsName))
+	zone := generateLimitConnZone(zoneName, policy.Spec.ConnectionLimit)
+	conn := generateLimitConn(zoneName, policy.Spec.ConnectionLimit, 503)
+	p.ConnectionLimit.Zone = &zone
+	p.ConnectionLimit.Conn = &conn
+
+	return res
+}
+
+func (p *policiesCfg) addAuthorizationConfig(policy *conf_v1.Policy, ownerDetails policyOwnerDetails, isPlus bool) *validationResults {
+	res := newValidationResults()
+	polKey := fmt.Sprintf("%v/%v", policy.Namespace, policy.Name)
+	if p.Authorization.Auth != nil {
+		res.addWarningf("Multiple authorization policies in the same context is not valid. Authorization policy %s will be ignored", polKey)
+		return res
+	}
+
+	authzPol := policy.Spec.Authorization
+	rejectCode := generateIntFromPointer(authzPol.RejectCode, 403)
+
+	if !isPlus {
+		// in NGINX, the requirements are checked by jwt_auth.js against the claims of the token it has verified
+		requirements, err := json.Marshal(authorizationRequirements{AllOf: authzPol.AllOf, AnyOf: authzPol.AnyOf})
+		if err != nil {
+			res.addWarningf("Authorization policy %s could not be encoded: %v", polKey, err)
+			res.isError = true
+			return res
+		}
+		p.Authorization.Auth = &version2.Authorization{
+			Requirements: string(requirements),
+			RejectCode:   rejectCode,
+		}
+		return res
+	}
+
+	name := rfc1123ToSnake(fmt.Sprintf("authz_%v_%v_%v_%v", policy.Namespace, policy.Name, ownerDetails.vsNamespace, ownerDetails.vsName))
+
+	var allOfVars, anyOfVars []string
+	for i, req := range authzPol.AllOf {
+		variable := fmt.Sprintf("%s_all_%d", name, i)
+		p.Authorization.Maps = append(p.Authorization.Maps, generateClaimRequirementMap(req, "$"+variable, ownerDetails))
+		p.Authorization.AuthJWTClaimSets = append(p.Authorization.AuthJWTClaimSets, generateAuthJwtClaimSet(conf_v1.JWTCondition{Claim: req.Claim}, ownerDetails))
+		allOfVars = append(allOfVars, fmt.Sprintf("${%s}", variable))
+	}
+	for i, req := range authzPol.AnyOf {
+		variable := fmt.Sprintf("%s_any_%d", name, i)
+		p.Authorization.Maps = append(p.Authorization.Maps, generateClaimRequirementMap(req, "$"+variable, ownerDetails))
+		p.Authorization.AuthJWTClaimSets = append(p.Authorization.AuthJWTClaimSets, generateAuthJwtClaimSet(conf_v1.JWTCondition{Claim: req.Claim}, ownerDetails))
+		anyOfVars = append(anyOfVars, fmt.Sprintf("${%s}", variable))
+	}
+
+	// the requirements are met when all the allOf results are 1 and at least one of the anyOf results, if any, is 1
+	pattern := "^" + strings.Repeat("1", len(allOfVars)) + ":"
+	if len(anyOfVars) > 0 {
+		pattern += ".*1"
+	}
+	p.Authorization.Maps = append(p.Authorization.Maps, version2.Map{
+		Source:   fmt.Sprintf(`"%s:%s"`, strings.Join(allOfVars, ""), strings.Join(anyOfVars, "")),
+		Variable: "$" + name,
+		Parameters: []version2.Parameter{
+			{
+				Value:  "default",
+				Result: "0",
+			},
+			{
+				Value:  fmt.Sprintf(`"~%s"`, pattern),
+				Result: "1",
+			},
+		},
+	})
+
+	p.Authorization.Auth = &version2.Authorization{
+		Variable:   "$" + name,
+		RejectCode: rejectCode,
+	}
+
+	return res
+}
+
+// authorizationRequirements holds the claim requirements of an Authorization policy checked by njs.
+type authorizationRequirements struct {
+	AllOf []conf_v1.ClaimRequirement `json:"allOf,omitempty"`
+	AnyOf []conf_v1.ClaimRequirement `json:"anyOf,omitempty"`
+}
+
+// generateClaimRequirementMap generates a map that sets the variable to 1 if the claim meets the requirement.
+// The elements of array claims are separated by commas, and the parts of claims such as scope are separated by spaces.
+func generateClaimRequirementMap(req conf_v1.ClaimRequirement, variable string, ownerDetails policyOwnerDetails) version2.Map {
+	params := []version2.Parameter{
+		{
+			Value:  "default",
+			Result: "0",
+		},
+	}
+
+	for _, v := range req.Values {
+		params = append(params, version2.Parameter{
+			Value:  fmt.Sprintf(`"~(^|[ ,])%s([ ,]|$)"`, regexp.QuoteMeta(v)),
+			Result: "1",
+		})
+	}
+
+	return version2.Map{
+		Source:     generateAuthJwtClaimSetVariable(req.Claim, ownerDetails.vsNamespace, ownerDetails.vsName),
+		Variable:   variable,
+		Parameters: params,
+	}
+}
+
+// hasAuthentication checks if the policies authenticate the JWT checked by an Authorization policy.
+func (p *policiesCfg) hasAuthentication(isPlus bool) bool {
+	if isPlus {
+		return p.JWTAuth.Auth != nil || p.OIDC
+	}
+	// in NGINX, the claims are only checked by jwt_auth.js against a token it has verified
+	return p.JWTAuth.Auth != nil
+}
+
+// authRequestPolicies returns the types of the policies that authenticate requests with auth_request.
+// A location can only have one auth_request, which also replaces the one of the server.
+func (p *policiesCfg) authRequestPolicies(isPlus bool) []string {
+	var types []string
+	if p.ExternalAuth.Auth != nil {
+		types = append(types, "externalAuth")
+	}
+	if p.APIKey.Key != nil {
+		types = append(types, "apiKey")
+	}
+	if !isPlus && p.JWTAuth.Auth != nil {
+		types = append(types, "jwt")
+	}
+	if !isPlus && p.OIDC {
+		types = append(types, "oidc")
+	}
+	return types
+}
+
+// ParseExternalAuthService parses the auth service of an ExternalAuth policy in the format service:port.
+func ParseExternalAuthService(authService string) (string, uint16, error) {
+	name, port, err := net.SplitHostPort(authService)
+	if err != nil {
+		return "", 0, err
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %s: %w", port, err)
+	}
+
+	return name, uint16(p), nil
+}
+
+// generateHeaderVariable generates the name of the NGINX variable with the prefix for the header.
+func generateHeaderVariable(prefix string, header string) string {
+	return prefix + strings.ToLower(strings.ReplaceAll(header, "-", "_"))
+}
+
+// generateCORSOriginMap generates a map which resolves to the origin of a request if the origin is allowed
+// and to an empty value otherwise, so that NGINX doesn't add the Access-Control-Allow-Origin header.
+func generateCORSOriginMap(allowOrigins []string, variable string) *version2.Map {
+	params := []version2.Parameter{
+		{
+			Value:  "default",
+			Result: `""`,
+		},
+	}
+
+	for _, o := range allowOrigins {
+		params = append(params, version2.Parameter{
+			Value:  fmt.Sprintf(`"%s"`, o),
+			Result: "$http_origin",
+		})
+	}
+
+	return &version2.Map{
+		Source:     "$http_origin",
+		Variable:   variable,
+		Parameters: params,
+	}
 }
 
 func rfc1123ToSnake(rfc1123String string) string {
 	return strings.Replace(rfc1123String, "-", "_", -1)
 }
 
-func generateAPIKeyClients(secretData map[string][]byte) []apiKeyClient {
+func generateAPIKeyClients(secretData map[string][]byte, tiers []conf_v1.APIKeyTier) []apiKeyClient {
+	clientTiers := make(map[string]string)
+	for _, tier := range tiers {
+		for _, clientID := range tier.ClientIDs {
+			clientTiers[clientID] = tier.Name
+		}
+	}
+
 	var clients []apiKeyClient
 	for clientID, apiKey := range secretData {
-
-		h := sha256.New()
-		h.Write(apiKey)
-		sha256Hash := hex.EncodeToString(h.Sum(nil))
-		clients = append(clients, apiKeyClient{ClientID: clientID, HashedKey: sha256Hash}) //
+		// the keys are validated with the secret
+		hashedKey, _ := secrets.HashAPIKey(apiKey)
+		clients = append(clients, apiKeyClient{ClientID: clientID, HashedKey: hashedKey, Tier: clientTiers[clientID]})
 	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ClientID < clients[j].ClientID
+	})
 	return clients
 }
 
+func generateAPIKeyTierMapName(mapName string) string {
+	return mapName + "_tier"
+}
+
+// generateAPIKeyTierMap generates a map from the client names to the rate-limit tiers of the clients, if any.
+func generateAPIKeyTierMap(mapName string, apiKeyClients []apiKeyClient) *version2.Map {
+	params := []version2.Parameter{
+		{
+			Value:  "default",
+			Result: "\"\"",
+		},
+	}
+	for _, client := range apiKeyClients {
+		if client.Tier != "" {
+			params = append(params, version2.Parameter{
+				Value:  fmt.Sprintf("\"%s\"", client.ClientID),
+				Result: fmt.Sprintf("\"%s\"", client.Tier),
+			})
+		}
+	}
+	if len(params) == 1 {
+		return nil
+	}
+
+	return &version2.Map{
+		Source:     fmt.Sprintf("$%s", mapName),
+		Variable:   fmt.Sprintf("$%s", generateAPIKeyTierMapName(mapName)),
+		Parameters: params,
+	}
+}
+
 func generateAPIKeyClientMap(mapName string, apiKeyClients []apiKeyClient) *version2.Map {
 	defaultParam := version2.Parameter{
 		Value:  "default",
@@ -1711,7 +2815,7 @@ func (vsc *virtualServerConfigurator) generatePolicies(
 					path,
 				)
 			case pol.Spec.JWTAuth != nil:
-				res = config.addJWTAuthConfig(pol.Spec.JWTAuth, key, polNamespace, policyOpts.secretRefs)
+				res = config.addJWTAuthConfig(pol.Spec.JWTAuth, key, polNamespace, policyOpts.secretRefs, ownerDetails, vsc.isPlus)
 			case pol.Spec.BasicAuth != nil:
 				res = config.addBasicAuthConfig(pol.Spec.BasicAuth, key, polNamespace, policyOpts.secretRefs)
 			case pol.Spec.IngressMTLS != nil:
@@ -1726,12 +2830,26 @@ func (vsc *virtualServerConfigurator) generatePolicies(
 			case pol.Spec.EgressMTLS != nil:
 				res = config.addEgressMTLSConfig(pol.Spec.EgressMTLS, key, polNamespace, policyOpts.secretRefs)
 			case pol.Spec.OIDC != nil:
-				res = config.addOIDCConfig(pol.Spec.OIDC, key, polNamespace, policyOpts.secretRefs, vsc.oidcPolCfg)
+				res = config.addOIDCConfig(pol.Spec.OIDC, key, polNamespace, policyOpts.secretRefs, vsc.oidcPolCfg, ownerDetails, vsc.isPlus)
 			case pol.Spec.APIKey != nil:
 				res = config.addAPIKeyConfig(pol.Spec.APIKey, key, polNamespace, ownerDetails.vsNamespace,
 					ownerDetails.vsName, policyOpts.secretRefs)
 			case pol.Spec.WAF != nil:
 				res = config.addWAFConfig(vsc.cfgParams.Context, pol.Spec.WAF, key, polNamespace, policyOpts.apResources)
+			case pol.Spec.CORS != nil:
+				res = config.addCORSConfig(pol, ownerDetails)
+			case pol.Spec.ExternalAuth != nil:
+				res = config.addExternalAuthConfig(pol, ownerDetails)
+			case pol.Spec.Cache != nil:
+				res = config.addCacheConfig(pol, ownerDetails, vsc.isPlus)
+			case pol.Spec.FaultInjection != nil:
+				res = config.addFaultInjectionConfig(pol, ownerDetails)
+			case pol.Spec.ConnectionLimit != nil:
+				res = config.addConnectionLimitConfig(pol, ownerDetails)
+			case pol.Spec.Authorization != nil:
+				res = config.addAuthorizationConfig(pol, ownerDetails, vsc.isPlus)
+			case pol.Spec.SignatureValidation != nil:
+				res = config.addSignatureValidationConfig(pol.Spec.SignatureValidation, key, polNamespace, policyOpts.secretRefs)
 			default:
 				res = newValidationResults()
 			}
@@ -1749,6 +2867,51 @@ func (vsc *virtualServerConfigurator) generatePolicies(
 		}
 	}
 
+	if config.Authorization.Auth != nil && !policyOpts.authenticated && !config.hasAuthentication(vsc.isPlus) {
+		authnPolicies := "jwt"
+		if vsc.isPlus {
+			authnPolicies = "jwt or oidc"
+		}
+		vsc.addWarningf(ownerDetails.owner, "Authorization policy requires a policy of type %s in the same context or in the VirtualServer spec", authnPolicies)
+		return policiesCfg{
+			ErrorReturn: &version2.Return{Code: 500},
+		}
+	}
+
+	if config.ExternalAuth.Auth != nil && config.APIKey.Key != nil {
+		vsc.addWarningf(ownerDetails.owner, "ExternalAuth and APIKey policies cannot be used together in the same context")
+		return policiesCfg{
+			ErrorReturn: &version2.Return{Code: 500},
+		}
+	}
+
+	if !vsc.isPlus && config.OIDC && (config.JWTAuth.Auth != nil || config.ExternalAuth.Auth != nil || config.APIKey.Key != nil) {
+		vsc.addWarningf(ownerDetails.owner, "OIDC policies cannot be used together with JWT, ExternalAuth or APIKey policies in the same context")
+		return policiesCfg{
+			ErrorReturn: &version2.Return{Code: 500},
+		}
+	}
+
+	if !vsc.isPlus && config.JWTAuth.Auth != nil && (config.ExternalAuth.Auth != nil || config.APIKey.Key != nil) {
+		vsc.addWarningf(ownerDetails.owner, "JWT policies cannot be used together with ExternalAuth or APIKey policies in the same context")
+		return policiesCfg{
+			ErrorReturn: &version2.Return{Code: 500},
+		}
+	}
+
+	if authRequestPolicies := config.authRequestPolicies(vsc.isPlus); len(authRequestPolicies) > 0 {
+		for _, specPolicy := range policyOpts.authRequestPolicies {
+			// a policy of the same type in a route overrides the one of the spec
+			if !slices.Contains(authRequestPolicies, specPolicy) {
+				vsc.addWarningf(ownerDetails.owner, "The %s policy of the VirtualServer spec cannot be used together with the %s policy of a route, because both use auth_request",
+					specPolicy, strings.Join(authRequestPolicies, " and "))
+				return policiesCfg{
+					ErrorReturn: &version2.Return{Code: 500},
+				}
+			}
+		}
+	}
+
 	if len(config.RateLimit.PolicyGroupMaps) > 0 {
 		for _, v := range generateLRZGroupMaps(config.RateLimit.Zones) {
 			if hasDuplicateMapDefaults(v) {
@@ -1891,6 +3054,47 @@ func generateLimitReqOptions(rateLimitPol *conf_v1.RateLimit) version2.LimitReqO
 	}
 }
 
+func generateLimitConnZone(zoneName string, connLimitPol *conf_v1.ConnectionLimit) version2.LimitConnZone {
+	return version2.LimitConnZone{
+		Key:      connLimitPol.Key,
+		ZoneName: zoneName,
+		ZoneSize: connLimitPol.ZoneSize,
+	}
+}
+
+// generateLimitConn generates a connection limit. A defaultRejectCode of 0 means the reject code is not used,
+// as in the stream context.
+func generateLimitConn(zoneName string, connLimitPol *conf_v1.ConnectionLimit, defaultRejectCode int) version2.LimitConn {
+	limitConn := version2.LimitConn{
+		ZoneName:    zoneName,
+		Connections: connLimitPol.Connections,
+		DryRun:      generateBool(connLimitPol.DryRun, false),
+	}
+	if defaultRejectCode != 0 {
+		limitConn.RejectCode = generateIntFromPointer(connLimitPol.RejectCode, defaultRejectCode)
+	}
+
+	return limitConn
+}
+
+func removeDuplicateLimitConnZones(zones []version2.LimitConnZone) []version2.LimitConnZone {
+	if len(zones) == 0 {
+		return nil
+	}
+
+	encountered := make(map[string]bool)
+	var result []version2.LimitConnZone
+
+	for _, z := range zones {
+		if !encountered[z.ZoneName] {
+			encountered[z.ZoneName] = true
+			result = append(result, z)
+		}
+	}
+
+	return result
+}
+
 func removeDuplicateLimitReqZones(rlz []version2.LimitReqZone) []version2.LimitReqZone {
 	encountered := make(map[string]bool)
 	result := []version2.LimitReqZone{}
@@ -1905,6 +3109,24 @@ func removeDuplicateLimitReqZones(rlz []version2.LimitReqZone) []version2.LimitR
 	return result
 }
 
+func removeDuplicateCacheZones(zones []version2.CacheZone) []version2.CacheZone {
+	if len(zones) == 0 {
+		return nil
+	}
+
+	encountered := make(map[string]bool)
+	var result []version2.CacheZone
+
+	for _, z := range zones {
+		if !encountered[z.Name] {
+			encountered[z.Name] = true
+			result = append(result, z)
+		}
+	}
+
+	return result
+}
+
 func removeDuplicateMaps(maps []version2.Map) []version2.Map {
 	if len(maps) == 0 {
 		return nil
@@ -1969,12 +3191,19 @@ func addPoliciesCfgToLocation(cfg policiesCfg, location *version2.Location) {
 	location.Deny = cfg.Deny
 	location.LimitReqOptions = cfg.RateLimit.Options
 	location.LimitReqs = cfg.RateLimit.Reqs
+	location.LimitConn = cfg.ConnectionLimit.Conn
+	location.Authorization = cfg.Authorization.Auth
 	location.JWTAuth = cfg.JWTAuth.Auth
 	location.BasicAuth = cfg.BasicAuth
 	location.EgressMTLS = cfg.EgressMTLS
 	location.OIDC = cfg.OIDC
 	location.WAF = cfg.WAF
 	location.APIKey = cfg.APIKey.Key
+	location.CORS = cfg.CORS.Headers
+	location.ExternalAuth = cfg.ExternalAuth.Auth
+	location.Cache = cfg.Cache.Cache
+	location.FaultInjection = cfg.FaultInjection.Fault
+	location.SignatureValidation = cfg.SignatureValidation
 	location.PoliciesErrorReturn = cfg.ErrorReturn
 }
 
@@ -2347,6 +3576,30 @@ func generateBool(s *bool, defaultS bool) bool {
 	return defaultS
 }
 
+// GenerateRoutePath returns the path of a route with the modifier of the NGINX location directive
+// that corresponds to the path type of the route. Routes without a path type already include the modifier.
+func GenerateRoutePath(route conf_v1.Route) string {
+	switch route.PathType {
+	case conf_v1.PathTypeExact:
+		if route.CaseInsensitive {
+			return fmt.Sprintf("~*^%s$", regexp.QuoteMeta(route.Path))
+		}
+		return fmt.Sprintf("=%s", route.Path)
+	case conf_v1.PathTypePrefix:
+		if route.CaseInsensitive {
+			return fmt.Sprintf("~*^%s", regexp.QuoteMeta(route.Path))
+		}
+		return route.Path
+	case conf_v1.PathTypeRegularExpression:
+		if route.CaseInsensitive {
+			return fmt.Sprintf("~*%s", route.Path)
+		}
+		return fmt.Sprintf("~%s", route.Path)
+	}
+
+	return route.Path
+}
+
 func generatePath(path string) string {
 	// Wrap the regular expression (if present) inside double quotes (") to avoid NGINX parsing errors
 	if strings.HasPrefix(path, "~*") {
@@ -2379,7 +3632,7 @@ type errorPageDetails struct {
 }
 
 func generateLocation(path string, upstreamName string, upstream conf_v1.Upstream, action *conf_v1.Action,
-	cfgParams *ConfigParams, errorPages errorPageDetails, internal bool, proxySSLName string,
+	mirrorUpstreamName string, cfgParams *ConfigParams, errorPages errorPageDetails, internal bool, proxySSLName string,
 	originalPath string, locSnippets string, enableSnippets bool, retLocIndex int, isVSR bool, vsrName string,
 	vsrNamespace string, vscWarnings Warnings,
 ) (version2.Location, *version2.ReturnLocation) {
@@ -2395,8 +3648,99 @@ func generateLocation(path string, upstreamName string, upstream conf_v1.Upstrea
 
 	checkGrpcErrorPageCodes(errorPages, isGRPC(upstream.Type), upstream.Name, vscWarnings)
 
-	return generateLocationForProxying(path, upstreamName, upstream, cfgParams, errorPages.pages, internal,
-		errorPages.index, proxySSLName, action.Proxy, originalPath, locationSnippets, isVSR, vsrName, vsrNamespace), nil
+	loc := generateLocationForProxying(path, upstreamName, upstream, cfgParams, errorPages.pages, internal,
+		errorPages.index, proxySSLName, action.Proxy, originalPath, locationSnippets, isVSR, vsrName, vsrNamespace)
+	loc.Mirror = generateMirror(action.Proxy, mirrorUpstreamName)
+
+	return loc, nil
+}
+
+func generateMirror(proxy *conf_v1.ActionProxy, mirrorUpstreamName string) *version2.Mirror {
+	if proxy == nil || proxy.Mirror == nil {
+		return nil
+	}
+
+	percentage := generateIntFromPointer(proxy.Mirror.Percentage, 100)
+
+	return &version2.Mirror{
+		Path:         fmt.Sprintf("/%vmirror_%s_%d", internalLocationPrefix, mirrorUpstreamName, percentage),
+		RequestBody:  generateBool(proxy.Mirror.RequestBody, true),
+		UpstreamName: mirrorUpstreamName,
+		Percentage:   percentage,
+	}
+}
+
+// generateMirrorLocations generates an internal location for every unique mirror referenced by the locations,
+// along with the split clients that sample the mirrored requests when only a percentage of them is mirrored.
+func (vsc *virtualServerConfigurator) generateMirrorLocations(
+	vs *conf_v1.VirtualServer,
+	locations []version2.Location,
+	crUpstreams map[string]conf_v1.Upstream,
+	variableNamer *VariableNamer,
+) ([]version2.MirrorLocation, []version2.SplitClient) {
+	var mirrorLocations []version2.MirrorLocation
+	var splitClients []version2.SplitClient
+	generatedPaths := make(map[string]bool)
+	generatedVariables := make(map[string]bool)
+
+	for i := range locations {
+		m := locations[i].Mirror
+		if m == nil {
+			continue
+		}
+
+		upstream := crUpstreams[m.UpstreamName]
+		if isGRPC(upstream.Type) {
+			vsc.addWarningf(vs, "mirroring requests to the gRPC upstream %s is not supported", upstream.Name)
+			locations[i].Mirror = nil
+			continue
+		}
+
+		if generatedPaths[m.Path] {
+			continue
+		}
+		generatedPaths[m.Path] = true
+
+		var variable string
+		if m.Percentage < 100 {
+			variable = variableNamer.GetNameForMirrorVariable(m.Percentage)
+			if !generatedVariables[variable] {
+				generatedVariables[variable] = true
+				splitClients = append(splitClients, version2.SplitClient{
+					Source:   "$request_id",
+					Variable: variable,
+					Distributions: []version2.Distribution{
+						{
+							Weight: fmt.Sprintf("%d%%", m.Percentage),
+							Value:  "1",
+						},
+						{
+							Weight: "*",
+							Value:  `""`,
+						},
+					},
+				})
+			}
+		}
+
+		namespace := vs.Namespace
+		if locations[i].IsVSR {
+			namespace = locations[i].VSRNamespace
+		}
+
+		mirrorLocations = append(mirrorLocations, version2.MirrorLocation{
+			Path:                m.Path,
+			ProxyPass:           generateProxyPass(upstream.TLS.Enable, m.UpstreamName, true, nil),
+			ProxyConnectTimeout: generateTimeWithDefault(upstream.ProxyConnectTimeout, vsc.cfgParams.ProxyConnectTimeout),
+			ProxyReadTimeout:    generateTimeWithDefault(upstream.ProxyReadTimeout, vsc.cfgParams.ProxyReadTimeout),
+			ProxySendTimeout:    generateTimeWithDefault(upstream.ProxySendTimeout, vsc.cfgParams.ProxySendTimeout),
+			ProxySSLName:        generateProxySSLName(upstream.Service, namespace),
+			HasKeepalive:        upstreamHasKeepalive(upstream, vsc.cfgParams),
+			Variable:            variable,
+		})
+	}
+
+	return mirrorLocations, splitClients
 }
 
 func generateProxySetHeaders(proxy *conf_v1.ActionProxy) []version2.Header {
@@ -2460,6 +3804,34 @@ func generateProxyIgnoreHeaders(proxy *conf_v1.ActionProxy) string {
 	return strings.Join(proxy.ResponseHeaders.Ignore, " ")
 }
 
+// cacheControlHeaders are the response headers ignored by a Cache policy with ignoreCacheControl.
+var cacheControlHeaders = []string{"Cache-Control", "Expires"}
+
+// addCacheIgnoreHeaders adds the headers ignored by the Cache policy of the locations, or of the VirtualServer spec,
+// to the response headers ignored by the locations, because a location can only have one proxy_ignore_headers.
+func addCacheIgnoreHeaders(locations []version2.Location, specCache *version2.Cache) {
+	for i := range locations {
+		loc := &locations[i]
+
+		cache := loc.Cache
+		if cache == nil {
+			cache = specCache
+		}
+		// the cache is not used for gRPC
+		if cache == nil || !cache.IgnoreCacheControl || loc.GRPCPass != "" {
+			continue
+		}
+
+		headers := strings.Fields(loc.ProxyIgnoreHeaders)
+		for _, h := range cacheControlHeaders {
+			if !slices.ContainsFunc(headers, func(ignored string) bool { return strings.EqualFold(ignored, h) }) {
+				headers = append(headers, h)
+			}
+		}
+		loc.ProxyIgnoreHeaders = strings.Join(headers, " ")
+	}
+}
+
 func generateProxyAddHeaders(proxy *conf_v1.ActionProxy) []version2.AddHeader {
 	if proxy == nil || proxy.ResponseHeaders == nil {
 		return nil
@@ -2483,6 +3855,8 @@ func generateLocationForProxying(path string, upstreamName string, upstream conf
 	cfgParams *ConfigParams, errorPages []conf_v1.ErrorPage, internal bool, errPageIndex int,
 	proxySSLName string, proxy *conf_v1.ActionProxy, originalPath string, locationSnippets []string, isVSR bool, vsrName string, vsrNamespace string,
 ) version2.Location {
+	upstream = overrideUpstreamProxySettings(upstream, proxy)
+
 	return version2.Location{
 		Path:                     generatePath(path),
 		Internal:                 internal,
@@ -2519,6 +3893,37 @@ func generateLocationForProxying(path string, upstreamName string, upstream conf
 	}
 }
 
+// overrideUpstreamProxySettings returns a copy of the upstream with the proxy settings overridden by the action.
+func overrideUpstreamProxySettings(upstream conf_v1.Upstream, proxy *conf_v1.ActionProxy) conf_v1.Upstream {
+	if proxy == nil {
+		return upstream
+	}
+
+	if proxy.ConnectTimeout != "" {
+		upstream.ProxyConnectTimeout = proxy.ConnectTimeout
+	}
+	if proxy.ReadTimeout != "" {
+		upstream.ProxyReadTimeout = proxy.ReadTimeout
+	}
+	if proxy.SendTimeout != "" {
+		upstream.ProxySendTimeout = proxy.SendTimeout
+	}
+	if proxy.Buffering != nil {
+		upstream.ProxyBuffering = proxy.Buffering
+	}
+	if proxy.Buffers != nil {
+		upstream.ProxyBuffers = proxy.Buffers
+	}
+	if proxy.BufferSize != "" {
+		upstream.ProxyBufferSize = proxy.BufferSize
+	}
+	if proxy.ClientMaxBodySize != "" {
+		upstream.ClientMaxBodySize = proxy.ClientMaxBodySize
+	}
+
+	return upstream
+}
+
 func generateProxyInterceptErrors(errorPages []conf_v1.ErrorPage) bool {
 	return len(errorPages) > 0
 }
@@ -2596,6 +4001,7 @@ func generateLocationForReturn(path string, locationSnippets []string, actionRet
 
 type routingCfg struct {
 	Maps                     []version2.Map
+	Geos                     []version2.Geo
 	SplitClients             []version2.SplitClient
 	Locations                []version2.Location
 	InternalRedirectLocation version2.InternalRedirectLocation
@@ -2607,6 +4013,7 @@ type routingCfg struct {
 
 func generateSplits(
 	splits []conf_v1.Split,
+	splitKey *conf_v1.SplitKey,
 	upstreamNamer *upstreamNamer,
 	crUpstreams map[string]conf_v1.Upstream,
 	VariableNamer *VariableNamer,
@@ -2641,8 +4048,15 @@ func generateSplits(
 		distributions = append(distributions, d)
 	}
 
+	source := "$request_id"
+	if splitKey != nil {
+		splitKeyMap := generateSplitKeyMap(splitKey, VariableNamer)
+		source = splitKeyMap.Variable
+		maps = append(maps, splitKeyMap)
+	}
+
 	if WeightChangesDynamicReload && len(splits) == 2 {
-		scs, weightMap := generateSplitsForWeightChangesDynamicReload(splits, scIndex, VariableNamer)
+		scs, weightMap := generateSplitsForWeightChangesDynamicReload(splits, scIndex, source, VariableNamer)
 		kvZoneName := VariableNamer.GetNameOfKeyvalZoneForSplitClientIndex(scIndex)
 		kvz := version2.KeyValZone{
 			Name:  kvZoneName,
@@ -2668,7 +4082,7 @@ func generateSplits(
 		twoWaySplitClients = append(twoWaySplitClients, scWithWeights)
 	} else {
 		splitClient := version2.SplitClient{
-			Source:        "$request_id",
+			Source:        source,
 			Variable:      VariableNamer.GetNameForSplitClientVariable(scIndex),
 			Distributions: distributions,
 		}
@@ -2682,9 +4096,10 @@ func generateSplits(
 		path := fmt.Sprintf("/%vsplits_%d_split_%d", internalLocationPrefix, scIndex, i)
 		upstreamName := upstreamNamer.GetNameForUpstreamFromAction(s.Action)
 		upstream := crUpstreams[upstreamName]
+		mirrorUpstreamName := upstreamNamer.GetNameForMirrorUpstreamFromAction(s.Action)
 		proxySSLName := generateProxySSLName(upstream.Service, upstreamNamer.namespace)
 		newRetLocIndex := retLocIndex + len(returnLocations)
-		loc, returnLoc := generateLocation(path, upstreamName, upstream, s.Action, cfgParams, errorPages, true,
+		loc, returnLoc := generateLocation(path, upstreamName, upstream, s.Action, mirrorUpstreamName, cfgParams, errorPages, true,
 			proxySSLName, originalPath, locSnippets, enableSnippets, newRetLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings)
 		locations = append(locations, loc)
 		if returnLoc != nil {
@@ -2695,6 +4110,62 @@ func generateSplits(
 	return splitClients, locations, returnLocations, maps, keyValZones, keyVals, twoWaySplitClients
 }
 
+// generateSplitKeySource returns the variable which holds the value of the split key in a request.
+func generateSplitKeySource(splitKey *conf_v1.SplitKey, variableNamer *VariableNamer) string {
+	if splitKey.Header != "" {
+		return fmt.Sprintf("$http_%s", strings.ReplaceAll(splitKey.Header, "-", "_"))
+	}
+
+	if splitKey.Cookie != "" {
+		return fmt.Sprintf("$cookie_%s", splitKey.Cookie)
+	}
+
+	if splitKey.Argument != "" {
+		return fmt.Sprintf("$arg_%s", splitKey.Argument)
+	}
+
+	return generateAuthJwtClaimSetVariable(splitKey.JWTClaim, variableNamer.vsNamespace, variableNamer.vsName)
+}
+
+// generateSplitKeyMap generates a map which resolves to the value of the split key in a request.
+// Requests without the split key fall back to $request_id, so that they are distributed randomly.
+func generateSplitKeyMap(splitKey *conf_v1.SplitKey, variableNamer *VariableNamer) version2.Map {
+	source := generateSplitKeySource(splitKey, variableNamer)
+
+	return version2.Map{
+		Source:   source,
+		Variable: variableNamer.GetNameForSplitKeyVariable(source),
+		Parameters: []version2.Parameter{
+			{
+				Value:  `""`,
+				Result: "$request_id",
+			},
+			{
+				Value:  "default",
+				Result: source,
+			},
+		},
+	}
+}
+
+// generateSplitKeyAuthJwtClaimSets generates the auth_jwt_claim_set for every JWT claim used as a split key of the route.
+func generateSplitKeyAuthJwtClaimSets(route conf_v1.Route, owner policyOwnerDetails) []version2.AuthJWTClaimSet {
+	var claimSets []version2.AuthJWTClaimSet
+
+	splitKeys := []*conf_v1.SplitKey{route.SplitKey}
+	for _, m := range route.Matches {
+		splitKeys = append(splitKeys, m.SplitKey)
+	}
+
+	for _, k := range splitKeys {
+		if k != nil && k.JWTClaim != "" {
+			claimSets = append(claimSets, generateAuthJwtClaimSet(conf_v1.JWTCondition{Claim: k.JWTClaim}, owner))
+		}
+	}
+
+	return claimSets
+}
+
 func generateDefaultSplitsConfig(
 	route conf_v1.Route,
 	upstreamNamer *upstreamNamer,
@@ -2713,7 +4184,7 @@ func generateDefaultSplitsConfig(
 	vscWarnings Warnings,
 	weightChangesDynamicReload bool,
 ) routingCfg {
-	scs, locs, returnLocs, maps, keyValZones, keyVals, twoWaySplitClients := generateSplits(route.Splits, upstreamNamer, crUpstreams, VariableNamer, scIndex, cfgParams, errorPages, originalPath, locSnippets, enableSnippets, retLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings, weightChangesDynamicReload)
+	scs, locs, returnLocs, maps, keyValZones, keyVals, twoWaySplitClients := generateSplits(route.Splits, route.SplitKey, upstreamNamer, crUpstreams, VariableNamer, scIndex, cfgParams, errorPages, originalPath, locSnippets, enableSnippets, retLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings, weightChangesDynamicReload)
 
 	var irl version2.InternalRedirectLocation
 	if weightChangesDynamicReload && len(route.Splits) == 2 {
@@ -2740,7 +4211,7 @@ func generateDefaultSplitsConfig(
 	}
 }
 
-func generateSplitsForWeightChangesDynamicReload(splits []conf_v1.Split, scIndex int, VariableNamer *VariableNamer) ([]version2.SplitClient, version2.Map) {
+func generateSplitsForWeightChangesDynamicReload(splits []conf_v1.Split, scIndex int, source string, VariableNamer *VariableNamer) ([]version2.SplitClient, version2.Map) {
 	var splitClients []version2.SplitClient
 	var mapParameters []version2.Parameter
 	for i := 0; i <= 100; i++ {
@@ -2763,7 +4234,7 @@ func generateSplitsForWeightChangesDynamicReload(splits []conf_v1.Split, scIndex
 			distributions = append(distributions, distribution)
 		}
 		split = version2.SplitClient{
-			Source:        "$request_id",
+			Source:        source,
 			Variable:      VariableNamer.GetNameOfSplitClientsForWeights(scIndex, i, j),
 			Distributions: distributions,
 		}
@@ -2801,25 +4272,22 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 ) routingCfg {
 	// Generate maps
 	var maps []version2.Map
+	var geos []version2.Geo
 	var twoWaySplitClients []version2.TwoWaySplitClients
 
 	for i, m := range route.Matches {
 		for j, c := range m.Conditions {
-			source := getNameForSourceForMatchesRouteMapFromCondition(c)
 			variable := VariableNamer.GetNameForVariableForMatchesRouteMap(index, i, j)
 			successfulResult := "1"
 			if j < len(m.Conditions)-1 {
 				successfulResult = VariableNamer.GetNameForVariableForMatchesRouteMap(index, i, j+1)
 			}
 
-			params := generateParametersForMatchesRouteMap(c.Value, successfulResult)
-
-			matchMap := version2.Map{
-				Source:     source,
-				Variable:   variable,
-				Parameters: params,
-			}
+			matchMap, geo := generateConditionConfig(c, variable, successfulResult)
 			maps = append(maps, matchMap)
+			if geo != nil {
+				geos = append(geos, *geo)
+			}
 		}
 	}
 
@@ -2884,9 +4352,14 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 
 	for i, m := range route.Matches {
 		if len(m.Splits) > 0 {
+			splitKey := m.SplitKey
+			if splitKey == nil {
+				splitKey = route.SplitKey
+			}
 			newRetLocIndex := retLocIndex + len(returnLocations)
 			scs, locs, returnLocs, mps, kvzs, kvs, twscs := generateSplits(
 				m.Splits,
+				splitKey,
 				upstreamNamer,
 				crUpstreams,
 				VariableNamer,
@@ -2915,9 +4388,10 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 			path := fmt.Sprintf("/%vmatches_%d_match_%d", internalLocationPrefix, index, i)
 			upstreamName := upstreamNamer.GetNameForUpstreamFromAction(m.Action)
 			upstream := crUpstreams[upstreamName]
+			mirrorUpstreamName := upstreamNamer.GetNameForMirrorUpstreamFromAction(m.Action)
 			proxySSLName := generateProxySSLName(upstream.Service, upstreamNamer.namespace)
 			newRetLocIndex := retLocIndex + len(returnLocations)
-			loc, returnLoc := generateLocation(path, upstreamName, upstream, m.Action, cfgParams, errorPages, true,
+			loc, returnLoc := generateLocation(path, upstreamName, upstream, m.Action, mirrorUpstreamName, cfgParams, errorPages, true,
 				proxySSLName, route.Path, locSnippets, enableSnippets, newRetLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings)
 			locations = append(locations, loc)
 			if returnLoc != nil {
@@ -2931,6 +4405,7 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 		newRetLocIndex := retLocIndex + len(returnLocations)
 		scs, locs, returnLocs, mps, kvzs, kvs, twscs := generateSplits(
 			route.Splits,
+			route.SplitKey,
 			upstreamNamer,
 			crUpstreams,
 			VariableNamer,
@@ -2958,9 +4433,10 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 		path := fmt.Sprintf("/%vmatches_%d_default", internalLocationPrefix, index)
 		upstreamName := upstreamNamer.GetNameForUpstreamFromAction(route.Action)
 		upstream := crUpstreams[upstreamName]
+		mirrorUpstreamName := upstreamNamer.GetNameForMirrorUpstreamFromAction(route.Action)
 		proxySSLName := generateProxySSLName(upstream.Service, upstreamNamer.namespace)
 		newRetLocIndex := retLocIndex + len(returnLocations)
-		loc, returnLoc := generateLocation(path, upstreamName, upstream, route.Action, cfgParams, errorPages, true,
+		loc, returnLoc := generateLocation(path, upstreamName, upstream, route.Action, mirrorUpstreamName, cfgParams, errorPages, true,
 			proxySSLName, route.Path, locSnippets, enableSnippets, newRetLocIndex, isVSR, vsrName, vsrNamespace, vscWarnings)
 		locations = append(locations, loc)
 		if returnLoc != nil {
@@ -2976,6 +4452,7 @@ func generateMatchesConfig(route conf_v1.Route, upstreamNamer *upstreamNamer, cr
 
 	return routingCfg{
 		Maps:                     maps,
+		Geos:                     geos,
 		Locations:                locations,
 		InternalRedirectLocation: irl,
 		SplitClients:             splitClients,
@@ -2993,7 +4470,7 @@ var specialMapParameters = map[string]bool{
 	"volatile":  true,
 }
 
-func generateValueForMatchesRouteMap(matchedValue string) (value string, isNegative bool) {
+func generateValueForMatchesRouteMap(matchedValue string, isRegex bool) (value string, isNegative bool) {
 	if len(matchedValue) == 0 {
 		return `""`, false
 	}
@@ -3003,6 +4480,10 @@ func generateValueForMatchesRouteMap(matchedValue string) (value string, isNegat
 		matchedValue = matchedValue[1:]
 	}
 
+	if isRegex {
+		return fmt.Sprintf(`"~%s"`, matchedValue), isNegative
+	}
+
 	if _, exists := specialMapParameters[matchedValue]; exists {
 		return `\` + matchedValue, isNegative
 	}
@@ -3010,8 +4491,27 @@ func generateValueForMatchesRouteMap(matchedValue string) (value string, isNegat
 	return fmt.Sprintf(`"%s"`, matchedValue), isNegative
 }
 
-func generateParametersForMatchesRouteMap(matchedValue string, successfulResult string) []version2.Parameter {
-	value, isNegative := generateValueForMatchesRouteMap(matchedValue)
+func generateParametersForMatchesRouteMap(condition conf_v1.Condition, successfulResult string) []version2.Parameter {
+	var values []string
+	var isNegative bool
+
+	switch {
+	case len(condition.Methods) > 0:
+		for _, m := range condition.Methods {
+			values = append(values, fmt.Sprintf(`"%s"`, m))
+		}
+	case len(condition.SourceCIDRs) > 0:
+		// the source of the map is the geo variable, which is 1 for the matching client addresses
+		values = []string{`"1"`}
+	case condition.Present != nil:
+		// an absent header, cookie, argument or variable has an empty value
+		values = []string{`""`}
+		isNegative = *condition.Present
+	default:
+		var value string
+		value, isNegative = generateValueForMatchesRouteMap(condition.Value, condition.Regex)
+		values = []string{value}
+	}
 
 	valueResult := successfulResult
 	defaultResult := "0"
@@ -3020,20 +4520,54 @@ func generateParametersForMatchesRouteMap(matchedValue string, successfulResult
 		defaultResult = successfulResult
 	}
 
-	params := []version2.Parameter{
-		{
-			Value:  value,
+	var params []version2.Parameter
+	for _, v := range values {
+		params = append(params, version2.Parameter{
+			Value:  v,
 			Result: valueResult,
-		},
-		{
-			Value:  "default",
-			Result: defaultResult,
-		},
+		})
 	}
+	params = append(params, version2.Parameter{
+		Value:  "default",
+		Result: defaultResult,
+	})
 
 	return params
 }
 
+// generateConditionConfig generates the map that sets the variable to successfulResult when the condition is satisfied.
+// For a condition on the client IP address, it also generates the geo block the map is based on.
+func generateConditionConfig(condition conf_v1.Condition, variable string, successfulResult string) (version2.Map, *version2.Geo) {
+	source := getNameForSourceForMatchesRouteMapFromCondition(condition)
+
+	var geo *version2.Geo
+	if len(condition.SourceCIDRs) > 0 {
+		geo = &version2.Geo{
+			Source:   source,
+			Variable: fmt.Sprintf("%s_geo", variable),
+			Parameters: []version2.Parameter{
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		}
+		for _, cidr := range condition.SourceCIDRs {
+			geo.Parameters = append(geo.Parameters, version2.Parameter{
+				Value:  cidr,
+				Result: "1",
+			})
+		}
+		source = geo.Variable
+	}
+
+	return version2.Map{
+		Source:     source,
+		Variable:   variable,
+		Parameters: generateParametersForMatchesRouteMap(condition, successfulResult),
+	}, geo
+}
+
 func getNameForSourceForMatchesRouteMapFromCondition(condition conf_v1.Condition) string {
 	if condition.Header != "" {
 		return fmt.Sprintf("$http_%s", strings.ReplaceAll(condition.Header, "-", "_"))
@@ -3047,6 +4581,14 @@ func getNameForSourceForMatchesRouteMapFromCondition(condition conf_v1.Condition
 		return fmt.Sprintf("$arg_%s", condition.Argument)
 	}
 
+	if len(condition.Methods) > 0 {
+		return "$request_method"
+	}
+
+	if len(condition.SourceCIDRs) > 0 {
+		return "$remote_addr"
+	}
+
 	return condition.Variable
 }
 
@@ -3065,6 +4607,7 @@ func (vsc *virtualServerConfigurator) generateSSLConfig(owner runtime.Object, tl
 				CertificateKey:  pemFileNameForWildcardTLSSecret,
 				RejectHandshake: false,
 			}
+			vsc.addOCSPConfig(owner, &ssl, tls.OCSP)
 			return &ssl
 		}
 		return nil
@@ -3093,10 +4636,104 @@ func (vsc *virtualServerConfigurator) generateSSLConfig(owner runtime.Object, tl
 		CertificateKey:  name,
 		RejectHandshake: rejectHandshake,
 	}
+	vsc.addOCSPConfig(owner, &ssl, tls.OCSP)
 
 	return &ssl
 }
 
+// addOCSPConfig adds the OCSP stapling of the server certificate and the resolver for the OCSP responders.
+// NGINX resolves the OCSP responders with the resolver of the server, so the resolver is only added when the ConfigMap
+// doesn't configure one, otherwise it would also replace the ConfigMap resolver for the upstreams of the server.
+func (vsc *virtualServerConfigurator) addOCSPConfig(owner runtime.Object, ssl *version2.SSL, ocsp *conf_v1.TLSOCSP) {
+	if ocsp == nil {
+		return
+	}
+
+	ssl.OCSPStapling = ocsp.Stapling && !ssl.RejectHandshake
+	if ssl.OCSPStapling {
+		ssl.OCSPStaplingResponder = ocsp.StaplingResponder
+	}
+
+	if ocsp.Resolver != nil && vsc.isResolverConfigured {
+		vsc.addWarningf(owner, "The resolver in tls.ocsp is ignored because the resolver is configured in the ConfigMap, which is used for the OCSP responders instead")
+		return
+	}
+
+	if ocsp.Resolver != nil {
+		ssl.OCSPResolver = &version2.Resolver{
+			Addresses: ocsp.Resolver.Addresses,
+			Valid:     ocsp.Resolver.Valid,
+			Timeout:   ocsp.Resolver.Timeout,
+			IPv6:      generateBool(ocsp.Resolver.IPv6, true),
+		}
+	}
+}
+
+// isOCSPResolverRequired checks if the server needs a resolver for the hostnames of the OCSP responders.
+func isOCSPResolverRequired(ssl *version2.SSL, ingressMTLS *version2.IngressMTLS) bool {
+	if ssl == nil || ssl.OCSPResolver != nil {
+		return false
+	}
+	return ssl.OCSPStapling || (ingressMTLS != nil && ingressMTLS.OCSP != "")
+}
+
+// generateRealIPConfig generates the real IP settings of a VirtualServer. The settings of the custom listeners override
+// the ConfigMap settings. NGINX applies the settings to the whole server rather than to a listener, so when the HTTP and
+// HTTPS listeners have different settings, neither is trusted over the other and the ConfigMap settings are used.
+func (vsc *virtualServerConfigurator) generateRealIPConfig(vsEx *VirtualServerEx, useCustomListeners bool) ([]string, string) {
+	if !useCustomListeners {
+		return vsc.cfgParams.SetRealIPFrom, vsc.cfgParams.RealIPHeader
+	}
+
+	listeners := []struct {
+		port          int
+		setRealIPFrom []string
+		realIPHeader  string
+	}{
+		{vsEx.HTTPPort, vsEx.HTTPSetRealIPFrom, vsEx.HTTPRealIPHeader},
+		{vsEx.HTTPSPort, vsEx.HTTPSSetRealIPFrom, vsEx.HTTPSRealIPHeader},
+	}
+
+	var setRealIPFrom []string
+	var realIPHeader string
+	configured := false
+	for _, l := range listeners {
+		if l.port == 0 {
+			continue
+		}
+
+		addresses := l.setRealIPFrom
+		if len(addresses) == 0 {
+			addresses = vsc.cfgParams.SetRealIPFrom
+		}
+		header := generateString(l.realIPHeader, vsc.cfgParams.RealIPHeader)
+
+		if configured && (!sameAddresses(addresses, setRealIPFrom) || header != realIPHeader) {
+			vsc.addWarningf(vsEx.VirtualServer, "The HTTP and HTTPS listeners have different setRealIPFrom or realIPHeader settings, which cannot be applied per listener. The ConfigMap settings will be used")
+			return vsc.cfgParams.SetRealIPFrom, vsc.cfgParams.RealIPHeader
+		}
+
+		setRealIPFrom = addresses
+		realIPHeader = header
+		configured = true
+	}
+
+	return setRealIPFrom, realIPHeader
+}
+
+// sameAddresses checks if two lists contain the same addresses regardless of their order.
+func sameAddresses(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, address := range a {
+		if !slices.Contains(b, address) {
+			return false
+		}
+	}
+	return true
+}
+
 func generateTLSRedirectConfig(tls *conf_v1.TLS) *version2.TLSRedirect {
 	if tls == nil || tls.Redirect == nil || !tls.Redirect.Enable {
 		return nil
@@ -3117,6 +4754,11 @@ func generateTLSRedirectBasedOn(basedOn string) string {
 	return "$scheme"
 }
 
+// isStaticUpstream reports whether the upstream uses static servers instead of the endpoints of a service.
+func isStaticUpstream(upstream conf_v1.Upstream) bool {
+	return len(upstream.Servers) > 0
+}
+
 func createEndpointsFromUpstream(upstream version2.Upstream) []string {
 	var endpoints []string
 
@@ -3145,6 +4787,10 @@ func createUpstreamsForPlus(
 			nl.Debugf(l, "Service %s is Type ExternalName, skipping NGINX Plus endpoints update via API", u.Service)
 			continue
 		}
+		if isStaticUpstream(u) {
+			nl.Debugf(l, "Upstream %s has static servers, skipping NGINX Plus endpoints update via API", u.Name)
+			continue
+		}
 
 		upstreamName := upstreamNamer.GetNameForUpstream(u.Name)
 		upstreamNamespace := virtualServerEx.VirtualServer.Namespace
@@ -3169,6 +4815,10 @@ func createUpstreamsForPlus(
 				nl.Debugf(l, "Service %s is Type ExternalName, skipping NGINX Plus endpoints update via API", u.Service)
 				continue
 			}
+			if isStaticUpstream(u) {
+				nl.Debugf(l, "Upstream %s has static servers, skipping NGINX Plus endpoints update via API", u.Name)
+				continue
+			}
 
 			upstreamName := upstreamNamer.GetNameForUpstream(u.Name)
 			upstreamNamespace := vsr.Namespace
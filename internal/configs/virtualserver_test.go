@@ -215,6 +215,16 @@ func TestVariableNamer(t *testing.T) {
 	if result != expected {
 		t.Errorf("GetNameForVariableForMatchesRouteMainMap() returned %q but expected %q", result, expected)
 	}
+
+	// GetNameForMirrorVariable()
+	percentage := 10
+
+	expected = "$vs_default_cafe_mirror_10"
+
+	result = variableNamer.GetNameForMirrorVariable(percentage)
+	if result != expected {
+		t.Errorf("GetNameForMirrorVariable() returned %q but expected %q", result, expected)
+	}
 }
 
 func TestGenerateVSConfig_GeneratesConfigWithGunzipOn(t *testing.T) {
@@ -4086,7 +4096,7 @@ func TestGenerateVirtualServerConfigWithInternalRoutesWarning(t *testing.T) {
 	}
 }
 
-func TestGenerateVirtualServerConfigForVirtualServerWithSplits(t *testing.T) {
+func TestGenerateVirtualServerConfigWithMirror(t *testing.T) {
 	t.Parallel()
 	virtualServerEx := VirtualServerEx{
 		VirtualServer: &conf_v1.VirtualServer{
@@ -4098,186 +4108,105 @@ func TestGenerateVirtualServerConfigForVirtualServerWithSplits(t *testing.T) {
 				Host: "cafe.example.com",
 				Upstreams: []conf_v1.Upstream{
 					{
-						Name:    "tea-v1",
-						Service: "tea-svc-v1",
+						Name:    "tea",
+						Service: "tea-svc",
 						Port:    80,
 					},
 					{
 						Name:    "tea-v2",
-						Service: "tea-svc-v2",
+						Service: "tea-v2-svc",
 						Port:    80,
 					},
 				},
 				Routes: []conf_v1.Route{
 					{
 						Path: "/tea",
-						Splits: []conf_v1.Split{
-							{
-								Weight: 90,
-								Action: &conf_v1.Action{
-									Pass: "tea-v1",
-								},
-							},
-							{
-								Weight: 10,
-								Action: &conf_v1.Action{
-									Pass: "tea-v2",
+						Action: &conf_v1.Action{
+							Proxy: &conf_v1.ActionProxy{
+								Upstream: "tea",
+								Mirror: &conf_v1.ProxyMirror{
+									Upstream:   "tea-v2",
+									Percentage: createPointerFromInt(10),
 								},
 							},
 						},
 					},
 					{
-						Path:  "/coffee",
-						Route: "default/coffee",
+						Path: "/tea-all",
+						Action: &conf_v1.Action{
+							Proxy: &conf_v1.ActionProxy{
+								Upstream: "tea",
+								Mirror: &conf_v1.ProxyMirror{
+									Upstream:    "tea-v2",
+									RequestBody: createPointerFromBool(false),
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		Endpoints: map[string][]string{
-			"default/tea-svc-v1:80": {
+			"default/tea-svc:80": {
 				"10.0.0.20:80",
 			},
-			"default/tea-svc-v2:80": {
-				"10.0.0.21:80",
-			},
-			"default/coffee-svc-v1:80": {
+			"default/tea-v2-svc:80": {
 				"10.0.0.30:80",
 			},
-			"default/coffee-svc-v2:80": {
-				"10.0.0.31:80",
-			},
-		},
-		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-			{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "coffee",
-					Namespace: "default",
-				},
-				Spec: conf_v1.VirtualServerRouteSpec{
-					Host: "cafe.example.com",
-					Upstreams: []conf_v1.Upstream{
-						{
-							Name:    "coffee-v1",
-							Service: "coffee-svc-v1",
-							Port:    80,
-						},
-						{
-							Name:    "coffee-v2",
-							Service: "coffee-svc-v2",
-							Port:    80,
-						},
-					},
-					Subroutes: []conf_v1.Route{
-						{
-							Path: "/coffee",
-							Splits: []conf_v1.Split{
-								{
-									Weight: 40,
-									Action: &conf_v1.Action{
-										Pass: "coffee-v1",
-									},
-								},
-								{
-									Weight: 60,
-									Action: &conf_v1.Action{
-										Pass: "coffee-v2",
-									},
-								},
-							},
-						},
-					},
-				},
-			},
 		},
 	}
 
-	baseCfgParams := ConfigParams{Context: context.Background()}
+	baseCfgParams := ConfigParams{
+		Context:      context.Background(),
+		ServerTokens: "off",
+		Keepalive:    16,
+	}
 
 	expected := version2.VirtualServerConfig{
 		Upstreams: []version2.Upstream{
 			{
-				Name: "vs_default_cafe_tea-v1",
 				UpstreamLabels: version2.UpstreamLabels{
-					Service:           "tea-svc-v1",
+					Service:           "tea-svc",
 					ResourceType:      "virtualserver",
 					ResourceName:      "cafe",
 					ResourceNamespace: "default",
 				},
+				Name: "vs_default_cafe_tea",
 				Servers: []version2.UpstreamServer{
 					{
 						Address: "10.0.0.20:80",
 					},
 				},
+				Keepalive: 16,
 			},
 			{
-				Name: "vs_default_cafe_tea-v2",
 				UpstreamLabels: version2.UpstreamLabels{
-					Service:           "tea-svc-v2",
+					Service:           "tea-v2-svc",
 					ResourceType:      "virtualserver",
 					ResourceName:      "cafe",
 					ResourceNamespace: "default",
 				},
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "10.0.0.21:80",
-					},
-				},
-			},
-			{
-				Name: "vs_default_cafe_vsr_default_coffee_coffee-v1",
-				UpstreamLabels: version2.UpstreamLabels{
-					Service:           "coffee-svc-v1",
-					ResourceType:      "virtualserverroute",
-					ResourceName:      "coffee",
-					ResourceNamespace: "default",
-				},
+				Name: "vs_default_cafe_tea-v2",
 				Servers: []version2.UpstreamServer{
 					{
 						Address: "10.0.0.30:80",
 					},
 				},
-			},
-			{
-				Name: "vs_default_cafe_vsr_default_coffee_coffee-v2",
-				UpstreamLabels: version2.UpstreamLabels{
-					Service:           "coffee-svc-v2",
-					ResourceType:      "virtualserverroute",
-					ResourceName:      "coffee",
-					ResourceNamespace: "default",
-				},
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "10.0.0.31:80",
-					},
-				},
+				Keepalive: 16,
 			},
 		},
 		SplitClients: []version2.SplitClient{
 			{
 				Source:   "$request_id",
-				Variable: "$vs_default_cafe_splits_0",
+				Variable: "$vs_default_cafe_mirror_10",
 				Distributions: []version2.Distribution{
-					{
-						Weight: "90%",
-						Value:  "/internal_location_splits_0_split_0",
-					},
 					{
 						Weight: "10%",
-						Value:  "/internal_location_splits_0_split_1",
-					},
-				},
-			},
-			{
-				Source:   "$request_id",
-				Variable: "$vs_default_cafe_splits_1",
-				Distributions: []version2.Distribution{
-					{
-						Weight: "40%",
-						Value:  "/internal_location_splits_1_split_0",
+						Value:  "1",
 					},
 					{
-						Weight: "60%",
-						Value:  "/internal_location_splits_1_split_1",
+						Weight: "*",
+						Value:  `""`,
 					},
 				},
 			},
@@ -4285,74 +4214,62 @@ func TestGenerateVirtualServerConfigForVirtualServerWithSplits(t *testing.T) {
 		HTTPSnippets:  []string{},
 		LimitReqZones: []version2.LimitReqZone{},
 		Server: version2.Server{
-			ServerName:  "cafe.example.com",
-			StatusZone:  "cafe.example.com",
-			VSNamespace: "default",
-			VSName:      "cafe",
-			InternalRedirectLocations: []version2.InternalRedirectLocation{
-				{
-					Path:        "/tea",
-					Destination: "$vs_default_cafe_splits_0",
-				},
-				{
-					Path:        "/coffee",
-					Destination: "$vs_default_cafe_splits_1",
-				},
-			},
+			ServerName:   "cafe.example.com",
+			StatusZone:   "cafe.example.com",
+			VSNamespace:  "default",
+			VSName:       "cafe",
+			ServerTokens: "off",
 			Locations: []version2.Location{
 				{
-					Path:                     "/internal_location_splits_0_split_0",
-					ProxyPass:                "http://vs_default_cafe_tea-v1$request_uri",
+					Path:                     "/tea",
+					ProxyPass:                "http://vs_default_cafe_tea",
 					ProxyNextUpstream:        "error timeout",
 					ProxyNextUpstreamTimeout: "0s",
 					ProxyNextUpstreamTries:   0,
-					Internal:                 true,
-					ProxySSLName:             "tea-svc-v1.default.svc",
+					HasKeepalive:             true,
+					ProxySSLName:             "tea-svc.default.svc",
 					ProxyPassRequestHeaders:  true,
 					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "tea-svc-v1",
+					ServiceName:              "tea-svc",
+					Mirror: &version2.Mirror{
+						Path:         "/internal_location_mirror_vs_default_cafe_tea-v2_10",
+						RequestBody:  true,
+						UpstreamName: "vs_default_cafe_tea-v2",
+						Percentage:   10,
+					},
 				},
 				{
-					Path:                     "/internal_location_splits_0_split_1",
-					ProxyPass:                "http://vs_default_cafe_tea-v2$request_uri",
+					Path:                     "/tea-all",
+					ProxyPass:                "http://vs_default_cafe_tea",
 					ProxyNextUpstream:        "error timeout",
 					ProxyNextUpstreamTimeout: "0s",
 					ProxyNextUpstreamTries:   0,
-					Internal:                 true,
-					ProxySSLName:             "tea-svc-v2.default.svc",
+					HasKeepalive:             true,
+					ProxySSLName:             "tea-svc.default.svc",
 					ProxyPassRequestHeaders:  true,
 					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "tea-svc-v2",
+					ServiceName:              "tea-svc",
+					Mirror: &version2.Mirror{
+						Path:         "/internal_location_mirror_vs_default_cafe_tea-v2_100",
+						RequestBody:  false,
+						UpstreamName: "vs_default_cafe_tea-v2",
+						Percentage:   100,
+					},
 				},
+			},
+			MirrorLocations: []version2.MirrorLocation{
 				{
-					Path:                     "/internal_location_splits_1_split_0",
-					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v1$request_uri",
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "0s",
-					ProxyNextUpstreamTries:   0,
-					Internal:                 true,
-					ProxySSLName:             "coffee-svc-v1.default.svc",
-					ProxyPassRequestHeaders:  true,
-					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "coffee-svc-v1",
-					IsVSR:                    true,
-					VSRName:                  "coffee",
-					VSRNamespace:             "default",
+					Path:         "/internal_location_mirror_vs_default_cafe_tea-v2_10",
+					ProxyPass:    "http://vs_default_cafe_tea-v2$request_uri",
+					ProxySSLName: "tea-v2-svc.default.svc",
+					HasKeepalive: true,
+					Variable:     "$vs_default_cafe_mirror_10",
 				},
 				{
-					Path:                     "/internal_location_splits_1_split_1",
-					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v2$request_uri",
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "0s",
-					ProxyNextUpstreamTries:   0,
-					Internal:                 true,
-					ProxySSLName:             "coffee-svc-v2.default.svc",
-					ProxyPassRequestHeaders:  true,
-					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "coffee-svc-v2",
-					IsVSR:                    true,
-					VSRName:                  "coffee",
-					VSRNamespace:             "default",
+					Path:         "/internal_location_mirror_vs_default_cafe_tea-v2_100",
+					ProxyPass:    "http://vs_default_cafe_tea-v2$request_uri",
+					ProxySSLName: "tea-v2-svc.default.svc",
+					HasKeepalive: true,
 				},
 			},
 		},
@@ -4373,7 +4290,7 @@ func TestGenerateVirtualServerConfigForVirtualServerWithSplits(t *testing.T) {
 	}
 }
 
-func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
+func TestGenerateVirtualServerConfigWithExternalAuth(t *testing.T) {
 	t.Parallel()
 	virtualServerEx := VirtualServerEx{
 		VirtualServer: &conf_v1.VirtualServer{
@@ -4385,62 +4302,217 @@ func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 				Host: "cafe.example.com",
 				Upstreams: []conf_v1.Upstream{
 					{
-						Name:    "tea-v1",
-						Service: "tea-svc-v1",
-						Port:    80,
-					},
-					{
-						Name:    "tea-v2",
-						Service: "tea-svc-v2",
+						Name:    "tea",
+						Service: "tea-svc",
 						Port:    80,
 					},
 				},
 				Routes: []conf_v1.Route{
 					{
 						Path: "/tea",
-						Matches: []conf_v1.Match{
+						Policies: []conf_v1.PolicyReference{
 							{
-								Conditions: []conf_v1.Condition{
-									{
-										Header: "x-version",
-										Value:  "v2",
-									},
-								},
-								Action: &conf_v1.Action{
-									Pass: "tea-v2",
-								},
+								Name: "ext-auth-policy",
 							},
 						},
 						Action: &conf_v1.Action{
-							Pass: "tea-v1",
+							Pass: "tea",
 						},
 					},
-					{
-						Path:  "/coffee",
-						Route: "default/coffee",
+				},
+			},
+		},
+		Policies: map[string]*conf_v1.Policy{
+			"default/ext-auth-policy": {
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "ext-auth-policy",
+					Namespace: "default",
+				},
+				Spec: conf_v1.PolicySpec{
+					ExternalAuth: &conf_v1.ExternalAuth{
+						AuthService:           "auth-svc:8080",
+						AuthPath:              "/verify",
+						ForwardRequestHeaders: []string{"Authorization"},
+						ResponseHeaders:       []string{"X-User"},
+						SigninURL:             "https://sso.example.com/signin",
 					},
 				},
 			},
 		},
 		Endpoints: map[string][]string{
-			"default/tea-svc-v1:80": {
+			"default/tea-svc:80": {
 				"10.0.0.20:80",
 			},
-			"default/tea-svc-v2:80": {
-				"10.0.0.21:80",
-			},
-			"default/coffee-svc-v1:80": {
-				"10.0.0.30:80",
-			},
-			"default/coffee-svc-v2:80": {
-				"10.0.0.31:80",
+			"default/auth-svc:8080": {
+				"10.0.0.40:8080",
 			},
 		},
-		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-			{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "coffee",
-					Namespace: "default",
+	}
+
+	baseCfgParams := ConfigParams{
+		Context:      context.Background(),
+		ServerTokens: "off",
+	}
+
+	expected := version2.VirtualServerConfig{
+		Upstreams: []version2.Upstream{
+			{
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "auth-svc",
+					ResourceType:      "virtualserver",
+					ResourceName:      "cafe",
+					ResourceNamespace: "default",
+				},
+				Name: "vs_default_cafe_ext_auth_default_ext-auth-policy",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.40:8080",
+					},
+				},
+			},
+			{
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "tea-svc",
+					ResourceType:      "virtualserver",
+					ResourceName:      "cafe",
+					ResourceNamespace: "default",
+				},
+				Name: "vs_default_cafe_tea",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.20:80",
+					},
+				},
+			},
+		},
+		HTTPSnippets:  []string{},
+		LimitReqZones: []version2.LimitReqZone{},
+		Server: version2.Server{
+			ServerName:   "cafe.example.com",
+			StatusZone:   "cafe.example.com",
+			VSNamespace:  "default",
+			VSName:       "cafe",
+			ServerTokens: "off",
+			Locations: []version2.Location{
+				{
+					Path:                     "/tea",
+					ProxyPass:                "http://vs_default_cafe_tea",
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "0s",
+					ProxyNextUpstreamTries:   0,
+					ProxySSLName:             "tea-svc.default.svc",
+					ProxyPassRequestHeaders:  true,
+					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+					ServiceName:              "tea-svc",
+					ExternalAuth: &version2.ExternalAuth{
+						URI:       "/internal_location_ext_auth_default_ext-auth-policy",
+						SigninURL: "https://sso.example.com/signin",
+						ResponseHeaders: []version2.ExternalAuthResponseHeader{
+							{
+								Name:     "X-User",
+								Variable: "$ext_auth_resp_x_user",
+								Value:    "$upstream_http_x_user",
+							},
+						},
+					},
+				},
+			},
+			ExternalAuthLocations: []version2.ExternalAuthLocation{
+				{
+					Path:      "/internal_location_ext_auth_default_ext-auth-policy",
+					ProxyPass: "http://vs_default_cafe_ext_auth_default_ext-auth-policy/verify",
+					ForwardHeaders: []version2.Header{
+						{
+							Name:  "Authorization",
+							Value: "$http_authorization",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	isPlus := false
+	isResolverConfigured := false
+	isWildcardEnabled := false
+	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, &StaticConfigParams{}, isWildcardEnabled, &fakeBV)
+
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("GenerateVirtualServerConfig() mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
+	}
+}
+
+func TestGenerateVirtualServerConfigForVirtualServerWithSplits(t *testing.T) {
+	t.Parallel()
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea-v1",
+						Service: "tea-svc-v1",
+						Port:    80,
+					},
+					{
+						Name:    "tea-v2",
+						Service: "tea-svc-v2",
+						Port:    80,
+					},
+				},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Splits: []conf_v1.Split{
+							{
+								Weight: 90,
+								Action: &conf_v1.Action{
+									Pass: "tea-v1",
+								},
+							},
+							{
+								Weight: 10,
+								Action: &conf_v1.Action{
+									Pass: "tea-v2",
+								},
+							},
+						},
+					},
+					{
+						Path:  "/coffee",
+						Route: "default/coffee",
+					},
+				},
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tea-svc-v1:80": {
+				"10.0.0.20:80",
+			},
+			"default/tea-svc-v2:80": {
+				"10.0.0.21:80",
+			},
+			"default/coffee-svc-v1:80": {
+				"10.0.0.30:80",
+			},
+			"default/coffee-svc-v2:80": {
+				"10.0.0.31:80",
+			},
+		},
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "coffee",
+					Namespace: "default",
 				},
 				Spec: conf_v1.VirtualServerRouteSpec{
 					Host: "cafe.example.com",
@@ -4459,22 +4531,20 @@ func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 					Subroutes: []conf_v1.Route{
 						{
 							Path: "/coffee",
-							Matches: []conf_v1.Match{
+							Splits: []conf_v1.Split{
 								{
-									Conditions: []conf_v1.Condition{
-										{
-											Argument: "version",
-											Value:    "v2",
-										},
+									Weight: 40,
+									Action: &conf_v1.Action{
+										Pass: "coffee-v1",
 									},
+								},
+								{
+									Weight: 60,
 									Action: &conf_v1.Action{
 										Pass: "coffee-v2",
 									},
 								},
 							},
-							Action: &conf_v1.Action{
-								Pass: "coffee-v1",
-							},
 						},
 					},
 				},
@@ -4487,13 +4557,13 @@ func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 	expected := version2.VirtualServerConfig{
 		Upstreams: []version2.Upstream{
 			{
+				Name: "vs_default_cafe_tea-v1",
 				UpstreamLabels: version2.UpstreamLabels{
 					Service:           "tea-svc-v1",
 					ResourceType:      "virtualserver",
 					ResourceName:      "cafe",
 					ResourceNamespace: "default",
 				},
-				Name: "vs_default_cafe_tea-v1",
 				Servers: []version2.UpstreamServer{
 					{
 						Address: "10.0.0.20:80",
@@ -4543,60 +4613,32 @@ func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 				},
 			},
 		},
-		Maps: []version2.Map{
-			{
-				Source:   "$http_x_version",
-				Variable: "$vs_default_cafe_matches_0_match_0_cond_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"v2"`,
-						Result: "1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
-			},
-			{
-				Source:   "$vs_default_cafe_matches_0_match_0_cond_0",
-				Variable: "$vs_default_cafe_matches_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "~^1",
-						Result: "/internal_location_matches_0_match_0",
-					},
-					{
-						Value:  "default",
-						Result: "/internal_location_matches_0_default",
-					},
-				},
-			},
+		SplitClients: []version2.SplitClient{
 			{
-				Source:   "$arg_version",
-				Variable: "$vs_default_cafe_matches_1_match_0_cond_0",
-				Parameters: []version2.Parameter{
+				Source:   "$request_id",
+				Variable: "$vs_default_cafe_splits_0",
+				Distributions: []version2.Distribution{
 					{
-						Value:  `"v2"`,
-						Result: "1",
+						Weight: "90%",
+						Value:  "/internal_location_splits_0_split_0",
 					},
 					{
-						Value:  "default",
-						Result: "0",
+						Weight: "10%",
+						Value:  "/internal_location_splits_0_split_1",
 					},
 				},
 			},
 			{
-				Source:   "$vs_default_cafe_matches_1_match_0_cond_0",
-				Variable: "$vs_default_cafe_matches_1",
-				Parameters: []version2.Parameter{
+				Source:   "$request_id",
+				Variable: "$vs_default_cafe_splits_1",
+				Distributions: []version2.Distribution{
 					{
-						Value:  "~^1",
-						Result: "/internal_location_matches_1_match_0",
+						Weight: "40%",
+						Value:  "/internal_location_splits_1_split_0",
 					},
 					{
-						Value:  "default",
-						Result: "/internal_location_matches_1_default",
+						Weight: "60%",
+						Value:  "/internal_location_splits_1_split_1",
 					},
 				},
 			},
@@ -4611,64 +4653,64 @@ func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 			InternalRedirectLocations: []version2.InternalRedirectLocation{
 				{
 					Path:        "/tea",
-					Destination: "$vs_default_cafe_matches_0",
+					Destination: "$vs_default_cafe_splits_0",
 				},
 				{
 					Path:        "/coffee",
-					Destination: "$vs_default_cafe_matches_1",
+					Destination: "$vs_default_cafe_splits_1",
 				},
 			},
 			Locations: []version2.Location{
 				{
-					Path:                     "/internal_location_matches_0_match_0",
-					ProxyPass:                "http://vs_default_cafe_tea-v2$request_uri",
+					Path:                     "/internal_location_splits_0_split_0",
+					ProxyPass:                "http://vs_default_cafe_tea-v1$request_uri",
 					ProxyNextUpstream:        "error timeout",
 					ProxyNextUpstreamTimeout: "0s",
 					ProxyNextUpstreamTries:   0,
 					Internal:                 true,
-					ProxySSLName:             "tea-svc-v2.default.svc",
+					ProxySSLName:             "tea-svc-v1.default.svc",
 					ProxyPassRequestHeaders:  true,
 					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "tea-svc-v2",
+					ServiceName:              "tea-svc-v1",
 				},
 				{
-					Path:                     "/internal_location_matches_0_default",
-					ProxyPass:                "http://vs_default_cafe_tea-v1$request_uri",
+					Path:                     "/internal_location_splits_0_split_1",
+					ProxyPass:                "http://vs_default_cafe_tea-v2$request_uri",
 					ProxyNextUpstream:        "error timeout",
 					ProxyNextUpstreamTimeout: "0s",
 					ProxyNextUpstreamTries:   0,
 					Internal:                 true,
-					ProxySSLName:             "tea-svc-v1.default.svc",
+					ProxySSLName:             "tea-svc-v2.default.svc",
 					ProxyPassRequestHeaders:  true,
 					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "tea-svc-v1",
+					ServiceName:              "tea-svc-v2",
 				},
 				{
-					Path:                     "/internal_location_matches_1_match_0",
-					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v2$request_uri",
+					Path:                     "/internal_location_splits_1_split_0",
+					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v1$request_uri",
 					ProxyNextUpstream:        "error timeout",
 					ProxyNextUpstreamTimeout: "0s",
 					ProxyNextUpstreamTries:   0,
 					Internal:                 true,
-					ProxySSLName:             "coffee-svc-v2.default.svc",
+					ProxySSLName:             "coffee-svc-v1.default.svc",
 					ProxyPassRequestHeaders:  true,
 					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "coffee-svc-v2",
+					ServiceName:              "coffee-svc-v1",
 					IsVSR:                    true,
 					VSRName:                  "coffee",
 					VSRNamespace:             "default",
 				},
 				{
-					Path:                     "/internal_location_matches_1_default",
-					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v1$request_uri",
+					Path:                     "/internal_location_splits_1_split_1",
+					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v2$request_uri",
 					ProxyNextUpstream:        "error timeout",
 					ProxyNextUpstreamTimeout: "0s",
 					ProxyNextUpstreamTries:   0,
 					Internal:                 true,
-					ProxySSLName:             "coffee-svc-v1.default.svc",
+					ProxySSLName:             "coffee-svc-v2.default.svc",
 					ProxyPassRequestHeaders:  true,
 					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "coffee-svc-v1",
+					ServiceName:              "coffee-svc-v2",
 					IsVSR:                    true,
 					VSRName:                  "coffee",
 					VSRNamespace:             "default",
@@ -4692,47 +4734,8 @@ func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 	}
 }
 
-func TestGenerateVirtualServerConfigForVirtualServerRoutesWithDos(t *testing.T) {
+func TestGenerateVirtualServerConfigForVirtualServerWithMatches(t *testing.T) {
 	t.Parallel()
-	dosResources := map[string]*appProtectDosResource{
-		"/coffee": {
-			AppProtectDosEnable:          "on",
-			AppProtectDosLogEnable:       false,
-			AppProtectDosMonitorURI:      "test.example.com",
-			AppProtectDosMonitorProtocol: "http",
-			AppProtectDosMonitorTimeout:  0,
-			AppProtectDosName:            "my-dos-coffee",
-			AppProtectDosAccessLogDst:    "svc.dns.com:123",
-			AppProtectDosPolicyFile:      "",
-			AppProtectDosLogConfFile:     "",
-			AppProtectDosAllowListPath:   "/etc/nginx/dos/allowlist/default_coffee",
-		},
-		"/tea": {
-			AppProtectDosEnable:          "on",
-			AppProtectDosLogEnable:       false,
-			AppProtectDosMonitorURI:      "test.example.com",
-			AppProtectDosMonitorProtocol: "http",
-			AppProtectDosMonitorTimeout:  0,
-			AppProtectDosName:            "my-dos-tea",
-			AppProtectDosAccessLogDst:    "svc.dns.com:123",
-			AppProtectDosPolicyFile:      "",
-			AppProtectDosLogConfFile:     "",
-			AppProtectDosAllowListPath:   "/etc/nginx/dos/allowlist/default_tea",
-		},
-		"/juice": {
-			AppProtectDosEnable:          "on",
-			AppProtectDosLogEnable:       false,
-			AppProtectDosMonitorURI:      "test.example.com",
-			AppProtectDosMonitorProtocol: "http",
-			AppProtectDosMonitorTimeout:  0,
-			AppProtectDosName:            "my-dos-juice",
-			AppProtectDosAccessLogDst:    "svc.dns.com:123",
-			AppProtectDosPolicyFile:      "",
-			AppProtectDosLogConfFile:     "",
-			AppProtectDosAllowListPath:   "/etc/nginx/dos/allowlist/default_juice",
-		},
-	}
-
 	virtualServerEx := VirtualServerEx{
 		VirtualServer: &conf_v1.VirtualServer{
 			ObjectMeta: meta_v1.ObjectMeta{
@@ -4741,18 +4744,41 @@ func TestGenerateVirtualServerConfigForVirtualServerRoutesWithDos(t *testing.T)
 			},
 			Spec: conf_v1.VirtualServerSpec{
 				Host: "cafe.example.com",
-				Routes: []conf_v1.Route{
+				Upstreams: []conf_v1.Upstream{
 					{
-						Path:  "/coffee",
-						Route: "default/coffee",
+						Name:    "tea-v1",
+						Service: "tea-svc-v1",
+						Port:    80,
 					},
 					{
-						Path:  "/tea",
-						Route: "default/tea",
+						Name:    "tea-v2",
+						Service: "tea-svc-v2",
+						Port:    80,
 					},
+				},
+				Routes: []conf_v1.Route{
 					{
-						Path:  "/juice",
-						Route: "default/juice",
+						Path: "/tea",
+						Matches: []conf_v1.Match{
+							{
+								Conditions: []conf_v1.Condition{
+									{
+										Header: "x-version",
+										Value:  "v2",
+									},
+								},
+								Action: &conf_v1.Action{
+									Pass: "tea-v2",
+								},
+							},
+						},
+						Action: &conf_v1.Action{
+							Pass: "tea-v1",
+						},
+					},
+					{
+						Path:  "/coffee",
+						Route: "default/coffee",
 					},
 				},
 			},
@@ -4770,12 +4796,6 @@ func TestGenerateVirtualServerConfigForVirtualServerRoutesWithDos(t *testing.T)
 			"default/coffee-svc-v2:80": {
 				"10.0.0.31:80",
 			},
-			"default/juice-svc-v1:80": {
-				"10.0.0.33:80",
-			},
-			"default/juice-svc-v2:80": {
-				"10.0.0.34:80",
-			},
 		},
 		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
 			{
@@ -4813,7 +4833,6 @@ func TestGenerateVirtualServerConfigForVirtualServerRoutesWithDos(t *testing.T)
 									},
 								},
 							},
-							Dos: "test_ns/dos_protected",
 							Action: &conf_v1.Action{
 								Pass: "coffee-v1",
 							},
@@ -4821,204 +4840,211 @@ func TestGenerateVirtualServerConfigForVirtualServerRoutesWithDos(t *testing.T)
 					},
 				},
 			},
+		},
+	}
+
+	baseCfgParams := ConfigParams{Context: context.Background()}
+
+	expected := version2.VirtualServerConfig{
+		Upstreams: []version2.Upstream{
 			{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "tea",
-					Namespace: "default",
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "tea-svc-v1",
+					ResourceType:      "virtualserver",
+					ResourceName:      "cafe",
+					ResourceNamespace: "default",
 				},
-				Spec: conf_v1.VirtualServerRouteSpec{
-					Host: "cafe.example.com",
-					Upstreams: []conf_v1.Upstream{
-						{
-							Name:    "tea-v1",
-							Service: "tea-svc-v1",
-							Port:    80,
-						},
-						{
-							Name:    "tea-v2",
-							Service: "tea-svc-v2",
-							Port:    80,
-						},
+				Name: "vs_default_cafe_tea-v1",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.20:80",
 					},
-					Subroutes: []conf_v1.Route{
-						{
-							Path: "/tea",
-							Dos:  "test_ns/dos_protected",
-							Action: &conf_v1.Action{
-								Pass: "tea-v1",
-							},
-						},
+				},
+			},
+			{
+				Name: "vs_default_cafe_tea-v2",
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "tea-svc-v2",
+					ResourceType:      "virtualserver",
+					ResourceName:      "cafe",
+					ResourceNamespace: "default",
+				},
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.21:80",
 					},
 				},
 			},
 			{
-				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "juice",
-					Namespace: "default",
+				Name: "vs_default_cafe_vsr_default_coffee_coffee-v1",
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "coffee-svc-v1",
+					ResourceType:      "virtualserverroute",
+					ResourceName:      "coffee",
+					ResourceNamespace: "default",
 				},
-				Spec: conf_v1.VirtualServerRouteSpec{
-					Host: "cafe.example.com",
-					Upstreams: []conf_v1.Upstream{
-						{
-							Name:    "juice-v1",
-							Service: "juice-svc-v1",
-							Port:    80,
-						},
-						{
-							Name:    "juice-v2",
-							Service: "juice-svc-v2",
-							Port:    80,
-						},
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.30:80",
 					},
-					Subroutes: []conf_v1.Route{
-						{
-							Path: "/juice",
-							Dos:  "test_ns/dos_protected",
-							Splits: []conf_v1.Split{
-								{
-									Weight: 80,
-									Action: &conf_v1.Action{
-										Pass: "juice-v1",
-									},
-								},
-								{
-									Weight: 20,
-									Action: &conf_v1.Action{
-										Pass: "juice-v2",
-									},
-								},
-							},
-						},
+				},
+			},
+			{
+				Name: "vs_default_cafe_vsr_default_coffee_coffee-v2",
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "coffee-svc-v2",
+					ResourceType:      "virtualserverroute",
+					ResourceName:      "coffee",
+					ResourceNamespace: "default",
+				},
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.31:80",
 					},
 				},
 			},
 		},
-	}
-
-	baseCfgParams := ConfigParams{Context: context.Background()}
-
-	expected := []version2.Location{
-		{
-			Path:                     "/internal_location_matches_0_match_0",
-			ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v2$request_uri",
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyNextUpstreamTries:   0,
-			Internal:                 true,
-			ProxySSLName:             "coffee-svc-v2.default.svc",
-			ProxyPassRequestHeaders:  true,
-			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-			ServiceName:              "coffee-svc-v2",
-			IsVSR:                    true,
-			VSRName:                  "coffee",
-			VSRNamespace:             "default",
-			Dos: &version2.Dos{
-				Enable:               "on",
-				Name:                 "my-dos-coffee",
-				ApDosMonitorURI:      "test.example.com",
-				ApDosMonitorProtocol: "http",
-				ApDosAccessLogDest:   "svc.dns.com:123",
-				AllowListPath:        "/etc/nginx/dos/allowlist/default_coffee",
+		Maps: []version2.Map{
+			{
+				Source:   "$http_x_version",
+				Variable: "$vs_default_cafe_matches_0_match_0_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v2"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
 			},
-		},
-		{
-			Path:                     "/internal_location_matches_0_default",
-			ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v1$request_uri",
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyNextUpstreamTries:   0,
-			Internal:                 true,
-			ProxySSLName:             "coffee-svc-v1.default.svc",
-			ProxyPassRequestHeaders:  true,
-			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-			ServiceName:              "coffee-svc-v1",
-			IsVSR:                    true,
-			VSRName:                  "coffee",
-			VSRNamespace:             "default",
-			Dos: &version2.Dos{
-				Enable:               "on",
-				Name:                 "my-dos-coffee",
-				ApDosMonitorURI:      "test.example.com",
-				ApDosMonitorProtocol: "http",
-				ApDosAccessLogDest:   "svc.dns.com:123",
-				AllowListPath:        "/etc/nginx/dos/allowlist/default_coffee",
+			{
+				Source:   "$vs_default_cafe_matches_0_match_0_cond_0",
+				Variable: "$vs_default_cafe_matches_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "~^1",
+						Result: "/internal_location_matches_0_match_0",
+					},
+					{
+						Value:  "default",
+						Result: "/internal_location_matches_0_default",
+					},
+				},
 			},
-		},
-		{
-			Path:                     "/tea",
-			ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea-v1",
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyNextUpstreamTries:   0,
-			Internal:                 false,
-			ProxySSLName:             "tea-svc-v1.default.svc",
-			ProxyPassRequestHeaders:  true,
-			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-			ServiceName:              "tea-svc-v1",
-			IsVSR:                    true,
-			VSRName:                  "tea",
-			VSRNamespace:             "default",
-			Dos: &version2.Dos{
-				Enable:               "on",
-				Name:                 "my-dos-tea",
-				ApDosMonitorURI:      "test.example.com",
-				ApDosMonitorProtocol: "http",
-				ApDosAccessLogDest:   "svc.dns.com:123",
-				AllowListPath:        "/etc/nginx/dos/allowlist/default_tea",
+			{
+				Source:   "$arg_version",
+				Variable: "$vs_default_cafe_matches_1_match_0_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v2"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
 			},
-		},
-		{
-			Path:                     "/internal_location_splits_0_split_0",
-			Internal:                 true,
-			ProxyPass:                "http://vs_default_cafe_vsr_default_juice_juice-v1$request_uri",
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyPassRequestHeaders:  true,
-			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-			ProxySSLName:             "juice-svc-v1.default.svc",
-			Dos: &version2.Dos{
-				Enable:               "on",
-				Name:                 "my-dos-juice",
-				ApDosMonitorURI:      "test.example.com",
-				ApDosMonitorProtocol: "http",
-				ApDosAccessLogDest:   "svc.dns.com:123",
-				AllowListPath:        "/etc/nginx/dos/allowlist/default_juice",
+			{
+				Source:   "$vs_default_cafe_matches_1_match_0_cond_0",
+				Variable: "$vs_default_cafe_matches_1",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "~^1",
+						Result: "/internal_location_matches_1_match_0",
+					},
+					{
+						Value:  "default",
+						Result: "/internal_location_matches_1_default",
+					},
+				},
 			},
-			ServiceName:  "juice-svc-v1",
-			IsVSR:        true,
-			VSRName:      "juice",
-			VSRNamespace: "default",
 		},
-		{
-			Path:                     "/internal_location_splits_0_split_1",
-			Internal:                 true,
-			ProxyPass:                "http://vs_default_cafe_vsr_default_juice_juice-v2$request_uri",
-			ProxyNextUpstream:        "error timeout",
-			ProxyNextUpstreamTimeout: "0s",
-			ProxyPassRequestHeaders:  true,
-			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-			ProxySSLName:             "juice-svc-v2.default.svc",
-			Dos: &version2.Dos{
-				Enable:               "on",
-				Name:                 "my-dos-juice",
-				ApDosMonitorURI:      "test.example.com",
-				ApDosMonitorProtocol: "http",
-				ApDosAccessLogDest:   "svc.dns.com:123",
-				AllowListPath:        "/etc/nginx/dos/allowlist/default_juice",
+		HTTPSnippets:  []string{},
+		LimitReqZones: []version2.LimitReqZone{},
+		Server: version2.Server{
+			ServerName:  "cafe.example.com",
+			StatusZone:  "cafe.example.com",
+			VSNamespace: "default",
+			VSName:      "cafe",
+			InternalRedirectLocations: []version2.InternalRedirectLocation{
+				{
+					Path:        "/tea",
+					Destination: "$vs_default_cafe_matches_0",
+				},
+				{
+					Path:        "/coffee",
+					Destination: "$vs_default_cafe_matches_1",
+				},
+			},
+			Locations: []version2.Location{
+				{
+					Path:                     "/internal_location_matches_0_match_0",
+					ProxyPass:                "http://vs_default_cafe_tea-v2$request_uri",
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "0s",
+					ProxyNextUpstreamTries:   0,
+					Internal:                 true,
+					ProxySSLName:             "tea-svc-v2.default.svc",
+					ProxyPassRequestHeaders:  true,
+					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+					ServiceName:              "tea-svc-v2",
+				},
+				{
+					Path:                     "/internal_location_matches_0_default",
+					ProxyPass:                "http://vs_default_cafe_tea-v1$request_uri",
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "0s",
+					ProxyNextUpstreamTries:   0,
+					Internal:                 true,
+					ProxySSLName:             "tea-svc-v1.default.svc",
+					ProxyPassRequestHeaders:  true,
+					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+					ServiceName:              "tea-svc-v1",
+				},
+				{
+					Path:                     "/internal_location_matches_1_match_0",
+					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v2$request_uri",
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "0s",
+					ProxyNextUpstreamTries:   0,
+					Internal:                 true,
+					ProxySSLName:             "coffee-svc-v2.default.svc",
+					ProxyPassRequestHeaders:  true,
+					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+					ServiceName:              "coffee-svc-v2",
+					IsVSR:                    true,
+					VSRName:                  "coffee",
+					VSRNamespace:             "default",
+				},
+				{
+					Path:                     "/internal_location_matches_1_default",
+					ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v1$request_uri",
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "0s",
+					ProxyNextUpstreamTries:   0,
+					Internal:                 true,
+					ProxySSLName:             "coffee-svc-v1.default.svc",
+					ProxyPassRequestHeaders:  true,
+					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+					ServiceName:              "coffee-svc-v1",
+					IsVSR:                    true,
+					VSRName:                  "coffee",
+					VSRNamespace:             "default",
+				},
 			},
-			ServiceName:  "juice-svc-v2",
-			IsVSR:        true,
-			VSRName:      "juice",
-			VSRNamespace: "default",
 		},
 	}
 
 	isPlus := false
 	isResolverConfigured := false
-	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, &StaticConfigParams{MainAppProtectDosLoadModule: true}, false, &fakeBV)
+	isWildcardEnabled := false
+	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, &StaticConfigParams{}, isWildcardEnabled, &fakeBV)
 
-	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, dosResources)
-	if diff := cmp.Diff(expected, result.Server.Locations); diff != "" {
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+	if diff := cmp.Diff(expected, result); diff != "" {
 		t.Errorf("GenerateVirtualServerConfig() mismatch (-want +got):\n%s", diff)
 	}
 
@@ -5027,135 +5053,130 @@ func TestGenerateVirtualServerConfigForVirtualServerRoutesWithDos(t *testing.T)
 	}
 }
 
-func TestGenerateVirtualServerConfigForVirtualServerWithReturns(t *testing.T) {
+func TestGenerateVirtualServerConfigForVirtualServerRoutesWithDos(t *testing.T) {
 	t.Parallel()
+	dosResources := map[string]*appProtectDosResource{
+		"/coffee": {
+			AppProtectDosEnable:          "on",
+			AppProtectDosLogEnable:       false,
+			AppProtectDosMonitorURI:      "test.example.com",
+			AppProtectDosMonitorProtocol: "http",
+			AppProtectDosMonitorTimeout:  0,
+			AppProtectDosName:            "my-dos-coffee",
+			AppProtectDosAccessLogDst:    "svc.dns.com:123",
+			AppProtectDosPolicyFile:      "",
+			AppProtectDosLogConfFile:     "",
+			AppProtectDosAllowListPath:   "/etc/nginx/dos/allowlist/default_coffee",
+		},
+		"/tea": {
+			AppProtectDosEnable:          "on",
+			AppProtectDosLogEnable:       false,
+			AppProtectDosMonitorURI:      "test.example.com",
+			AppProtectDosMonitorProtocol: "http",
+			AppProtectDosMonitorTimeout:  0,
+			AppProtectDosName:            "my-dos-tea",
+			AppProtectDosAccessLogDst:    "svc.dns.com:123",
+			AppProtectDosPolicyFile:      "",
+			AppProtectDosLogConfFile:     "",
+			AppProtectDosAllowListPath:   "/etc/nginx/dos/allowlist/default_tea",
+		},
+		"/juice": {
+			AppProtectDosEnable:          "on",
+			AppProtectDosLogEnable:       false,
+			AppProtectDosMonitorURI:      "test.example.com",
+			AppProtectDosMonitorProtocol: "http",
+			AppProtectDosMonitorTimeout:  0,
+			AppProtectDosName:            "my-dos-juice",
+			AppProtectDosAccessLogDst:    "svc.dns.com:123",
+			AppProtectDosPolicyFile:      "",
+			AppProtectDosLogConfFile:     "",
+			AppProtectDosAllowListPath:   "/etc/nginx/dos/allowlist/default_juice",
+		},
+	}
+
 	virtualServerEx := VirtualServerEx{
 		VirtualServer: &conf_v1.VirtualServer{
 			ObjectMeta: meta_v1.ObjectMeta{
-				Name:      "returns",
+				Name:      "cafe",
 				Namespace: "default",
 			},
 			Spec: conf_v1.VirtualServerSpec{
-				Host: "example.com",
+				Host: "cafe.example.com",
 				Routes: []conf_v1.Route{
 					{
-						Path: "/return",
-						Action: &conf_v1.Action{
-							Return: &conf_v1.ActionReturn{
-								Body: "hello 0",
-							},
-						},
+						Path:  "/coffee",
+						Route: "default/coffee",
 					},
 					{
-						Path: "/splits-with-return",
-						Splits: []conf_v1.Split{
-							{
-								Weight: 90,
-								Action: &conf_v1.Action{
-									Return: &conf_v1.ActionReturn{
-										Body: "hello 1",
-									},
-								},
-							},
-							{
-								Weight: 10,
-								Action: &conf_v1.Action{
-									Return: &conf_v1.ActionReturn{
-										Body: "hello 2",
-									},
-								},
-							},
-						},
-					},
-					{
-						Path: "/matches-with-return",
-						Matches: []conf_v1.Match{
-							{
-								Conditions: []conf_v1.Condition{
-									{
-										Header: "x-version",
-										Value:  "v2",
-									},
-								},
-								Action: &conf_v1.Action{
-									Return: &conf_v1.ActionReturn{
-										Body: "hello 3",
-									},
-								},
-							},
-						},
-						Action: &conf_v1.Action{
-							Return: &conf_v1.ActionReturn{
-								Body: "hello 4",
-							},
-						},
+						Path:  "/tea",
+						Route: "default/tea",
 					},
 					{
-						Path:  "/more",
-						Route: "default/more-returns",
+						Path:  "/juice",
+						Route: "default/juice",
 					},
 				},
 			},
 		},
+		Endpoints: map[string][]string{
+			"default/tea-svc-v1:80": {
+				"10.0.0.20:80",
+			},
+			"default/tea-svc-v2:80": {
+				"10.0.0.21:80",
+			},
+			"default/coffee-svc-v1:80": {
+				"10.0.0.30:80",
+			},
+			"default/coffee-svc-v2:80": {
+				"10.0.0.31:80",
+			},
+			"default/juice-svc-v1:80": {
+				"10.0.0.33:80",
+			},
+			"default/juice-svc-v2:80": {
+				"10.0.0.34:80",
+			},
+		},
 		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
 			{
 				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "more-returns",
+					Name:      "coffee",
 					Namespace: "default",
 				},
 				Spec: conf_v1.VirtualServerRouteSpec{
-					Host: "example.com",
-					Subroutes: []conf_v1.Route{
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
 						{
-							Path: "/more/return",
-							Action: &conf_v1.Action{
-								Return: &conf_v1.ActionReturn{
-									Body: "hello 5",
-								},
-							},
+							Name:    "coffee-v1",
+							Service: "coffee-svc-v1",
+							Port:    80,
 						},
 						{
-							Path: "/more/splits-with-return",
-							Splits: []conf_v1.Split{
-								{
-									Weight: 90,
-									Action: &conf_v1.Action{
-										Return: &conf_v1.ActionReturn{
-											Body: "hello 6",
-										},
-									},
-								},
-								{
-									Weight: 10,
-									Action: &conf_v1.Action{
-										Return: &conf_v1.ActionReturn{
-											Body: "hello 7",
-										},
-									},
-								},
-							},
+							Name:    "coffee-v2",
+							Service: "coffee-svc-v2",
+							Port:    80,
 						},
+					},
+					Subroutes: []conf_v1.Route{
 						{
-							Path: "/more/matches-with-return",
+							Path: "/coffee",
 							Matches: []conf_v1.Match{
 								{
 									Conditions: []conf_v1.Condition{
 										{
-											Header: "x-version",
-											Value:  "v2",
+											Argument: "version",
+											Value:    "v2",
 										},
 									},
 									Action: &conf_v1.Action{
-										Return: &conf_v1.ActionReturn{
-											Body: "hello 8",
-										},
+										Pass: "coffee-v2",
 									},
 								},
 							},
+							Dos: "test_ns/dos_protected",
 							Action: &conf_v1.Action{
-								Return: &conf_v1.ActionReturn{
-									Body: "hello 9",
-								},
+								Pass: "coffee-v1",
 							},
 						},
 					},
@@ -5163,30 +5184,69 @@ func TestGenerateVirtualServerConfigForVirtualServerWithReturns(t *testing.T) {
 			},
 			{
 				ObjectMeta: meta_v1.ObjectMeta{
-					Name:      "header-returns",
+					Name:      "tea",
 					Namespace: "default",
 				},
 				Spec: conf_v1.VirtualServerRouteSpec{
-					Host: "example.com",
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
+						{
+							Name:    "tea-v1",
+							Service: "tea-svc-v1",
+							Port:    80,
+						},
+						{
+							Name:    "tea-v2",
+							Service: "tea-svc-v2",
+							Port:    80,
+						},
+					},
 					Subroutes: []conf_v1.Route{
 						{
-							Path: "/header/return",
+							Path: "/tea",
+							Dos:  "test_ns/dos_protected",
 							Action: &conf_v1.Action{
-								Return: &conf_v1.ActionReturn{
-									Headers: []conf_v1.Header{{Name: "return-header", Value: "value 1"}},
-									Body:    "hello 10",
-								},
+								Pass: "tea-v1",
 							},
 						},
+					},
+				},
+			},
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "juice",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Host: "cafe.example.com",
+					Upstreams: []conf_v1.Upstream{
 						{
-							Path: "/header/return-multiple",
-							Action: &conf_v1.Action{
-								Return: &conf_v1.ActionReturn{
-									Headers: []conf_v1.Header{
-										{Name: "return-header", Value: "value 1"},
-										{Name: "return-header-2", Value: "value 2"},
+							Name:    "juice-v1",
+							Service: "juice-svc-v1",
+							Port:    80,
+						},
+						{
+							Name:    "juice-v2",
+							Service: "juice-svc-v2",
+							Port:    80,
+						},
+					},
+					Subroutes: []conf_v1.Route{
+						{
+							Path: "/juice",
+							Dos:  "test_ns/dos_protected",
+							Splits: []conf_v1.Split{
+								{
+									Weight: 80,
+									Action: &conf_v1.Action{
+										Pass: "juice-v1",
+									},
+								},
+								{
+									Weight: 20,
+									Action: &conf_v1.Action{
+										Pass: "juice-v2",
 									},
-									Body: "hello 11",
 								},
 							},
 						},
@@ -5198,110 +5258,411 @@ func TestGenerateVirtualServerConfigForVirtualServerWithReturns(t *testing.T) {
 
 	baseCfgParams := ConfigParams{Context: context.Background()}
 
-	expected := version2.VirtualServerConfig{
-		Maps: []version2.Map{
-			{
-				Source:   "$http_x_version",
-				Variable: "$vs_default_returns_matches_0_match_0_cond_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"v2"`,
-						Result: "1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
-			},
-			{
-				Source:   "$vs_default_returns_matches_0_match_0_cond_0",
-				Variable: "$vs_default_returns_matches_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "~^1",
-						Result: "/internal_location_matches_0_match_0",
-					},
-					{
-						Value:  "default",
-						Result: "/internal_location_matches_0_default",
-					},
-				},
-			},
-			{
-				Source:   "$http_x_version",
-				Variable: "$vs_default_returns_matches_1_match_0_cond_0",
-				Parameters: []version2.Parameter{
-					{
-						Value:  `"v2"`,
-						Result: "1",
-					},
-					{
-						Value:  "default",
-						Result: "0",
-					},
-				},
-			},
-			{
-				Source:   "$vs_default_returns_matches_1_match_0_cond_0",
-				Variable: "$vs_default_returns_matches_1",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "~^1",
-						Result: "/internal_location_matches_1_match_0",
-					},
-					{
-						Value:  "default",
-						Result: "/internal_location_matches_1_default",
-					},
-				},
+	expected := []version2.Location{
+		{
+			Path:                     "/internal_location_matches_0_match_0",
+			ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v2$request_uri",
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			Internal:                 true,
+			ProxySSLName:             "coffee-svc-v2.default.svc",
+			ProxyPassRequestHeaders:  true,
+			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+			ServiceName:              "coffee-svc-v2",
+			IsVSR:                    true,
+			VSRName:                  "coffee",
+			VSRNamespace:             "default",
+			Dos: &version2.Dos{
+				Enable:               "on",
+				Name:                 "my-dos-coffee",
+				ApDosMonitorURI:      "test.example.com",
+				ApDosMonitorProtocol: "http",
+				ApDosAccessLogDest:   "svc.dns.com:123",
+				AllowListPath:        "/etc/nginx/dos/allowlist/default_coffee",
 			},
 		},
-		SplitClients: []version2.SplitClient{
-			{
-				Source:   "$request_id",
-				Variable: "$vs_default_returns_splits_0",
-				Distributions: []version2.Distribution{
+		{
+			Path:                     "/internal_location_matches_0_default",
+			ProxyPass:                "http://vs_default_cafe_vsr_default_coffee_coffee-v1$request_uri",
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			Internal:                 true,
+			ProxySSLName:             "coffee-svc-v1.default.svc",
+			ProxyPassRequestHeaders:  true,
+			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+			ServiceName:              "coffee-svc-v1",
+			IsVSR:                    true,
+			VSRName:                  "coffee",
+			VSRNamespace:             "default",
+			Dos: &version2.Dos{
+				Enable:               "on",
+				Name:                 "my-dos-coffee",
+				ApDosMonitorURI:      "test.example.com",
+				ApDosMonitorProtocol: "http",
+				ApDosAccessLogDest:   "svc.dns.com:123",
+				AllowListPath:        "/etc/nginx/dos/allowlist/default_coffee",
+			},
+		},
+		{
+			Path:                     "/tea",
+			ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea-v1",
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyNextUpstreamTries:   0,
+			Internal:                 false,
+			ProxySSLName:             "tea-svc-v1.default.svc",
+			ProxyPassRequestHeaders:  true,
+			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+			ServiceName:              "tea-svc-v1",
+			IsVSR:                    true,
+			VSRName:                  "tea",
+			VSRNamespace:             "default",
+			Dos: &version2.Dos{
+				Enable:               "on",
+				Name:                 "my-dos-tea",
+				ApDosMonitorURI:      "test.example.com",
+				ApDosMonitorProtocol: "http",
+				ApDosAccessLogDest:   "svc.dns.com:123",
+				AllowListPath:        "/etc/nginx/dos/allowlist/default_tea",
+			},
+		},
+		{
+			Path:                     "/internal_location_splits_0_split_0",
+			Internal:                 true,
+			ProxyPass:                "http://vs_default_cafe_vsr_default_juice_juice-v1$request_uri",
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyPassRequestHeaders:  true,
+			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+			ProxySSLName:             "juice-svc-v1.default.svc",
+			Dos: &version2.Dos{
+				Enable:               "on",
+				Name:                 "my-dos-juice",
+				ApDosMonitorURI:      "test.example.com",
+				ApDosMonitorProtocol: "http",
+				ApDosAccessLogDest:   "svc.dns.com:123",
+				AllowListPath:        "/etc/nginx/dos/allowlist/default_juice",
+			},
+			ServiceName:  "juice-svc-v1",
+			IsVSR:        true,
+			VSRName:      "juice",
+			VSRNamespace: "default",
+		},
+		{
+			Path:                     "/internal_location_splits_0_split_1",
+			Internal:                 true,
+			ProxyPass:                "http://vs_default_cafe_vsr_default_juice_juice-v2$request_uri",
+			ProxyNextUpstream:        "error timeout",
+			ProxyNextUpstreamTimeout: "0s",
+			ProxyPassRequestHeaders:  true,
+			ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+			ProxySSLName:             "juice-svc-v2.default.svc",
+			Dos: &version2.Dos{
+				Enable:               "on",
+				Name:                 "my-dos-juice",
+				ApDosMonitorURI:      "test.example.com",
+				ApDosMonitorProtocol: "http",
+				ApDosAccessLogDest:   "svc.dns.com:123",
+				AllowListPath:        "/etc/nginx/dos/allowlist/default_juice",
+			},
+			ServiceName:  "juice-svc-v2",
+			IsVSR:        true,
+			VSRName:      "juice",
+			VSRNamespace: "default",
+		},
+	}
+
+	isPlus := false
+	isResolverConfigured := false
+	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, &StaticConfigParams{MainAppProtectDosLoadModule: true}, false, &fakeBV)
+
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, dosResources)
+	if diff := cmp.Diff(expected, result.Server.Locations); diff != "" {
+		t.Errorf("GenerateVirtualServerConfig() mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(warnings) != 0 {
+		t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
+	}
+}
+
+func TestGenerateVirtualServerConfigForVirtualServerWithReturns(t *testing.T) {
+	t.Parallel()
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "returns",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "example.com",
+				Routes: []conf_v1.Route{
 					{
-						Weight: "90%",
-						Value:  "/internal_location_splits_0_split_0",
+						Path: "/return",
+						Action: &conf_v1.Action{
+							Return: &conf_v1.ActionReturn{
+								Body: "hello 0",
+							},
+						},
 					},
 					{
-						Weight: "10%",
-						Value:  "/internal_location_splits_0_split_1",
+						Path: "/splits-with-return",
+						Splits: []conf_v1.Split{
+							{
+								Weight: 90,
+								Action: &conf_v1.Action{
+									Return: &conf_v1.ActionReturn{
+										Body: "hello 1",
+									},
+								},
+							},
+							{
+								Weight: 10,
+								Action: &conf_v1.Action{
+									Return: &conf_v1.ActionReturn{
+										Body: "hello 2",
+									},
+								},
+							},
+						},
 					},
-				},
-			},
-			{
-				Source:   "$request_id",
-				Variable: "$vs_default_returns_splits_1",
-				Distributions: []version2.Distribution{
 					{
-						Weight: "90%",
-						Value:  "/internal_location_splits_1_split_0",
+						Path: "/matches-with-return",
+						Matches: []conf_v1.Match{
+							{
+								Conditions: []conf_v1.Condition{
+									{
+										Header: "x-version",
+										Value:  "v2",
+									},
+								},
+								Action: &conf_v1.Action{
+									Return: &conf_v1.ActionReturn{
+										Body: "hello 3",
+									},
+								},
+							},
+						},
+						Action: &conf_v1.Action{
+							Return: &conf_v1.ActionReturn{
+								Body: "hello 4",
+							},
+						},
 					},
 					{
-						Weight: "10%",
-						Value:  "/internal_location_splits_1_split_1",
+						Path:  "/more",
+						Route: "default/more-returns",
 					},
 				},
 			},
 		},
-		HTTPSnippets:  []string{},
-		LimitReqZones: []version2.LimitReqZone{},
-		Server: version2.Server{
-			ServerName:  "example.com",
-			StatusZone:  "example.com",
-			VSNamespace: "default",
-			VSName:      "returns",
-			InternalRedirectLocations: []version2.InternalRedirectLocation{
-				{
-					Path:        "/splits-with-return",
-					Destination: "$vs_default_returns_splits_0",
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "more-returns",
+					Namespace: "default",
 				},
-				{
-					Path:        "/matches-with-return",
-					Destination: "$vs_default_returns_matches_0",
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Host: "example.com",
+					Subroutes: []conf_v1.Route{
+						{
+							Path: "/more/return",
+							Action: &conf_v1.Action{
+								Return: &conf_v1.ActionReturn{
+									Body: "hello 5",
+								},
+							},
+						},
+						{
+							Path: "/more/splits-with-return",
+							Splits: []conf_v1.Split{
+								{
+									Weight: 90,
+									Action: &conf_v1.Action{
+										Return: &conf_v1.ActionReturn{
+											Body: "hello 6",
+										},
+									},
+								},
+								{
+									Weight: 10,
+									Action: &conf_v1.Action{
+										Return: &conf_v1.ActionReturn{
+											Body: "hello 7",
+										},
+									},
+								},
+							},
+						},
+						{
+							Path: "/more/matches-with-return",
+							Matches: []conf_v1.Match{
+								{
+									Conditions: []conf_v1.Condition{
+										{
+											Header: "x-version",
+											Value:  "v2",
+										},
+									},
+									Action: &conf_v1.Action{
+										Return: &conf_v1.ActionReturn{
+											Body: "hello 8",
+										},
+									},
+								},
+							},
+							Action: &conf_v1.Action{
+								Return: &conf_v1.ActionReturn{
+									Body: "hello 9",
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "header-returns",
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Host: "example.com",
+					Subroutes: []conf_v1.Route{
+						{
+							Path: "/header/return",
+							Action: &conf_v1.Action{
+								Return: &conf_v1.ActionReturn{
+									Headers: []conf_v1.Header{{Name: "return-header", Value: "value 1"}},
+									Body:    "hello 10",
+								},
+							},
+						},
+						{
+							Path: "/header/return-multiple",
+							Action: &conf_v1.Action{
+								Return: &conf_v1.ActionReturn{
+									Headers: []conf_v1.Header{
+										{Name: "return-header", Value: "value 1"},
+										{Name: "return-header-2", Value: "value 2"},
+									},
+									Body: "hello 11",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	baseCfgParams := ConfigParams{Context: context.Background()}
+
+	expected := version2.VirtualServerConfig{
+		Maps: []version2.Map{
+			{
+				Source:   "$http_x_version",
+				Variable: "$vs_default_returns_matches_0_match_0_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v2"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$vs_default_returns_matches_0_match_0_cond_0",
+				Variable: "$vs_default_returns_matches_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "~^1",
+						Result: "/internal_location_matches_0_match_0",
+					},
+					{
+						Value:  "default",
+						Result: "/internal_location_matches_0_default",
+					},
+				},
+			},
+			{
+				Source:   "$http_x_version",
+				Variable: "$vs_default_returns_matches_1_match_0_cond_0",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `"v2"`,
+						Result: "1",
+					},
+					{
+						Value:  "default",
+						Result: "0",
+					},
+				},
+			},
+			{
+				Source:   "$vs_default_returns_matches_1_match_0_cond_0",
+				Variable: "$vs_default_returns_matches_1",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "~^1",
+						Result: "/internal_location_matches_1_match_0",
+					},
+					{
+						Value:  "default",
+						Result: "/internal_location_matches_1_default",
+					},
+				},
+			},
+		},
+		SplitClients: []version2.SplitClient{
+			{
+				Source:   "$request_id",
+				Variable: "$vs_default_returns_splits_0",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "90%",
+						Value:  "/internal_location_splits_0_split_0",
+					},
+					{
+						Weight: "10%",
+						Value:  "/internal_location_splits_0_split_1",
+					},
+				},
+			},
+			{
+				Source:   "$request_id",
+				Variable: "$vs_default_returns_splits_1",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "90%",
+						Value:  "/internal_location_splits_1_split_0",
+					},
+					{
+						Weight: "10%",
+						Value:  "/internal_location_splits_1_split_1",
+					},
+				},
+			},
+		},
+		HTTPSnippets:  []string{},
+		LimitReqZones: []version2.LimitReqZone{},
+		Server: version2.Server{
+			ServerName:  "example.com",
+			StatusZone:  "example.com",
+			VSNamespace: "default",
+			VSName:      "returns",
+			InternalRedirectLocations: []version2.InternalRedirectLocation{
+				{
+					Path:        "/splits-with-return",
+					Destination: "$vs_default_returns_splits_0",
+				},
+				{
+					Path:        "/matches-with-return",
+					Destination: "$vs_default_returns_matches_0",
 				},
 				{
 					Path:        "/more/splits-with-return",
@@ -5749,6 +6110,7 @@ func TestGenerateVirtualServerConfigJWKSPolicy(t *testing.T) {
 				},
 			},
 			JWKSAuthEnabled: true,
+			JWTAuthEnabled:  true,
 			ServerName:      "cafe.example.com",
 			StatusZone:      "cafe.example.com",
 			ProxyProtocol:   true,
@@ -6355,58 +6717,403 @@ func TestGenerateVirtualServerConfigAPIKeyClientMaps(t *testing.T) {
 					"Policy default/api-key-policy-3 is missing or invalid",
 				},
 			},
-			name: "policy does not exist",
+			name: "policy does not exist",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			virtualServerEx.VirtualServer.Spec.Policies = tc.specPolicies
+			virtualServerEx.VirtualServer.Spec.Routes[0].Policies = tc.route1Policies
+			virtualServerEx.VirtualServer.Spec.Routes[1].Policies = tc.route2Policies
+			vsConf, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+
+			sort.Slice(vsConf.Maps, func(i, j int) bool {
+				return vsConf.Maps[i].Variable < vsConf.Maps[j].Variable
+			})
+
+			if !cmp.Equal(tc.expectedSpecAPIKey, vsConf.Server.APIKey) {
+				t.Error(cmp.Diff(tc.expectedSpecAPIKey, vsConf.Server.APIKey))
+			}
+
+			if !cmp.Equal(tc.expectedRoute1APIKey, vsConf.Server.Locations[0].APIKey) {
+				t.Error(cmp.Diff(tc.expectedRoute1APIKey, vsConf.Server.Locations[0].APIKey))
+			}
+
+			if !cmp.Equal(tc.expectedRoute2APIKey, vsConf.Server.Locations[1].APIKey) {
+				t.Error(cmp.Diff(tc.expectedRoute2APIKey, vsConf.Server.Locations[1].APIKey))
+			}
+
+			if !cmp.Equal(tc.expectedMapList, vsConf.Maps) {
+				t.Error(cmp.Diff(tc.expectedMapList, vsConf.Maps))
+			}
+
+			if len(warnings) != 0 {
+				t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
+			}
+		})
+
+		for _, tc := range invalidTests {
+			t.Run(tc.name, func(t *testing.T) {
+				virtualServerEx.VirtualServer.Spec.Policies = tc.specPolicies
+				virtualServerEx.VirtualServer.Spec.Routes[0].Policies = tc.teaPolicies
+				virtualServerEx.VirtualServer.Spec.Routes[1].Policies = tc.coffeePolicies
+				_, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+
+				if len(warnings) == 0 {
+					t.Errorf("GenerateVirtualServerConfig() does not return the expected error %v", tc.expectedWarnings)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		msg             string
+		virtualServerEx VirtualServerEx
+		expected        version2.VirtualServerConfig
+	}{
+		{
+			msg: "rate limits at vs spec level with zone sync enabled",
+			virtualServerEx: VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cafe",
+						Namespace: "default",
+					},
+					Spec: conf_v1.VirtualServerSpec{
+						Host: "cafe.example.com",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "rate-limit-policy",
+							},
+						},
+						Upstreams: []conf_v1.Upstream{
+							{
+								Name:    "tea",
+								Service: "tea-svc",
+								Port:    80,
+							},
+							{
+								Name:    "coffee",
+								Service: "coffee-svc",
+								Port:    80,
+							},
+						},
+						Routes: []conf_v1.Route{
+							{
+								Path: "/tea",
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
+							},
+							{
+								Path: "/coffee",
+								Action: &conf_v1.Action{
+									Pass: "coffee",
+								},
+							},
+						},
+					},
+				},
+				Policies: map[string]*conf_v1.Policy{
+					"default/rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$binary_remote_addr",
+								ZoneSize: "10M",
+								Rate:     "10r/s",
+							},
+						},
+					},
+				},
+				Endpoints: map[string][]string{
+					"default/tea-svc:80": {
+						"10.0.0.20:80",
+					},
+					"default/coffee-svc:80": {
+						"10.0.0.30:80",
+					},
+				},
+				ZoneSync: true,
+			},
+			expected: version2.VirtualServerConfig{
+				Upstreams: []version2.Upstream{
+					{
+						UpstreamLabels: version2.UpstreamLabels{
+							Service:           "coffee-svc",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
+							ResourceNamespace: "default",
+						},
+						Name: "vs_default_cafe_coffee",
+						Servers: []version2.UpstreamServer{
+							{
+								Address: "10.0.0.30:80",
+							},
+						},
+					},
+					{
+						UpstreamLabels: version2.UpstreamLabels{
+							Service:           "tea-svc",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
+							ResourceNamespace: "default",
+						},
+						Name: "vs_default_cafe_tea",
+						Servers: []version2.UpstreamServer{
+							{
+								Address: "10.0.0.20:80",
+							},
+						},
+					},
+				},
+				HTTPSnippets: []string{},
+				LimitReqZones: []version2.LimitReqZone{
+					{
+						Key:      "$binary_remote_addr",
+						ZoneName: "pol_rl_default_rate_limit_policy_default_cafe_sync",
+						ZoneSize: "10M",
+						Rate:     "10r/s",
+						Sync:     true,
+					},
+				},
+				Server: version2.Server{
+					ServerName:   "cafe.example.com",
+					StatusZone:   "cafe.example.com",
+					ServerTokens: "off",
+					VSNamespace:  "default",
+					VSName:       "cafe",
+					LimitReqs: []version2.LimitReq{
+						{ZoneName: "pol_rl_default_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
+					},
+					LimitReqOptions: version2.LimitReqOptions{
+						DryRun:     false,
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
+					Locations: []version2.Location{
+						{
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_tea",
+							ProxyNextUpstream:        "error timeout",
+							ProxyNextUpstreamTimeout: "0s",
+							ProxyNextUpstreamTries:   0,
+							ProxySSLName:             "tea-svc.default.svc",
+							ProxyPassRequestHeaders:  true,
+							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+							ServiceName:              "tea-svc",
+						},
+						{
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
+							ProxyNextUpstream:        "error timeout",
+							ProxyNextUpstreamTimeout: "0s",
+							ProxyNextUpstreamTries:   0,
+							ProxySSLName:             "coffee-svc.default.svc",
+							ProxyPassRequestHeaders:  true,
+							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+							ServiceName:              "coffee-svc",
+						},
+					},
+				},
+			},
+		},
+		{
+			msg: "rate limits at vs spec level without zone sync",
+			virtualServerEx: VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cafe",
+						Namespace: "default",
+					},
+					Spec: conf_v1.VirtualServerSpec{
+						Host: "cafe.example.com",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "rate-limit-policy",
+							},
+						},
+						Upstreams: []conf_v1.Upstream{
+							{
+								Name:    "tea",
+								Service: "tea-svc",
+								Port:    80,
+							},
+							{
+								Name:    "coffee",
+								Service: "coffee-svc",
+								Port:    80,
+							},
+						},
+						Routes: []conf_v1.Route{
+							{
+								Path: "/tea",
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
+							},
+							{
+								Path: "/coffee",
+								Action: &conf_v1.Action{
+									Pass: "coffee",
+								},
+							},
+						},
+					},
+				},
+				Policies: map[string]*conf_v1.Policy{
+					"default/rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$binary_remote_addr",
+								ZoneSize: "10M",
+								Rate:     "10r/s",
+							},
+						},
+					},
+				},
+				Endpoints: map[string][]string{
+					"default/tea-svc:80": {
+						"10.0.0.20:80",
+					},
+					"default/coffee-svc:80": {
+						"10.0.0.30:80",
+					},
+				},
+			},
+			expected: version2.VirtualServerConfig{
+				Upstreams: []version2.Upstream{
+					{
+						UpstreamLabels: version2.UpstreamLabels{
+							Service:           "coffee-svc",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
+							ResourceNamespace: "default",
+						},
+						Name: "vs_default_cafe_coffee",
+						Servers: []version2.UpstreamServer{
+							{
+								Address: "10.0.0.30:80",
+							},
+						},
+					},
+					{
+						UpstreamLabels: version2.UpstreamLabels{
+							Service:           "tea-svc",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
+							ResourceNamespace: "default",
+						},
+						Name: "vs_default_cafe_tea",
+						Servers: []version2.UpstreamServer{
+							{
+								Address: "10.0.0.20:80",
+							},
+						},
+					},
+				},
+				HTTPSnippets: []string{},
+				LimitReqZones: []version2.LimitReqZone{
+					{
+						Key:      "$binary_remote_addr",
+						ZoneName: "pol_rl_default_rate_limit_policy_default_cafe",
+						ZoneSize: "10M",
+						Rate:     "10r/s",
+						Sync:     false,
+					},
+				},
+				Server: version2.Server{
+					ServerName:   "cafe.example.com",
+					StatusZone:   "cafe.example.com",
+					ServerTokens: "off",
+					VSNamespace:  "default",
+					VSName:       "cafe",
+					LimitReqs: []version2.LimitReq{
+						{ZoneName: "pol_rl_default_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+					},
+					LimitReqOptions: version2.LimitReqOptions{
+						DryRun:     false,
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
+					Locations: []version2.Location{
+						{
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_tea",
+							ProxyNextUpstream:        "error timeout",
+							ProxyNextUpstreamTimeout: "0s",
+							ProxyNextUpstreamTries:   0,
+							ProxySSLName:             "tea-svc.default.svc",
+							ProxyPassRequestHeaders:  true,
+							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+							ServiceName:              "tea-svc",
+						},
+						{
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
+							ProxyNextUpstream:        "error timeout",
+							ProxyNextUpstreamTimeout: "0s",
+							ProxyNextUpstreamTries:   0,
+							ProxySSLName:             "coffee-svc.default.svc",
+							ProxyPassRequestHeaders:  true,
+							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+							ServiceName:              "coffee-svc",
+						},
+					},
+				},
+			},
 		},
 	}
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			virtualServerEx.VirtualServer.Spec.Policies = tc.specPolicies
-			virtualServerEx.VirtualServer.Spec.Routes[0].Policies = tc.route1Policies
-			virtualServerEx.VirtualServer.Spec.Routes[1].Policies = tc.route2Policies
-			vsConf, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
-
-			sort.Slice(vsConf.Maps, func(i, j int) bool {
-				return vsConf.Maps[i].Variable < vsConf.Maps[j].Variable
-			})
-
-			if !cmp.Equal(tc.expectedSpecAPIKey, vsConf.Server.APIKey) {
-				t.Error(cmp.Diff(tc.expectedSpecAPIKey, vsConf.Server.APIKey))
-			}
+	baseCfgParams := ConfigParams{
+		Context:      context.Background(),
+		ServerTokens: "off",
+	}
 
-			if !cmp.Equal(tc.expectedRoute1APIKey, vsConf.Server.Locations[0].APIKey) {
-				t.Error(cmp.Diff(tc.expectedRoute1APIKey, vsConf.Server.Locations[0].APIKey))
-			}
+	vsc := newVirtualServerConfigurator(
+		&baseCfgParams,
+		false,
+		false,
+		&StaticConfigParams{},
+		false,
+		&fakeBV,
+	)
 
-			if !cmp.Equal(tc.expectedRoute2APIKey, vsConf.Server.Locations[1].APIKey) {
-				t.Error(cmp.Diff(tc.expectedRoute2APIKey, vsConf.Server.Locations[1].APIKey))
-			}
+	for _, test := range tests {
+		result, warnings := vsc.GenerateVirtualServerConfig(&test.virtualServerEx, nil, nil)
 
-			if !cmp.Equal(tc.expectedMapList, vsConf.Maps) {
-				t.Error(cmp.Diff(tc.expectedMapList, vsConf.Maps))
-			}
+		sort.Slice(result.Maps, func(i, j int) bool {
+			return result.Maps[i].Variable < result.Maps[j].Variable
+		})
 
-			if len(warnings) != 0 {
-				t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
-			}
+		sort.Slice(test.expected.Maps, func(i, j int) bool {
+			return test.expected.Maps[i].Variable < test.expected.Maps[j].Variable
 		})
 
-		for _, tc := range invalidTests {
-			t.Run(tc.name, func(t *testing.T) {
-				virtualServerEx.VirtualServer.Spec.Policies = tc.specPolicies
-				virtualServerEx.VirtualServer.Spec.Routes[0].Policies = tc.teaPolicies
-				virtualServerEx.VirtualServer.Spec.Routes[1].Policies = tc.coffeePolicies
-				_, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("GenerateVirtualServerConfig() mismatch (-want +got):\n%s", diff)
+			t.Error(test.msg)
+		}
 
-				if len(warnings) == 0 {
-					t.Errorf("GenerateVirtualServerConfig() does not return the expected error %v", tc.expectedWarnings)
-				}
-			})
+		if len(warnings) != 0 {
+			t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
 		}
 	}
 }
 
-func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
+func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
@@ -6415,7 +7122,248 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 		expected        version2.VirtualServerConfig
 	}{
 		{
-			msg: "rate limits at vs spec level with zone sync enabled",
+			msg: "jwt claim rate limits at vs spec level, no default with zonesync enabled",
+			virtualServerEx: VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cafe",
+						Namespace: "default",
+					},
+					Spec: conf_v1.VirtualServerSpec{
+						Host: "cafe.example.com",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "premium-rate-limit-policy",
+							},
+							{
+								Name: "basic-rate-limit-policy",
+							},
+						},
+						Upstreams: []conf_v1.Upstream{
+							{
+								Name:    "tea",
+								Service: "tea-svc",
+								Port:    80,
+							},
+							{
+								Name:    "coffee",
+								Service: "coffee-svc",
+								Port:    80,
+							},
+						},
+						Routes: []conf_v1.Route{
+							{
+								Path: "/tea",
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
+							},
+							{
+								Path: "/coffee",
+								Action: &conf_v1.Action{
+									Pass: "coffee",
+								},
+							},
+						},
+					},
+				},
+				Policies: map[string]*conf_v1.Policy{
+					"default/premium-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "premium-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "10M",
+								Rate:     "10r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "premium",
+									},
+								},
+							},
+						},
+					},
+					"default/basic-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "basic-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "20M",
+								Rate:     "20r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "basic",
+									},
+								},
+							},
+						},
+					},
+				},
+				Endpoints: map[string][]string{
+					"default/tea-svc:80": {
+						"10.0.0.20:80",
+					},
+					"default/coffee-svc:80": {
+						"10.0.0.30:80",
+					},
+				},
+				ZoneSync: true,
+			},
+			expected: version2.VirtualServerConfig{
+				Maps: []version2.Map{
+					{
+						Source:   "$jwt_default_cafe_user_type_tier",
+						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "basic",
+								Result: "rl_default_cafe_match_basic",
+							},
+							{
+								Value:  "premium",
+								Result: "rl_default_cafe_match_premium",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_premium",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_basic",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
+				},
+				AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
+				Upstreams: []version2.Upstream{
+					{
+						UpstreamLabels: version2.UpstreamLabels{
+							Service:           "coffee-svc",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
+							ResourceNamespace: "default",
+						},
+						Name: "vs_default_cafe_coffee",
+						Servers: []version2.UpstreamServer{
+							{
+								Address: "10.0.0.30:80",
+							},
+						},
+					},
+					{
+						UpstreamLabels: version2.UpstreamLabels{
+							Service:           "tea-svc",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
+							ResourceNamespace: "default",
+						},
+						Name: "vs_default_cafe_tea",
+						Servers: []version2.UpstreamServer{
+							{
+								Address: "10.0.0.20:80",
+							},
+						},
+					},
+				},
+				HTTPSnippets: []string{},
+				LimitReqZones: []version2.LimitReqZone{
+					{
+						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						ZoneSize:      "10M",
+						Rate:          "10r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_premium",
+						GroupValue:    "premium",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						Sync:          true,
+					},
+					{
+						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						ZoneSize:      "20M",
+						Rate:          "20r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_basic",
+						GroupValue:    "basic",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						Sync:          true,
+					},
+				},
+				Server: version2.Server{
+					ServerName:   "cafe.example.com",
+					StatusZone:   "cafe.example.com",
+					ServerTokens: "off",
+					VSNamespace:  "default",
+					VSName:       "cafe",
+					LimitReqs: []version2.LimitReq{
+						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
+					},
+					LimitReqOptions: version2.LimitReqOptions{
+						DryRun:     false,
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
+					Locations: []version2.Location{
+						{
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_tea",
+							ProxyNextUpstream:        "error timeout",
+							ProxyNextUpstreamTimeout: "0s",
+							ProxyNextUpstreamTries:   0,
+							ProxySSLName:             "tea-svc.default.svc",
+							ProxyPassRequestHeaders:  true,
+							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+							ServiceName:              "tea-svc",
+						},
+						{
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
+							ProxyNextUpstream:        "error timeout",
+							ProxyNextUpstreamTimeout: "0s",
+							ProxyNextUpstreamTries:   0,
+							ProxySSLName:             "coffee-svc.default.svc",
+							ProxyPassRequestHeaders:  true,
+							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+							ServiceName:              "coffee-svc",
+						},
+					},
+				},
+			},
+		},
+		{
+			msg: "jwt claim rate limits at vs spec level, no default",
 			virtualServerEx: VirtualServerEx{
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
@@ -6426,7 +7374,10 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 						Host: "cafe.example.com",
 						Policies: []conf_v1.PolicyReference{
 							{
-								Name: "rate-limit-policy",
+								Name: "premium-rate-limit-policy",
+							},
+							{
+								Name: "basic-rate-limit-policy",
 							},
 						},
 						Upstreams: []conf_v1.Upstream{
@@ -6458,16 +7409,41 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 					},
 				},
 				Policies: map[string]*conf_v1.Policy{
-					"default/rate-limit-policy": {
+					"default/premium-rate-limit-policy": {
 						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "rate-limit-policy",
+							Name:      "premium-rate-limit-policy",
 							Namespace: "default",
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
-								Key:      "$binary_remote_addr",
+								Key:      "$jwt_claim_sub",
 								ZoneSize: "10M",
 								Rate:     "10r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "premium",
+									},
+								},
+							},
+						},
+					},
+					"default/basic-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "basic-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "20M",
+								Rate:     "20r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "basic",
+									},
+								},
 							},
 						},
 					},
@@ -6480,9 +7456,53 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
-				ZoneSync: true,
 			},
 			expected: version2.VirtualServerConfig{
+				Maps: []version2.Map{
+					{
+						Source:   "$jwt_default_cafe_user_type_tier",
+						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "basic",
+								Result: "rl_default_cafe_match_basic",
+							},
+							{
+								Value:  "premium",
+								Result: "rl_default_cafe_match_premium",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_premium",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_basic",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
+				},
+				AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
 				Upstreams: []version2.Upstream{
 					{
 						UpstreamLabels: version2.UpstreamLabels{
@@ -6516,11 +7536,26 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 				HTTPSnippets: []string{},
 				LimitReqZones: []version2.LimitReqZone{
 					{
-						Key:      "$binary_remote_addr",
-						ZoneName: "pol_rl_default_rate_limit_policy_default_cafe_sync",
-						ZoneSize: "10M",
-						Rate:     "10r/s",
-						Sync:     true,
+						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
+						ZoneSize:      "10M",
+						Rate:          "10r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_premium",
+						GroupValue:    "premium",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
+					},
+					{
+						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
+						ZoneSize:      "20M",
+						Rate:          "20r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_basic",
+						GroupValue:    "basic",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
 					},
 				},
 				Server: version2.Server{
@@ -6530,7 +7565,8 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 					VSNamespace:  "default",
 					VSName:       "cafe",
 					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
 					},
 					LimitReqOptions: version2.LimitReqOptions{
 						DryRun:     false,
@@ -6565,7 +7601,7 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 			},
 		},
 		{
-			msg: "rate limits at vs spec level without zone sync",
+			msg: "jwt claim rate limits at vs spec level, with default",
 			virtualServerEx: VirtualServerEx{
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
@@ -6576,7 +7612,10 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 						Host: "cafe.example.com",
 						Policies: []conf_v1.PolicyReference{
 							{
-								Name: "rate-limit-policy",
+								Name: "premium-rate-limit-policy",
+							},
+							{
+								Name: "basic-rate-limit-policy",
 							},
 						},
 						Upstreams: []conf_v1.Upstream{
@@ -6599,39 +7638,114 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 								},
 							},
 							{
-								Path: "/coffee",
-								Action: &conf_v1.Action{
-									Pass: "coffee",
-								},
+								Path: "/coffee",
+								Action: &conf_v1.Action{
+									Pass: "coffee",
+								},
+							},
+						},
+					},
+				},
+				Policies: map[string]*conf_v1.Policy{
+					"default/premium-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "premium-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "10M",
+								Rate:     "10r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "premium",
+									},
+								},
+							},
+						},
+					},
+					"default/basic-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "basic-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "20M",
+								Rate:     "20r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "basic",
+									},
+									Default: true,
+								},
+							},
+						},
+					},
+				},
+				Endpoints: map[string][]string{
+					"default/tea-svc:80": {
+						"10.0.0.20:80",
+					},
+					"default/coffee-svc:80": {
+						"10.0.0.30:80",
+					},
+				},
+			},
+			expected: version2.VirtualServerConfig{
+				Maps: []version2.Map{
+					{
+						Source:   "$jwt_default_cafe_user_type_tier",
+						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "basic",
+								Result: "rl_default_cafe_match_basic",
+							},
+							{
+								Value:  "default",
+								Result: "rl_default_cafe_match_basic",
+							},
+							{
+								Value:  "premium",
+								Result: "rl_default_cafe_match_premium",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_premium",
+								Result: "Val$jwt_claim_sub",
 							},
 						},
 					},
-				},
-				Policies: map[string]*conf_v1.Policy{
-					"default/rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "rate-limit-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$binary_remote_addr",
-								ZoneSize: "10M",
-								Rate:     "10r/s",
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_basic",
+								Result: "Val$jwt_claim_sub",
 							},
 						},
 					},
 				},
-				Endpoints: map[string][]string{
-					"default/tea-svc:80": {
-						"10.0.0.20:80",
-					},
-					"default/coffee-svc:80": {
-						"10.0.0.30:80",
-					},
-				},
-			},
-			expected: version2.VirtualServerConfig{
+				AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
 				Upstreams: []version2.Upstream{
 					{
 						UpstreamLabels: version2.UpstreamLabels{
@@ -6665,11 +7779,27 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 				HTTPSnippets: []string{},
 				LimitReqZones: []version2.LimitReqZone{
 					{
-						Key:      "$binary_remote_addr",
-						ZoneName: "pol_rl_default_rate_limit_policy_default_cafe",
-						ZoneSize: "10M",
-						Rate:     "10r/s",
-						Sync:     false,
+						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
+						ZoneSize:      "10M",
+						Rate:          "10r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_premium",
+						GroupValue:    "premium",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
+					},
+					{
+						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
+						ZoneSize:      "20M",
+						Rate:          "20r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_basic",
+						GroupValue:    "basic",
+						GroupDefault:  true,
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
 					},
 				},
 				Server: version2.Server{
@@ -6679,7 +7809,8 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 					VSNamespace:  "default",
 					VSName:       "cafe",
 					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
 					},
 					LimitReqOptions: version2.LimitReqOptions{
 						DryRun:     false,
@@ -6713,54 +7844,8 @@ func TestGenerateVirtualServerConfigRateLimit(t *testing.T) {
 				},
 			},
 		},
-	}
-
-	baseCfgParams := ConfigParams{
-		Context:      context.Background(),
-		ServerTokens: "off",
-	}
-
-	vsc := newVirtualServerConfigurator(
-		&baseCfgParams,
-		false,
-		false,
-		&StaticConfigParams{},
-		false,
-		&fakeBV,
-	)
-
-	for _, test := range tests {
-		result, warnings := vsc.GenerateVirtualServerConfig(&test.virtualServerEx, nil, nil)
-
-		sort.Slice(result.Maps, func(i, j int) bool {
-			return result.Maps[i].Variable < result.Maps[j].Variable
-		})
-
-		sort.Slice(test.expected.Maps, func(i, j int) bool {
-			return test.expected.Maps[i].Variable < test.expected.Maps[j].Variable
-		})
-
-		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("GenerateVirtualServerConfig() mismatch (-want +got):\n%s", diff)
-			t.Error(test.msg)
-		}
-
-		if len(warnings) != 0 {
-			t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
-		}
-	}
-}
-
-func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		msg             string
-		virtualServerEx VirtualServerEx
-		expected        version2.VirtualServerConfig
-	}{
 		{
-			msg: "jwt claim rate limits at vs spec level, no default with zonesync enabled",
+			msg: "jwt claim rate limits at vs route level, with default",
 			virtualServerEx: VirtualServerEx{
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
@@ -6769,14 +7854,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
-						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "premium-rate-limit-policy",
-							},
-							{
-								Name: "basic-rate-limit-policy",
-							},
-						},
 						Upstreams: []conf_v1.Upstream{
 							{
 								Name:    "tea",
@@ -6795,6 +7872,14 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Action: &conf_v1.Action{
 									Pass: "tea",
 								},
+								Policies: []conf_v1.PolicyReference{
+									{
+										Name: "premium-rate-limit-policy",
+									},
+									{
+										Name: "basic-rate-limit-policy",
+									},
+								},
 							},
 							{
 								Path: "/coffee",
@@ -6840,6 +7925,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 										Claim: "user_type.tier",
 										Match: "basic",
 									},
+									Default: true,
 								},
 							},
 						},
@@ -6853,18 +7939,21 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
-				ZoneSync: true,
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
 					{
 						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "basic",
 								Result: "rl_default_cafe_match_basic",
 							},
+							{
+								Value:  "default",
+								Result: "rl_default_cafe_match_basic",
+							},
 							{
 								Value:  "premium",
 								Result: "rl_default_cafe_match_premium",
@@ -6872,8 +7961,8 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
@@ -6886,8 +7975,8 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
@@ -6934,28 +8023,27 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 				HTTPSnippets: []string{},
 				LimitReqZones: []version2.LimitReqZone{
 					{
-						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
-						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_premium",
 						GroupValue:    "premium",
 						GroupSource:   "$jwt_default_cafe_user_type_tier",
-						Sync:          true,
 					},
 					{
-						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
-						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_basic",
 						GroupValue:    "basic",
+						GroupDefault:  true,
 						GroupSource:   "$jwt_default_cafe_user_type_tier",
-						Sync:          true,
 					},
 				},
 				Server: version2.Server{
@@ -6964,15 +8052,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
-					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
-						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
-					},
-					LimitReqOptions: version2.LimitReqOptions{
-						DryRun:     false,
-						LogLevel:   "error",
-						RejectCode: 503,
-					},
 					Locations: []version2.Location{
 						{
 							Path:                     "/tea",
@@ -6984,6 +8063,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "tea-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
 						},
 						{
 							Path:                     "/coffee",
@@ -7001,7 +8089,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 			},
 		},
 		{
-			msg: "jwt claim rate limits at vs spec level, no default",
+			msg: "jwt claim rate limits at two different vs route levels, with defaults",
 			virtualServerEx: VirtualServerEx{
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
@@ -7010,14 +8098,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
-						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "premium-rate-limit-policy",
-							},
-							{
-								Name: "basic-rate-limit-policy",
-							},
-						},
 						Upstreams: []conf_v1.Upstream{
 							{
 								Name:    "tea",
@@ -7036,12 +8116,28 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Action: &conf_v1.Action{
 									Pass: "tea",
 								},
+								Policies: []conf_v1.PolicyReference{
+									{
+										Name: "premium-rate-limit-policy",
+									},
+									{
+										Name: "basic-rate-limit-policy",
+									},
+								},
 							},
 							{
 								Path: "/coffee",
 								Action: &conf_v1.Action{
 									Pass: "coffee",
 								},
+								Policies: []conf_v1.PolicyReference{
+									{
+										Name: "premium-rate-limit-policy",
+									},
+									{
+										Name: "basic-rate-limit-policy",
+									},
+								},
 							},
 						},
 					},
@@ -7081,30 +8177,81 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 										Claim: "user_type.tier",
 										Match: "basic",
 									},
+									Default: true,
 								},
 							},
 						},
 					},
-				},
-				Endpoints: map[string][]string{
-					"default/tea-svc:80": {
-						"10.0.0.20:80",
-					},
-					"default/coffee-svc:80": {
-						"10.0.0.30:80",
+				},
+				Endpoints: map[string][]string{
+					"default/tea-svc:80": {
+						"10.0.0.20:80",
+					},
+					"default/coffee-svc:80": {
+						"10.0.0.30:80",
+					},
+				},
+			},
+			expected: version2.VirtualServerConfig{
+				Maps: []version2.Map{
+					{
+						Source:   "$jwt_default_cafe_user_type_tier",
+						Variable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "basic",
+								Result: "rl_default_cafe_match_basic",
+							},
+							{
+								Value:  "default",
+								Result: "rl_default_cafe_match_basic",
+							},
+							{
+								Value:  "premium",
+								Result: "rl_default_cafe_match_premium",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_premium",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_basic",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
 					},
-				},
-			},
-			expected: version2.VirtualServerConfig{
-				Maps: []version2.Map{
 					{
 						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$rl_default_cafe_group_user_type_tier_route_L2NvZmZlZQ",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "basic",
 								Result: "rl_default_cafe_match_basic",
 							},
+							{
+								Value:  "default",
+								Result: "rl_default_cafe_match_basic",
+							},
 							{
 								Value:  "premium",
 								Result: "rl_default_cafe_match_premium",
@@ -7112,7 +8259,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Source:   "$rl_default_cafe_group_user_type_tier_route_L2NvZmZlZQ",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -7126,7 +8273,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Source:   "$rl_default_cafe_group_user_type_tier_route_L2NvZmZlZQ",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -7179,7 +8326,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_premium",
 						GroupValue:    "premium",
 						GroupSource:   "$jwt_default_cafe_user_type_tier",
@@ -7190,9 +8337,10 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_basic",
 						GroupValue:    "basic",
+						GroupDefault:  true,
 						GroupSource:   "$jwt_default_cafe_user_type_tier",
 					},
 				},
@@ -7202,15 +8350,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
-					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-					},
-					LimitReqOptions: version2.LimitReqOptions{
-						DryRun:     false,
-						LogLevel:   "error",
-						RejectCode: 503,
-					},
 					Locations: []version2.Location{
 						{
 							Path:                     "/tea",
@@ -7222,6 +8361,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "tea-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
 						},
 						{
 							Path:                     "/coffee",
@@ -7233,13 +8381,22 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "coffee-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			msg: "jwt claim rate limits at vs spec level, with default",
+			msg: "jwt claim rate limits at vsr /tea level, with default",
 			virtualServerEx: VirtualServerEx{
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
@@ -7248,20 +8405,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
-						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "premium-rate-limit-policy",
-							},
-							{
-								Name: "basic-rate-limit-policy",
-							},
-						},
 						Upstreams: []conf_v1.Upstream{
-							{
-								Name:    "tea",
-								Service: "tea-svc",
-								Port:    80,
-							},
 							{
 								Name:    "coffee",
 								Service: "coffee-svc",
@@ -7270,10 +8414,8 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Routes: []conf_v1.Route{
 							{
-								Path: "/tea",
-								Action: &conf_v1.Action{
-									Pass: "tea",
-								},
+								Path:  "/tea",
+								Route: "default/tea",
 							},
 							{
 								Path: "/coffee",
@@ -7333,12 +8475,46 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
+				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+					{
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "tea",
+							Namespace: "default",
+						},
+						Spec: conf_v1.VirtualServerRouteSpec{
+							Host: "cafe.example.com",
+							Upstreams: []conf_v1.Upstream{
+								{
+									Name:    "tea",
+									Service: "tea-svc",
+									Port:    80,
+								},
+							},
+							Subroutes: []conf_v1.Route{
+								{
+									Path: "/tea",
+									Action: &conf_v1.Action{
+										Pass: "tea",
+									},
+									Policies: []conf_v1.PolicyReference{
+										{
+											Name: "premium-rate-limit-policy",
+										},
+										{
+											Name: "basic-rate-limit-policy",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
 					{
 						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Variable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "basic",
@@ -7355,7 +8531,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -7369,7 +8545,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -7402,11 +8578,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					{
 						UpstreamLabels: version2.UpstreamLabels{
 							Service:           "tea-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
+							ResourceType:      "virtualserverroute",
+							ResourceName:      "tea",
 							ResourceNamespace: "default",
 						},
-						Name: "vs_default_cafe_tea",
+						Name: "vs_default_cafe_vsr_default_tea_tea",
 						Servers: []version2.UpstreamServer{
 							{
 								Address: "10.0.0.20:80",
@@ -7422,7 +8598,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_premium",
 						GroupValue:    "premium",
 						GroupSource:   "$jwt_default_cafe_user_type_tier",
@@ -7433,7 +8609,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_basic",
 						GroupValue:    "basic",
 						GroupDefault:  true,
@@ -7446,44 +8622,47 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
-					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-					},
-					LimitReqOptions: version2.LimitReqOptions{
-						DryRun:     false,
-						LogLevel:   "error",
-						RejectCode: 503,
-					},
 					Locations: []version2.Location{
 						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_tea",
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
+							ProxySSLName:             "coffee-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
+							ServiceName:              "coffee-svc",
 						},
 						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
+							ProxySSLName:             "tea-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
+							ServiceName:              "tea-svc",
+							IsVSR:                    true,
+							VSRName:                  "tea",
+							VSRNamespace:             "default",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
 						},
 					},
 				},
 			},
 		},
 		{
-			msg: "jwt claim rate limits at vs route level, with default",
+			msg: "jwt claim rate limits at vsr /tea level & at vs spec level, with default",
 			virtualServerEx: VirtualServerEx{
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
@@ -7493,11 +8672,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
 						Upstreams: []conf_v1.Upstream{
-							{
-								Name:    "tea",
-								Service: "tea-svc",
-								Port:    80,
-							},
 							{
 								Name:    "coffee",
 								Service: "coffee-svc",
@@ -7506,62 +8680,118 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Routes: []conf_v1.Route{
 							{
-								Path: "/tea",
+								Path:  "/tea",
+								Route: "default/tea",
+							},
+							{
+								Path: "/coffee",
 								Action: &conf_v1.Action{
-									Pass: "tea",
+									Pass: "coffee",
 								},
-								Policies: []conf_v1.PolicyReference{
-									{
-										Name: "premium-rate-limit-policy",
+							},
+						},
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "premium-rate-limit-policy",
+							},
+							{
+								Name: "basic-rate-limit-policy",
+							},
+						},
+					},
+				},
+				Policies: map[string]*conf_v1.Policy{
+					"default/premium-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "premium-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "10M",
+								Rate:     "10r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "premium",
 									},
-									{
-										Name: "basic-rate-limit-policy",
+								},
+							},
+						},
+					},
+					"default/basic-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "basic-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "20M",
+								Rate:     "20r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "basic",
 									},
+									Default: true,
 								},
 							},
-							{
-								Path: "/coffee",
-								Action: &conf_v1.Action{
-									Pass: "coffee",
+						},
+					},
+					"default/gold-rate-limit-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "gold-rate-limit-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							RateLimit: &conf_v1.RateLimit{
+								Key:      "$jwt_claim_sub",
+								ZoneSize: "10M",
+								Rate:     "100r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									JWT: &conf_v1.JWTCondition{
+										Claim: "user_type.tier",
+										Match: "gold",
+									},
 								},
 							},
 						},
 					},
-				},
-				Policies: map[string]*conf_v1.Policy{
-					"default/premium-rate-limit-policy": {
+					"default/silver-rate-limit-policy": {
 						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "premium-rate-limit-policy",
+							Name:      "silver-rate-limit-policy",
 							Namespace: "default",
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
 								Key:      "$jwt_claim_sub",
 								ZoneSize: "10M",
-								Rate:     "10r/s",
+								Rate:     "50r/s",
 								Condition: &conf_v1.RateLimitCondition{
 									JWT: &conf_v1.JWTCondition{
 										Claim: "user_type.tier",
-										Match: "premium",
+										Match: "silver",
 									},
 								},
 							},
 						},
 					},
-					"default/basic-rate-limit-policy": {
+					"default/bronze-rate-limit-policy": {
 						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "basic-rate-limit-policy",
+							Name:      "bronze-rate-limit-policy",
 							Namespace: "default",
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
 								Key:      "$jwt_claim_sub",
-								ZoneSize: "20M",
-								Rate:     "20r/s",
+								ZoneSize: "10M",
+								Rate:     "10r/s",
 								Condition: &conf_v1.RateLimitCondition{
 									JWT: &conf_v1.JWTCondition{
 										Claim: "user_type.tier",
-										Match: "basic",
+										Match: "bronze",
 									},
 									Default: true,
 								},
@@ -7577,12 +8807,49 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
+				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+					{
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "tea",
+							Namespace: "default",
+						},
+						Spec: conf_v1.VirtualServerRouteSpec{
+							Host: "cafe.example.com",
+							Upstreams: []conf_v1.Upstream{
+								{
+									Name:    "tea",
+									Service: "tea-svc",
+									Port:    80,
+								},
+							},
+							Subroutes: []conf_v1.Route{
+								{
+									Path: "/tea",
+									Action: &conf_v1.Action{
+										Pass: "tea",
+									},
+									Policies: []conf_v1.PolicyReference{
+										{
+											Name: "gold-rate-limit-policy",
+										},
+										{
+											Name: "silver-rate-limit-policy",
+										},
+										{
+											Name: "bronze-rate-limit-policy",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
 					{
 						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "basic",
@@ -7599,7 +8866,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -7613,7 +8880,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -7626,6 +8893,70 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							},
 						},
 					},
+					{
+						Source:   "$jwt_default_cafe_user_type_tier",
+						Variable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "bronze",
+								Result: "rl_default_cafe_match_bronze",
+							},
+							{
+								Value:  "default",
+								Result: "rl_default_cafe_match_bronze",
+							},
+							{
+								Value:  "silver",
+								Result: "rl_default_cafe_match_silver",
+							},
+							{
+								Value:  "gold",
+								Result: "rl_default_cafe_match_gold",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						Variable: "$pol_rl_default_gold_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_gold",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						Variable: "$pol_rl_default_silver_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_silver",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						Variable: "$pol_rl_default_bronze_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_bronze",
+								Result: "Val$jwt_claim_sub",
+							},
+						},
+					},
 				},
 				AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
 				Upstreams: []version2.Upstream{
@@ -7646,11 +8977,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					{
 						UpstreamLabels: version2.UpstreamLabels{
 							Service:           "tea-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
+							ResourceType:      "virtualserverroute",
+							ResourceName:      "tea",
 							ResourceNamespace: "default",
 						},
-						Name: "vs_default_cafe_tea",
+						Name: "vs_default_cafe_vsr_default_tea_tea",
 						Servers: []version2.UpstreamServer{
 							{
 								Address: "10.0.0.20:80",
@@ -7666,7 +8997,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
 						PolicyValue:   "rl_default_cafe_match_premium",
 						GroupValue:    "premium",
 						GroupSource:   "$jwt_default_cafe_user_type_tier",
@@ -7677,12 +9008,46 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
 						PolicyValue:   "rl_default_cafe_match_basic",
 						GroupValue:    "basic",
 						GroupDefault:  true,
 						GroupSource:   "$jwt_default_cafe_user_type_tier",
 					},
+					{
+						Key:           "$pol_rl_default_gold_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_gold_rate_limit_policy_default_cafe",
+						ZoneSize:      "10M",
+						Rate:          "100r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						PolicyValue:   "rl_default_cafe_match_gold",
+						GroupValue:    "gold",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
+					},
+					{
+						Key:           "$pol_rl_default_silver_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_silver_rate_limit_policy_default_cafe",
+						ZoneSize:      "10M",
+						Rate:          "50r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						PolicyValue:   "rl_default_cafe_match_silver",
+						GroupValue:    "silver",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
+					},
+					{
+						Key:           "$pol_rl_default_bronze_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_bronze_rate_limit_policy_default_cafe",
+						ZoneSize:      "10M",
+						Rate:          "10r/s",
+						PolicyResult:  "$jwt_claim_sub",
+						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						PolicyValue:   "rl_default_cafe_match_bronze",
+						GroupValue:    "bronze",
+						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						GroupDefault:  true,
+					},
 				},
 				Server: version2.Server{
 					ServerName:   "cafe.example.com",
@@ -7690,10 +9055,30 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
+					LimitReqs: []version2.LimitReq{
+						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+					},
+					LimitReqOptions: version2.LimitReqOptions{
+						DryRun:     false,
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
 					Locations: []version2.Location{
+						{
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
+							ProxyNextUpstream:        "error timeout",
+							ProxyNextUpstreamTimeout: "0s",
+							ProxyNextUpstreamTries:   0,
+							ProxySSLName:             "coffee-svc.default.svc",
+							ProxyPassRequestHeaders:  true,
+							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+							ServiceName:              "coffee-svc",
+						},
 						{
 							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_tea",
+							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
@@ -7701,9 +9086,13 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "tea-svc",
+							IsVSR:                    true,
+							VSRName:                  "tea",
+							VSRNamespace:             "default",
 							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_gold_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_silver_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_bronze_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
 							},
 							LimitReqOptions: version2.LimitReqOptions{
 								DryRun:     false,
@@ -7711,24 +9100,24 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								RejectCode: 503,
 							},
 						},
-						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
-							ProxyNextUpstream:        "error timeout",
-							ProxyNextUpstreamTimeout: "0s",
-							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
-							ProxyPassRequestHeaders:  true,
-							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
-						},
 					},
 				},
 			},
 		},
 		{
-			msg: "jwt claim rate limits at two different vs route levels, with defaults",
+			msg: "apikey claim rate limits at vs spec level, no default with zonesync enabled",
 			virtualServerEx: VirtualServerEx{
+				SecretRefs: map[string]*secrets.SecretReference{
+					"default/api-key-secret-spec": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeAPIKey,
+							Data: map[string][]byte{
+								"premium": []byte("premiumpassword"),
+								"basic":   []byte("basicpassword"),
+							},
+						},
+					},
+				},
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
 						Name:      "cafe",
@@ -7736,6 +9125,17 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "premium-rate-limit-policy",
+							},
+							{
+								Name: "basic-rate-limit-policy",
+							},
+							{
+								Name: "api-key-policy",
+							},
+						},
 						Upstreams: []conf_v1.Upstream{
 							{
 								Name:    "tea",
@@ -7746,21 +9146,13 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Name:    "coffee",
 								Service: "coffee-svc",
 								Port:    80,
-							},
-						},
-						Routes: []conf_v1.Route{
-							{
-								Path: "/tea",
-								Action: &conf_v1.Action{
-									Pass: "tea",
-								},
-								Policies: []conf_v1.PolicyReference{
-									{
-										Name: "premium-rate-limit-policy",
-									},
-									{
-										Name: "basic-rate-limit-policy",
-									},
+							},
+						},
+						Routes: []conf_v1.Route{
+							{
+								Path: "/tea",
+								Action: &conf_v1.Action{
+									Pass: "tea",
 								},
 							},
 							{
@@ -7768,14 +9160,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Action: &conf_v1.Action{
 									Pass: "coffee",
 								},
-								Policies: []conf_v1.PolicyReference{
-									{
-										Name: "premium-rate-limit-policy",
-									},
-									{
-										Name: "basic-rate-limit-policy",
-									},
-								},
 							},
 						},
 					},
@@ -7788,13 +9172,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
+								Key:      "$apikey_client_name",
 								ZoneSize: "10M",
 								Rate:     "10r/s",
 								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "premium",
+									Variables: &[]conf_v1.VariableCondition{
+										{
+											Match: "premium",
+											Name:  "$apikey_client_name",
+										},
 									},
 								},
 							},
@@ -7807,15 +9193,31 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
+								Key:      "$apikey_client_name",
 								ZoneSize: "20M",
 								Rate:     "20r/s",
 								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "basic",
+									Variables: &[]conf_v1.VariableCondition{
+										{
+											Match: "basic",
+											Name:  "$apikey_client_name",
+										},
 									},
-									Default: true,
+								},
+							},
+						},
+					},
+					"default/api-key-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "api-key-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							APIKey: &conf_v1.APIKey{
+								ClientSecret: "api-key-secret-spec",
+								SuppliedIn: &conf_v1.SuppliedIn{
+									Header: []string{"X-API-Key"},
+									Query:  []string{"api-key"},
 								},
 							},
 						},
@@ -7829,103 +9231,71 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
+				ZoneSync: true,
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
 					{
-						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
+						Source:   "$apikey_client_name",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
 						Parameters: []version2.Parameter{
 							{
-								Value:  "basic",
-								Result: "rl_default_cafe_match_basic",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic",
+								Value:  `"basic"`,
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
 							{
-								Value:  "premium",
-								Result: "rl_default_cafe_match_premium",
+								Value:  `"premium"`,
+								Result: "rl_default_cafe_match_premium_rate_limit_policy",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
 								Result: "''",
 							},
 							{
-								Value:  "rl_default_cafe_match_premium",
-								Result: "Val$jwt_claim_sub",
+								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
+								Result: "Val$apikey_client_name",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
 								Result: "''",
 							},
 							{
-								Value:  "rl_default_cafe_match_basic",
-								Result: "Val$jwt_claim_sub",
-							},
-						},
-					},
-					{
-						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_route_L2NvZmZlZQ",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "basic",
-								Result: "rl_default_cafe_match_basic",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic",
-							},
-							{
-								Value:  "premium",
-								Result: "rl_default_cafe_match_premium",
+								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
+								Result: "Val$apikey_client_name",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_route_L2NvZmZlZQ",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Source:   "$apikey_auth_token",
+						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_premium",
-								Result: "Val$jwt_claim_sub",
+								Result: `""`,
 							},
-						},
-					},
-					{
-						Source:   "$rl_default_cafe_group_user_type_tier_route_L2NvZmZlZQ",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
 							{
-								Value:  "default",
-								Result: "''",
+								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
+								Result: `"premium"`,
 							},
 							{
-								Value:  "rl_default_cafe_match_basic",
-								Result: "Val$jwt_claim_sub",
+								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
+								Result: `"basic"`,
 							},
 						},
 					},
 				},
-				AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
 				Upstreams: []version2.Upstream{
 					{
 						UpstreamLabels: version2.UpstreamLabels{
@@ -7959,27 +9329,28 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 				HTTPSnippets: []string{},
 				LimitReqZones: []version2.LimitReqZone{
 					{
-						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_premium",
-						GroupValue:    "premium",
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						PolicyResult:  "$apikey_client_name",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
+						GroupValue:    `"premium"`,
+						GroupSource:   "$apikey_client_name",
+						Sync:          true,
 					},
 					{
-						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
+						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_route_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_basic",
-						GroupValue:    "basic",
-						GroupDefault:  true,
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						PolicyResult:  "$apikey_client_name",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
+						GroupValue:    `"basic"`,
+						GroupSource:   "$apikey_client_name",
+						Sync:          true,
 					},
 				},
 				Server: version2.Server{
@@ -7988,6 +9359,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
+					LimitReqs: []version2.LimitReq{
+						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
+					},
+					LimitReqOptions: version2.LimitReqOptions{
+						DryRun:     false,
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
 					Locations: []version2.Location{
 						{
 							Path:                     "/tea",
@@ -7999,15 +9379,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "tea-svc",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
-							},
 						},
 						{
 							Path:                     "/coffee",
@@ -8019,23 +9390,31 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "coffee-svc",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
-							},
 						},
 					},
+					APIKeyEnabled: true,
+					APIKey: &version2.APIKey{
+						Header:  []string{"X-API-Key"},
+						Query:   []string{"api-key"},
+						MapName: "apikey_auth_client_name_default_cafe_api_key_policy",
+					},
 				},
 			},
 		},
 		{
-			msg: "jwt claim rate limits at vsr /tea level, with default",
+			msg: "apikey claim rate limits at vs spec level, no default",
 			virtualServerEx: VirtualServerEx{
+				SecretRefs: map[string]*secrets.SecretReference{
+					"default/api-key-secret-spec": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeAPIKey,
+							Data: map[string][]byte{
+								"premium": []byte("premiumpassword"),
+								"basic":   []byte("basicpassword"),
+							},
+						},
+					},
+				},
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
 						Name:      "cafe",
@@ -8043,7 +9422,23 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "premium-rate-limit-policy",
+							},
+							{
+								Name: "basic-rate-limit-policy",
+							},
+							{
+								Name: "api-key-policy",
+							},
+						},
 						Upstreams: []conf_v1.Upstream{
+							{
+								Name:    "tea",
+								Service: "tea-svc",
+								Port:    80,
+							},
 							{
 								Name:    "coffee",
 								Service: "coffee-svc",
@@ -8052,8 +9447,10 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Routes: []conf_v1.Route{
 							{
-								Path:  "/tea",
-								Route: "default/tea",
+								Path: "/tea",
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
 							},
 							{
 								Path: "/coffee",
@@ -8072,13 +9469,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
+								Key:      "$apikey_client_name",
 								ZoneSize: "10M",
 								Rate:     "10r/s",
 								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "premium",
+									Variables: &[]conf_v1.VariableCondition{
+										{
+											Match: "premium",
+											Name:  "$apikey_client_name",
+										},
 									},
 								},
 							},
@@ -8091,15 +9490,31 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
+								Key:      "$apikey_client_name",
 								ZoneSize: "20M",
 								Rate:     "20r/s",
 								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "basic",
+									Variables: &[]conf_v1.VariableCondition{
+										{
+											Match: "basic",
+											Name:  "$apikey_client_name",
+										},
 									},
-									Default: true,
+								},
+							},
+						},
+					},
+					"default/api-key-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "api-key-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							APIKey: &conf_v1.APIKey{
+								ClientSecret: "api-key-secret-spec",
+								SuppliedIn: &conf_v1.SuppliedIn{
+									Header: []string{"X-API-Key"},
+									Query:  []string{"api-key"},
 								},
 							},
 						},
@@ -8113,63 +9528,25 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
-				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-					{
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "tea",
-							Namespace: "default",
-						},
-						Spec: conf_v1.VirtualServerRouteSpec{
-							Host: "cafe.example.com",
-							Upstreams: []conf_v1.Upstream{
-								{
-									Name:    "tea",
-									Service: "tea-svc",
-									Port:    80,
-								},
-							},
-							Subroutes: []conf_v1.Route{
-								{
-									Path: "/tea",
-									Action: &conf_v1.Action{
-										Pass: "tea",
-									},
-									Policies: []conf_v1.PolicyReference{
-										{
-											Name: "premium-rate-limit-policy",
-										},
-										{
-											Name: "basic-rate-limit-policy",
-										},
-									},
-								},
-							},
-						},
-					},
-				},
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
 					{
-						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						Source:   "$apikey_client_name",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
 						Parameters: []version2.Parameter{
 							{
-								Value:  "basic",
-								Result: "rl_default_cafe_match_basic",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic",
+								Value:  `"basic"`,
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
 							{
-								Value:  "premium",
-								Result: "rl_default_cafe_match_premium",
+								Value:  `"premium"`,
+								Result: "rl_default_cafe_match_premium_rate_limit_policy",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -8177,13 +9554,13 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Result: "''",
 							},
 							{
-								Value:  "rl_default_cafe_match_premium",
-								Result: "Val$jwt_claim_sub",
+								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
+								Result: "Val$apikey_client_name",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -8191,13 +9568,30 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Result: "''",
 							},
 							{
-								Value:  "rl_default_cafe_match_basic",
-								Result: "Val$jwt_claim_sub",
+								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
+								Result: "Val$apikey_client_name",
+							},
+						},
+					},
+					{
+						Source:   "$apikey_auth_token",
+						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+							{
+								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
+								Result: `"premium"`,
+							},
+							{
+								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
+								Result: `"basic"`,
 							},
 						},
 					},
 				},
-				AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
 				Upstreams: []version2.Upstream{
 					{
 						UpstreamLabels: version2.UpstreamLabels{
@@ -8216,11 +9610,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					{
 						UpstreamLabels: version2.UpstreamLabels{
 							Service:           "tea-svc",
-							ResourceType:      "virtualserverroute",
-							ResourceName:      "tea",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
 							ResourceNamespace: "default",
 						},
-						Name: "vs_default_cafe_vsr_default_tea_tea",
+						Name: "vs_default_cafe_tea",
 						Servers: []version2.UpstreamServer{
 							{
 								Address: "10.0.0.20:80",
@@ -8235,23 +9629,22 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_premium",
-						GroupValue:    "premium",
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						PolicyResult:  "$apikey_client_name",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
+						GroupValue:    `"premium"`,
+						GroupSource:   "$apikey_client_name",
 					},
 					{
 						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_basic",
-						GroupValue:    "basic",
-						GroupDefault:  true,
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						PolicyResult:  "$apikey_client_name",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
+						GroupValue:    `"basic"`,
+						GroupSource:   "$apikey_client_name",
 					},
 				},
 				Server: version2.Server{
@@ -8260,48 +9653,62 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
+					LimitReqs: []version2.LimitReq{
+						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+					},
+					LimitReqOptions: version2.LimitReqOptions{
+						DryRun:     false,
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
 					Locations: []version2.Location{
 						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_tea",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
+							ProxySSLName:             "tea-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
+							ServiceName:              "tea-svc",
 						},
 						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
+							ProxySSLName:             "coffee-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
-							IsVSR:                    true,
-							VSRName:                  "tea",
-							VSRNamespace:             "default",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
-							},
+							ServiceName:              "coffee-svc",
 						},
 					},
+					APIKeyEnabled: true,
+					APIKey: &version2.APIKey{
+						Header:  []string{"X-API-Key"},
+						Query:   []string{"api-key"},
+						MapName: "apikey_auth_client_name_default_cafe_api_key_policy",
+					},
 				},
 			},
 		},
 		{
-			msg: "jwt claim rate limits at vsr /tea level & at vs spec level, with default",
+			msg: "apikey claim rate limits at vs spec level, with default",
 			virtualServerEx: VirtualServerEx{
+				SecretRefs: map[string]*secrets.SecretReference{
+					"default/api-key-secret-spec": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeAPIKey,
+							Data: map[string][]byte{
+								"premium": []byte("premiumpassword"),
+								"basic":   []byte("basicpassword"),
+							},
+						},
+					},
+				},
 				VirtualServer: &conf_v1.VirtualServer{
 					ObjectMeta: meta_v1.ObjectMeta{
 						Name:      "cafe",
@@ -8309,7 +9716,23 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
+						Policies: []conf_v1.PolicyReference{
+							{
+								Name: "premium-rate-limit-policy",
+							},
+							{
+								Name: "basic-rate-limit-policy",
+							},
+							{
+								Name: "api-key-policy",
+							},
+						},
 						Upstreams: []conf_v1.Upstream{
+							{
+								Name:    "tea",
+								Service: "tea-svc",
+								Port:    80,
+							},
 							{
 								Name:    "coffee",
 								Service: "coffee-svc",
@@ -8318,8 +9741,10 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Routes: []conf_v1.Route{
 							{
-								Path:  "/tea",
-								Route: "default/tea",
+								Path: "/tea",
+								Action: &conf_v1.Action{
+									Pass: "tea",
+								},
 							},
 							{
 								Path: "/coffee",
@@ -8328,14 +9753,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								},
 							},
 						},
-						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "premium-rate-limit-policy",
-							},
-							{
-								Name: "basic-rate-limit-policy",
-							},
-						},
 					},
 				},
 				Policies: map[string]*conf_v1.Policy{
@@ -8346,13 +9763,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
+								Key:      "$apikey_client_name",
 								ZoneSize: "10M",
 								Rate:     "10r/s",
 								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "premium",
+									Variables: &[]conf_v1.VariableCondition{
+										{
+											Match: "premium",
+											Name:  "$apikey_client_name",
+										},
 									},
 								},
 							},
@@ -8365,73 +9784,32 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Spec: conf_v1.PolicySpec{
 							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
+								Key:      "$apikey_client_name",
 								ZoneSize: "20M",
-								Rate:     "20r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "basic",
-									},
-									Default: true,
-								},
-							},
-						},
-					},
-					"default/gold-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "gold-rate-limit-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
-								ZoneSize: "10M",
-								Rate:     "100r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "gold",
-									},
-								},
-							},
-						},
-					},
-					"default/silver-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "silver-rate-limit-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
-								ZoneSize: "10M",
-								Rate:     "50r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "silver",
+								Rate:     "20r/s",
+								Condition: &conf_v1.RateLimitCondition{
+									Variables: &[]conf_v1.VariableCondition{
+										{
+											Match: "basic",
+											Name:  "$apikey_client_name",
+										},
 									},
+									Default: true,
 								},
 							},
 						},
 					},
-					"default/bronze-rate-limit-policy": {
+					"default/api-key-policy": {
 						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "bronze-rate-limit-policy",
+							Name:      "api-key-policy",
 							Namespace: "default",
 						},
 						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$jwt_claim_sub",
-								ZoneSize: "10M",
-								Rate:     "10r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									JWT: &conf_v1.JWTCondition{
-										Claim: "user_type.tier",
-										Match: "bronze",
-									},
-									Default: true,
+							APIKey: &conf_v1.APIKey{
+								ClientSecret: "api-key-secret-spec",
+								SuppliedIn: &conf_v1.SuppliedIn{
+									Header: []string{"X-API-Key"},
+									Query:  []string{"api-key"},
 								},
 							},
 						},
@@ -8445,66 +9823,29 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
-				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-					{
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "tea",
-							Namespace: "default",
-						},
-						Spec: conf_v1.VirtualServerRouteSpec{
-							Host: "cafe.example.com",
-							Upstreams: []conf_v1.Upstream{
-								{
-									Name:    "tea",
-									Service: "tea-svc",
-									Port:    80,
-								},
-							},
-							Subroutes: []conf_v1.Route{
-								{
-									Path: "/tea",
-									Action: &conf_v1.Action{
-										Pass: "tea",
-									},
-									Policies: []conf_v1.PolicyReference{
-										{
-											Name: "gold-rate-limit-policy",
-										},
-										{
-											Name: "silver-rate-limit-policy",
-										},
-										{
-											Name: "bronze-rate-limit-policy",
-										},
-									},
-								},
-							},
-						},
-					},
-				},
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
 					{
-						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Source:   "$apikey_client_name",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
 						Parameters: []version2.Parameter{
 							{
-								Value:  "basic",
-								Result: "rl_default_cafe_match_basic",
+								Value:  `"basic"`,
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
 							{
 								Value:  "default",
-								Result: "rl_default_cafe_match_basic",
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
 							{
-								Value:  "premium",
-								Result: "rl_default_cafe_match_premium",
+								Value:  `"premium"`,
+								Result: "rl_default_cafe_match_premium_rate_limit_policy",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -8512,13 +9853,13 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Result: "''",
 							},
 							{
-								Value:  "rl_default_cafe_match_premium",
-								Result: "Val$jwt_claim_sub",
+								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
+								Result: "Val$apikey_client_name",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_spec_Lw",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -8526,77 +9867,30 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Result: "''",
 							},
 							{
-								Value:  "rl_default_cafe_match_basic",
-								Result: "Val$jwt_claim_sub",
-							},
-						},
-					},
-					{
-						Source:   "$jwt_default_cafe_user_type_tier",
-						Variable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "bronze",
-								Result: "rl_default_cafe_match_bronze",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_bronze",
-							},
-							{
-								Value:  "silver",
-								Result: "rl_default_cafe_match_silver",
-							},
-							{
-								Value:  "gold",
-								Result: "rl_default_cafe_match_gold",
-							},
-						},
-					},
-					{
-						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						Variable: "$pol_rl_default_gold_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_gold",
-								Result: "Val$jwt_claim_sub",
+								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
+								Result: "Val$apikey_client_name",
 							},
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						Variable: "$pol_rl_default_silver_rate_limit_policy_default_cafe",
+						Source:   "$apikey_auth_token",
+						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_silver",
-								Result: "Val$jwt_claim_sub",
+								Result: `""`,
 							},
-						},
-					},
-					{
-						Source:   "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						Variable: "$pol_rl_default_bronze_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
 							{
-								Value:  "default",
-								Result: "''",
+								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
+								Result: `"basic"`,
 							},
 							{
-								Value:  "rl_default_cafe_match_bronze",
-								Result: "Val$jwt_claim_sub",
+								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
+								Result: `"premium"`,
 							},
 						},
 					},
 				},
-				AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
 				Upstreams: []version2.Upstream{
 					{
 						UpstreamLabels: version2.UpstreamLabels{
@@ -8615,11 +9909,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					{
 						UpstreamLabels: version2.UpstreamLabels{
 							Service:           "tea-svc",
-							ResourceType:      "virtualserverroute",
-							ResourceName:      "tea",
+							ResourceType:      "virtualserver",
+							ResourceName:      "cafe",
 							ResourceNamespace: "default",
 						},
-						Name: "vs_default_cafe_vsr_default_tea_tea",
+						Name: "vs_default_cafe_tea",
 						Servers: []version2.UpstreamServer{
 							{
 								Address: "10.0.0.20:80",
@@ -8634,56 +9928,22 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
-						PolicyValue:   "rl_default_cafe_match_premium",
-						GroupValue:    "premium",
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						PolicyResult:  "$apikey_client_name",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
+						GroupValue:    `"premium"`,
+						GroupSource:   "$apikey_client_name",
 					},
 					{
 						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_spec_Lw",
-						PolicyValue:   "rl_default_cafe_match_basic",
-						GroupValue:    "basic",
-						GroupDefault:  true,
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
-					},
-					{
-						Key:           "$pol_rl_default_gold_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_gold_rate_limit_policy_default_cafe",
-						ZoneSize:      "10M",
-						Rate:          "100r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_gold",
-						GroupValue:    "gold",
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
-					},
-					{
-						Key:           "$pol_rl_default_silver_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_silver_rate_limit_policy_default_cafe",
-						ZoneSize:      "10M",
-						Rate:          "50r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_silver",
-						GroupValue:    "silver",
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
-					},
-					{
-						Key:           "$pol_rl_default_bronze_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_bronze_rate_limit_policy_default_cafe",
-						ZoneSize:      "10M",
-						Rate:          "10r/s",
-						PolicyResult:  "$jwt_claim_sub",
-						GroupVariable: "$rl_default_cafe_group_user_type_tier_subroute_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_bronze",
-						GroupValue:    "bronze",
-						GroupSource:   "$jwt_default_cafe_user_type_tier",
+						PolicyResult:  "$apikey_client_name",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
+						GroupValue:    `"basic"`,
+						GroupSource:   "$apikey_client_name",
 						GroupDefault:  true,
 					},
 				},
@@ -8704,46 +9964,39 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 					Locations: []version2.Location{
 						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_tea",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
+							ProxySSLName:             "tea-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
+							ServiceName:              "tea-svc",
 						},
 						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
+							ProxySSLName:             "coffee-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
-							IsVSR:                    true,
-							VSRName:                  "tea",
-							VSRNamespace:             "default",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_gold_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_silver_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_bronze_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
-							},
+							ServiceName:              "coffee-svc",
 						},
 					},
+					APIKeyEnabled: true,
+					APIKey: &version2.APIKey{
+						Header:  []string{"X-API-Key"},
+						Query:   []string{"api-key"},
+						MapName: "apikey_auth_client_name_default_cafe_api_key_policy",
+					},
 				},
 			},
 		},
 		{
-			msg: "apikey claim rate limits at vs spec level, no default with zonesync enabled",
+			msg: "apikey claim rate limits at vs route level, with default",
 			virtualServerEx: VirtualServerEx{
 				SecretRefs: map[string]*secrets.SecretReference{
 					"default/api-key-secret-spec": {
@@ -8764,12 +10017,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
 						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "premium-rate-limit-policy",
-							},
-							{
-								Name: "basic-rate-limit-policy",
-							},
 							{
 								Name: "api-key-policy",
 							},
@@ -8792,6 +10039,14 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Action: &conf_v1.Action{
 									Pass: "tea",
 								},
+								Policies: []conf_v1.PolicyReference{
+									{
+										Name: "premium-rate-limit-policy",
+									},
+									{
+										Name: "basic-rate-limit-policy",
+									},
+								},
 							},
 							{
 								Path: "/coffee",
@@ -8841,6 +10096,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 											Name:  "$apikey_client_name",
 										},
 									},
+									Default: true,
 								},
 							},
 						},
@@ -8869,18 +10125,21 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
-				ZoneSync: true,
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
 					{
 						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						Parameters: []version2.Parameter{
 							{
 								Value:  `"basic"`,
 								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
+							{
+								Value:  "default",
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
+							},
 							{
 								Value:  `"premium"`,
 								Result: "rl_default_cafe_match_premium_rate_limit_policy",
@@ -8888,8 +10147,8 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
@@ -8902,8 +10161,8 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
 								Value:  "default",
@@ -8967,28 +10226,27 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 				HTTPSnippets: []string{},
 				LimitReqZones: []version2.LimitReqZone{
 					{
-						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
-						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe_sync",
+						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
 						GroupValue:    `"premium"`,
 						GroupSource:   "$apikey_client_name",
-						Sync:          true,
 					},
 					{
-						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
-						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe_sync",
+						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
 						GroupValue:    `"basic"`,
 						GroupSource:   "$apikey_client_name",
-						Sync:          true,
+						GroupDefault:  true,
 					},
 				},
 				Server: version2.Server{
@@ -8997,15 +10255,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
-					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
-						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe_sync", Burst: 0, NoDelay: false, Delay: 0},
-					},
-					LimitReqOptions: version2.LimitReqOptions{
-						DryRun:     false,
-						LogLevel:   "error",
-						RejectCode: 503,
-					},
+
 					Locations: []version2.Location{
 						{
 							Path:                     "/tea",
@@ -9017,6 +10267,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "tea-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
 						},
 						{
 							Path:                     "/coffee",
@@ -9040,7 +10299,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 			},
 		},
 		{
-			msg: "apikey claim rate limits at vs spec level, no default",
+			msg: "apikey claim rate limits at two different vs route levels, with default",
 			virtualServerEx: VirtualServerEx{
 				SecretRefs: map[string]*secrets.SecretReference{
 					"default/api-key-secret-spec": {
@@ -9061,12 +10320,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
 						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "premium-rate-limit-policy",
-							},
-							{
-								Name: "basic-rate-limit-policy",
-							},
 							{
 								Name: "api-key-policy",
 							},
@@ -9089,12 +10342,28 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Action: &conf_v1.Action{
 									Pass: "tea",
 								},
+								Policies: []conf_v1.PolicyReference{
+									{
+										Name: "premium-rate-limit-policy",
+									},
+									{
+										Name: "basic-rate-limit-policy",
+									},
+								},
 							},
 							{
 								Path: "/coffee",
 								Action: &conf_v1.Action{
 									Pass: "coffee",
 								},
+								Policies: []conf_v1.PolicyReference{
+									{
+										Name: "premium-rate-limit-policy",
+									},
+									{
+										Name: "basic-rate-limit-policy",
+									},
+								},
 							},
 						},
 					},
@@ -9138,6 +10407,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 											Name:  "$apikey_client_name",
 										},
 									},
+									Default: true,
 								},
 							},
 						},
@@ -9169,14 +10439,36 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
+					{
+						Source:   "$apikey_auth_token",
+						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+							{
+								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
+								Result: `"premium"`,
+							},
+							{
+								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
+								Result: `"basic"`,
+							},
+						},
+					},
 					{
 						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						Parameters: []version2.Parameter{
 							{
 								Value:  `"basic"`,
 								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
+							{
+								Value:  "default",
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
+							},
 							{
 								Value:  `"premium"`,
 								Result: "rl_default_cafe_match_premium_rate_limit_policy",
@@ -9184,7 +10476,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -9198,7 +10490,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -9212,20 +10504,48 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$apikey_auth_token",
-						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
+						Source:   "$apikey_client_name",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
 						Parameters: []version2.Parameter{
+							{
+								Value:  `"basic"`,
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
+							},
 							{
 								Value:  "default",
-								Result: `""`,
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
 							{
-								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
-								Result: `"premium"`,
+								Value:  `"premium"`,
+								Result: "rl_default_cafe_match_premium_rate_limit_policy",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
 							},
 							{
-								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
-								Result: `"basic"`,
+								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
+								Result: "Val$apikey_client_name",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
+								Result: "Val$apikey_client_name",
 							},
 						},
 					},
@@ -9268,7 +10588,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
 						GroupValue:    `"premium"`,
 						GroupSource:   "$apikey_client_name",
@@ -9279,10 +10599,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
 						GroupValue:    `"basic"`,
 						GroupSource:   "$apikey_client_name",
+						GroupDefault:  true,
 					},
 				},
 				Server: version2.Server{
@@ -9291,15 +10612,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
-					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-					},
-					LimitReqOptions: version2.LimitReqOptions{
-						DryRun:     false,
-						LogLevel:   "error",
-						RejectCode: 503,
-					},
+
 					Locations: []version2.Location{
 						{
 							Path:                     "/tea",
@@ -9311,6 +10624,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "tea-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
 						},
 						{
 							Path:                     "/coffee",
@@ -9322,6 +10644,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
 							ServiceName:              "coffee-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
 						},
 					},
 					APIKeyEnabled: true,
@@ -9334,7 +10665,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 			},
 		},
 		{
-			msg: "apikey claim rate limits at vs spec level, with default",
+			msg: "apikey rate limits at vsr /tea level, with default",
 			virtualServerEx: VirtualServerEx{
 				SecretRefs: map[string]*secrets.SecretReference{
 					"default/api-key-secret-spec": {
@@ -9355,22 +10686,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					Spec: conf_v1.VirtualServerSpec{
 						Host: "cafe.example.com",
 						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "premium-rate-limit-policy",
-							},
-							{
-								Name: "basic-rate-limit-policy",
-							},
 							{
 								Name: "api-key-policy",
 							},
 						},
 						Upstreams: []conf_v1.Upstream{
-							{
-								Name:    "tea",
-								Service: "tea-svc",
-								Port:    80,
-							},
 							{
 								Name:    "coffee",
 								Service: "coffee-svc",
@@ -9379,10 +10699,8 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Routes: []conf_v1.Route{
 							{
-								Path: "/tea",
-								Action: &conf_v1.Action{
-									Pass: "tea",
-								},
+								Path:  "/tea",
+								Route: "default/tea",
 							},
 							{
 								Path: "/coffee",
@@ -9428,45 +10746,97 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 								Condition: &conf_v1.RateLimitCondition{
 									Variables: &[]conf_v1.VariableCondition{
 										{
-											Match: "basic",
-											Name:  "$apikey_client_name",
+											Match: "basic",
+											Name:  "$apikey_client_name",
+										},
+									},
+									Default: true,
+								},
+							},
+						},
+					},
+					"default/api-key-policy": {
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "api-key-policy",
+							Namespace: "default",
+						},
+						Spec: conf_v1.PolicySpec{
+							APIKey: &conf_v1.APIKey{
+								ClientSecret: "api-key-secret-spec",
+								SuppliedIn: &conf_v1.SuppliedIn{
+									Header: []string{"X-API-Key"},
+									Query:  []string{"api-key"},
+								},
+							},
+						},
+					},
+				},
+				Endpoints: map[string][]string{
+					"default/tea-svc:80": {
+						"10.0.0.20:80",
+					},
+					"default/coffee-svc:80": {
+						"10.0.0.30:80",
+					},
+				},
+				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+					{
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "tea",
+							Namespace: "default",
+						},
+						Spec: conf_v1.VirtualServerRouteSpec{
+							Host: "cafe.example.com",
+							Upstreams: []conf_v1.Upstream{
+								{
+									Name:    "tea",
+									Service: "tea-svc",
+									Port:    80,
+								},
+							},
+							Subroutes: []conf_v1.Route{
+								{
+									Path: "/tea",
+									Action: &conf_v1.Action{
+										Pass: "tea",
+									},
+									Policies: []conf_v1.PolicyReference{
+										{
+											Name: "premium-rate-limit-policy",
+										},
+										{
+											Name: "basic-rate-limit-policy",
 										},
 									},
-									Default: true,
-								},
-							},
-						},
-					},
-					"default/api-key-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "api-key-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							APIKey: &conf_v1.APIKey{
-								ClientSecret: "api-key-secret-spec",
-								SuppliedIn: &conf_v1.SuppliedIn{
-									Header: []string{"X-API-Key"},
-									Query:  []string{"api-key"},
 								},
 							},
 						},
 					},
 				},
-				Endpoints: map[string][]string{
-					"default/tea-svc:80": {
-						"10.0.0.20:80",
-					},
-					"default/coffee-svc:80": {
-						"10.0.0.30:80",
-					},
-				},
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
+					{
+						Source:   "$apikey_auth_token",
+						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+							{
+								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
+								Result: `"premium"`,
+							},
+							{
+								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
+								Result: `"basic"`,
+							},
+						},
+					},
 					{
 						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
 						Parameters: []version2.Parameter{
 							{
 								Value:  `"basic"`,
@@ -9483,7 +10853,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -9497,7 +10867,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -9510,24 +10880,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							},
 						},
 					},
-					{
-						Source:   "$apikey_auth_token",
-						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: `""`,
-							},
-							{
-								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
-								Result: `"basic"`,
-							},
-							{
-								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
-								Result: `"premium"`,
-							},
-						},
-					},
 				},
 				Upstreams: []version2.Upstream{
 					{
@@ -9547,11 +10899,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					{
 						UpstreamLabels: version2.UpstreamLabels{
 							Service:           "tea-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
+							ResourceType:      "virtualserverroute",
+							ResourceName:      "tea",
 							ResourceNamespace: "default",
 						},
-						Name: "vs_default_cafe_tea",
+						Name: "vs_default_cafe_vsr_default_tea_tea",
 						Servers: []version2.UpstreamServer{
 							{
 								Address: "10.0.0.20:80",
@@ -9567,7 +10919,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
 						GroupValue:    `"premium"`,
 						GroupSource:   "$apikey_client_name",
@@ -9578,7 +10930,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_spec_Lw",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
 						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
 						GroupValue:    `"basic"`,
 						GroupSource:   "$apikey_client_name",
@@ -9591,37 +10943,41 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					ServerTokens: "off",
 					VSNamespace:  "default",
 					VSName:       "cafe",
-					LimitReqs: []version2.LimitReq{
-						{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-						{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-					},
-					LimitReqOptions: version2.LimitReqOptions{
-						DryRun:     false,
-						LogLevel:   "error",
-						RejectCode: 503,
-					},
+
 					Locations: []version2.Location{
 						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_tea",
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
+							ProxySSLName:             "coffee-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
+							ServiceName:              "coffee-svc",
 						},
 						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
+							ProxySSLName:             "tea-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
+							ServiceName:              "tea-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
+							IsVSR:        true,
+							VSRName:      "tea",
+							VSRNamespace: "default",
 						},
 					},
 					APIKeyEnabled: true,
@@ -9634,7 +10990,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 			},
 		},
 		{
-			msg: "apikey claim rate limits at vs route level, with default",
+			msg: "apikey rate limits at vsr /tea level & at vs spec level, with default",
 			virtualServerEx: VirtualServerEx{
 				SecretRefs: map[string]*secrets.SecretReference{
 					"default/api-key-secret-spec": {
@@ -9660,11 +11016,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							},
 						},
 						Upstreams: []conf_v1.Upstream{
-							{
-								Name:    "tea",
-								Service: "tea-svc",
-								Port:    80,
-							},
 							{
 								Name:    "coffee",
 								Service: "coffee-svc",
@@ -9673,9 +11024,13 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 						Routes: []conf_v1.Route{
 							{
-								Path: "/tea",
+								Path:  "/tea",
+								Route: "default/tea",
+							},
+							{
+								Path: "/coffee",
 								Action: &conf_v1.Action{
-									Pass: "tea",
+									Pass: "coffee",
 								},
 								Policies: []conf_v1.PolicyReference{
 									{
@@ -9686,12 +11041,6 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 									},
 								},
 							},
-							{
-								Path: "/coffee",
-								Action: &conf_v1.Action{
-									Pass: "coffee",
-								},
-							},
 						},
 					},
 				},
@@ -9763,12 +11112,64 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						"10.0.0.30:80",
 					},
 				},
+				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+					{
+						ObjectMeta: meta_v1.ObjectMeta{
+							Name:      "tea",
+							Namespace: "default",
+						},
+						Spec: conf_v1.VirtualServerRouteSpec{
+							Host: "cafe.example.com",
+							Upstreams: []conf_v1.Upstream{
+								{
+									Name:    "tea",
+									Service: "tea-svc",
+									Port:    80,
+								},
+							},
+							Subroutes: []conf_v1.Route{
+								{
+									Path: "/tea",
+									Action: &conf_v1.Action{
+										Pass: "tea",
+									},
+									Policies: []conf_v1.PolicyReference{
+										{
+											Name: "premium-rate-limit-policy",
+										},
+										{
+											Name: "basic-rate-limit-policy",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 			expected: version2.VirtualServerConfig{
 				Maps: []version2.Map{
+					{
+						Source:   "$apikey_auth_token",
+						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+							{
+								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
+								Result: `"premium"`,
+							},
+							{
+								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
+								Result: `"basic"`,
+							},
+						},
+					},
 					{
 						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
 						Parameters: []version2.Parameter{
 							{
 								Value:  `"basic"`,
@@ -9785,7 +11186,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
 						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -9799,7 +11200,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
+						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
 						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
 						Parameters: []version2.Parameter{
 							{
@@ -9813,20 +11214,48 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						},
 					},
 					{
-						Source:   "$apikey_auth_token",
-						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
+						Source:   "$apikey_client_name",
+						Variable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
 						Parameters: []version2.Parameter{
+							{
+								Value:  `"basic"`,
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
+							},
 							{
 								Value:  "default",
-								Result: `""`,
+								Result: "rl_default_cafe_match_basic_rate_limit_policy",
 							},
 							{
-								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
-								Result: `"premium"`,
+								Value:  `"premium"`,
+								Result: "rl_default_cafe_match_premium_rate_limit_policy",
 							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
+						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
 							{
-								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
-								Result: `"basic"`,
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
+								Result: "Val$apikey_client_name",
+							},
+						},
+					},
+					{
+						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
+						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: "''",
+							},
+							{
+								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
+								Result: "Val$apikey_client_name",
 							},
 						},
 					},
@@ -9849,11 +11278,11 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					{
 						UpstreamLabels: version2.UpstreamLabels{
 							Service:           "tea-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
+							ResourceType:      "virtualserverroute",
+							ResourceName:      "tea",
 							ResourceNamespace: "default",
 						},
-						Name: "vs_default_cafe_tea",
+						Name: "vs_default_cafe_vsr_default_tea_tea",
 						Servers: []version2.UpstreamServer{
 							{
 								Address: "10.0.0.20:80",
@@ -9869,7 +11298,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "10M",
 						Rate:          "10r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
 						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
 						GroupValue:    `"premium"`,
 						GroupSource:   "$apikey_client_name",
@@ -9880,7 +11309,7 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 						ZoneSize:      "20M",
 						Rate:          "20r/s",
 						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
+						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
 						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
 						GroupValue:    `"basic"`,
 						GroupSource:   "$apikey_client_name",
@@ -9896,15 +11325,15 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 
 					Locations: []version2.Location{
 						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_tea",
+							Path:                     "/coffee",
+							ProxyPass:                "http://vs_default_cafe_coffee",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
+							ProxySSLName:             "coffee-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
+							ServiceName:              "coffee-svc",
 							LimitReqs: []version2.LimitReq{
 								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
 								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
@@ -9916,15 +11345,27 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 							},
 						},
 						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
+							Path:                     "/tea",
+							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
 							ProxyNextUpstream:        "error timeout",
 							ProxyNextUpstreamTimeout: "0s",
 							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
+							ProxySSLName:             "tea-svc.default.svc",
 							ProxyPassRequestHeaders:  true,
 							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
+							ServiceName:              "tea-svc",
+							LimitReqs: []version2.LimitReq{
+								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+							},
+							LimitReqOptions: version2.LimitReqOptions{
+								DryRun:     false,
+								LogLevel:   "error",
+								RejectCode: 503,
+							},
+							IsVSR:        true,
+							VSRName:      "tea",
+							VSRNamespace: "default",
 						},
 					},
 					APIKeyEnabled: true,
@@ -9936,515 +11377,720 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 				},
 			},
 		},
-		{
-			msg: "apikey claim rate limits at two different vs route levels, with default",
-			virtualServerEx: VirtualServerEx{
-				SecretRefs: map[string]*secrets.SecretReference{
-					"default/api-key-secret-spec": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeAPIKey,
-							Data: map[string][]byte{
-								"premium": []byte("premiumpassword"),
-								"basic":   []byte("basicpassword"),
-							},
-						},
+	}
+
+	baseCfgParams := ConfigParams{
+		Context:      context.Background(),
+		ServerTokens: "off",
+	}
+
+	vsc := newVirtualServerConfigurator(
+		&baseCfgParams,
+		false,
+		false,
+		&StaticConfigParams{},
+		false,
+		&fakeBV,
+	)
+
+	for _, test := range tests {
+		result, warnings := vsc.GenerateVirtualServerConfig(&test.virtualServerEx, nil, nil)
+
+		for i, m := range result.Maps {
+			sort.Slice(m.Parameters, func(i, j int) bool {
+				return m.Parameters[i].Value < m.Parameters[j].Value
+			})
+			result.Maps[i] = m
+		}
+
+		sort.Slice(result.Maps, func(i, j int) bool {
+			return result.Maps[i].Variable < result.Maps[j].Variable && result.Maps[i].Source < result.Maps[j].Source
+		})
+
+		for i, m := range test.expected.Maps {
+			sort.Slice(m.Parameters, func(i, j int) bool {
+				return m.Parameters[i].Value < m.Parameters[j].Value
+			})
+			test.expected.Maps[i] = m
+		}
+
+		sort.Slice(test.expected.Maps, func(i, j int) bool {
+			return test.expected.Maps[i].Variable < test.expected.Maps[j].Variable && test.expected.Maps[i].Source < test.expected.Maps[j].Source
+		})
+
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("GenerateVirtualServerConfig() mismatch (-want +got):\n%s", diff)
+			t.Error(test.msg)
+		}
+
+		if len(warnings) != 0 {
+			t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
+		}
+	}
+}
+
+func TestGenerateVirtualServerConfigWithRateLimitGroupsWarning(t *testing.T) {
+	t.Parallel()
+
+	virtualServerEx := VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "cafe",
+				Namespace: "default",
+			},
+			Spec: conf_v1.VirtualServerSpec{
+				Host: "cafe.example.com",
+				Policies: []conf_v1.PolicyReference{
+					{
+						Name: "premium-rate-limit-policy",
+					},
+					{
+						Name: "basic-rate-limit-policy",
 					},
 				},
-				VirtualServer: &conf_v1.VirtualServer{
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "cafe",
-						Namespace: "default",
+				Upstreams: []conf_v1.Upstream{
+					{
+						Name:    "tea",
+						Service: "tea-svc",
+						Port:    80,
 					},
-					Spec: conf_v1.VirtualServerSpec{
-						Host: "cafe.example.com",
-						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "api-key-policy",
-							},
-						},
-						Upstreams: []conf_v1.Upstream{
-							{
-								Name:    "tea",
-								Service: "tea-svc",
-								Port:    80,
-							},
-							{
-								Name:    "coffee",
-								Service: "coffee-svc",
-								Port:    80,
-							},
-						},
-						Routes: []conf_v1.Route{
-							{
-								Path: "/tea",
-								Action: &conf_v1.Action{
-									Pass: "tea",
-								},
-								Policies: []conf_v1.PolicyReference{
-									{
-										Name: "premium-rate-limit-policy",
-									},
-									{
-										Name: "basic-rate-limit-policy",
-									},
-								},
-							},
-							{
-								Path: "/coffee",
-								Action: &conf_v1.Action{
-									Pass: "coffee",
-								},
-								Policies: []conf_v1.PolicyReference{
-									{
-										Name: "premium-rate-limit-policy",
-									},
-									{
-										Name: "basic-rate-limit-policy",
-									},
-								},
-							},
-						},
+					{
+						Name:    "coffee",
+						Service: "coffee-svc",
+						Port:    80,
 					},
 				},
-				Policies: map[string]*conf_v1.Policy{
-					"default/premium-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "premium-rate-limit-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$apikey_client_name",
-								ZoneSize: "10M",
-								Rate:     "10r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									Variables: &[]conf_v1.VariableCondition{
-										{
-											Match: "premium",
-											Name:  "$apikey_client_name",
-										},
-									},
-								},
-							},
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Pass: "tea",
 						},
 					},
-					"default/basic-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "basic-rate-limit-policy",
-							Namespace: "default",
+					{
+						Path: "/coffee",
+						Action: &conf_v1.Action{
+							Pass: "coffee",
 						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$apikey_client_name",
-								ZoneSize: "20M",
-								Rate:     "20r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									Variables: &[]conf_v1.VariableCondition{
-										{
-											Match: "basic",
-											Name:  "$apikey_client_name",
-										},
-									},
-									Default: true,
-								},
+					},
+				},
+			},
+		},
+		Policies: map[string]*conf_v1.Policy{
+			"default/premium-rate-limit-policy": {
+				Spec: conf_v1.PolicySpec{
+					RateLimit: &conf_v1.RateLimit{
+						Key:      "$jwt_claim_sub",
+						ZoneSize: "10M",
+						Rate:     "10r/s",
+						Condition: &conf_v1.RateLimitCondition{
+							JWT: &conf_v1.JWTCondition{
+								Claim: "user_type.tier",
+								Match: "premium",
 							},
+							Default: true,
 						},
 					},
-					"default/api-key-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "api-key-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							APIKey: &conf_v1.APIKey{
-								ClientSecret: "api-key-secret-spec",
-								SuppliedIn: &conf_v1.SuppliedIn{
-									Header: []string{"X-API-Key"},
-									Query:  []string{"api-key"},
-								},
+				},
+			},
+			"default/basic-rate-limit-policy": {
+				Spec: conf_v1.PolicySpec{
+					RateLimit: &conf_v1.RateLimit{
+						Key:      "$jwt_claim_sub",
+						ZoneSize: "20M",
+						Rate:     "20r/s",
+						Condition: &conf_v1.RateLimitCondition{
+							JWT: &conf_v1.JWTCondition{
+								Claim: "user_type.tier",
+								Match: "basic",
 							},
+							Default: true,
 						},
 					},
 				},
-				Endpoints: map[string][]string{
-					"default/tea-svc:80": {
-						"10.0.0.20:80",
+			},
+		},
+		Endpoints: map[string][]string{
+			"default/tea-svc:80": {
+				"10.0.0.20:80",
+			},
+			"default/coffee-svc:80": {
+				"10.0.0.30:80",
+			},
+		},
+	}
+	expected := version2.VirtualServerConfig{
+		Maps: []version2.Map{
+			{
+				Source:   "$rl_default_cafe_group_user_type_tier",
+				Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+				Parameters: []version2.Parameter{
+					{
+						Value:  "default",
+						Result: "''",
 					},
-					"default/coffee-svc:80": {
-						"10.0.0.30:80",
+					{
+						Value:  "rl_default_cafe_match_basic",
+						Result: "Val$jwt_claim_sub",
 					},
 				},
 			},
-			expected: version2.VirtualServerConfig{
-				Maps: []version2.Map{
+			{
+				Source:   "$rl_default_cafe_group_user_type_tier",
+				Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+				Parameters: []version2.Parameter{
 					{
-						Source:   "$apikey_auth_token",
-						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: `""`,
-							},
-							{
-								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
-								Result: `"premium"`,
-							},
-							{
-								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
-								Result: `"basic"`,
-							},
-						},
+						Value:  "default",
+						Result: "''",
 					},
 					{
-						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
-						Parameters: []version2.Parameter{
-							{
-								Value:  `"basic"`,
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  `"premium"`,
-								Result: "rl_default_cafe_match_premium_rate_limit_policy",
-							},
-						},
+						Value:  "rl_default_cafe_match_premium",
+						Result: "Val$jwt_claim_sub",
 					},
+				},
+			},
+			{
+				Source:   "$jwt_default_cafe_user_type_tier",
+				Variable: "$rl_default_cafe_group_user_type_tier",
+				Parameters: []version2.Parameter{
 					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
-						},
+						Value:  "basic",
+						Result: "rl_default_cafe_match_basic",
+					},
+					{
+						Value:  "premium",
+						Result: "rl_default_cafe_match_premium",
+					},
+				},
+			},
+		},
+		AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
+		Upstreams: []version2.Upstream{
+			{
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "coffee-svc",
+					ResourceType:      "virtualserver",
+					ResourceName:      "cafe",
+					ResourceNamespace: "default",
+				},
+				Name: "vs_default_cafe_coffee",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.30:80",
+					},
+				},
+			},
+			{
+				UpstreamLabels: version2.UpstreamLabels{
+					Service:           "tea-svc",
+					ResourceType:      "virtualserver",
+					ResourceName:      "cafe",
+					ResourceNamespace: "default",
+				},
+				Name: "vs_default_cafe_tea",
+				Servers: []version2.UpstreamServer{
+					{
+						Address: "10.0.0.20:80",
+					},
+				},
+			},
+		},
+		HTTPSnippets: []string{},
+		LimitReqZones: []version2.LimitReqZone{
+			{
+				Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
+				ZoneName:      "pol_rl_default_premium-rate-limit-policy_default_cafe",
+				ZoneSize:      "10M",
+				Rate:          "10r/s",
+				PolicyResult:  "$jwt_claim_sub",
+				GroupVariable: "$rl_default_cafe_group_user_type_tier",
+				PolicyValue:   "rl_default_cafe_match_premium",
+				GroupValue:    "premium",
+				GroupSource:   "$jwt_default_cafe_user_type_tier",
+			},
+			{
+				Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
+				ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
+				ZoneSize:      "20M",
+				Rate:          "20r/s",
+				PolicyResult:  "$jwt_claim_sub",
+				GroupVariable: "$rl_default_cafe_group_user_type_tier",
+				PolicyValue:   "rl_default_cafe_match_basic",
+				GroupValue:    "basic",
+				GroupSource:   "$jwt_default_cafe_user_type_tier",
+			},
+		},
+		Server: version2.Server{
+			ServerName:   "cafe.example.com",
+			StatusZone:   "cafe.example.com",
+			ServerTokens: "off",
+			VSNamespace:  "default",
+			VSName:       "cafe",
+			LimitReqs: []version2.LimitReq{
+				{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+				{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
+			},
+			LimitReqOptions: version2.LimitReqOptions{
+				DryRun:     false,
+				LogLevel:   "error",
+				RejectCode: 503,
+			},
+			Locations: []version2.Location{
+				{
+					Path:                     "/tea",
+					ProxyPass:                "http://vs_default_cafe_tea",
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "0s",
+					ProxyNextUpstreamTries:   0,
+					ProxySSLName:             "tea-svc.default.svc",
+					ProxyPassRequestHeaders:  true,
+					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+					ServiceName:              "tea-svc",
+				},
+				{
+					Path:                     "/coffee",
+					ProxyPass:                "http://vs_default_cafe_coffee",
+					ProxyNextUpstream:        "error timeout",
+					ProxyNextUpstreamTimeout: "0s",
+					ProxyNextUpstreamTries:   0,
+					ProxySSLName:             "coffee-svc.default.svc",
+					ProxyPassRequestHeaders:  true,
+					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+					ServiceName:              "coffee-svc",
+				},
+			},
+		},
+	}
+
+	baseCfgParams := ConfigParams{
+		Context:         context.Background(),
+		ServerTokens:    "off",
+		Keepalive:       16,
+		ServerSnippets:  []string{"# server snippet"},
+		ProxyProtocol:   true,
+		SetRealIPFrom:   []string{"0.0.0.0/0"},
+		RealIPHeader:    "X-Real-IP",
+		RealIPRecursive: true,
+	}
+
+	isPlus := true
+	isResolverConfigured := false
+	staticConfigParams := &StaticConfigParams{TLSPassthrough: true, NginxServiceMesh: true, EnableInternalRoutes: false}
+	isWildcardEnabled := false
+	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, staticConfigParams, isWildcardEnabled, &fakeBV)
+
+	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
+	if diff := cmp.Diff(expected, result); diff == "" {
+		t.Errorf("GenerateVirtualServerConfig() should not configure internal route")
+	}
+
+	if len(warnings) != 1 {
+		t.Errorf("GenerateVirtualServerConfig should return warning about tiered rate limits with duplicate defaults")
+	}
+}
+
+func TestGeneratePolicies(t *testing.T) {
+	t.Parallel()
+	ownerDetails := policyOwnerDetails{
+		owner:          nil, // nil is OK for the unit test
+		ownerNamespace: "default",
+		vsNamespace:    "default",
+		vsName:         "test",
+	}
+	mTLSCertPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt"
+	mTLSCrlPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crl"
+	mTLSCertAndCrlPath := fmt.Sprintf("%s %s", mTLSCertPath, mTLSCrlPath)
+	policyOpts := policyOptions{
+		tls:      true,
+		zoneSync: false,
+		secretRefs: map[string]*secrets.SecretReference{
+			"default/ingress-mtls-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeCA,
+				},
+				Path: mTLSCertPath,
+			},
+			"default/ingress-mtls-secret-crl": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeCA,
+					Data: map[string][]byte{
+						"ca.crl": []byte("base64crl"),
+					},
+				},
+				Path: mTLSCertAndCrlPath,
+			},
+			"default/egress-mtls-secret": {
+				Secret: &api_v1.Secret{
+					Type: api_v1.SecretTypeTLS,
+				},
+				Path: "/etc/nginx/secrets/default-egress-mtls-secret",
+			},
+			"default/egress-trusted-ca-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeCA,
+				},
+				Path: "/etc/nginx/secrets/default-egress-trusted-ca-secret",
+			},
+			"default/egress-trusted-ca-secret-crl": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeCA,
+				},
+				Path: mTLSCertAndCrlPath,
+			},
+			"default/jwt-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeJWK,
+				},
+				Path: "/etc/nginx/secrets/default-jwt-secret",
+			},
+			"default/htpasswd-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeHtpasswd,
+				},
+				Path: "/etc/nginx/secrets/default-htpasswd-secret",
+			},
+			"default/oidc-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeOIDC,
+					Data: map[string][]byte{
+						"client-secret": []byte("super_secret_123"),
+						"session-key":   []byte("5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e"),
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
-						},
+				},
+			},
+			"default/api-key-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeAPIKey,
+					Data: map[string][]byte{
+						"client1": []byte("password"),
 					},
-					{
-						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						Parameters: []version2.Parameter{
-							{
-								Value:  `"basic"`,
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  `"premium"`,
-								Result: "rl_default_cafe_match_premium_rate_limit_policy",
-							},
-						},
+				},
+			},
+			"default/api-key-secret-2": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeAPIKey,
+					Data: map[string][]byte{
+						"client2": []byte("password2"),
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
+				},
+			},
+			"default/hmac-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeHMAC,
+				},
+				Path: "/etc/nginx/secrets/default-hmac-secret",
+			},
+		},
+		apResources: &appProtectResourcesForVS{
+			Policies: map[string]string{
+				"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+			},
+			LogConfs: map[string]string{
+				"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
+			},
+		},
+	}
+
+	tests := []struct {
+		policyRefs []conf_v1.PolicyReference
+		policies   map[string]*conf_v1.Policy
+		context    string
+		path       string
+		expected   policiesCfg
+		msg        string
+	}{
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "allow-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/allow-policy": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"127.0.0.1"},
 						},
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
+				},
+			},
+			expected: policiesCfg{
+				Allow:   []string{"127.0.0.1"},
+				Context: ctx,
+			},
+			msg: "explicit reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "allow-policy",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/allow-policy": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"127.0.0.1"},
 						},
 					},
 				},
-				Upstreams: []version2.Upstream{
-					{
-						UpstreamLabels: version2.UpstreamLabels{
-							Service:           "coffee-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
-							ResourceNamespace: "default",
-						},
-						Name: "vs_default_cafe_coffee",
-						Servers: []version2.UpstreamServer{
-							{
-								Address: "10.0.0.30:80",
-							},
+			},
+			expected: policiesCfg{
+				Allow:   []string{"127.0.0.1"},
+				Context: ctx,
+			},
+			msg: "implicit reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "allow-policy-1",
+				},
+				{
+					Name: "allow-policy-2",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/allow-policy-1": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"127.0.0.1"},
 						},
 					},
-					{
-						UpstreamLabels: version2.UpstreamLabels{
-							Service:           "tea-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
-							ResourceNamespace: "default",
-						},
-						Name: "vs_default_cafe_tea",
-						Servers: []version2.UpstreamServer{
-							{
-								Address: "10.0.0.20:80",
-							},
+				},
+				"default/allow-policy-2": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"127.0.0.2"},
 						},
 					},
 				},
-				HTTPSnippets: []string{},
-				LimitReqZones: []version2.LimitReqZone{
-					{
-						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
-						ZoneSize:      "10M",
-						Rate:          "10r/s",
-						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
-						GroupValue:    `"premium"`,
-						GroupSource:   "$apikey_client_name",
+			},
+			expected: policiesCfg{
+				Allow:   []string{"127.0.0.1", "127.0.0.2"},
+				Context: ctx,
+			},
+			msg: "merging",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "rateLimit-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/rateLimit-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "rateLimit-policy",
+						Namespace: "default",
 					},
-					{
-						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
-						ZoneSize:      "20M",
-						Rate:          "20r/s",
-						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
-						GroupValue:    `"basic"`,
-						GroupSource:   "$apikey_client_name",
-						GroupDefault:  true,
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+							LogLevel: "notice",
+						},
 					},
 				},
-				Server: version2.Server{
-					ServerName:   "cafe.example.com",
-					StatusZone:   "cafe.example.com",
-					ServerTokens: "off",
-					VSNamespace:  "default",
-					VSName:       "cafe",
-
-					Locations: []version2.Location{
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				RateLimit: rateLimit{
+					Reqs: []version2.LimitReq{
 						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_tea",
-							ProxyNextUpstream:        "error timeout",
-							ProxyNextUpstreamTimeout: "0s",
-							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
-							ProxyPassRequestHeaders:  true,
-							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
-							},
+							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
 						},
+					},
+					Zones: []version2.LimitReqZone{
 						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
-							ProxyNextUpstream:        "error timeout",
-							ProxyNextUpstreamTimeout: "0s",
-							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
-							ProxyPassRequestHeaders:  true,
-							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
-							},
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
 						},
 					},
-					APIKeyEnabled: true,
-					APIKey: &version2.APIKey{
-						Header:  []string{"X-API-Key"},
-						Query:   []string{"api-key"},
-						MapName: "apikey_auth_client_name_default_cafe_api_key_policy",
+					Options: version2.LimitReqOptions{
+						LogLevel:   "notice",
+						RejectCode: 503,
 					},
 				},
 			},
+			msg: "rate limit reference",
 		},
 		{
-			msg: "apikey rate limits at vsr /tea level, with default",
-			virtualServerEx: VirtualServerEx{
-				SecretRefs: map[string]*secrets.SecretReference{
-					"default/api-key-secret-spec": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeAPIKey,
-							Data: map[string][]byte{
-								"premium": []byte("premiumpassword"),
-								"basic":   []byte("basicpassword"),
-							},
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "rateLimit-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "rateLimit-policy2",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/rateLimit-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "rateLimit-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
 						},
 					},
 				},
-				VirtualServer: &conf_v1.VirtualServer{
+				"default/rateLimit-policy2": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "cafe",
+						Name:      "rateLimit-policy2",
 						Namespace: "default",
 					},
-					Spec: conf_v1.VirtualServerSpec{
-						Host: "cafe.example.com",
-						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "api-key-policy",
-							},
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test2",
+							ZoneSize: "20M",
+							Rate:     "20r/s",
 						},
-						Upstreams: []conf_v1.Upstream{
-							{
-								Name:    "coffee",
-								Service: "coffee-svc",
-								Port:    80,
-							},
+					},
+				},
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				RateLimit: rateLimit{
+					Zones: []version2.LimitReqZone{
+						{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
 						},
-						Routes: []conf_v1.Route{
-							{
-								Path:  "/tea",
-								Route: "default/tea",
-							},
-							{
-								Path: "/coffee",
-								Action: &conf_v1.Action{
-									Pass: "coffee",
-								},
-							},
+						{
+							Key:      "test2",
+							ZoneSize: "20M",
+							Rate:     "20r/s",
+							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
 						},
 					},
-				},
-				Policies: map[string]*conf_v1.Policy{
-					"default/premium-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "premium-rate-limit-policy",
-							Namespace: "default",
+					Options: version2.LimitReqOptions{
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
+					Reqs: []version2.LimitReq{
+						{
+							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
 						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$apikey_client_name",
-								ZoneSize: "10M",
-								Rate:     "10r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									Variables: &[]conf_v1.VariableCondition{
-										{
-											Match: "premium",
-											Name:  "$apikey_client_name",
-										},
-									},
-								},
-							},
+						{
+							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
 						},
 					},
-					"default/basic-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "basic-rate-limit-policy",
-							Namespace: "default",
+				},
+			},
+			msg: "multi rate limit reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "rateLimitScale-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/rateLimitScale-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "rateLimitScale-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+							LogLevel: "notice",
+							Scale:    true,
 						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$apikey_client_name",
-								ZoneSize: "20M",
-								Rate:     "20r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									Variables: &[]conf_v1.VariableCondition{
-										{
-											Match: "basic",
-											Name:  "$apikey_client_name",
-										},
-									},
-									Default: true,
-								},
-							},
+					},
+				},
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				RateLimit: rateLimit{
+					Zones: []version2.LimitReqZone{
+						{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "5r/s",
+							ZoneName: "pol_rl_default_rateLimitScale_policy_default_test",
+						},
+					},
+					Options: version2.LimitReqOptions{
+						LogLevel:   "notice",
+						RejectCode: 503,
+					},
+					Reqs: []version2.LimitReq{
+						{
+							ZoneName: "pol_rl_default_rateLimitScale_policy_default_test",
 						},
 					},
-					"default/api-key-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "api-key-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							APIKey: &conf_v1.APIKey{
-								ClientSecret: "api-key-secret-spec",
-								SuppliedIn: &conf_v1.SuppliedIn{
-									Header: []string{"X-API-Key"},
-									Query:  []string{"api-key"},
+				},
+			},
+			msg: "rate limit reference with scale",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "rateLimit-basic-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "rateLimit-premium-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/rateLimit-basic-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "rateLimit-basic-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "$apikey_client_name",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+							LogLevel: "notice",
+							Condition: &conf_v1.RateLimitCondition{
+								Variables: &[]conf_v1.VariableCondition{
+									{
+										Name:  "$apikey_client_name",
+										Match: "basic",
+									},
 								},
+								Default: true,
 							},
 						},
 					},
 				},
-				Endpoints: map[string][]string{
-					"default/tea-svc:80": {
-						"10.0.0.20:80",
-					},
-					"default/coffee-svc:80": {
-						"10.0.0.30:80",
+				"default/rateLimit-premium-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "rateLimit-premium-policy",
+						Namespace: "default",
 					},
-				},
-				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-					{
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "tea",
-							Namespace: "default",
-						},
-						Spec: conf_v1.VirtualServerRouteSpec{
-							Host: "cafe.example.com",
-							Upstreams: []conf_v1.Upstream{
-								{
-									Name:    "tea",
-									Service: "tea-svc",
-									Port:    80,
-								},
-							},
-							Subroutes: []conf_v1.Route{
-								{
-									Path: "/tea",
-									Action: &conf_v1.Action{
-										Pass: "tea",
-									},
-									Policies: []conf_v1.PolicyReference{
-										{
-											Name: "premium-rate-limit-policy",
-										},
-										{
-											Name: "basic-rate-limit-policy",
-										},
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "$apikey_client_name",
+							ZoneSize: "10M",
+							Rate:     "100r/s",
+							LogLevel: "notice",
+							Condition: &conf_v1.RateLimitCondition{
+								Variables: &[]conf_v1.VariableCondition{
+									{
+										Name:  "$apikey_client_name",
+										Match: "premium",
 									},
 								},
 							},
@@ -10452,1277 +12098,1761 @@ func TestGenerateVirtualServerConfigRateLimitGroups(t *testing.T) {
 					},
 				},
 			},
-			expected: version2.VirtualServerConfig{
-				Maps: []version2.Map{
-					{
-						Source:   "$apikey_auth_token",
-						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: `""`,
-							},
-							{
-								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
-								Result: `"premium"`,
-							},
-							{
-								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
-								Result: `"basic"`,
-							},
-						},
-					},
-					{
-						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						Parameters: []version2.Parameter{
-							{
-								Value:  `"basic"`,
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  `"premium"`,
-								Result: "rl_default_cafe_match_premium_rate_limit_policy",
-							},
+			context: "route",
+			path:    "/coffee",
+			expected: policiesCfg{
+				Context: ctx,
+				RateLimit: rateLimit{
+					Zones: []version2.LimitReqZone{
+						{
+							Key:           "$pol_rl_default_rateLimit_basic_policy_default_test",
+							ZoneSize:      "10M",
+							Rate:          "10r/s",
+							ZoneName:      "pol_rl_default_rateLimit_basic_policy_default_test",
+							GroupValue:    `"basic"`,
+							GroupVariable: "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
+							PolicyValue:   "rl_default_test_match_ratelimit_basic_policy",
+							PolicyResult:  "$apikey_client_name",
+							GroupSource:   "$apikey_client_name",
+							GroupDefault:  true,
 						},
-					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
+						{
+							Key:           "$pol_rl_default_rateLimit_premium_policy_default_test",
+							ZoneSize:      "10M",
+							Rate:          "100r/s",
+							ZoneName:      "pol_rl_default_rateLimit_premium_policy_default_test",
+							GroupValue:    `"premium"`,
+							GroupVariable: "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
+							PolicyValue:   "rl_default_test_match_ratelimit_premium_policy",
+							PolicyResult:  "$apikey_client_name",
+							GroupSource:   "$apikey_client_name",
 						},
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
-						},
+					Options: version2.LimitReqOptions{
+						LogLevel:   "notice",
+						RejectCode: 503,
 					},
-				},
-				Upstreams: []version2.Upstream{
-					{
-						UpstreamLabels: version2.UpstreamLabels{
-							Service:           "coffee-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
-							ResourceNamespace: "default",
+					Reqs: []version2.LimitReq{
+						{
+							ZoneName: "pol_rl_default_rateLimit_basic_policy_default_test",
 						},
-						Name: "vs_default_cafe_coffee",
-						Servers: []version2.UpstreamServer{
-							{
-								Address: "10.0.0.30:80",
-							},
+						{
+							ZoneName: "pol_rl_default_rateLimit_premium_policy_default_test",
 						},
 					},
-					{
-						UpstreamLabels: version2.UpstreamLabels{
-							Service:           "tea-svc",
-							ResourceType:      "virtualserverroute",
-							ResourceName:      "tea",
-							ResourceNamespace: "default",
-						},
-						Name: "vs_default_cafe_vsr_default_tea_tea",
-						Servers: []version2.UpstreamServer{
-							{
-								Address: "10.0.0.20:80",
+					GroupMaps: []version2.Map{
+						{
+							Source:   "$apikey_client_name",
+							Variable: "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
+							Parameters: []version2.Parameter{
+								{Value: `"premium"`, Result: "rl_default_test_match_ratelimit_premium_policy"},
+								{Value: `"basic"`, Result: "rl_default_test_match_ratelimit_basic_policy"},
+								{Value: "default", Result: "rl_default_test_match_ratelimit_basic_policy"},
 							},
 						},
 					},
-				},
-				HTTPSnippets: []string{},
-				LimitReqZones: []version2.LimitReqZone{
-					{
-						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
-						ZoneSize:      "10M",
-						Rate:          "10r/s",
-						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
-						GroupValue:    `"premium"`,
-						GroupSource:   "$apikey_client_name",
-					},
-					{
-						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
-						ZoneSize:      "20M",
-						Rate:          "20r/s",
-						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
-						GroupValue:    `"basic"`,
-						GroupSource:   "$apikey_client_name",
-						GroupDefault:  true,
-					},
-				},
-				Server: version2.Server{
-					ServerName:   "cafe.example.com",
-					StatusZone:   "cafe.example.com",
-					ServerTokens: "off",
-					VSNamespace:  "default",
-					VSName:       "cafe",
-
-					Locations: []version2.Location{
+					PolicyGroupMaps: []version2.Map{
 						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
-							ProxyNextUpstream:        "error timeout",
-							ProxyNextUpstreamTimeout: "0s",
-							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
-							ProxyPassRequestHeaders:  true,
-							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
+							Source:   "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
+							Variable: "$pol_rl_default_rateLimit_basic_policy_default_test",
+							Parameters: []version2.Parameter{
+								{Value: "default", Result: "''"},
+								{Value: "rl_default_test_match_ratelimit_basic_policy", Result: "Val$apikey_client_name"},
+							},
 						},
 						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
-							ProxyNextUpstream:        "error timeout",
-							ProxyNextUpstreamTimeout: "0s",
-							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
-							ProxyPassRequestHeaders:  true,
-							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
+							Source:   "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
+							Variable: "$pol_rl_default_rateLimit_premium_policy_default_test",
+							Parameters: []version2.Parameter{
+								{Value: "default", Result: "''"},
+								{Value: "rl_default_test_match_ratelimit_premium_policy", Result: "Val$apikey_client_name"},
 							},
-							IsVSR:        true,
-							VSRName:      "tea",
-							VSRNamespace: "default",
 						},
 					},
-					APIKeyEnabled: true,
-					APIKey: &version2.APIKey{
-						Header:  []string{"X-API-Key"},
-						Query:   []string{"api-key"},
-						MapName: "apikey_auth_client_name_default_cafe_api_key_policy",
-					},
 				},
 			},
+			msg: "tiered rate limits",
 		},
 		{
-			msg: "apikey rate limits at vsr /tea level & at vs spec level, with default",
-			virtualServerEx: VirtualServerEx{
-				SecretRefs: map[string]*secrets.SecretReference{
-					"default/api-key-secret-spec": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeAPIKey,
-							Data: map[string][]byte{
-								"premium": []byte("premiumpassword"),
-								"basic":   []byte("basicpassword"),
-							},
-						},
-					},
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "jwt-policy",
+					Namespace: "default",
 				},
-				VirtualServer: &conf_v1.VirtualServer{
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "cafe",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
-					Spec: conf_v1.VirtualServerSpec{
-						Host: "cafe.example.com",
-						Policies: []conf_v1.PolicyReference{
-							{
-								Name: "api-key-policy",
-							},
-						},
-						Upstreams: []conf_v1.Upstream{
-							{
-								Name:    "coffee",
-								Service: "coffee-svc",
-								Port:    80,
-							},
-						},
-						Routes: []conf_v1.Route{
-							{
-								Path:  "/tea",
-								Route: "default/tea",
-							},
-							{
-								Path: "/coffee",
-								Action: &conf_v1.Action{
-									Pass: "coffee",
-								},
-								Policies: []conf_v1.PolicyReference{
-									{
-										Name: "premium-rate-limit-policy",
-									},
-									{
-										Name: "basic-rate-limit-policy",
-									},
-								},
-							},
+					Spec: conf_v1.PolicySpec{
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "My Test API",
+							Secret: "jwt-secret",
 						},
 					},
 				},
-				Policies: map[string]*conf_v1.Policy{
-					"default/premium-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "premium-rate-limit-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$apikey_client_name",
-								ZoneSize: "10M",
-								Rate:     "10r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									Variables: &[]conf_v1.VariableCondition{
-										{
-											Match: "premium",
-											Name:  "$apikey_client_name",
-										},
-									},
-								},
-							},
-						},
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				JWTAuth: jwtAuth{
+					Enabled: true,
+					Auth: &version2.JWTAuth{
+						Secret: "/etc/nginx/secrets/default-jwt-secret",
+						Realm:  "My Test API",
 					},
-					"default/basic-rate-limit-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "basic-rate-limit-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							RateLimit: &conf_v1.RateLimit{
-								Key:      "$apikey_client_name",
-								ZoneSize: "20M",
-								Rate:     "20r/s",
-								Condition: &conf_v1.RateLimitCondition{
-									Variables: &[]conf_v1.VariableCondition{
-										{
-											Match: "basic",
-											Name:  "$apikey_client_name",
-										},
-									},
-									Default: true,
-								},
-							},
-						},
+					JWKSEnabled: false,
+				},
+			},
+			msg: "jwt reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "jwt-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/jwt-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "jwt-policy",
+						Namespace: "default",
 					},
-					"default/api-key-policy": {
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "api-key-policy",
-							Namespace: "default",
-						},
-						Spec: conf_v1.PolicySpec{
-							APIKey: &conf_v1.APIKey{
-								ClientSecret: "api-key-secret-spec",
-								SuppliedIn: &conf_v1.SuppliedIn{
-									Header: []string{"X-API-Key"},
-									Query:  []string{"api-key"},
-								},
-							},
+					Spec: conf_v1.PolicySpec{
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:    "My Test API",
+							Secret:   "jwt-secret",
+							Issuer:   "https://idp.example.com",
+							Audience: []string{"orders", "payments"},
 						},
 					},
 				},
-				Endpoints: map[string][]string{
-					"default/tea-svc:80": {
-						"10.0.0.20:80",
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				JWTAuth: jwtAuth{
+					Enabled: true,
+					Auth: &version2.JWTAuth{
+						Secret:   "/etc/nginx/secrets/default-jwt-secret",
+						Realm:    "My Test API",
+						Issuer:   "https://idp.example.com",
+						Audience: "orders payments",
 					},
-					"default/coffee-svc:80": {
-						"10.0.0.30:80",
+				},
+			},
+			msg: "jwt reference with issuer and audience",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "jwt-policy-2",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/jwt-policy-2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "jwt-policy-2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:    "My Test API",
+							JwksURI:  "https://idp.example.com:443/keys",
+							KeyCache: "1h",
+						},
 					},
 				},
-				VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
-					{
-						ObjectMeta: meta_v1.ObjectMeta{
-							Name:      "tea",
-							Namespace: "default",
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				JWTAuth: jwtAuth{
+					Enabled: true,
+					Auth: &version2.JWTAuth{
+						Key:   "default/jwt-policy-2",
+						Realm: "My Test API",
+						JwksURI: version2.JwksURI{
+							JwksScheme: "https",
+							JwksHost:   "idp.example.com",
+							JwksPort:   "443",
+							JwksPath:   "/keys",
 						},
-						Spec: conf_v1.VirtualServerRouteSpec{
-							Host: "cafe.example.com",
-							Upstreams: []conf_v1.Upstream{
-								{
-									Name:    "tea",
-									Service: "tea-svc",
-									Port:    80,
-								},
-							},
-							Subroutes: []conf_v1.Route{
-								{
-									Path: "/tea",
-									Action: &conf_v1.Action{
-										Pass: "tea",
-									},
-									Policies: []conf_v1.PolicyReference{
-										{
-											Name: "premium-rate-limit-policy",
-										},
-										{
-											Name: "basic-rate-limit-policy",
-										},
-									},
-								},
-							},
+						KeyCache: "1h",
+					},
+					JWKSEnabled: true,
+				},
+			},
+			msg: "Basic jwks example",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "jwt-policy-2",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/jwt-policy-2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "jwt-policy-2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:    "My Test API",
+							JwksURI:  "https://idp.example.com/keys",
+							KeyCache: "1h",
 						},
 					},
 				},
 			},
-			expected: version2.VirtualServerConfig{
-				Maps: []version2.Map{
-					{
-						Source:   "$apikey_auth_token",
-						Variable: "$apikey_auth_client_name_default_cafe_api_key_policy",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: `""`,
-							},
-							{
-								Value:  `"e96ac3dd8ef94a6c4bb88f216231c1968e1700add139d722fe406cd0cae73074"`,
-								Result: `"premium"`,
-							},
-							{
-								Value:  `"e1e1a4f93c814d938254e6fd7da12f096c9948eae7bc4137656202a413a0f3f4"`,
-								Result: `"basic"`,
-							},
+			expected: policiesCfg{
+				Context: ctx,
+				JWTAuth: jwtAuth{
+					Enabled: true,
+					Auth: &version2.JWTAuth{
+						Key:   "default/jwt-policy-2",
+						Realm: "My Test API",
+						JwksURI: version2.JwksURI{
+							JwksScheme: "https",
+							JwksHost:   "idp.example.com",
+							JwksPort:   "",
+							JwksPath:   "/keys",
 						},
+						KeyCache: "1h",
 					},
-					{
-						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						Parameters: []version2.Parameter{
-							{
-								Value:  `"basic"`,
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  `"premium"`,
-								Result: "rl_default_cafe_match_premium_rate_limit_policy",
-							},
-						},
+					JWKSEnabled: true,
+				},
+			},
+			msg: "Basic jwks example, no port in JwksURI",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "basic-auth-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/basic-auth-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "basic-auth-policy",
+						Namespace: "default",
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
+					Spec: conf_v1.PolicySpec{
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "My Test API",
+							Secret: "htpasswd-secret",
 						},
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
-						},
+				},
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				BasicAuth: &version2.BasicAuth{
+					Secret: "/etc/nginx/secrets/default-htpasswd-secret",
+					Realm:  "My Test API",
+				},
+			},
+			msg: "basic auth reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy",
+						Namespace: "default",
 					},
-					{
-						Source:   "$apikey_client_name",
-						Variable: "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						Parameters: []version2.Parameter{
-							{
-								Value:  `"basic"`,
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  "default",
-								Result: "rl_default_cafe_match_basic_rate_limit_policy",
-							},
-							{
-								Value:  `"premium"`,
-								Result: "rl_default_cafe_match_premium_rate_limit_policy",
-							},
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
+							VerifyClient:     "off",
 						},
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_premium_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
+				},
+			},
+			context: "spec",
+			expected: policiesCfg{
+				Context: ctx,
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:   mTLSCertPath,
+					VerifyClient: "off",
+					VerifyDepth:  1,
+				},
+			},
+			msg: "ingressMTLS reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy-crl",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy-crl": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy-crl",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret-crl",
+							VerifyClient:     "off",
 						},
 					},
-					{
-						Source:   "$rl_default_cafe_variable_apikey_client_name_subroute_L3RlYQ",
-						Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						Parameters: []version2.Parameter{
-							{
-								Value:  "default",
-								Result: "''",
-							},
-							{
-								Value:  "rl_default_cafe_match_basic_rate_limit_policy",
-								Result: "Val$apikey_client_name",
-							},
+				},
+			},
+			context: "spec",
+			expected: policiesCfg{
+				Context: ctx,
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:   mTLSCertPath,
+					ClientCrl:    mTLSCrlPath,
+					VerifyClient: "off",
+					VerifyDepth:  1,
+				},
+			},
+			msg: "ingressMTLS reference with ca.crl field in secret",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy-crl",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy-crl": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy-crl",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
+							CrlFileName:      "default-ingress-mtls-secret-ca.crl",
+							VerifyClient:     "off",
 						},
 					},
 				},
-				Upstreams: []version2.Upstream{
-					{
-						UpstreamLabels: version2.UpstreamLabels{
-							Service:           "coffee-svc",
-							ResourceType:      "virtualserver",
-							ResourceName:      "cafe",
-							ResourceNamespace: "default",
-						},
-						Name: "vs_default_cafe_coffee",
-						Servers: []version2.UpstreamServer{
-							{
-								Address: "10.0.0.30:80",
-							},
-						},
+			},
+			context: "spec",
+			expected: policiesCfg{
+				Context: ctx,
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:   mTLSCertPath,
+					ClientCrl:    mTLSCrlPath,
+					VerifyClient: "off",
+					VerifyDepth:  1,
+				},
+			},
+			msg: "ingressMTLS reference with crl field in policy",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy",
+						Namespace: "default",
 					},
-					{
-						UpstreamLabels: version2.UpstreamLabels{
-							Service:           "tea-svc",
-							ResourceType:      "virtualserverroute",
-							ResourceName:      "tea",
-							ResourceNamespace: "default",
-						},
-						Name: "vs_default_cafe_vsr_default_tea_tea",
-						Servers: []version2.UpstreamServer{
-							{
-								Address: "10.0.0.20:80",
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
+							VerifyClient:     "on",
+							ForwardCertificate: &conf_v1.ForwardCertificate{
+								SubjectHeader: "X-Client-Subject-DN",
+								SANsHeader:    "X-Client-SANs",
 							},
 						},
 					},
 				},
-				HTTPSnippets: []string{},
-				LimitReqZones: []version2.LimitReqZone{
-					{
-						Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_premium_rate_limit_policy_default_cafe",
-						ZoneSize:      "10M",
-						Rate:          "10r/s",
-						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						PolicyValue:   "rl_default_cafe_match_premium_rate_limit_policy",
-						GroupValue:    `"premium"`,
-						GroupSource:   "$apikey_client_name",
-					},
-					{
-						Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-						ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
-						ZoneSize:      "20M",
-						Rate:          "20r/s",
-						PolicyResult:  "$apikey_client_name",
-						GroupVariable: "$rl_default_cafe_variable_apikey_client_name_route_L2NvZmZlZQ",
-						PolicyValue:   "rl_default_cafe_match_basic_rate_limit_policy",
-						GroupValue:    `"basic"`,
-						GroupSource:   "$apikey_client_name",
-						GroupDefault:  true,
+			},
+			context: "spec",
+			expected: policiesCfg{
+				Context: ctx,
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:   mTLSCertPath,
+					VerifyClient: "on",
+					VerifyDepth:  1,
+					ForwardHeaders: []version2.Header{
+						{Name: "X-Client-Subject-DN", Value: "$ingress_mtls_client_s_dn"},
+						{Name: "X-Client-SANs", Value: "$ingress_mtls_client_sans"},
 					},
 				},
-				Server: version2.Server{
-					ServerName:   "cafe.example.com",
-					StatusZone:   "cafe.example.com",
-					ServerTokens: "off",
-					VSNamespace:  "default",
-					VSName:       "cafe",
-
-					Locations: []version2.Location{
-						{
-							Path:                     "/coffee",
-							ProxyPass:                "http://vs_default_cafe_coffee",
-							ProxyNextUpstream:        "error timeout",
-							ProxyNextUpstreamTimeout: "0s",
-							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "coffee-svc.default.svc",
-							ProxyPassRequestHeaders:  true,
-							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "coffee-svc",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
-							},
-						},
-						{
-							Path:                     "/tea",
-							ProxyPass:                "http://vs_default_cafe_vsr_default_tea_tea",
-							ProxyNextUpstream:        "error timeout",
-							ProxyNextUpstreamTimeout: "0s",
-							ProxyNextUpstreamTries:   0,
-							ProxySSLName:             "tea-svc.default.svc",
-							ProxyPassRequestHeaders:  true,
-							ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-							ServiceName:              "tea-svc",
-							LimitReqs: []version2.LimitReq{
-								{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-								{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-							},
-							LimitReqOptions: version2.LimitReqOptions{
-								DryRun:     false,
-								LogLevel:   "error",
-								RejectCode: 503,
+			},
+			msg: "ingressMTLS reference with forwarded certificate headers",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ingress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ingress-mtls-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ingress-mtls-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						IngressMTLS: &conf_v1.IngressMTLS{
+							ClientCertSecret: "ingress-mtls-secret",
+							VerifyClient:     "on",
+							OCSP: &conf_v1.IngressMTLSOCSP{
+								LeafOnly:  true,
+								Responder: "http://ocsp.example.com",
 							},
-							IsVSR:        true,
-							VSRName:      "tea",
-							VSRNamespace: "default",
 						},
 					},
-					APIKeyEnabled: true,
-					APIKey: &version2.APIKey{
-						Header:  []string{"X-API-Key"},
-						Query:   []string{"api-key"},
-						MapName: "apikey_auth_client_name_default_cafe_api_key_policy",
-					},
 				},
 			},
+			context: "spec",
+			expected: policiesCfg{
+				Context: ctx,
+				IngressMTLS: &version2.IngressMTLS{
+					ClientCert:    mTLSCertPath,
+					VerifyClient:  "on",
+					VerifyDepth:   1,
+					OCSP:          "leaf",
+					OCSPResponder: "http://ocsp.example.com",
+				},
+			},
+			msg: "ingressMTLS reference with ocsp",
 		},
-	}
-
-	baseCfgParams := ConfigParams{
-		Context:      context.Background(),
-		ServerTokens: "off",
-	}
-
-	vsc := newVirtualServerConfigurator(
-		&baseCfgParams,
-		false,
-		false,
-		&StaticConfigParams{},
-		false,
-		&fakeBV,
-	)
-
-	for _, test := range tests {
-		result, warnings := vsc.GenerateVirtualServerConfig(&test.virtualServerEx, nil, nil)
-
-		for i, m := range result.Maps {
-			sort.Slice(m.Parameters, func(i, j int) bool {
-				return m.Parameters[i].Value < m.Parameters[j].Value
-			})
-			result.Maps[i] = m
-		}
-
-		sort.Slice(result.Maps, func(i, j int) bool {
-			return result.Maps[i].Variable < result.Maps[j].Variable && result.Maps[i].Source < result.Maps[j].Source
-		})
-
-		for i, m := range test.expected.Maps {
-			sort.Slice(m.Parameters, func(i, j int) bool {
-				return m.Parameters[i].Value < m.Parameters[j].Value
-			})
-			test.expected.Maps[i] = m
-		}
-
-		sort.Slice(test.expected.Maps, func(i, j int) bool {
-			return test.expected.Maps[i].Variable < test.expected.Maps[j].Variable && test.expected.Maps[i].Source < test.expected.Maps[j].Source
-		})
-
-		if diff := cmp.Diff(test.expected, result); diff != "" {
-			t.Errorf("GenerateVirtualServerConfig() mismatch (-want +got):\n%s", diff)
-			t.Error(test.msg)
-		}
-
-		if len(warnings) != 0 {
-			t.Errorf("GenerateVirtualServerConfig returned warnings: %v", vsc.warnings)
-		}
-	}
-}
-
-func TestGenerateVirtualServerConfigWithRateLimitGroupsWarning(t *testing.T) {
-	t.Parallel()
-
-	virtualServerEx := VirtualServerEx{
-		VirtualServer: &conf_v1.VirtualServer{
-			ObjectMeta: meta_v1.ObjectMeta{
-				Name:      "cafe",
-				Namespace: "default",
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "egress-mtls-policy",
+					Namespace: "default",
+				},
 			},
-			Spec: conf_v1.VirtualServerSpec{
-				Host: "cafe.example.com",
-				Policies: []conf_v1.PolicyReference{
-					{
-						Name: "premium-rate-limit-policy",
+			policies: map[string]*conf_v1.Policy{
+				"default/egress-mtls-policy": {
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TLSSecret:         "egress-mtls-secret",
+							ServerName:        true,
+							SessionReuse:      createPointerFromBool(false),
+							TrustedCertSecret: "egress-trusted-ca-secret",
+						},
 					},
-					{
-						Name: "basic-rate-limit-policy",
+				},
+			},
+			context: "route",
+			expected: policiesCfg{
+				Context: ctx,
+				EgressMTLS: &version2.EgressMTLS{
+					Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
+					CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
+					Ciphers:        "DEFAULT",
+					Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
+					ServerName:     true,
+					SessionReuse:   false,
+					VerifyDepth:    1,
+					VerifyServer:   false,
+					TrustedCert:    "/etc/nginx/secrets/default-egress-trusted-ca-secret",
+					SSLName:        "$proxy_host",
+				},
+			},
+			msg: "egressMTLS reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "egress-mtls-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/egress-mtls-policy": {
+					Spec: conf_v1.PolicySpec{
+						EgressMTLS: &conf_v1.EgressMTLS{
+							TLSSecret:         "egress-mtls-secret",
+							ServerName:        true,
+							SessionReuse:      createPointerFromBool(false),
+							TrustedCertSecret: "egress-trusted-ca-secret-crl",
+						},
 					},
 				},
-				Upstreams: []conf_v1.Upstream{
-					{
-						Name:    "tea",
-						Service: "tea-svc",
-						Port:    80,
-					},
-					{
-						Name:    "coffee",
-						Service: "coffee-svc",
-						Port:    80,
-					},
+			},
+			context: "route",
+			expected: policiesCfg{
+				Context: ctx,
+				EgressMTLS: &version2.EgressMTLS{
+					Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
+					CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
+					Ciphers:        "DEFAULT",
+					Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
+					ServerName:     true,
+					SessionReuse:   false,
+					VerifyDepth:    1,
+					VerifyServer:   false,
+					TrustedCert:    mTLSCertPath,
+					SSLName:        "$proxy_host",
 				},
-				Routes: []conf_v1.Route{
-					{
-						Path: "/tea",
-						Action: &conf_v1.Action{
-							Pass: "tea",
-						},
+			},
+			msg: "egressMTLS with crt and crl",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "oidc-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/oidc-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy",
+						Namespace: "default",
 					},
-					{
-						Path: "/coffee",
-						Action: &conf_v1.Action{
-							Pass: "coffee",
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							AuthEndpoint:          "http://example.com/auth",
+							TokenEndpoint:         "http://example.com/token",
+							JWKSURI:               "http://example.com/jwks",
+							ClientID:              "client-id",
+							ClientSecret:          "oidc-secret",
+							Scope:                 "scope",
+							RedirectURI:           "/redirect",
+							ZoneSyncLeeway:        createPointerFromInt(20),
+							AccessTokenEnable:     true,
+							EndSessionEndpoint:    "http://example.com/logout",
+							PostLogoutRedirectURI: "/_logout",
 						},
 					},
 				},
 			},
+			expected: policiesCfg{
+				Context: ctx,
+				OIDC:    true,
+			},
+			msg: "oidc reference",
 		},
-		Policies: map[string]*conf_v1.Policy{
-			"default/premium-rate-limit-policy": {
-				Spec: conf_v1.PolicySpec{
-					RateLimit: &conf_v1.RateLimit{
-						Key:      "$jwt_claim_sub",
-						ZoneSize: "10M",
-						Rate:     "10r/s",
-						Condition: &conf_v1.RateLimitCondition{
-							JWT: &conf_v1.JWTCondition{
-								Claim: "user_type.tier",
-								Match: "premium",
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "api-key-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/api-key-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "api-key-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						APIKey: &conf_v1.APIKey{
+							SuppliedIn: &conf_v1.SuppliedIn{
+								Header: []string{"X-API-Key"},
+								Query:  []string{"api-key"},
 							},
-							Default: true,
+							ClientSecret: "api-key-secret",
 						},
 					},
 				},
 			},
-			"default/basic-rate-limit-policy": {
-				Spec: conf_v1.PolicySpec{
-					RateLimit: &conf_v1.RateLimit{
-						Key:      "$jwt_claim_sub",
-						ZoneSize: "20M",
-						Rate:     "20r/s",
-						Condition: &conf_v1.RateLimitCondition{
-							JWT: &conf_v1.JWTCondition{
-								Claim: "user_type.tier",
-								Match: "basic",
-							},
-							Default: true,
+			expected: policiesCfg{
+				Context: ctx,
+				APIKey: apiKeyAuth{
+					Key: &version2.APIKey{
+						Header:  []string{"X-API-Key"},
+						Query:   []string{"api-key"},
+						MapName: "apikey_auth_client_name_default_test_api_key_policy",
+					},
+					Enabled:   true,
+					ClientMap: nil,
+					Clients: []apiKeyClient{
+						{
+							ClientID:  "client1",
+							HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
 						},
 					},
 				},
 			},
+			msg: "api key reference",
 		},
-		Endpoints: map[string][]string{
-			"default/tea-svc:80": {
-				"10.0.0.20:80",
-			},
-			"default/coffee-svc:80": {
-				"10.0.0.30:80",
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "api-key-policy",
+					Namespace: "default",
+				},
 			},
-		},
-	}
-	expected := version2.VirtualServerConfig{
-		Maps: []version2.Map{
-			{
-				Source:   "$rl_default_cafe_group_user_type_tier",
-				Variable: "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "default",
-						Result: "''",
+			policies: map[string]*conf_v1.Policy{
+				"default/api-key-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "api-key-policy",
+						Namespace: "default",
 					},
-					{
-						Value:  "rl_default_cafe_match_basic",
-						Result: "Val$jwt_claim_sub",
+					Spec: conf_v1.PolicySpec{
+						APIKey: &conf_v1.APIKey{
+							SuppliedIn: &conf_v1.SuppliedIn{
+								Header: []string{"X-API-Key"},
+								Query:  []string{"api-key"},
+							},
+							ClientSecret: "api-key-secret",
+						},
 					},
 				},
 			},
-			{
-				Source:   "$rl_default_cafe_group_user_type_tier",
-				Variable: "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "default",
-						Result: "''",
+			expected: policiesCfg{
+				Context: ctx,
+				APIKey: apiKeyAuth{
+					Key: &version2.APIKey{
+						Header:  []string{"X-API-Key"},
+						Query:   []string{"api-key"},
+						MapName: "apikey_auth_client_name_default_test_api_key_policy",
 					},
-					{
-						Value:  "rl_default_cafe_match_premium",
-						Result: "Val$jwt_claim_sub",
+					Enabled:   true,
+					ClientMap: nil,
+					Clients: []apiKeyClient{
+						{
+							ClientID:  "client1",
+							HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+						},
 					},
 				},
 			},
-			{
-				Source:   "$jwt_default_cafe_user_type_tier",
-				Variable: "$rl_default_cafe_group_user_type_tier",
-				Parameters: []version2.Parameter{
-					{
-						Value:  "basic",
-						Result: "rl_default_cafe_match_basic",
+			msg: "api key same secrets for different policies",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "api-key-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/api-key-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "api-key-policy",
+						Namespace: "default",
 					},
-					{
-						Value:  "premium",
-						Result: "rl_default_cafe_match_premium",
+					Spec: conf_v1.PolicySpec{
+						APIKey: &conf_v1.APIKey{
+							SuppliedIn: &conf_v1.SuppliedIn{
+								Header: []string{"X-API-Key"},
+							},
+							ClientSecret: "api-key-secret",
+							Tiers: []conf_v1.APIKeyTier{
+								{
+									Name:      "premium",
+									ClientIDs: []string{"client1"},
+								},
+							},
+						},
 					},
 				},
 			},
-		},
-		AuthJWTClaimSets: []version2.AuthJWTClaimSet{{Variable: "$jwt_default_cafe_user_type_tier", Claim: "user_type tier"}},
-		Upstreams: []version2.Upstream{
-			{
-				UpstreamLabels: version2.UpstreamLabels{
-					Service:           "coffee-svc",
-					ResourceType:      "virtualserver",
-					ResourceName:      "cafe",
-					ResourceNamespace: "default",
-				},
-				Name: "vs_default_cafe_coffee",
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "10.0.0.30:80",
+			expected: policiesCfg{
+				Context: ctx,
+				APIKey: apiKeyAuth{
+					Key: &version2.APIKey{
+						Header:      []string{"X-API-Key"},
+						MapName:     "apikey_auth_client_name_default_test_api_key_policy",
+						TierMapName: "apikey_auth_client_name_default_test_api_key_policy_tier",
+					},
+					Enabled:   true,
+					ClientMap: nil,
+					Clients: []apiKeyClient{
+						{
+							ClientID:  "client1",
+							HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+							Tier:      "premium",
+						},
 					},
 				},
 			},
-			{
-				UpstreamLabels: version2.UpstreamLabels{
-					Service:           "tea-svc",
-					ResourceType:      "virtualserver",
-					ResourceName:      "cafe",
-					ResourceNamespace: "default",
+			msg: "api key with tiers",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "signature-policy",
+					Namespace: "default",
 				},
-				Name: "vs_default_cafe_tea",
-				Servers: []version2.UpstreamServer{
-					{
-						Address: "10.0.0.20:80",
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/signature-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "signature-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						SignatureValidation: &conf_v1.SignatureValidation{
+							Secret: "hmac-secret",
+						},
 					},
 				},
 			},
-		},
-		HTTPSnippets: []string{},
-		LimitReqZones: []version2.LimitReqZone{
-			{
-				Key:           "$pol_rl_default_premium_rate_limit_policy_default_cafe",
-				ZoneName:      "pol_rl_default_premium-rate-limit-policy_default_cafe",
-				ZoneSize:      "10M",
-				Rate:          "10r/s",
-				PolicyResult:  "$jwt_claim_sub",
-				GroupVariable: "$rl_default_cafe_group_user_type_tier",
-				PolicyValue:   "rl_default_cafe_match_premium",
-				GroupValue:    "premium",
-				GroupSource:   "$jwt_default_cafe_user_type_tier",
-			},
-			{
-				Key:           "$pol_rl_default_basic_rate_limit_policy_default_cafe",
-				ZoneName:      "pol_rl_default_basic_rate_limit_policy_default_cafe",
-				ZoneSize:      "20M",
-				Rate:          "20r/s",
-				PolicyResult:  "$jwt_claim_sub",
-				GroupVariable: "$rl_default_cafe_group_user_type_tier",
-				PolicyValue:   "rl_default_cafe_match_basic",
-				GroupValue:    "basic",
-				GroupSource:   "$jwt_default_cafe_user_type_tier",
+			expected: policiesCfg{
+				Context: ctx,
+				SignatureValidation: &version2.SignatureValidation{
+					KeyFile:            "/etc/nginx/secrets/default-hmac-secret",
+					Format:             "plain",
+					Algorithm:          "sha256",
+					Header:             "X-Hub-Signature-256",
+					TimestampTolerance: 300,
+					RejectCode:         401,
+				},
 			},
+			msg: "signature validation reference with defaults",
 		},
-		Server: version2.Server{
-			ServerName:   "cafe.example.com",
-			StatusZone:   "cafe.example.com",
-			ServerTokens: "off",
-			VSNamespace:  "default",
-			VSName:       "cafe",
-			LimitReqs: []version2.LimitReq{
-				{ZoneName: "pol_rl_default_premium_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-				{ZoneName: "pol_rl_default_basic_rate_limit_policy_default_cafe", Burst: 0, NoDelay: false, Delay: 0},
-			},
-			LimitReqOptions: version2.LimitReqOptions{
-				DryRun:     false,
-				LogLevel:   "error",
-				RejectCode: 503,
-			},
-			Locations: []version2.Location{
+		{
+			policyRefs: []conf_v1.PolicyReference{
 				{
-					Path:                     "/tea",
-					ProxyPass:                "http://vs_default_cafe_tea",
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "0s",
-					ProxyNextUpstreamTries:   0,
-					ProxySSLName:             "tea-svc.default.svc",
-					ProxyPassRequestHeaders:  true,
-					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "tea-svc",
+					Name:      "signature-policy",
+					Namespace: "default",
 				},
-				{
-					Path:                     "/coffee",
-					ProxyPass:                "http://vs_default_cafe_coffee",
-					ProxyNextUpstream:        "error timeout",
-					ProxyNextUpstreamTimeout: "0s",
-					ProxyNextUpstreamTries:   0,
-					ProxySSLName:             "coffee-svc.default.svc",
-					ProxyPassRequestHeaders:  true,
-					ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
-					ServiceName:              "coffee-svc",
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/signature-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "signature-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						SignatureValidation: &conf_v1.SignatureValidation{
+							Secret:             "hmac-secret",
+							Format:             "stripe",
+							Algorithm:          "sha512",
+							Header:             "X-Signature",
+							TimestampTolerance: createPointerFromInt(0),
+							RejectCode:         createPointerFromInt(403),
+						},
+					},
+				},
+			},
+			expected: policiesCfg{
+				Context: ctx,
+				SignatureValidation: &version2.SignatureValidation{
+					KeyFile:            "/etc/nginx/secrets/default-hmac-secret",
+					Format:             "stripe",
+					Algorithm:          "sha512",
+					Header:             "X-Signature",
+					TimestampTolerance: 0,
+					RejectCode:         403,
 				},
 			},
+			msg: "signature validation reference",
 		},
-	}
-
-	baseCfgParams := ConfigParams{
-		Context:         context.Background(),
-		ServerTokens:    "off",
-		Keepalive:       16,
-		ServerSnippets:  []string{"# server snippet"},
-		ProxyProtocol:   true,
-		SetRealIPFrom:   []string{"0.0.0.0/0"},
-		RealIPHeader:    "X-Real-IP",
-		RealIPRecursive: true,
-	}
-
-	isPlus := true
-	isResolverConfigured := false
-	staticConfigParams := &StaticConfigParams{TLSPassthrough: true, NginxServiceMesh: true, EnableInternalRoutes: false}
-	isWildcardEnabled := false
-	vsc := newVirtualServerConfigurator(&baseCfgParams, isPlus, isResolverConfigured, staticConfigParams, isWildcardEnabled, &fakeBV)
-
-	result, warnings := vsc.GenerateVirtualServerConfig(&virtualServerEx, nil, nil)
-	if diff := cmp.Diff(expected, result); diff == "" {
-		t.Errorf("GenerateVirtualServerConfig() should not configure internal route")
-	}
-
-	if len(warnings) != 1 {
-		t.Errorf("GenerateVirtualServerConfig should return warning about tiered rate limits with duplicate defaults")
-	}
-}
-
-func TestGeneratePolicies(t *testing.T) {
-	t.Parallel()
-	ownerDetails := policyOwnerDetails{
-		owner:          nil, // nil is OK for the unit test
-		ownerNamespace: "default",
-		vsNamespace:    "default",
-		vsName:         "test",
-	}
-	mTLSCertPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt"
-	mTLSCrlPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crl"
-	mTLSCertAndCrlPath := fmt.Sprintf("%s %s", mTLSCertPath, mTLSCrlPath)
-	policyOpts := policyOptions{
-		tls:      true,
-		zoneSync: false,
-		secretRefs: map[string]*secrets.SecretReference{
-			"default/ingress-mtls-secret": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeCA,
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "waf-policy",
+					Namespace: "default",
 				},
-				Path: mTLSCertPath,
 			},
-			"default/ingress-mtls-secret-crl": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeCA,
-					Data: map[string][]byte{
-						"ca.crl": []byte("base64crl"),
+			policies: map[string]*conf_v1.Policy{
+				"default/waf-policy": {
+					Spec: conf_v1.PolicySpec{
+						WAF: &conf_v1.WAF{
+							Enable:   true,
+							ApPolicy: "default/dataguard-alarm",
+							SecurityLog: &conf_v1.SecurityLog{
+								Enable:    true,
+								ApLogConf: "default/logconf",
+								LogDest:   "syslog:server=127.0.0.1:514",
+							},
+						},
 					},
 				},
-				Path: mTLSCertAndCrlPath,
 			},
-			"default/egress-mtls-secret": {
-				Secret: &api_v1.Secret{
-					Type: api_v1.SecretTypeTLS,
+			context: "route",
+			path:    "/coffee",
+			expected: policiesCfg{
+				Context: ctx,
+				WAF: &version2.WAF{
+					Enable:              "on",
+					ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
+					ApSecurityLogEnable: true,
+					ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf syslog:server=127.0.0.1:514"},
 				},
-				Path: "/etc/nginx/secrets/default-egress-mtls-secret",
 			},
-			"default/egress-trusted-ca-secret": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeCA,
+			msg: "WAF reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "cors-policy",
+					Namespace: "default",
 				},
-				Path: "/etc/nginx/secrets/default-egress-trusted-ca-secret",
 			},
-			"default/egress-trusted-ca-secret-crl": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeCA,
+			policies: map[string]*conf_v1.Policy{
+				"default/cors-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cors-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						CORS: &conf_v1.CORS{
+							AllowOrigins:     []string{"https://example.com", `~^https://.+\.example\.org$`},
+							AllowHeaders:     []string{"Content-Type", "Authorization"},
+							ExposeHeaders:    []string{"X-Total-Count"},
+							AllowCredentials: true,
+							MaxAge:           createPointerFromInt(600),
+						},
+					},
 				},
-				Path: mTLSCertAndCrlPath,
 			},
-			"default/jwt-secret": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeJWK,
+			context: "spec",
+			expected: policiesCfg{
+				Context: ctx,
+				CORS: cors{
+					Headers: &version2.CORS{
+						AllowOrigin:      "$pol_cors_default_cors_policy_default_test_origin",
+						AllowMethods:     "GET, HEAD, POST",
+						AllowHeaders:     "Content-Type, Authorization",
+						ExposeHeaders:    "X-Total-Count",
+						AllowCredentials: true,
+						MaxAge:           600,
+					},
+					OriginMap: &version2.Map{
+						Source:   "$http_origin",
+						Variable: "$pol_cors_default_cors_policy_default_test_origin",
+						Parameters: []version2.Parameter{
+							{
+								Value:  "default",
+								Result: `""`,
+							},
+							{
+								Value:  `"https://example.com"`,
+								Result: "$http_origin",
+							},
+							{
+								Value:  `"~^https://.+\.example\.org$"`,
+								Result: "$http_origin",
+							},
+						},
+					},
 				},
-				Path: "/etc/nginx/secrets/default-jwt-secret",
 			},
-			"default/htpasswd-secret": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeHtpasswd,
+			msg: "cors reference",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "cors-policy-all-origins",
+					Namespace: "default",
 				},
-				Path: "/etc/nginx/secrets/default-htpasswd-secret",
 			},
-			"default/oidc-secret": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeOIDC,
-					Data: map[string][]byte{
-						"client-secret": []byte("super_secret_123"),
+			policies: map[string]*conf_v1.Policy{
+				"default/cors-policy-all-origins": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cors-policy-all-origins",
+						Namespace: "default",
 					},
-				},
-			},
-			"default/api-key-secret": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeAPIKey,
-					Data: map[string][]byte{
-						"client1": []byte("password"),
+					Spec: conf_v1.PolicySpec{
+						CORS: &conf_v1.CORS{
+							AllowOrigins: []string{"*"},
+							AllowMethods: []string{"GET", "PUT"},
+						},
 					},
 				},
 			},
-			"default/api-key-secret-2": {
-				Secret: &api_v1.Secret{
-					Type: secrets.SecretTypeAPIKey,
-					Data: map[string][]byte{
-						"client2": []byte("password2"),
+			context: "route",
+			path:    "/coffee",
+			expected: policiesCfg{
+				Context: ctx,
+				CORS: cors{
+					Headers: &version2.CORS{
+						AllowOrigin:  "*",
+						AllowMethods: "GET, PUT",
 					},
 				},
 			},
+			msg: "cors reference with all origins",
 		},
-		apResources: &appProtectResourcesForVS{
-			Policies: map[string]string{
-				"default/dataguard-alarm": "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-			},
-			LogConfs: map[string]string{
-				"default/logconf": "/etc/nginx/waf/nac-logconfs/default-logconf",
-			},
-		},
-	}
-
-	tests := []struct {
-		policyRefs []conf_v1.PolicyReference
-		policies   map[string]*conf_v1.Policy
-		context    string
-		path       string
-		expected   policiesCfg
-		msg        string
-	}{
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "allow-policy",
+					Name:      "cache-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/allow-policy": {
+				"default/cache-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cache-policy",
+						Namespace: "default",
+					},
 					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Allow: []string{"127.0.0.1"},
+						Cache: &conf_v1.Cache{
+							MaxSize:  "1g",
+							Inactive: "1h",
+							Key:      "${scheme}${host}${request_uri}",
+							Valid: []conf_v1.CacheValid{
+								{Codes: []string{"200", "302"}, Time: "10m"},
+							},
+							Bypass:             []string{"$http_pragma", "$cookie_nocache"},
+							IgnoreCacheControl: true,
+							PurgeAllowedCIDRs:  []string{"10.0.0.0/8"},
 						},
 					},
 				},
 			},
+			context: "route",
+			path:    "/coffee",
 			expected: policiesCfg{
-				Allow:   []string{"127.0.0.1"},
 				Context: ctx,
+				Cache: cache{
+					Cache: &version2.Cache{
+						ZoneName:           "pol_cache_default_cache_policy_default_test",
+						Key:                "${scheme}${host}${request_uri}",
+						Methods:            "GET HEAD",
+						Valid:              []version2.CacheValid{{Codes: "200 302", Time: "10m"}},
+						Bypass:             "$http_pragma $cookie_nocache",
+						IgnoreCacheControl: true,
+					},
+					Zone: &version2.CacheZone{
+						Name:     "pol_cache_default_cache_policy_default_test",
+						Path:     "/var/cache/nginx/pol_cache_default_cache_policy_default_test",
+						Size:     "10m",
+						MaxSize:  "1g",
+						Inactive: "1h",
+					},
+				},
 			},
-			msg: "explicit reference",
+			msg: "cache reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name: "allow-policy",
+					Name:      "fault-policy",
+					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/allow-policy": {
+				"default/fault-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "fault-policy",
+						Namespace: "default",
+					},
 					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Allow: []string{"127.0.0.1"},
+						FaultInjection: &conf_v1.FaultInjection{
+							Percentage: 10,
+							Delay:      "1m500ms",
+							Condition: &conf_v1.Condition{
+								Header: "X-Chaos",
+								Value:  "on",
+							},
 						},
 					},
 				},
 			},
+			context: "route",
+			path:    "/coffee",
 			expected: policiesCfg{
-				Allow:   []string{"127.0.0.1"},
 				Context: ctx,
+				FaultInjection: faultInjection{
+					Fault: &version2.FaultInjection{
+						Check:         "${pol_fault_injection_default_fault_policy_default_test_split}${pol_fault_injection_default_fault_policy_default_test_condition}${fault_injection_delayed}",
+						Value:         "11",
+						DelayLocation: "@pol_fault_injection_default_fault_policy_default_test",
+					},
+					Location: &version2.FaultInjectionLocation{
+						Name:  "@pol_fault_injection_default_fault_policy_default_test",
+						Delay: 60500,
+					},
+					SplitClient: &version2.SplitClient{
+						Source:   "$request_id",
+						Variable: "$pol_fault_injection_default_fault_policy_default_test_split",
+						Distributions: []version2.Distribution{
+							{
+								Weight: "10%",
+								Value:  "1",
+							},
+							{
+								Weight: "*",
+								Value:  "0",
+							},
+						},
+					},
+					ConditionMap: &version2.Map{
+						Source:   "$http_X_Chaos",
+						Variable: "$pol_fault_injection_default_fault_policy_default_test_condition",
+						Parameters: []version2.Parameter{
+							{
+								Value:  `"on"`,
+								Result: "1",
+							},
+							{
+								Value:  "default",
+								Result: "0",
+							},
+						},
+					},
+				},
 			},
-			msg: "implicit reference",
+			msg: "fault injection reference with delay",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name: "allow-policy-1",
-				},
-				{
-					Name: "allow-policy-2",
+					Name:      "fault-policy-abort",
+					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/allow-policy-1": {
-					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Allow: []string{"127.0.0.1"},
-						},
+				"default/fault-policy-abort": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "fault-policy-abort",
+						Namespace: "default",
 					},
-				},
-				"default/allow-policy-2": {
 					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Allow: []string{"127.0.0.2"},
+						FaultInjection: &conf_v1.FaultInjection{
+							Percentage: 100,
+							Status:     503,
 						},
 					},
 				},
 			},
+			context: "route",
+			path:    "/coffee",
 			expected: policiesCfg{
-				Allow:   []string{"127.0.0.1", "127.0.0.2"},
 				Context: ctx,
+				FaultInjection: faultInjection{
+					Fault: &version2.FaultInjection{
+						Check:  "${pol_fault_injection_default_fault_policy_abort_default_test_split}",
+						Value:  "1",
+						Status: 503,
+					},
+					SplitClient: &version2.SplitClient{
+						Source:   "$request_id",
+						Variable: "$pol_fault_injection_default_fault_policy_abort_default_test_split",
+						Distributions: []version2.Distribution{
+							{
+								Weight: "100%",
+								Value:  "1",
+							},
+						},
+					},
+				},
 			},
-			msg: "merging",
+			msg: "fault injection reference with status",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "rateLimit-policy",
+					Name:      "conn-limit-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/rateLimit-policy": {
+				"default/conn-limit-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-policy",
+						Name:      "conn-limit-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-							LogLevel: "notice",
+						ConnectionLimit: &conf_v1.ConnectionLimit{
+							Key:         "${binary_remote_addr}",
+							ZoneSize:    "10M",
+							Connections: 5,
+							DryRun:      createPointerFromBool(true),
+						},
+					},
+				},
+			},
+			context: "route",
+			path:    "/coffee",
+			expected: policiesCfg{
+				Context: ctx,
+				ConnectionLimit: connectionLimit{
+					Zone: &version2.LimitConnZone{
+						Key:      "${binary_remote_addr}",
+						ZoneName: "pol_cl_default_conn_limit_policy_default_test",
+						ZoneSize: "10M",
+					},
+					Conn: &version2.LimitConn{
+						ZoneName:    "pol_cl_default_conn_limit_policy_default_test",
+						Connections: 5,
+						DryRun:      true,
+						RejectCode:  503,
+					},
+				},
+			},
+			msg: "connection limit reference",
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{Context: ctx}, false, false, &StaticConfigParams{}, false, &fakeBV)
+	// required to test the scaling of the ratelimit
+	vsc.IngressControllerReplicas = 2
+
+	for _, tc := range tests {
+		t.Run(tc.msg, func(t *testing.T) {
+			result := vsc.generatePolicies(ownerDetails, tc.policyRefs, tc.policies, tc.context, tc.path, policyOpts)
+			result.BundleValidator = nil
+
+			if !reflect.DeepEqual(tc.expected, result) {
+				t.Error(cmp.Diff(tc.expected, result))
+			}
+			if len(vsc.warnings) > 0 {
+				t.Errorf("generatePolicies() returned unexpected warnings %v for the case of %s", vsc.warnings, tc.msg)
+			}
+		})
+	}
+}
+
+func TestGeneratePoliciesAuthorization(t *testing.T) {
+	t.Parallel()
+	ownerDetails := policyOwnerDetails{
+		owner:          nil, // nil is OK for the unit test
+		ownerNamespace: "default",
+		vsNamespace:    "default",
+		vsName:         "test",
+	}
+	policyRefs := []conf_v1.PolicyReference{
+		{
+			Name:      "authz-policy",
+			Namespace: "default",
+		},
+	}
+	policies := map[string]*conf_v1.Policy{
+		"default/authz-policy": {
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "authz-policy",
+				Namespace: "default",
+			},
+			Spec: conf_v1.PolicySpec{
+				Authorization: &conf_v1.Authorization{
+					AllOf: []conf_v1.ClaimRequirement{
+						{Claim: "scope", Values: []string{"orders:read"}},
+					},
+					AnyOf: []conf_v1.ClaimRequirement{
+						{Claim: "realm_access.roles", Values: []string{"admin", "support"}},
+					},
+				},
+			},
+		},
+	}
+	// the JWT is authenticated by policies in the VirtualServer spec
+	policyOpts := policyOptions{
+		authenticated: true,
+	}
+
+	tests := []struct {
+		isPlus   bool
+		expected authorization
+		msg      string
+	}{
+		{
+			isPlus: false,
+			expected: authorization{
+				Auth: &version2.Authorization{
+					Requirements: `{"allOf":[{"claim":"scope","values":["orders:read"]}],"anyOf":[{"claim":"realm_access.roles","values":["admin","support"]}]}`,
+					RejectCode:   403,
+				},
+			},
+			msg: "nginx",
+		},
+		{
+			isPlus: true,
+			expected: authorization{
+				Auth: &version2.Authorization{
+					Variable:   "$authz_default_authz_policy_default_test",
+					RejectCode: 403,
+				},
+				Maps: []version2.Map{
+					{
+						Source:   "$jwt_default_test_scope",
+						Variable: "$authz_default_authz_policy_default_test_all_0",
+						Parameters: []version2.Parameter{
+							{Value: "default", Result: "0"},
+							{Value: `"~(^|[ ,])orders:read([ ,]|$)"`, Result: "1"},
+						},
+					},
+					{
+						Source:   "$jwt_default_test_realm_access_roles",
+						Variable: "$authz_default_authz_policy_default_test_any_0",
+						Parameters: []version2.Parameter{
+							{Value: "default", Result: "0"},
+							{Value: `"~(^|[ ,])admin([ ,]|$)"`, Result: "1"},
+							{Value: `"~(^|[ ,])support([ ,]|$)"`, Result: "1"},
+						},
+					},
+					{
+						Source:   `"${authz_default_authz_policy_default_test_all_0}:${authz_default_authz_policy_default_test_any_0}"`,
+						Variable: "$authz_default_authz_policy_default_test",
+						Parameters: []version2.Parameter{
+							{Value: "default", Result: "0"},
+							{Value: `"~^1:.*1"`, Result: "1"},
+						},
+					},
+				},
+				AuthJWTClaimSets: []version2.AuthJWTClaimSet{
+					{Variable: "$jwt_default_test_scope", Claim: "scope"},
+					{Variable: "$jwt_default_test_realm_access_roles", Claim: "realm_access roles"},
+				},
+			},
+			msg: "nginx plus",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.msg, func(t *testing.T) {
+			vsc := newVirtualServerConfigurator(&ConfigParams{Context: ctx}, tc.isPlus, false, &StaticConfigParams{}, false, &fakeBV)
+
+			result := vsc.generatePolicies(ownerDetails, policyRefs, policies, routeContext, "/orders", policyOpts)
+
+			if !reflect.DeepEqual(tc.expected, result.Authorization) {
+				t.Error(cmp.Diff(tc.expected, result.Authorization))
+			}
+			if len(vsc.warnings) > 0 {
+				t.Errorf("generatePolicies() returned unexpected warnings %v for the case of %s", vsc.warnings, tc.msg)
+			}
+		})
+	}
+}
+
+func TestGeneratePoliciesJWTIssuerAndAudienceForPlus(t *testing.T) {
+	t.Parallel()
+	ownerDetails := policyOwnerDetails{
+		owner:          nil, // nil is OK for the unit test
+		ownerNamespace: "default",
+		vsNamespace:    "default",
+		vsName:         "test",
+	}
+	policyRefs := []conf_v1.PolicyReference{
+		{
+			Name:      "jwt-policy",
+			Namespace: "default",
+		},
+	}
+	policies := map[string]*conf_v1.Policy{
+		"default/jwt-policy": {
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "jwt-policy",
+				Namespace: "default",
+			},
+			Spec: conf_v1.PolicySpec{
+				JWTAuth: &conf_v1.JWTAuth{
+					Realm:    "My Test API",
+					Secret:   "jwt-secret",
+					Issuer:   "https://idp.example.com",
+					Audience: []string{"orders", "payments"},
+				},
+			},
+		},
+	}
+	policyOpts := policyOptions{
+		secretRefs: map[string]*secrets.SecretReference{
+			"default/jwt-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeJWK,
+				},
+				Path: "/etc/nginx/secrets/default-jwt-secret",
+			},
+		},
+	}
+
+	expectedRequire := []string{"$jwt_default_jwt_policy_default_test_iss", "$jwt_default_jwt_policy_default_test_aud"}
+	expectedMaps := []version2.Map{
+		{
+			Source:   "$jwt_claim_iss",
+			Variable: "$jwt_default_jwt_policy_default_test_iss",
+			Parameters: []version2.Parameter{
+				{Value: "default", Result: "0"},
+				{Value: `"~^https://idp\.example\.com$"`, Result: "1"},
+			},
+		},
+		{
+			Source:   "$jwt_claim_aud",
+			Variable: "$jwt_default_jwt_policy_default_test_aud",
+			Parameters: []version2.Parameter{
+				{Value: "default", Result: "0"},
+				{Value: `"~(^|,)orders(,|$)"`, Result: "1"},
+				{Value: `"~(^|,)payments(,|$)"`, Result: "1"},
+			},
+		},
+	}
+
+	vsc := newVirtualServerConfigurator(&ConfigParams{Context: ctx}, true, false, &StaticConfigParams{}, false, &fakeBV)
+
+	result := vsc.generatePolicies(ownerDetails, policyRefs, policies, routeContext, "/orders", policyOpts)
+
+	if result.JWTAuth.Auth == nil {
+		t.Fatal("generatePolicies() returned no JWT configuration")
+	}
+	if !reflect.DeepEqual(expectedRequire, result.JWTAuth.Auth.Require) {
+		t.Error(cmp.Diff(expectedRequire, result.JWTAuth.Auth.Require))
+	}
+	if !reflect.DeepEqual(expectedMaps, result.JWTAuth.Maps) {
+		t.Error(cmp.Diff(expectedMaps, result.JWTAuth.Maps))
+	}
+	if len(vsc.warnings) > 0 {
+		t.Errorf("generatePolicies() returned unexpected warnings %v", vsc.warnings)
+	}
+}
+
+func TestGenerateJWTClaimVariables(t *testing.T) {
+	t.Parallel()
+	vsEx := &VirtualServerEx{
+		VirtualServer: &conf_v1.VirtualServer{
+			Spec: conf_v1.VirtualServerSpec{
+				ServerSnippets: "add_header X-Org $jwt_claim_org;",
+				Routes: []conf_v1.Route{
+					{
+						Path: "/tea",
+						Action: &conf_v1.Action{
+							Proxy: &conf_v1.ActionProxy{
+								Upstream: "tea",
+								RequestHeaders: &conf_v1.ProxyRequestHeaders{
+									Set: []conf_v1.Header{
+										{Name: "X-Tenant", Value: "${jwt_claim_tenant}"},
+										{Name: "X-Subject", Value: "${jwt_claim_sub}"},
+									},
+								},
+							},
 						},
 					},
 				},
 			},
-			expected: policiesCfg{
-				Context: ctx,
-				RateLimit: rateLimit{
-					Reqs: []version2.LimitReq{
-						{
-							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
-						},
-					},
-					Zones: []version2.LimitReqZone{
+		},
+		VirtualServerRoutes: []*conf_v1.VirtualServerRoute{
+			{
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Subroutes: []conf_v1.Route{
 						{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
+							Path:             "/coffee",
+							LocationSnippets: "add_header X-Role $jwt_claim_role;",
+							Matches: []conf_v1.Match{
+								{
+									Splits: []conf_v1.Split{
+										{
+											Weight: 100,
+											Action: &conf_v1.Action{
+												Proxy: &conf_v1.ActionProxy{
+													Upstream: "coffee",
+													ResponseHeaders: &conf_v1.ProxyResponseHeaders{
+														Add: []conf_v1.AddHeader{
+															{Header: conf_v1.Header{Name: "X-Email", Value: "${jwt_claim_email} ${jwt_claim_tenant}"}},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+							Action: &conf_v1.Action{
+								Return: &conf_v1.ActionReturn{
+									Body: "${jwt_claim_ignored}",
+								},
+							},
 						},
 					},
-					Options: version2.LimitReqOptions{
-						LogLevel:   "notice",
-						RejectCode: 503,
-					},
 				},
 			},
-			msg: "rate limit reference",
 		},
+	}
+
+	tests := []struct {
+		enableSnippets bool
+		expected       []string
+		msg            string
+	}{
 		{
-			policyRefs: []conf_v1.PolicyReference{
-				{
-					Name:      "rateLimit-policy",
-					Namespace: "default",
-				},
-				{
-					Name:      "rateLimit-policy2",
-					Namespace: "default",
-				},
+			enableSnippets: true,
+			expected:       []string{"$jwt_claim_email", "$jwt_claim_org", "$jwt_claim_role", "$jwt_claim_tenant"},
+			msg:            "snippets enabled",
+		},
+		{
+			enableSnippets: false,
+			expected:       []string{"$jwt_claim_email", "$jwt_claim_tenant"},
+			msg:            "snippets disabled",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateJWTClaimVariables(vsEx, test.enableSnippets)
+		if !reflect.DeepEqual(test.expected, result) {
+			t.Errorf("generateJWTClaimVariables() mismatch for %s (-want +got):\n%s", test.msg, cmp.Diff(test.expected, result))
+		}
+	}
+}
+
+func TestGeneratePoliciesOIDCClaimHeaders(t *testing.T) {
+	t.Parallel()
+	ownerDetails := policyOwnerDetails{
+		owner:          nil, // nil is OK for the unit test
+		ownerNamespace: "default",
+		vsNamespace:    "default",
+		vsName:         "test",
+	}
+	policyRefs := []conf_v1.PolicyReference{
+		{
+			Name:      "oidc-policy",
+			Namespace: "default",
+		},
+	}
+	policies := map[string]*conf_v1.Policy{
+		"default/oidc-policy": {
+			ObjectMeta: meta_v1.ObjectMeta{
+				Name:      "oidc-policy",
+				Namespace: "default",
 			},
-			policies: map[string]*conf_v1.Policy{
-				"default/rateLimit-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-policy",
-						Namespace: "default",
-					},
-					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-						},
-					},
-				},
-				"default/rateLimit-policy2": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-policy2",
-						Namespace: "default",
-					},
-					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test2",
-							ZoneSize: "20M",
-							Rate:     "20r/s",
-						},
+			Spec: conf_v1.PolicySpec{
+				OIDC: &conf_v1.OIDC{
+					AuthEndpoint:  "http://example.com/auth",
+					TokenEndpoint: "http://example.com/token",
+					JWKSURI:       "http://example.com/jwks",
+					ClientID:      "client-id",
+					ClientSecret:  "oidc-secret",
+					ClaimHeaders: []conf_v1.ClaimHeader{
+						{Claim: "email", Header: "X-User-Email"},
+						{Claim: "realm_access.roles", Header: "X-User-Roles"},
 					},
+					RefreshLeeway:        createPointerFromInt(60),
+					BackchannelLogoutURI: "/back_channel_logout",
 				},
 			},
-			expected: policiesCfg{
-				Context: ctx,
-				RateLimit: rateLimit{
-					Zones: []version2.LimitReqZone{
-						{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
-						},
-						{
-							Key:      "test2",
-							ZoneSize: "20M",
-							Rate:     "20r/s",
-							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
-						},
-					},
-					Options: version2.LimitReqOptions{
-						LogLevel:   "error",
-						RejectCode: 503,
-					},
-					Reqs: []version2.LimitReq{
-						{
-							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
-						},
-						{
-							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
-						},
+		},
+	}
+	policyOpts := policyOptions{
+		secretRefs: map[string]*secrets.SecretReference{
+			"default/oidc-secret": {
+				Secret: &api_v1.Secret{
+					Type: secrets.SecretTypeOIDC,
+					Data: map[string][]byte{
+						"client-secret": []byte("super_secret_123"),
+						"session-key":   []byte("5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e"),
 					},
 				},
 			},
-			msg: "multi rate limit reference",
 		},
+	}
+
+	tests := []struct {
+		isPlus   bool
+		expected []version2.OIDCClaimHeader
+		msg      string
+	}{
+		{
+			isPlus: false,
+			expected: []version2.OIDCClaimHeader{
+				{Header: "X-User-Email", Claim: "email", Variable: "$oidc_claim_email"},
+				{Header: "X-User-Roles", Claim: "realm_access.roles", Variable: "$oidc_claim_realm_access_roles"},
+			},
+			msg: "nginx",
+		},
+		{
+			isPlus: true,
+			expected: []version2.OIDCClaimHeader{
+				{Header: "X-User-Email", Claim: "email", Variable: "$jwt_default_test_email"},
+				{Header: "X-User-Roles", Claim: "realm_access.roles", Variable: "$jwt_default_test_realm_access_roles"},
+			},
+			msg: "nginx plus",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.msg, func(t *testing.T) {
+			vsc := newVirtualServerConfigurator(&ConfigParams{Context: ctx}, tc.isPlus, false, &StaticConfigParams{}, false, &fakeBV)
+
+			result := vsc.generatePolicies(ownerDetails, policyRefs, policies, specContext, "/", policyOpts)
+
+			if !result.OIDC {
+				t.Fatalf("generatePolicies() did not enable OIDC for the case of %s, warnings %v", tc.msg, vsc.warnings)
+			}
+			oidc := vsc.oidcPolCfg.oidc
+			if !reflect.DeepEqual(tc.expected, oidc.ClaimHeaders) {
+				t.Error(cmp.Diff(tc.expected, oidc.ClaimHeaders))
+			}
+			if oidc.RefreshLeeway != 60 || oidc.BackchannelLogoutURI != "/back_channel_logout" {
+				t.Errorf("generatePolicies() returned refresh leeway %d and back-channel logout URI %q for the case of %s", oidc.RefreshLeeway, oidc.BackchannelLogoutURI, tc.msg)
+			}
+		})
+	}
+}
+
+func TestGenerateAPIKeyClients(t *testing.T) {
+	t.Parallel()
+	secretData := map[string][]byte{
+		"client2": []byte("sha256:5E884898DA28047151D0E56F8DC6292773603D0D6AABBDD62A11EF721D1542D8"),
+		"client1": []byte("password"),
+	}
+	tiers := []conf_v1.APIKeyTier{
+		{
+			Name:      "premium",
+			ClientIDs: []string{"client2"},
+		},
+	}
+
+	want := []apiKeyClient{
+		{
+			ClientID:  "client1",
+			HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+		},
+		{
+			ClientID:  "client2",
+			HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+			Tier:      "premium",
+		},
+	}
+
+	got := generateAPIKeyClients(secretData, tiers)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestGenerateAPIKeyTierMap(t *testing.T) {
+	t.Parallel()
+	clients := []apiKeyClient{
+		{
+			ClientID:  "client1",
+			HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+		},
+		{
+			ClientID:  "client2",
+			HashedKey: "6cf615d5bcaac778352a8f1f3360d23f02f34ec182e259897fd6ce485d7870d4",
+			Tier:      "premium",
+		},
+	}
+
+	want := &version2.Map{
+		Source:   "$apikey_auth_client_name_default_cafe_api_key_policy",
+		Variable: "$apikey_auth_client_name_default_cafe_api_key_policy_tier",
+		Parameters: []version2.Parameter{
+			{
+				Value:  "default",
+				Result: "\"\"",
+			},
+			{
+				Value:  "\"client2\"",
+				Result: "\"premium\"",
+			},
+		},
+	}
+
+	got := generateAPIKeyTierMap("apikey_auth_client_name_default_cafe_api_key_policy", clients)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	if got := generateAPIKeyTierMap("apikey_auth_client_name_default_cafe_api_key_policy", clients[:1]); got != nil {
+		t.Errorf("generateAPIKeyTierMap() returned %v for clients without tiers, want nil", got)
+	}
+}
+
+func TestGenerateSignatureValidationLocations(t *testing.T) {
+	t.Parallel()
+	specValidation := &version2.SignatureValidation{
+		KeyFile:   "/etc/nginx/secrets/default-spec-hmac-secret",
+		Format:    "plain",
+		Algorithm: "sha256",
+		Header:    "X-Hub-Signature-256",
+	}
+	routeValidation := &version2.SignatureValidation{
+		KeyFile:   "/etc/nginx/secrets/default-route-hmac-secret",
+		Format:    "slack",
+		Algorithm: "sha256",
+		Header:    "X-Slack-Signature",
+	}
+
+	locations := []version2.Location{
+		{
+			Path:                "/webhooks/slack",
+			ProxyPass:           "http://vs_default_cafe_slack",
+			ProxyPassRewrite:    "/events",
+			ClientMaxBodySize:   "2m",
+			SignatureValidation: routeValidation,
+			Allow:               []string{"10.0.0.0/8"},
+			LimitReqs:           []version2.LimitReq{{ZoneName: "pol_rl_default_rate-limit_default_cafe", Burst: 5}},
+			LimitReqOptions:     version2.LimitReqOptions{RejectCode: 429},
+			LimitConn:           &version2.LimitConn{ZoneName: "pol_cl_default_conn-limit_default_cafe", Connections: 10},
+		},
+		{
+			Path:              "= /webhooks/github",
+			ProxyPass:         "http://vs_default_cafe_github",
+			ProxyPassRewrite:  "/push",
+			ClientMaxBodySize: "0",
+		},
+		{
+			Path:              "/internal_location_splits_0_split_0",
+			Internal:          true,
+			ProxyPass:         "http://vs_default_cafe_v1$request_uri",
+			ClientMaxBodySize: "1m",
+		},
+		{
+			Path:                 "/coffee",
+			ProxyInterceptErrors: true,
+			InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
+			ErrorPages: []version2.ErrorPage{
+				{Name: "@return_0", Codes: "418", ResponseCode: 200},
+			},
+		},
+		{
+			Path:                "/error",
+			PoliciesErrorReturn: &version2.Return{Code: 500},
+		},
+	}
+
+	wantLocations := []version2.Location{
+		{
+			Path:                "@signature_validation_0",
+			ProxyPass:           "http://vs_default_cafe_slack",
+			Rewrites:            []string{`"^/webhooks/slack(.*)$" "/events$1" break`},
+			ClientMaxBodySize:   "2m",
+			SignatureValidation: routeValidation,
+			Allow:               []string{"10.0.0.0/8"},
+			LimitReqs:           []version2.LimitReq{{ZoneName: "pol_rl_default_rate-limit_default_cafe", Burst: 5}},
+			LimitReqOptions:     version2.LimitReqOptions{RejectCode: 429},
+			LimitConn:           &version2.LimitConn{ZoneName: "pol_cl_default_conn-limit_default_cafe", Connections: 10},
+		},
+		{
+			Path:              "@signature_validation_1",
+			ProxyPass:         "http://vs_default_cafe_github",
+			Rewrites:          []string{`"^/webhooks/github$" "/push" break`},
+			ClientMaxBodySize: "0",
+		},
+		{
+			Path:              "@signature_validation_2",
+			ProxyPass:         "http://vs_default_cafe_v1$request_uri",
+			ClientMaxBodySize: "1m",
+		},
+		{
+			Path:                 "@signature_validation_3",
+			ProxyInterceptErrors: true,
+			InternalProxyPass:    "http://unix:/var/lib/nginx/nginx-418-server.sock",
+			ErrorPages: []version2.ErrorPage{
+				{Name: "@return_0", Codes: "418", ResponseCode: 200},
+			},
+		},
+		{
+			Path:                "/error",
+			PoliciesErrorReturn: &version2.Return{Code: 500},
+		},
+	}
+	want := []version2.SignatureValidationLocation{
+		{
+			Path:              "/webhooks/slack",
+			NamedLocation:     "@signature_validation_0",
+			ClientMaxBodySize: "2m",
+			BodyBufferSize:    "2m",
+			Validation:        routeValidation,
+			Allow:             []string{"10.0.0.0/8"},
+			LimitReqs:         []version2.LimitReq{{ZoneName: "pol_rl_default_rate-limit_default_cafe", Burst: 5}},
+			LimitReqOptions:   version2.LimitReqOptions{RejectCode: 429},
+			LimitConn:         &version2.LimitConn{ZoneName: "pol_cl_default_conn-limit_default_cafe", Connections: 10},
+		},
+		{
+			Path:              "= /webhooks/github",
+			NamedLocation:     "@signature_validation_1",
+			ClientMaxBodySize: "0",
+			BodyBufferSize:    "1m",
+			Validation:        specValidation,
+		},
+		{
+			Path:              "/internal_location_splits_0_split_0",
+			Internal:          true,
+			NamedLocation:     "@signature_validation_2",
+			ClientMaxBodySize: "1m",
+			BodyBufferSize:    "1m",
+			Validation:        specValidation,
+		},
+		{
+			Path:              "/coffee",
+			NamedLocation:     "@signature_validation_3",
+			ClientMaxBodySize: "4m",
+			BodyBufferSize:    "4m",
+			Validation:        specValidation,
+		},
+	}
+
+	got := generateSignatureValidationLocations(locations, specValidation, "4m")
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if !cmp.Equal(wantLocations, locations) {
+		t.Error(cmp.Diff(wantLocations, locations))
+	}
+}
+
+func TestGeneratePolicies_GeneratesWAFPolicyOnValidApBundle(t *testing.T) {
+	t.Parallel()
+
+	ownerDetails := policyOwnerDetails{
+		owner:          nil, // nil is OK for the unit test
+		ownerNamespace: "default",
+		vsNamespace:    "default",
+		vsName:         "test",
+	}
+
+	tests := []struct {
+		name       string
+		policyRefs []conf_v1.PolicyReference
+		policies   map[string]*conf_v1.Policy
+		policyOpts policyOptions
+		context    string
+		path       string
+		want       policiesCfg
+	}{
 		{
+			name: "valid bundle",
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "rateLimitScale-policy",
+					Name:      "waf-bundle",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/rateLimitScale-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimitScale-policy",
-						Namespace: "default",
-					},
+				"default/waf-bundle": {
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-							LogLevel: "notice",
-							Scale:    true,
+						WAF: &conf_v1.WAF{
+							Enable:   true,
+							ApBundle: "testWAFPolicyBundle.tgz",
 						},
 					},
 				},
 			},
-			expected: policiesCfg{
+			context: "route",
+			want: policiesCfg{
 				Context: ctx,
-				RateLimit: rateLimit{
-					Zones: []version2.LimitReqZone{
-						{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "5r/s",
-							ZoneName: "pol_rl_default_rateLimitScale_policy_default_test",
-						},
-					},
-					Options: version2.LimitReqOptions{
-						LogLevel:   "notice",
-						RejectCode: 503,
-					},
-					Reqs: []version2.LimitReq{
-						{
-							ZoneName: "pol_rl_default_rateLimitScale_policy_default_test",
-						},
-					},
+				WAF: &version2.WAF{
+					Enable:   "on",
+					ApBundle: "/fake/bundle/path/testWAFPolicyBundle.tgz",
 				},
 			},
-			msg: "rate limit reference with scale",
 		},
 		{
+			name: "valid bundle with logConf",
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "rateLimit-basic-policy",
-					Namespace: "default",
-				},
-				{
-					Name:      "rateLimit-premium-policy",
+					Name:      "waf-bundle",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/rateLimit-basic-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-basic-policy",
-						Namespace: "default",
-					},
-					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "$apikey_client_name",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-							LogLevel: "notice",
-							Condition: &conf_v1.RateLimitCondition{
-								Variables: &[]conf_v1.VariableCondition{
-									{
-										Name:  "$apikey_client_name",
-										Match: "basic",
-									},
-								},
-								Default: true,
-							},
-						},
-					},
-				},
-				"default/rateLimit-premium-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-premium-policy",
-						Namespace: "default",
-					},
+				"default/waf-bundle": {
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "$apikey_client_name",
-							ZoneSize: "10M",
-							Rate:     "100r/s",
-							LogLevel: "notice",
-							Condition: &conf_v1.RateLimitCondition{
-								Variables: &[]conf_v1.VariableCondition{
-									{
-										Name:  "$apikey_client_name",
-										Match: "premium",
-									},
+						WAF: &conf_v1.WAF{
+							Enable:   true,
+							ApBundle: "testWAFPolicyBundle.tgz",
+							SecurityLogs: []*conf_v1.SecurityLog{
+								{
+									Enable:      true,
+									ApLogBundle: "secops_dashboard.tgz",
 								},
 							},
 						},
@@ -11730,744 +13860,836 @@ func TestGeneratePolicies(t *testing.T) {
 				},
 			},
 			context: "route",
-			path:    "/coffee",
-			expected: policiesCfg{
+			want: policiesCfg{
 				Context: ctx,
-				RateLimit: rateLimit{
-					Zones: []version2.LimitReqZone{
-						{
-							Key:           "$pol_rl_default_rateLimit_basic_policy_default_test",
-							ZoneSize:      "10M",
-							Rate:          "10r/s",
-							ZoneName:      "pol_rl_default_rateLimit_basic_policy_default_test",
-							GroupValue:    `"basic"`,
-							GroupVariable: "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
-							PolicyValue:   "rl_default_test_match_ratelimit_basic_policy",
-							PolicyResult:  "$apikey_client_name",
-							GroupSource:   "$apikey_client_name",
-							GroupDefault:  true,
-						},
-						{
-							Key:           "$pol_rl_default_rateLimit_premium_policy_default_test",
-							ZoneSize:      "10M",
-							Rate:          "100r/s",
-							ZoneName:      "pol_rl_default_rateLimit_premium_policy_default_test",
-							GroupValue:    `"premium"`,
-							GroupVariable: "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
-							PolicyValue:   "rl_default_test_match_ratelimit_premium_policy",
-							PolicyResult:  "$apikey_client_name",
-							GroupSource:   "$apikey_client_name",
-						},
-					},
-					Options: version2.LimitReqOptions{
-						LogLevel:   "notice",
-						RejectCode: 503,
-					},
-					Reqs: []version2.LimitReq{
-						{
-							ZoneName: "pol_rl_default_rateLimit_basic_policy_default_test",
-						},
-						{
-							ZoneName: "pol_rl_default_rateLimit_premium_policy_default_test",
-						},
-					},
-					GroupMaps: []version2.Map{
-						{
-							Source:   "$apikey_client_name",
-							Variable: "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
-							Parameters: []version2.Parameter{
-								{Value: `"premium"`, Result: "rl_default_test_match_ratelimit_premium_policy"},
-								{Value: `"basic"`, Result: "rl_default_test_match_ratelimit_basic_policy"},
-								{Value: "default", Result: "rl_default_test_match_ratelimit_basic_policy"},
-							},
-						},
-					},
-					PolicyGroupMaps: []version2.Map{
-						{
-							Source:   "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
-							Variable: "$pol_rl_default_rateLimit_basic_policy_default_test",
-							Parameters: []version2.Parameter{
-								{Value: "default", Result: "''"},
-								{Value: "rl_default_test_match_ratelimit_basic_policy", Result: "Val$apikey_client_name"},
-							},
-						},
-						{
-							Source:   "$rl_default_test_variable_apikey_client_name_route_L2NvZmZlZQ",
-							Variable: "$pol_rl_default_rateLimit_premium_policy_default_test",
-							Parameters: []version2.Parameter{
-								{Value: "default", Result: "''"},
-								{Value: "rl_default_test_match_ratelimit_premium_policy", Result: "Val$apikey_client_name"},
-							},
-						},
-					},
+				WAF: &version2.WAF{
+					Enable:              "on",
+					ApBundle:            "/fake/bundle/path/testWAFPolicyBundle.tgz",
+					ApSecurityLogEnable: true,
+					ApLogConf:           []string{"/fake/bundle/path/secops_dashboard.tgz syslog:server=localhost:514"},
 				},
 			},
-			msg: "tiered rate limits",
 		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vsc := newVirtualServerConfigurator(&ConfigParams{Context: ctx}, false, false, &StaticConfigParams{}, false, &fakeBV)
+			res := vsc.generatePolicies(ownerDetails, tc.policyRefs, tc.policies, tc.context, tc.path, policyOptions{apResources: &appProtectResourcesForVS{}})
+			res.BundleValidator = nil
+			if !reflect.DeepEqual(tc.want, res) {
+				t.Error(cmp.Diff(tc.want, res))
+			}
+		})
+	}
+}
+
+func TestGeneratePoliciesFails(t *testing.T) {
+	t.Parallel()
+	ownerDetails := policyOwnerDetails{
+		owner:          nil, // nil is OK for the unit test
+		ownerName:      "test",
+		ownerNamespace: "default",
+		vsNamespace:    "default",
+		vsName:         "test",
+	}
+
+	dryRunOverride := true
+	rejectCodeOverride := 505
+
+	ingressMTLSCertPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt"
+	ingressMTLSCrlPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crl"
+
+	tests := []struct {
+		policyRefs        []conf_v1.PolicyReference
+		policies          map[string]*conf_v1.Policy
+		policyOpts        policyOptions
+		trustedCAFileName string
+		context           string
+		path              string
+		oidcPolCfg        *oidcPolicyCfg
+		expected          policiesCfg
+		expectedWarnings  Warnings
+		expectedOidc      *oidcPolicyCfg
+		msg               string
+	}{
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "jwt-policy",
+					Name:      "allow-policy",
 					Namespace: "default",
 				},
 			},
+			policies:   map[string]*conf_v1.Policy{},
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					"Policy default/allow-policy is missing or invalid",
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "missing policy",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name: "allow-policy",
+				},
+				{
+					Name: "deny-policy",
+				},
+			},
 			policies: map[string]*conf_v1.Policy{
-				"default/jwt-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy",
-						Namespace: "default",
+				"default/allow-policy": {
+					Spec: conf_v1.PolicySpec{
+						AccessControl: &conf_v1.AccessControl{
+							Allow: []string{"127.0.0.1"},
+						},
 					},
+				},
+				"default/deny-policy": {
 					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "My Test API",
-							Secret: "jwt-secret",
+						AccessControl: &conf_v1.AccessControl{
+							Deny: []string{"127.0.0.2"},
 						},
 					},
 				},
 			},
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
 				Context: ctx,
-				JWTAuth: jwtAuth{
-					Auth: &version2.JWTAuth{
-						Secret: "/etc/nginx/secrets/default-jwt-secret",
-						Realm:  "My Test API",
-					},
-					JWKSEnabled: false,
+				Allow:   []string{"127.0.0.1"},
+				Deny:    []string{"127.0.0.2"},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					"AccessControl policy (or policies) with deny rules is overridden by policy (or policies) with allow rules",
 				},
 			},
-			msg: "jwt reference",
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "conflicting policies",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "jwt-policy-2",
+					Name:      "rateLimit-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "rateLimit-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/jwt-policy-2": {
+				"default/rateLimit-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy-2",
+						Name:      "rateLimit-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:    "My Test API",
-							JwksURI:  "https://idp.example.com:443/keys",
-							KeyCache: "1h",
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+						},
+					},
+				},
+				"default/rateLimit-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "rateLimit-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:        "test2",
+							ZoneSize:   "20M",
+							Rate:       "20r/s",
+							DryRun:     &dryRunOverride,
+							LogLevel:   "info",
+							RejectCode: &rejectCodeOverride,
 						},
 					},
 				},
 			},
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
 				Context: ctx,
-				JWTAuth: jwtAuth{
-					Auth: &version2.JWTAuth{
-						Key:   "default/jwt-policy-2",
-						Realm: "My Test API",
-						JwksURI: version2.JwksURI{
-							JwksScheme: "https",
-							JwksHost:   "idp.example.com",
-							JwksPort:   "443",
-							JwksPath:   "/keys",
+				RateLimit: rateLimit{
+					Zones: []version2.LimitReqZone{
+						{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
+						},
+						{
+							Key:      "test2",
+							ZoneSize: "20M",
+							Rate:     "20r/s",
+							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
+						},
+					},
+					Options: version2.LimitReqOptions{
+						LogLevel:   "error",
+						RejectCode: 503,
+					},
+					Reqs: []version2.LimitReq{
+						{
+							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
+						},
+						{
+							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
 						},
-						KeyCache: "1h",
 					},
-					JWKSEnabled: true,
 				},
 			},
-			msg: "Basic jwks example",
+			expectedWarnings: Warnings{
+				nil: {
+					`RateLimit policy default/rateLimit-policy2 with limit request option dryRun='true' is overridden to dryRun='false' by the first policy reference in this context`,
+					`RateLimit policy default/rateLimit-policy2 with limit request option logLevel='info' is overridden to logLevel='error' by the first policy reference in this context`,
+					`RateLimit policy default/rateLimit-policy2 with limit request option rejectCode='505' is overridden to rejectCode='503' by the first policy reference in this context`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "rate limit policy limit request option override",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "jwt-policy-2",
+					Name:      "rateLimit-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "rateLimit-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/jwt-policy-2": {
+				"default/rateLimit-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy-2",
+						Name:      "rateLimit-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:    "My Test API",
-							JwksURI:  "https://idp.example.com/keys",
-							KeyCache: "1h",
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test",
+							ZoneSize: "10M",
+							Rate:     "10r/s",
+							Condition: &conf_v1.RateLimitCondition{
+								JWT: &conf_v1.JWTCondition{
+									Match: "Basic",
+									Claim: "user_details.level",
+								},
+								Default: true,
+							},
 						},
 					},
 				},
-			},
-			expected: policiesCfg{
-				Context: ctx,
-				JWTAuth: jwtAuth{
-					Auth: &version2.JWTAuth{
-						Key:   "default/jwt-policy-2",
-						Realm: "My Test API",
-						JwksURI: version2.JwksURI{
-							JwksScheme: "https",
-							JwksHost:   "idp.example.com",
-							JwksPort:   "",
-							JwksPath:   "/keys",
+				"default/rateLimit-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "rateLimit-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						RateLimit: &conf_v1.RateLimit{
+							Key:      "test2",
+							ZoneSize: "20M",
+							Rate:     "20r/s",
+							Condition: &conf_v1.RateLimitCondition{
+								JWT: &conf_v1.JWTCondition{
+									Match: "Premium",
+									Claim: "user_details.level",
+								},
+								Default: true,
+							},
 						},
-						KeyCache: "1h",
 					},
-					JWKSEnabled: true,
 				},
 			},
-			msg: "Basic jwks example, no port in JwksURI",
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`Tiered rate-limit Policies on [default/test] contain conflicting default values`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "tiered rate limit policy with duplicate defaults",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "basic-auth-policy",
+					Name:      "jwt-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/basic-auth-policy": {
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "My Test API",
-							Secret: "htpasswd-secret",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/jwt-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeJWK,
 						},
+						Error: errors.New("secret is invalid"),
 					},
 				},
 			},
 			expected: policiesCfg{
-				Context: ctx,
-				BasicAuth: &version2.BasicAuth{
-					Secret: "/etc/nginx/secrets/default-htpasswd-secret",
-					Realm:  "My Test API",
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
-			msg: "basic auth reference",
+			expectedWarnings: Warnings{
+				nil: {
+					`JWT policy default/jwt-policy references an invalid secret default/jwt-secret: secret is invalid`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "jwt reference missing secret",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy",
+					Name:      "jwt-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy": {
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret",
-							VerifyClient:     "off",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/jwt-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeCA,
 						},
 					},
 				},
 			},
-			context: "spec",
 			expected: policiesCfg{
-				Context: ctx,
-				IngressMTLS: &version2.IngressMTLS{
-					ClientCert:   mTLSCertPath,
-					VerifyClient: "off",
-					VerifyDepth:  1,
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
-			msg: "ingressMTLS reference",
+			expectedWarnings: Warnings{
+				nil: {
+					`JWT policy default/jwt-policy references a secret default/jwt-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/jwk'`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "jwt references wrong secret type",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy-crl",
+					Name:      "signature-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy-crl": {
+				"default/signature-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy-crl",
+						Name:      "signature-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret-crl",
-							VerifyClient:     "off",
+						SignatureValidation: &conf_v1.SignatureValidation{
+							Secret: "hmac-secret",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/hmac-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeJWK,
 						},
 					},
 				},
 			},
-			context: "spec",
 			expected: policiesCfg{
-				Context: ctx,
-				IngressMTLS: &version2.IngressMTLS{
-					ClientCert:   mTLSCertPath,
-					ClientCrl:    mTLSCrlPath,
-					VerifyClient: "off",
-					VerifyDepth:  1,
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
-			msg: "ingressMTLS reference with ca.crl field in secret",
+			expectedWarnings: Warnings{
+				nil: {
+					`Signature Validation policy default/signature-policy references a secret default/hmac-secret of a wrong type 'nginx.org/jwk', must be 'nginx.org/hmac'`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "signature validation references wrong secret type",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "ingress-mtls-policy-crl",
+					Name:      "jwt-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "jwt-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/ingress-mtls-policy-crl": {
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "ingress-mtls-policy-crl",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						IngressMTLS: &conf_v1.IngressMTLS{
-							ClientCertSecret: "ingress-mtls-secret",
-							CrlFileName:      "default-ingress-mtls-secret-ca.crl",
-							VerifyClient:     "off",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret",
 						},
 					},
 				},
-			},
-			context: "spec",
-			expected: policiesCfg{
-				Context: ctx,
-				IngressMTLS: &version2.IngressMTLS{
-					ClientCert:   mTLSCertPath,
-					ClientCrl:    mTLSCrlPath,
-					VerifyClient: "off",
-					VerifyDepth:  1,
-				},
-			},
-			msg: "ingressMTLS reference with crl field in policy",
-		},
-		{
-			policyRefs: []conf_v1.PolicyReference{
-				{
-					Name:      "egress-mtls-policy",
-					Namespace: "default",
+				"default/jwt-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "jwt-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:  "test",
+							Secret: "jwt-secret2",
+						},
+					},
 				},
 			},
-			policies: map[string]*conf_v1.Policy{
-				"default/egress-mtls-policy": {
-					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TLSSecret:         "egress-mtls-secret",
-							ServerName:        true,
-							SessionReuse:      createPointerFromBool(false),
-							TrustedCertSecret: "egress-trusted-ca-secret",
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/jwt-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeJWK,
+						},
+						Path: "/etc/nginx/secrets/default-jwt-secret",
+					},
+					"default/jwt-secret2": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeJWK,
 						},
+						Path: "/etc/nginx/secrets/default-jwt-secret2",
 					},
 				},
 			},
-			context: "route",
 			expected: policiesCfg{
 				Context: ctx,
-				EgressMTLS: &version2.EgressMTLS{
-					Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
-					CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
-					Ciphers:        "DEFAULT",
-					Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
-					ServerName:     true,
-					SessionReuse:   false,
-					VerifyDepth:    1,
-					VerifyServer:   false,
-					TrustedCert:    "/etc/nginx/secrets/default-egress-trusted-ca-secret",
-					SSLName:        "$proxy_host",
+				JWTAuth: jwtAuth{
+					Enabled: true,
+					Auth: &version2.JWTAuth{
+						Secret: "/etc/nginx/secrets/default-jwt-secret",
+						Realm:  "test",
+					},
 				},
 			},
-			msg: "egressMTLS reference",
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple jwt policies in the same context is not valid. JWT policy default/jwt-policy2 will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi jwt reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "egress-mtls-policy",
+					Name:      "basic-auth-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/egress-mtls-policy": {
+				"default/basic-auth-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "basic-auth-policy",
+						Namespace: "default",
+					},
 					Spec: conf_v1.PolicySpec{
-						EgressMTLS: &conf_v1.EgressMTLS{
-							TLSSecret:         "egress-mtls-secret",
-							ServerName:        true,
-							SessionReuse:      createPointerFromBool(false),
-							TrustedCertSecret: "egress-trusted-ca-secret-crl",
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret",
 						},
 					},
 				},
 			},
-			context: "route",
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/htpasswd-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeHtpasswd,
+						},
+						Error: errors.New("secret is invalid"),
+					},
+				},
+			},
 			expected: policiesCfg{
-				Context: ctx,
-				EgressMTLS: &version2.EgressMTLS{
-					Certificate:    "/etc/nginx/secrets/default-egress-mtls-secret",
-					CertificateKey: "/etc/nginx/secrets/default-egress-mtls-secret",
-					Ciphers:        "DEFAULT",
-					Protocols:      "TLSv1 TLSv1.1 TLSv1.2",
-					ServerName:     true,
-					SessionReuse:   false,
-					VerifyDepth:    1,
-					VerifyServer:   false,
-					TrustedCert:    mTLSCertPath,
-					SSLName:        "$proxy_host",
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
-			msg: "egressMTLS with crt and crl",
+			expectedWarnings: Warnings{
+				nil: {
+					`Basic Auth policy default/basic-auth-policy references an invalid secret default/htpasswd-secret: secret is invalid`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "basic auth reference missing secret",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "oidc-policy",
+					Name:      "basic-auth-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/oidc-policy": {
+				"default/basic-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "oidc-policy",
+						Name:      "basic-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						OIDC: &conf_v1.OIDC{
-							AuthEndpoint:          "http://example.com/auth",
-							TokenEndpoint:         "http://example.com/token",
-							JWKSURI:               "http://example.com/jwks",
-							ClientID:              "client-id",
-							ClientSecret:          "oidc-secret",
-							Scope:                 "scope",
-							RedirectURI:           "/redirect",
-							ZoneSyncLeeway:        createPointerFromInt(20),
-							AccessTokenEnable:     true,
-							EndSessionEndpoint:    "http://example.com/logout",
-							PostLogoutRedirectURI: "/_logout",
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/htpasswd-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeCA,
 						},
 					},
 				},
 			},
 			expected: policiesCfg{
-				Context: ctx,
-				OIDC:    true,
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
 			},
-			msg: "oidc reference",
+			expectedWarnings: Warnings{
+				nil: {
+					`Basic Auth policy default/basic-auth-policy references a secret default/htpasswd-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/htpasswd'`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "basic auth references wrong secret type",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "api-key-policy",
+					Name:      "basic-auth-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "basic-auth-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/api-key-policy": {
+				"default/basic-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "api-key-policy",
+						Name:      "basic-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						APIKey: &conf_v1.APIKey{
-							SuppliedIn: &conf_v1.SuppliedIn{
-								Header: []string{"X-API-Key"},
-								Query:  []string{"api-key"},
-							},
-							ClientSecret: "api-key-secret",
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret",
+						},
+					},
+				},
+				"default/basic-auth-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "basic-auth-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						BasicAuth: &conf_v1.BasicAuth{
+							Realm:  "test",
+							Secret: "htpasswd-secret2",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/htpasswd-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeHtpasswd,
+						},
+						Path: "/etc/nginx/secrets/default-htpasswd-secret",
+					},
+					"default/htpasswd-secret2": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeHtpasswd,
 						},
+						Path: "/etc/nginx/secrets/default-htpasswd-secret2",
 					},
 				},
 			},
 			expected: policiesCfg{
 				Context: ctx,
-				APIKey: apiKeyAuth{
-					Key: &version2.APIKey{
-						Header:  []string{"X-API-Key"},
-						Query:   []string{"api-key"},
-						MapName: "apikey_auth_client_name_default_test_api_key_policy",
-					},
-					Enabled:   true,
-					ClientMap: nil,
-					Clients: []apiKeyClient{
-						{
-							ClientID:  "client1",
-							HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
-						},
-					},
+				BasicAuth: &version2.BasicAuth{
+					Secret: "/etc/nginx/secrets/default-htpasswd-secret",
+					Realm:  "test",
 				},
 			},
-			msg: "api key reference",
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple basic auth policies in the same context is not valid. Basic auth policy default/basic-auth-policy2 will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi basic auth reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "api-key-policy",
+					Name:      "cors-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "cors-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/api-key-policy": {
+				"default/cors-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "api-key-policy",
+						Name:      "cors-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						APIKey: &conf_v1.APIKey{
-							SuppliedIn: &conf_v1.SuppliedIn{
-								Header: []string{"X-API-Key"},
-								Query:  []string{"api-key"},
-							},
-							ClientSecret: "api-key-secret",
+						CORS: &conf_v1.CORS{
+							AllowOrigins: []string{"*"},
 						},
 					},
 				},
-			},
-			expected: policiesCfg{
-				Context: ctx,
-				APIKey: apiKeyAuth{
-					Key: &version2.APIKey{
-						Header:  []string{"X-API-Key"},
-						Query:   []string{"api-key"},
-						MapName: "apikey_auth_client_name_default_test_api_key_policy",
-					},
-					Enabled:   true,
-					ClientMap: nil,
-					Clients: []apiKeyClient{
-						{
-							ClientID:  "client1",
-							HashedKey: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
-						},
+				"default/cors-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cors-policy2",
+						Namespace: "default",
 					},
-				},
-			},
-			msg: "api key same secrets for different policies",
-		},
-		{
-			policyRefs: []conf_v1.PolicyReference{
-				{
-					Name:      "waf-policy",
-					Namespace: "default",
-				},
-			},
-			policies: map[string]*conf_v1.Policy{
-				"default/waf-policy": {
 					Spec: conf_v1.PolicySpec{
-						WAF: &conf_v1.WAF{
-							Enable:   true,
-							ApPolicy: "default/dataguard-alarm",
-							SecurityLog: &conf_v1.SecurityLog{
-								Enable:    true,
-								ApLogConf: "default/logconf",
-								LogDest:   "syslog:server=127.0.0.1:514",
-							},
+						CORS: &conf_v1.CORS{
+							AllowOrigins: []string{"https://example.com"},
 						},
 					},
 				},
 			},
-			context: "route",
-			path:    "/coffee",
+			policyOpts: policyOptions{},
 			expected: policiesCfg{
 				Context: ctx,
-				WAF: &version2.WAF{
-					Enable:              "on",
-					ApPolicy:            "/etc/nginx/waf/nac-policies/default-dataguard-alarm",
-					ApSecurityLogEnable: true,
-					ApLogConf:           []string{"/etc/nginx/waf/nac-logconfs/default-logconf syslog:server=127.0.0.1:514"},
+				CORS: cors{
+					Headers: &version2.CORS{
+						AllowOrigin:  "*",
+						AllowMethods: "GET, HEAD, POST",
+					},
 				},
 			},
-			msg: "WAF reference",
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple cors policies in the same context is not valid. CORS policy default/cors-policy2 will be ignored`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi cors reference",
 		},
-	}
-
-	vsc := newVirtualServerConfigurator(&ConfigParams{Context: ctx}, false, false, &StaticConfigParams{}, false, &fakeBV)
-	// required to test the scaling of the ratelimit
-	vsc.IngressControllerReplicas = 2
-
-	for _, tc := range tests {
-		t.Run(tc.msg, func(t *testing.T) {
-			result := vsc.generatePolicies(ownerDetails, tc.policyRefs, tc.policies, tc.context, tc.path, policyOpts)
-			result.BundleValidator = nil
-
-			if !reflect.DeepEqual(tc.expected, result) {
-				t.Error(cmp.Diff(tc.expected, result))
-			}
-			if len(vsc.warnings) > 0 {
-				t.Errorf("generatePolicies() returned unexpected warnings %v for the case of %s", vsc.warnings, tc.msg)
-			}
-		})
-	}
-}
-
-func TestGeneratePolicies_GeneratesWAFPolicyOnValidApBundle(t *testing.T) {
-	t.Parallel()
-
-	ownerDetails := policyOwnerDetails{
-		owner:          nil, // nil is OK for the unit test
-		ownerNamespace: "default",
-		vsNamespace:    "default",
-		vsName:         "test",
-	}
-
-	tests := []struct {
-		name       string
-		policyRefs []conf_v1.PolicyReference
-		policies   map[string]*conf_v1.Policy
-		policyOpts policyOptions
-		context    string
-		path       string
-		want       policiesCfg
-	}{
 		{
-			name: "valid bundle",
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "waf-bundle",
+					Name:      "cache-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "cache-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/waf-bundle": {
+				"default/cache-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cache-policy",
+						Namespace: "default",
+					},
 					Spec: conf_v1.PolicySpec{
-						WAF: &conf_v1.WAF{
-							Enable:   true,
-							ApBundle: "testWAFPolicyBundle.tgz",
+						Cache: &conf_v1.Cache{},
+					},
+				},
+				"default/cache-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "cache-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						Cache: &conf_v1.Cache{
+							CacheZoneSize: "20m",
 						},
 					},
 				},
 			},
-			context: "route",
-			want: policiesCfg{
+			policyOpts: policyOptions{},
+			expected: policiesCfg{
 				Context: ctx,
-				WAF: &version2.WAF{
-					Enable:   "on",
-					ApBundle: "/fake/bundle/path/testWAFPolicyBundle.tgz",
+				Cache: cache{
+					Cache: &version2.Cache{
+						ZoneName: "pol_cache_default_cache_policy_default_test",
+						Methods:  "GET HEAD",
+					},
+					Zone: &version2.CacheZone{
+						Name: "pol_cache_default_cache_policy_default_test",
+						Path: "/var/cache/nginx/pol_cache_default_cache_policy_default_test",
+						Size: "10m",
+					},
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`Multiple cache policies in the same context is not valid. Cache policy default/cache-policy2 will be ignored`,
 				},
 			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "multi cache reference",
 		},
 		{
-			name: "valid bundle with logConf",
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "waf-bundle",
+					Name:      "fault-policy",
+					Namespace: "default",
+				},
+				{
+					Name:      "fault-policy2",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/waf-bundle": {
+				"default/fault-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "fault-policy",
+						Namespace: "default",
+					},
 					Spec: conf_v1.PolicySpec{
-						WAF: &conf_v1.WAF{
-							Enable:   true,
-							ApBundle: "testWAFPolicyBundle.tgz",
-							SecurityLogs: []*conf_v1.SecurityLog{
-								{
-									Enable:      true,
-									ApLogBundle: "secops_dashboard.tgz",
-								},
-							},
+						FaultInjection: &conf_v1.FaultInjection{
+							Percentage: 100,
+							Status:     503,
 						},
 					},
 				},
-			},
-			context: "route",
-			want: policiesCfg{
-				Context: ctx,
-				WAF: &version2.WAF{
-					Enable:              "on",
-					ApBundle:            "/fake/bundle/path/testWAFPolicyBundle.tgz",
-					ApSecurityLogEnable: true,
-					ApLogConf:           []string{"/fake/bundle/path/secops_dashboard.tgz syslog:server=localhost:514"},
-				},
-			},
-		},
-	}
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			vsc := newVirtualServerConfigurator(&ConfigParams{Context: ctx}, false, false, &StaticConfigParams{}, false, &fakeBV)
-			res := vsc.generatePolicies(ownerDetails, tc.policyRefs, tc.policies, tc.context, tc.path, policyOptions{apResources: &appProtectResourcesForVS{}})
-			res.BundleValidator = nil
-			if !reflect.DeepEqual(tc.want, res) {
-				t.Error(cmp.Diff(tc.want, res))
-			}
-		})
-	}
-}
-
-func TestGeneratePoliciesFails(t *testing.T) {
-	t.Parallel()
-	ownerDetails := policyOwnerDetails{
-		owner:          nil, // nil is OK for the unit test
-		ownerName:      "test",
-		ownerNamespace: "default",
-		vsNamespace:    "default",
-		vsName:         "test",
-	}
-
-	dryRunOverride := true
-	rejectCodeOverride := 505
-
-	ingressMTLSCertPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crt"
-	ingressMTLSCrlPath := "/etc/nginx/secrets/default-ingress-mtls-secret-ca.crl"
-
-	tests := []struct {
-		policyRefs        []conf_v1.PolicyReference
-		policies          map[string]*conf_v1.Policy
-		policyOpts        policyOptions
-		trustedCAFileName string
-		context           string
-		path              string
-		oidcPolCfg        *oidcPolicyCfg
-		expected          policiesCfg
-		expectedWarnings  Warnings
-		expectedOidc      *oidcPolicyCfg
-		msg               string
-	}{
-		{
-			policyRefs: []conf_v1.PolicyReference{
-				{
-					Name:      "allow-policy",
-					Namespace: "default",
+				"default/fault-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "fault-policy2",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						FaultInjection: &conf_v1.FaultInjection{
+							Percentage: 100,
+							Status:     500,
+						},
+					},
 				},
 			},
-			policies:   map[string]*conf_v1.Policy{},
 			policyOpts: policyOptions{},
 			expected: policiesCfg{
-				ErrorReturn: &version2.Return{
-					Code: 500,
+				Context: ctx,
+				FaultInjection: faultInjection{
+					Fault: &version2.FaultInjection{
+						Check:  "${pol_fault_injection_default_fault_policy_default_test_split}",
+						Value:  "1",
+						Status: 503,
+					},
+					SplitClient: &version2.SplitClient{
+						Source:   "$request_id",
+						Variable: "$pol_fault_injection_default_fault_policy_default_test_split",
+						Distributions: []version2.Distribution{
+							{
+								Weight: "100%",
+								Value:  "1",
+							},
+						},
+					},
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					"Policy default/allow-policy is missing or invalid",
+					`Multiple faultInjection policies in the same context is not valid. FaultInjection policy default/fault-policy2 will be ignored`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "missing policy",
+			msg:          "multi fault injection reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name: "allow-policy",
+					Name:      "conn-limit-policy",
+					Namespace: "default",
 				},
 				{
-					Name: "deny-policy",
+					Name:      "conn-limit-policy2",
+					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/allow-policy": {
+				"default/conn-limit-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "conn-limit-policy",
+						Namespace: "default",
+					},
 					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Allow: []string{"127.0.0.1"},
+						ConnectionLimit: &conf_v1.ConnectionLimit{
+							Key:         "${binary_remote_addr}",
+							ZoneSize:    "10M",
+							Connections: 5,
+							RejectCode:  createPointerFromInt(429),
 						},
 					},
 				},
-				"default/deny-policy": {
+				"default/conn-limit-policy2": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "conn-limit-policy2",
+						Namespace: "default",
+					},
 					Spec: conf_v1.PolicySpec{
-						AccessControl: &conf_v1.AccessControl{
-							Deny: []string{"127.0.0.2"},
+						ConnectionLimit: &conf_v1.ConnectionLimit{
+							Key:         "${server_addr}",
+							ZoneSize:    "10M",
+							Connections: 100,
 						},
 					},
 				},
@@ -12475,155 +14697,162 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			policyOpts: policyOptions{},
 			expected: policiesCfg{
 				Context: ctx,
-				Allow:   []string{"127.0.0.1"},
-				Deny:    []string{"127.0.0.2"},
+				ConnectionLimit: connectionLimit{
+					Zone: &version2.LimitConnZone{
+						Key:      "${binary_remote_addr}",
+						ZoneName: "pol_cl_default_conn_limit_policy_default_test",
+						ZoneSize: "10M",
+					},
+					Conn: &version2.LimitConn{
+						ZoneName:    "pol_cl_default_conn_limit_policy_default_test",
+						Connections: 5,
+						RejectCode:  429,
+					},
+				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					"AccessControl policy (or policies) with deny rules is overridden by policy (or policies) with allow rules",
+					`Multiple connectionLimit policies in the same context is not valid. ConnectionLimit policy default/conn-limit-policy2 will be ignored`,
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "conflicting policies",
+			msg:          "multi connection limit reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "rateLimit-policy",
+					Name:      "api-key-policy",
 					Namespace: "default",
 				},
 				{
-					Name:      "rateLimit-policy2",
+					Name:      "ext-auth-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/rateLimit-policy": {
+				"default/api-key-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-policy",
+						Name:      "api-key-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
+						APIKey: &conf_v1.APIKey{
+							SuppliedIn: &conf_v1.SuppliedIn{
+								Header: []string{"X-API-Key"},
+							},
+							ClientSecret: "api-key-secret",
 						},
 					},
 				},
-				"default/rateLimit-policy2": {
+				"default/ext-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-policy2",
+						Name:      "ext-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:        "test2",
-							ZoneSize:   "20M",
-							Rate:       "20r/s",
-							DryRun:     &dryRunOverride,
-							LogLevel:   "info",
-							RejectCode: &rejectCodeOverride,
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService: "auth-svc:8080",
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{},
-			expected: policiesCfg{
-				Context: ctx,
-				RateLimit: rateLimit{
-					Zones: []version2.LimitReqZone{
-						{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
-						},
-						{
-							Key:      "test2",
-							ZoneSize: "20M",
-							Rate:     "20r/s",
-							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/api-key-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeAPIKey,
+							Data: map[string][]byte{
+								"client1": []byte("password"),
+							},
 						},
 					},
-					Options: version2.LimitReqOptions{
-						LogLevel:   "error",
-						RejectCode: 503,
+				},
+			},
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					"ExternalAuth and APIKey policies cannot be used together in the same context",
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "api key and external auth references",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "authz-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/authz-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "authz-policy",
+						Namespace: "default",
 					},
-					Reqs: []version2.LimitReq{
-						{
-							ZoneName: "pol_rl_default_rateLimit_policy_default_test",
-						},
-						{
-							ZoneName: "pol_rl_default_rateLimit_policy2_default_test",
+					Spec: conf_v1.PolicySpec{
+						Authorization: &conf_v1.Authorization{
+							AllOf: []conf_v1.ClaimRequirement{
+								{Claim: "scope", Values: []string{"read"}},
+							},
 						},
 					},
 				},
 			},
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
 			expectedWarnings: Warnings{
 				nil: {
-					`RateLimit policy default/rateLimit-policy2 with limit request option dryRun='true' is overridden to dryRun='false' by the first policy reference in this context`,
-					`RateLimit policy default/rateLimit-policy2 with limit request option logLevel='info' is overridden to logLevel='error' by the first policy reference in this context`,
-					`RateLimit policy default/rateLimit-policy2 with limit request option rejectCode='505' is overridden to rejectCode='503' by the first policy reference in this context`,
+					"Authorization policy requires a policy of type jwt in the same context or in the VirtualServer spec",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "rate limit policy limit request option override",
+			msg:          "authorization reference without authentication",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "rateLimit-policy",
+					Name:      "ext-auth-policy",
 					Namespace: "default",
 				},
 				{
-					Name:      "rateLimit-policy2",
+					Name:      "authz-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/rateLimit-policy": {
+				"default/ext-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-policy",
+						Name:      "ext-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test",
-							ZoneSize: "10M",
-							Rate:     "10r/s",
-							Condition: &conf_v1.RateLimitCondition{
-								JWT: &conf_v1.JWTCondition{
-									Match: "Basic",
-									Claim: "user_details.level",
-								},
-								Default: true,
-							},
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService: "auth-svc:8080",
 						},
 					},
 				},
-				"default/rateLimit-policy2": {
+				"default/authz-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "rateLimit-policy2",
+						Name:      "authz-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						RateLimit: &conf_v1.RateLimit{
-							Key:      "test2",
-							ZoneSize: "20M",
-							Rate:     "20r/s",
-							Condition: &conf_v1.RateLimitCondition{
-								JWT: &conf_v1.JWTCondition{
-									Match: "Premium",
-									Claim: "user_details.level",
-								},
-								Default: true,
+						Authorization: &conf_v1.Authorization{
+							AllOf: []conf_v1.ClaimRequirement{
+								{Claim: "scope", Values: []string{"read"}},
 							},
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{},
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -12631,11 +14860,11 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Tiered rate-limit Policies on [default/test] contain conflicting default values`,
+					"Authorization policy requires a policy of type jwt in the same context or in the VirtualServer spec",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "tiered rate limit policy with duplicate defaults",
+			msg:          "authorization reference with an external auth reference",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
@@ -12643,6 +14872,10 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					Name:      "jwt-policy",
 					Namespace: "default",
 				},
+				{
+					Name:      "ext-auth-policy",
+					Namespace: "default",
+				},
 			},
 			policies: map[string]*conf_v1.Policy{
 				"default/jwt-policy": {
@@ -12652,19 +14885,20 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					},
 					Spec: conf_v1.PolicySpec{
 						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret",
+							Realm:   "My Test API",
+							JwksURI: "https://idp.example.com/keys",
 						},
 					},
 				},
-			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/jwt-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeJWK,
+				"default/ext-auth-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "ext-auth-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService: "auth-svc:8080",
 						},
-						Error: errors.New("secret is invalid"),
 					},
 				},
 			},
@@ -12675,20 +14909,35 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`JWT policy default/jwt-policy references an invalid secret default/jwt-secret: secret is invalid`,
+					"JWT policies cannot be used together with ExternalAuth or APIKey policies in the same context",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "jwt reference missing secret",
+			msg:          "jwt and external auth references",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "oidc-policy",
+					Namespace: "default",
+				},
 				{
 					Name:      "jwt-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
+				"default/oidc-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientID: "foo",
+						},
+					},
+				},
 				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
 						Name:      "jwt-policy",
@@ -12696,20 +14945,15 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					},
 					Spec: conf_v1.PolicySpec{
 						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret",
+							Realm:   "My Test API",
+							JwksURI: "https://idp.example.com/keys",
 						},
 					},
 				},
 			},
-			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/jwt-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeCA,
-						},
-					},
-				},
+			oidcPolCfg: &oidcPolicyCfg{
+				oidc: &version2.OIDC{ClientID: "foo"},
+				key:  "default/oidc-policy",
 			},
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
@@ -12718,11 +14962,14 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`JWT policy default/jwt-policy references a secret default/jwt-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/jwk'`,
+					"OIDC policies cannot be used together with JWT, ExternalAuth or APIKey policies in the same context",
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "jwt references wrong secret type",
+			expectedOidc: &oidcPolicyCfg{
+				oidc: &version2.OIDC{ClientID: "foo"},
+				key:  "default/oidc-policy",
+			},
+			msg: "oidc and jwt references",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
@@ -12730,10 +14977,6 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					Name:      "jwt-policy",
 					Namespace: "default",
 				},
-				{
-					Name:      "jwt-policy2",
-					Namespace: "default",
-				},
 			},
 			policies: map[string]*conf_v1.Policy{
 				"default/jwt-policy": {
@@ -12743,87 +14986,93 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					},
 					Spec: conf_v1.PolicySpec{
 						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret",
+							Realm:   "My Test API",
+							JwksURI: "https://idp.example.com/keys",
 						},
 					},
 				},
-				"default/jwt-policy2": {
+			},
+			policyOpts: policyOptions{
+				authRequestPolicies: []string{"externalAuth"},
+			},
+			context: "route",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					"The externalAuth policy of the VirtualServer spec cannot be used together with the jwt policy of a route, because both use auth_request",
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "route jwt reference with spec external auth",
+		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "ext-auth-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/ext-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "jwt-policy2",
+						Name:      "ext-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						JWTAuth: &conf_v1.JWTAuth{
-							Realm:  "test",
-							Secret: "jwt-secret2",
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService: "auth-svc:8080",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/jwt-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeJWK,
-						},
-						Path: "/etc/nginx/secrets/default-jwt-secret",
-					},
-					"default/jwt-secret2": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeJWK,
-						},
-						Path: "/etc/nginx/secrets/default-jwt-secret2",
-					},
-				},
+				authRequestPolicies: []string{"jwt"},
 			},
+			context: "route",
 			expected: policiesCfg{
-				Context: ctx,
-				JWTAuth: jwtAuth{
-					Auth: &version2.JWTAuth{
-						Secret: "/etc/nginx/secrets/default-jwt-secret",
-						Realm:  "test",
-					},
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Multiple jwt policies in the same context is not valid. JWT policy default/jwt-policy2 will be ignored`,
+					"The jwt policy of the VirtualServer spec cannot be used together with the externalAuth policy of a route, because both use auth_request",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "multi jwt reference",
+			msg:          "route external auth reference with spec jwt",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "basic-auth-policy",
+					Name:      "oidc-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/basic-auth-policy": {
+				"default/oidc-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy",
+						Name:      "oidc-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret",
+						OIDC: &conf_v1.OIDC{
+							ClientID: "foo",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/htpasswd-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeHtpasswd,
-						},
-						Error: errors.New("secret is invalid"),
-					},
-				},
+				authRequestPolicies: []string{"jwt"},
+			},
+			context: "route",
+			oidcPolCfg: &oidcPolicyCfg{
+				oidc: &version2.OIDC{ClientID: "foo"},
+				key:  "default/oidc-policy",
 			},
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
@@ -12832,42 +15081,40 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Basic Auth policy default/basic-auth-policy references an invalid secret default/htpasswd-secret: secret is invalid`,
+					"The jwt policy of the VirtualServer spec cannot be used together with the oidc policy of a route, because both use auth_request",
 				},
 			},
-			expectedOidc: &oidcPolicyCfg{},
-			msg:          "basic auth reference missing secret",
+			expectedOidc: &oidcPolicyCfg{
+				oidc: &version2.OIDC{ClientID: "foo"},
+				key:  "default/oidc-policy",
+			},
+			msg: "route oidc reference with spec jwt",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "basic-auth-policy",
+					Name:      "jwt-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/basic-auth-policy": {
+				"default/jwt-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy",
+						Name:      "jwt-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret",
+						JWTAuth: &conf_v1.JWTAuth{
+							Realm:   "My Test API",
+							JwksURI: "https://idp.example.com/keys",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/htpasswd-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeCA,
-						},
-					},
-				},
+				authRequestPolicies: []string{"oidc"},
 			},
+			context: "route",
 			expected: policiesCfg{
 				ErrorReturn: &version2.Return{
 					Code: 500,
@@ -12875,79 +15122,48 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Basic Auth policy default/basic-auth-policy references a secret default/htpasswd-secret of a wrong type 'nginx.org/ca', must be 'nginx.org/htpasswd'`,
+					"The oidc policy of the VirtualServer spec cannot be used together with the jwt policy of a route, because both use auth_request",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "basic auth references wrong secret type",
+			msg:          "route jwt reference with spec oidc",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
-					Name:      "basic-auth-policy",
-					Namespace: "default",
-				},
-				{
-					Name:      "basic-auth-policy2",
+					Name:      "ext-auth-policy",
 					Namespace: "default",
 				},
 			},
 			policies: map[string]*conf_v1.Policy{
-				"default/basic-auth-policy": {
-					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy",
-						Namespace: "default",
-					},
-					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret",
-						},
-					},
-				},
-				"default/basic-auth-policy2": {
+				"default/ext-auth-policy": {
 					ObjectMeta: meta_v1.ObjectMeta{
-						Name:      "basic-auth-policy2",
+						Name:      "ext-auth-policy",
 						Namespace: "default",
 					},
 					Spec: conf_v1.PolicySpec{
-						BasicAuth: &conf_v1.BasicAuth{
-							Realm:  "test",
-							Secret: "htpasswd-secret2",
+						ExternalAuth: &conf_v1.ExternalAuth{
+							AuthService: "auth-svc:8080",
 						},
 					},
 				},
 			},
 			policyOpts: policyOptions{
-				secretRefs: map[string]*secrets.SecretReference{
-					"default/htpasswd-secret": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeHtpasswd,
-						},
-						Path: "/etc/nginx/secrets/default-htpasswd-secret",
-					},
-					"default/htpasswd-secret2": {
-						Secret: &api_v1.Secret{
-							Type: secrets.SecretTypeHtpasswd,
-						},
-						Path: "/etc/nginx/secrets/default-htpasswd-secret2",
-					},
-				},
+				authRequestPolicies: []string{"apiKey"},
 			},
+			context: "route",
 			expected: policiesCfg{
-				Context: ctx,
-				BasicAuth: &version2.BasicAuth{
-					Secret: "/etc/nginx/secrets/default-htpasswd-secret",
-					Realm:  "test",
+				ErrorReturn: &version2.Return{
+					Code: 500,
 				},
 			},
 			expectedWarnings: Warnings{
 				nil: {
-					`Multiple basic auth policies in the same context is not valid. Basic auth policy default/basic-auth-policy2 will be ignored`,
+					"The apiKey policy of the VirtualServer spec cannot be used together with the externalAuth policy of a route, because both use auth_request",
 				},
 			},
 			expectedOidc: &oidcPolicyCfg{},
-			msg:          "multi basic auth reference",
+			msg:          "route external auth reference with spec api key",
 		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
@@ -13577,6 +15793,58 @@ func TestGeneratePoliciesFails(t *testing.T) {
 			expectedOidc: &oidcPolicyCfg{},
 			msg:          "oidc secret referencing wrong secret type",
 		},
+		{
+			policyRefs: []conf_v1.PolicyReference{
+				{
+					Name:      "oidc-policy",
+					Namespace: "default",
+				},
+			},
+			policies: map[string]*conf_v1.Policy{
+				"default/oidc-policy": {
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      "oidc-policy",
+						Namespace: "default",
+					},
+					Spec: conf_v1.PolicySpec{
+						OIDC: &conf_v1.OIDC{
+							ClientID:              "foo",
+							ClientSecret:          "oidc-secret",
+							AuthEndpoint:          "http://foo.com/bar",
+							TokenEndpoint:         "http://foo.com/bar",
+							JWKSURI:               "http://foo.com/bar",
+							EndSessionEndpoint:    "http://foo.com/bar",
+							PostLogoutRedirectURI: "/_logout",
+						},
+					},
+				},
+			},
+			policyOpts: policyOptions{
+				secretRefs: map[string]*secrets.SecretReference{
+					"default/oidc-secret": {
+						Secret: &api_v1.Secret{
+							Type: secrets.SecretTypeOIDC,
+							Data: map[string][]byte{
+								"client-secret": []byte("super_secret_123"),
+							},
+						},
+					},
+				},
+			},
+			context: "spec",
+			expected: policiesCfg{
+				ErrorReturn: &version2.Return{
+					Code: 500,
+				},
+			},
+			expectedWarnings: Warnings{
+				nil: {
+					`OIDC policy default/oidc-policy references a secret default/oidc-secret without the session-key key, which is required to encrypt the session cookies in NGINX`,
+				},
+			},
+			expectedOidc: &oidcPolicyCfg{},
+			msg:          "oidc secret without session key",
+		},
 		{
 			policyRefs: []conf_v1.PolicyReference{
 				{
@@ -13629,6 +15897,7 @@ func TestGeneratePoliciesFails(t *testing.T) {
 							Type: secrets.SecretTypeOIDC,
 							Data: map[string][]byte{
 								"client-secret": []byte("super_secret_123"),
+								"session-key":   []byte("5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e"),
 							},
 						},
 					},
@@ -13642,6 +15911,7 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					JwksURI:               "https://foo.com/certs",
 					ClientID:              "foo",
 					ClientSecret:          "super_secret_123",
+					SessionKey:            "5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e",
 					RedirectURI:           "/_codexch",
 					Scope:                 "openid",
 					ZoneSyncLeeway:        0,
@@ -13668,6 +15938,7 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					JwksURI:               "https://foo.com/certs",
 					ClientID:              "foo",
 					ClientSecret:          "super_secret_123",
+					SessionKey:            "5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e",
 					RedirectURI:           "/_codexch",
 					Scope:                 "openid",
 					EndSessionEndpoint:    "https://foo.com/logout",
@@ -13734,6 +16005,7 @@ func TestGeneratePoliciesFails(t *testing.T) {
 							Type: secrets.SecretTypeOIDC,
 							Data: map[string][]byte{
 								"client-secret": []byte("super_secret_123"),
+								"session-key":   []byte("5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e"),
 							},
 						},
 					},
@@ -13756,6 +16028,7 @@ func TestGeneratePoliciesFails(t *testing.T) {
 					JwksURI:               "https://foo.com/certs",
 					ClientID:              "foo",
 					ClientSecret:          "super_secret_123",
+					SessionKey:            "5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e",
 					RedirectURI:           "/_codexch",
 					Scope:                 "openid",
 					EndSessionEndpoint:    "https://foo.com/logout",
@@ -14071,6 +16344,7 @@ func TestGeneratePoliciesFails(t *testing.T) {
 							Type: secrets.SecretTypeOIDC,
 							Data: map[string][]byte{
 								"client-secret": []byte("super_secret_123"),
+								"session-key":   []byte("5d6c0b1f9e8a7c4b2d3e1f0a9b8c7d6e"),
 							},
 						},
 					},
@@ -15258,6 +17532,57 @@ func TestGenerateLocationForProxying(t *testing.T) {
 	}
 }
 
+func TestGenerateLocationForProxyingWithProxyOverrides(t *testing.T) {
+	t.Parallel()
+	cfgParams := ConfigParams{
+		Context:              context.Background(),
+		ProxyConnectTimeout:  "30s",
+		ProxyReadTimeout:     "31s",
+		ProxySendTimeout:     "32s",
+		ClientMaxBodySize:    "1m",
+		ProxyMaxTempFileSize: "1024m",
+		ProxyBuffering:       true,
+		ProxyBuffers:         "8 4k",
+		ProxyBufferSize:      "4k",
+	}
+	upstream := conf_v1.Upstream{
+		ProxyConnectTimeout: "10s",
+		ProxyReadTimeout:    "11s",
+		ClientMaxBodySize:   "2m",
+		ProxyBufferSize:     "8k",
+	}
+	proxy := &conf_v1.ActionProxy{
+		Upstream:          "test",
+		ReadTimeout:       "120s",
+		SendTimeout:       "121s",
+		Buffering:         createPointerFromBool(false),
+		Buffers:           &conf_v1.UpstreamBuffers{Number: 16, Size: "8k"},
+		ClientMaxBodySize: "100m",
+	}
+
+	expected := version2.Location{
+		Path:                     "/upload",
+		ProxyConnectTimeout:      "10s",
+		ProxyReadTimeout:         "120s",
+		ProxySendTimeout:         "121s",
+		ClientMaxBodySize:        "100m",
+		ProxyMaxTempFileSize:     "1024m",
+		ProxyBuffering:           false,
+		ProxyBuffers:             "16 8k",
+		ProxyBufferSize:          "8k",
+		ProxyPass:                "http://test-upstream",
+		ProxyNextUpstream:        "error timeout",
+		ProxyNextUpstreamTimeout: "0s",
+		ProxyPassRequestHeaders:  true,
+		ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+	}
+
+	result := generateLocationForProxying("/upload", "test-upstream", upstream, &cfgParams, nil, false, 0, "", proxy, "", nil, false, "", "")
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateLocationForProxying() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestGenerateLocationForGrpcProxying(t *testing.T) {
 	t.Parallel()
 	cfgParams := ConfigParams{
@@ -15485,13 +17810,14 @@ func TestGenerateLocationForRedirect(t *testing.T) {
 func TestGenerateSSLConfig(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		inputTLS         *conf_v1.TLS
-		inputSecretRefs  map[string]*secrets.SecretReference
-		inputCfgParams   *ConfigParams
-		wildcard         bool
-		expectedSSL      *version2.SSL
-		expectedWarnings Warnings
-		msg              string
+		inputTLS           *conf_v1.TLS
+		inputSecretRefs    map[string]*secrets.SecretReference
+		inputCfgParams     *ConfigParams
+		wildcard           bool
+		resolverConfigured bool
+		expectedSSL        *version2.SSL
+		expectedWarnings   Warnings
+		msg                string
 	}{
 		{
 			inputTLS:         nil,
@@ -15517,41 +17843,164 @@ func TestGenerateSSLConfig(t *testing.T) {
 			inputTLS: &conf_v1.TLS{
 				Secret: "",
 			},
-			inputSecretRefs: map[string]*secrets.SecretReference{},
-			inputCfgParams:  &ConfigParams{Context: context.Background()},
-			wildcard:        true,
+			inputSecretRefs: map[string]*secrets.SecretReference{},
+			inputCfgParams:  &ConfigParams{Context: context.Background()},
+			wildcard:        true,
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				Certificate:     pemFileNameForWildcardTLSSecret,
+				CertificateKey:  pemFileNameForWildcardTLSSecret,
+				RejectHandshake: false,
+			},
+			expectedWarnings: Warnings{},
+			msg:              "TLS field with empty secret and wildcard cert enabled",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "missing",
+			},
+			inputCfgParams: &ConfigParams{Context: context.Background()},
+			wildcard:       false,
+			inputSecretRefs: map[string]*secrets.SecretReference{
+				"default/missing": {
+					Error: errors.New("missing doesn't exist"),
+				},
+			},
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				RejectHandshake: true,
+			},
+			expectedWarnings: Warnings{
+				nil: []string{"TLS secret missing is invalid: missing doesn't exist"},
+			},
+			msg: "missing doesn't exist in the cluster with HTTPS",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "mistyped",
+			},
+			inputCfgParams: &ConfigParams{Context: context.Background()},
+			wildcard:       false,
+			inputSecretRefs: map[string]*secrets.SecretReference{
+				"default/mistyped": {
+					Secret: &api_v1.Secret{
+						Type: secrets.SecretTypeCA,
+					},
+				},
+			},
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				RejectHandshake: true,
+			},
+			expectedWarnings: Warnings{
+				nil: []string{"TLS secret mistyped is of a wrong type 'nginx.org/ca', must be 'kubernetes.io/tls'"},
+			},
+			msg: "wrong secret type",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+			},
+			inputSecretRefs: map[string]*secrets.SecretReference{
+				"default/secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+					Path: "secret.pem",
+				},
+			},
+			inputCfgParams: &ConfigParams{Context: context.Background()},
+			wildcard:       false,
+			expectedSSL: &version2.SSL{
+				HTTP2:           false,
+				Certificate:     "secret.pem",
+				CertificateKey:  "secret.pem",
+				RejectHandshake: false,
+			},
+			expectedWarnings: Warnings{},
+			msg:              "normal case with HTTPS",
+		},
+		{
+			inputTLS: &conf_v1.TLS{
+				Secret: "secret",
+				OCSP: &conf_v1.TLSOCSP{
+					Stapling:          true,
+					StaplingResponder: "http://ocsp.example.com",
+					Resolver: &conf_v1.OCSPResolver{
+						Addresses: []string{"10.0.0.10", "10.0.0.11:53"},
+						Valid:     "30s",
+						Timeout:   "5s",
+						IPv6:      createPointerFromBool(false),
+					},
+				},
+			},
+			inputSecretRefs: map[string]*secrets.SecretReference{
+				"default/secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+					Path: "secret.pem",
+				},
+			},
+			inputCfgParams: &ConfigParams{Context: context.Background()},
+			wildcard:       false,
 			expectedSSL: &version2.SSL{
-				HTTP2:           false,
-				Certificate:     pemFileNameForWildcardTLSSecret,
-				CertificateKey:  pemFileNameForWildcardTLSSecret,
-				RejectHandshake: false,
+				HTTP2:                 false,
+				Certificate:           "secret.pem",
+				CertificateKey:        "secret.pem",
+				RejectHandshake:       false,
+				OCSPStapling:          true,
+				OCSPStaplingResponder: "http://ocsp.example.com",
+				OCSPResolver: &version2.Resolver{
+					Addresses: []string{"10.0.0.10", "10.0.0.11:53"},
+					Valid:     "30s",
+					Timeout:   "5s",
+					IPv6:      false,
+				},
 			},
 			expectedWarnings: Warnings{},
-			msg:              "TLS field with empty secret and wildcard cert enabled",
+			msg:              "OCSP stapling with resolver",
 		},
 		{
 			inputTLS: &conf_v1.TLS{
-				Secret: "missing",
+				Secret: "secret",
+				OCSP: &conf_v1.TLSOCSP{
+					Stapling: true,
+					Resolver: &conf_v1.OCSPResolver{
+						Addresses: []string{"10.0.0.10"},
+					},
+				},
 			},
-			inputCfgParams: &ConfigParams{Context: context.Background()},
-			wildcard:       false,
 			inputSecretRefs: map[string]*secrets.SecretReference{
-				"default/missing": {
-					Error: errors.New("missing doesn't exist"),
+				"default/secret": {
+					Secret: &api_v1.Secret{
+						Type: api_v1.SecretTypeTLS,
+					},
+					Path: "secret.pem",
 				},
 			},
+			inputCfgParams:     &ConfigParams{Context: context.Background()},
+			wildcard:           false,
+			resolverConfigured: true,
 			expectedSSL: &version2.SSL{
 				HTTP2:           false,
-				RejectHandshake: true,
+				Certificate:     "secret.pem",
+				CertificateKey:  "secret.pem",
+				RejectHandshake: false,
+				OCSPStapling:    true,
 			},
 			expectedWarnings: Warnings{
-				nil: []string{"TLS secret missing is invalid: missing doesn't exist"},
+				nil: []string{"The resolver in tls.ocsp is ignored because the resolver is configured in the ConfigMap, which is used for the OCSP responders instead"},
 			},
-			msg: "missing doesn't exist in the cluster with HTTPS",
+			msg: "OCSP stapling with resolver and ConfigMap resolver",
 		},
 		{
 			inputTLS: &conf_v1.TLS{
 				Secret: "mistyped",
+				OCSP: &conf_v1.TLSOCSP{
+					Stapling:          true,
+					StaplingResponder: "http://ocsp.example.com",
+				},
 			},
 			inputCfgParams: &ConfigParams{Context: context.Background()},
 			wildcard:       false,
@@ -15569,30 +18018,7 @@ func TestGenerateSSLConfig(t *testing.T) {
 			expectedWarnings: Warnings{
 				nil: []string{"TLS secret mistyped is of a wrong type 'nginx.org/ca', must be 'kubernetes.io/tls'"},
 			},
-			msg: "wrong secret type",
-		},
-		{
-			inputTLS: &conf_v1.TLS{
-				Secret: "secret",
-			},
-			inputSecretRefs: map[string]*secrets.SecretReference{
-				"default/secret": {
-					Secret: &api_v1.Secret{
-						Type: api_v1.SecretTypeTLS,
-					},
-					Path: "secret.pem",
-				},
-			},
-			inputCfgParams: &ConfigParams{Context: context.Background()},
-			wildcard:       false,
-			expectedSSL: &version2.SSL{
-				HTTP2:           false,
-				Certificate:     "secret.pem",
-				CertificateKey:  "secret.pem",
-				RejectHandshake: false,
-			},
-			expectedWarnings: Warnings{},
-			msg:              "normal case with HTTPS",
+			msg: "OCSP stapling with wrong secret type",
 		},
 	}
 
@@ -15600,6 +18026,7 @@ func TestGenerateSSLConfig(t *testing.T) {
 
 	for _, test := range tests {
 		vsc := newVirtualServerConfigurator(&ConfigParams{Context: context.Background()}, false, false, &StaticConfigParams{}, test.wildcard, &fakeBV)
+		vsc.isResolverConfigured = test.resolverConfigured
 
 		// it is ok to use nil as the owner
 		result := vsc.generateSSLConfig(nil, test.inputTLS, namespace, test.inputSecretRefs, test.inputCfgParams)
@@ -15612,6 +18039,177 @@ func TestGenerateSSLConfig(t *testing.T) {
 	}
 }
 
+func TestIsOCSPResolverRequired(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		ssl         *version2.SSL
+		ingressMTLS *version2.IngressMTLS
+		expected    bool
+		msg         string
+	}{
+		{
+			ssl:      nil,
+			expected: false,
+			msg:      "no TLS",
+		},
+		{
+			ssl:      &version2.SSL{},
+			expected: false,
+			msg:      "no OCSP",
+		},
+		{
+			ssl:      &version2.SSL{OCSPStapling: true},
+			expected: true,
+			msg:      "OCSP stapling without resolver",
+		},
+		{
+			ssl:         &version2.SSL{},
+			ingressMTLS: &version2.IngressMTLS{OCSP: "on"},
+			expected:    true,
+			msg:         "ingressMTLS OCSP without resolver",
+		},
+		{
+			ssl:         &version2.SSL{OCSPStapling: true, OCSPResolver: &version2.Resolver{Addresses: []string{"10.0.0.10"}}},
+			ingressMTLS: &version2.IngressMTLS{OCSP: "on"},
+			expected:    false,
+			msg:         "OCSP with resolver",
+		},
+	}
+
+	for _, test := range tests {
+		result := isOCSPResolverRequired(test.ssl, test.ingressMTLS)
+		if result != test.expected {
+			t.Errorf("isOCSPResolverRequired() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
+func TestGenerateRealIPConfig(t *testing.T) {
+	t.Parallel()
+	vs := &conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	cfgParams := &ConfigParams{
+		Context:       context.Background(),
+		SetRealIPFrom: []string{"10.0.0.0/8"},
+		RealIPHeader:  "X-Forwarded-For",
+	}
+
+	tests := []struct {
+		vsEx                  *VirtualServerEx
+		useCustomListeners    bool
+		expectedSetRealIPFrom []string
+		expectedRealIPHeader  string
+		expectedWarnings      Warnings
+		msg                   string
+	}{
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer:     vs,
+				HTTPSetRealIPFrom: []string{"192.168.0.0/16"},
+				HTTPRealIPHeader:  "proxy_protocol",
+			},
+			useCustomListeners:    false,
+			expectedSetRealIPFrom: []string{"10.0.0.0/8"},
+			expectedRealIPHeader:  "X-Forwarded-For",
+			expectedWarnings:      Warnings{},
+			msg:                   "no custom listeners",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer: vs,
+				HTTPPort:      8083,
+			},
+			useCustomListeners:    true,
+			expectedSetRealIPFrom: []string{"10.0.0.0/8"},
+			expectedRealIPHeader:  "X-Forwarded-For",
+			expectedWarnings:      Warnings{},
+			msg:                   "custom listener without real IP settings",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer:     vs,
+				HTTPPort:          8083,
+				HTTPSetRealIPFrom: []string{"192.168.0.0/16"},
+				HTTPRealIPHeader:  "proxy_protocol",
+			},
+			useCustomListeners:    true,
+			expectedSetRealIPFrom: []string{"192.168.0.0/16"},
+			expectedRealIPHeader:  "proxy_protocol",
+			expectedWarnings:      Warnings{},
+			msg:                   "custom listener overrides the ConfigMap settings",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer:      vs,
+				HTTPPort:           8083,
+				HTTPSPort:          8443,
+				HTTPSetRealIPFrom:  []string{"192.168.0.0/16", "172.16.0.0/12"},
+				HTTPSSetRealIPFrom: []string{"172.16.0.0/12", "192.168.0.0/16"},
+				HTTPRealIPHeader:   "proxy_protocol",
+				HTTPSRealIPHeader:  "proxy_protocol",
+			},
+			useCustomListeners:    true,
+			expectedSetRealIPFrom: []string{"172.16.0.0/12", "192.168.0.0/16"},
+			expectedRealIPHeader:  "proxy_protocol",
+			expectedWarnings:      Warnings{},
+			msg:                   "same settings of the HTTP and HTTPS listeners",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer:      vs,
+				HTTPPort:           8083,
+				HTTPSPort:          8443,
+				HTTPSetRealIPFrom:  []string{"192.168.0.0/16"},
+				HTTPSSetRealIPFrom: []string{"172.16.0.0/12"},
+				HTTPRealIPHeader:   "proxy_protocol",
+				HTTPSRealIPHeader:  "proxy_protocol",
+			},
+			useCustomListeners:    true,
+			expectedSetRealIPFrom: []string{"10.0.0.0/8"},
+			expectedRealIPHeader:  "X-Forwarded-For",
+			expectedWarnings: Warnings{
+				vs: []string{"The HTTP and HTTPS listeners have different setRealIPFrom or realIPHeader settings, which cannot be applied per listener. The ConfigMap settings will be used"},
+			},
+			msg: "different addresses of the HTTP and HTTPS listeners",
+		},
+		{
+			vsEx: &VirtualServerEx{
+				VirtualServer:     vs,
+				HTTPPort:          8083,
+				HTTPSPort:         8443,
+				HTTPSetRealIPFrom: []string{"10.0.0.0/8"},
+				HTTPRealIPHeader:  "proxy_protocol",
+			},
+			useCustomListeners:    true,
+			expectedSetRealIPFrom: []string{"10.0.0.0/8"},
+			expectedRealIPHeader:  "X-Forwarded-For",
+			expectedWarnings: Warnings{
+				vs: []string{"The HTTP and HTTPS listeners have different setRealIPFrom or realIPHeader settings, which cannot be applied per listener. The ConfigMap settings will be used"},
+			},
+			msg: "different headers of the HTTP and HTTPS listeners",
+		},
+	}
+
+	for _, test := range tests {
+		vsc := newVirtualServerConfigurator(cfgParams, false, false, &StaticConfigParams{}, false, &fakeBV)
+
+		setRealIPFrom, realIPHeader := vsc.generateRealIPConfig(test.vsEx, test.useCustomListeners)
+		if !reflect.DeepEqual(setRealIPFrom, test.expectedSetRealIPFrom) {
+			t.Errorf("generateRealIPConfig() returned %v but expected %v for the case of %s", setRealIPFrom, test.expectedSetRealIPFrom, test.msg)
+		}
+		if realIPHeader != test.expectedRealIPHeader {
+			t.Errorf("generateRealIPConfig() returned %q but expected %q for the case of %s", realIPHeader, test.expectedRealIPHeader, test.msg)
+		}
+		if !reflect.DeepEqual(vsc.warnings, test.expectedWarnings) {
+			t.Errorf("generateRealIPConfig() returned warnings of \n%v but expected \n%v for the case of %s", vsc.warnings, test.expectedWarnings, test.msg)
+		}
+	}
+}
+
 func TestGenerateRedirectConfig(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -16181,6 +18779,7 @@ func TestGenerateSplits(t *testing.T) {
 		t.Run(test.msg, func(t *testing.T) {
 			resultSplitClients, resultLocations, resultReturnLocations, _, _, _, _ := generateSplits(
 				test.splits,
+				nil,
 				upstreamNamer,
 				crUpstreams,
 				variableNamer,
@@ -17852,6 +20451,7 @@ func TestGenerateSplitsWeightChangesDynamicReload(t *testing.T) {
 		t.Run(test.msg, func(t *testing.T) {
 			resultSplitClients, resultLocations, _, resultMaps, resultKeyValZones, resultKeyVals, resultTwoWaySplitClients := generateSplits(
 				test.splits,
+				nil,
 				upstreamNamer,
 				crUpstreams,
 				variableNamer,
@@ -17973,10 +20573,139 @@ func TestGenerateDefaultSplitsConfig(t *testing.T) {
 				VSRNamespace:             "default",
 			},
 		},
-		InternalRedirectLocation: version2.InternalRedirectLocation{
-			Path:        "/",
-			Destination: "$vs_default_cafe_splits_1",
-		},
+		InternalRedirectLocation: version2.InternalRedirectLocation{
+			Path:        "/",
+			Destination: "$vs_default_cafe_splits_1",
+		},
+	}
+
+	cfgParams := ConfigParams{Context: context.Background()}
+	locSnippet := ""
+	enableSnippets := false
+	weightChangesDynamicReload := false
+	crUpstreams := map[string]conf_v1.Upstream{
+		"vs_default_cafe_coffee-v1": {
+			Service: "coffee-v1",
+		},
+		"vs_default_cafe_coffee-v2": {
+			Service: "coffee-v2",
+		},
+	}
+
+	errorPageDetails := errorPageDetails{
+		pages: route.ErrorPages,
+		index: 0,
+		owner: nil,
+	}
+
+	result := generateDefaultSplitsConfig(route, upstreamNamer, crUpstreams, variableNamer, index, &cfgParams,
+		errorPageDetails, "", locSnippet, enableSnippets, 0, true, "coffee", "default", Warnings{}, weightChangesDynamicReload)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("generateDefaultSplitsConfig() returned \n%+v but expected \n%+v", result, expected)
+	}
+}
+
+func TestGenerateDefaultSplitsConfigWithSplitKey(t *testing.T) {
+	t.Parallel()
+	route := conf_v1.Route{
+		Path: "/",
+		Splits: []conf_v1.Split{
+			{
+				Weight: 90,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v1",
+				},
+			},
+			{
+				Weight: 10,
+				Action: &conf_v1.Action{
+					Pass: "coffee-v2",
+				},
+			},
+		},
+		SplitKey: &conf_v1.SplitKey{
+			Cookie: "session",
+		},
+	}
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	upstreamNamer := NewUpstreamNamerForVirtualServer(&virtualServer)
+	variableNamer := NewVSVariableNamer(&virtualServer)
+	index := 1
+
+	expected := routingCfg{
+		SplitClients: []version2.SplitClient{
+			{
+				Source:   "$vs_default_cafe_split_key_cookie_session",
+				Variable: "$vs_default_cafe_splits_1",
+				Distributions: []version2.Distribution{
+					{
+						Weight: "90%",
+						Value:  "/internal_location_splits_1_split_0",
+					},
+					{
+						Weight: "10%",
+						Value:  "/internal_location_splits_1_split_1",
+					},
+				},
+			},
+		},
+		Locations: []version2.Location{
+			{
+				Path:                     "/internal_location_splits_1_split_0",
+				ProxyPass:                "http://vs_default_cafe_coffee-v1$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ProxySSLName:             "coffee-v1.default.svc",
+				ProxyPassRequestHeaders:  true,
+				ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:              "coffee-v1",
+				IsVSR:                    true,
+				VSRName:                  "coffee",
+				VSRNamespace:             "default",
+			},
+			{
+				Path:                     "/internal_location_splits_1_split_1",
+				ProxyPass:                "http://vs_default_cafe_coffee-v2$request_uri",
+				ProxyNextUpstream:        "error timeout",
+				ProxyNextUpstreamTimeout: "0s",
+				ProxyNextUpstreamTries:   0,
+				Internal:                 true,
+				ProxySSLName:             "coffee-v2.default.svc",
+				ProxyPassRequestHeaders:  true,
+				ProxySetHeaders:          []version2.Header{{Name: "Host", Value: "$host"}},
+				ServiceName:              "coffee-v2",
+				IsVSR:                    true,
+				VSRName:                  "coffee",
+				VSRNamespace:             "default",
+			},
+		},
+		InternalRedirectLocation: version2.InternalRedirectLocation{
+			Path:        "/",
+			Destination: "$vs_default_cafe_splits_1",
+		},
+		Maps: []version2.Map{
+			{
+				Source:   "$cookie_session",
+				Variable: "$vs_default_cafe_split_key_cookie_session",
+				Parameters: []version2.Parameter{
+					{
+						Value:  `""`,
+						Result: "$request_id",
+					},
+					{
+						Value:  "default",
+						Result: "$cookie_session",
+					},
+				},
+			},
+		},
 	}
 
 	cfgParams := ConfigParams{Context: context.Background()}
@@ -18005,6 +20734,115 @@ func TestGenerateDefaultSplitsConfig(t *testing.T) {
 	}
 }
 
+func TestGenerateSplitKeyMap(t *testing.T) {
+	t.Parallel()
+	virtualServer := conf_v1.VirtualServer{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "cafe",
+			Namespace: "default",
+		},
+	}
+	variableNamer := NewVSVariableNamer(&virtualServer)
+
+	tests := []struct {
+		splitKey *conf_v1.SplitKey
+		expected version2.Map
+		msg      string
+	}{
+		{
+			splitKey: &conf_v1.SplitKey{
+				Header: "x-user-id",
+			},
+			expected: version2.Map{
+				Source:   "$http_x_user_id",
+				Variable: "$vs_default_cafe_split_key_http_x_user_id",
+				Parameters: []version2.Parameter{
+					{Value: `""`, Result: "$request_id"},
+					{Value: "default", Result: "$http_x_user_id"},
+				},
+			},
+			msg: "header",
+		},
+		{
+			splitKey: &conf_v1.SplitKey{
+				Argument: "user",
+			},
+			expected: version2.Map{
+				Source:   "$arg_user",
+				Variable: "$vs_default_cafe_split_key_arg_user",
+				Parameters: []version2.Parameter{
+					{Value: `""`, Result: "$request_id"},
+					{Value: "default", Result: "$arg_user"},
+				},
+			},
+			msg: "argument",
+		},
+		{
+			splitKey: &conf_v1.SplitKey{
+				JWTClaim: "user.id",
+			},
+			expected: version2.Map{
+				Source:   "$jwt_default_cafe_user_id",
+				Variable: "$vs_default_cafe_split_key_jwt_default_cafe_user_id",
+				Parameters: []version2.Parameter{
+					{Value: `""`, Result: "$request_id"},
+					{Value: "default", Result: "$jwt_default_cafe_user_id"},
+				},
+			},
+			msg: "jwt claim",
+		},
+	}
+
+	for _, test := range tests {
+		result := generateSplitKeyMap(test.splitKey, variableNamer)
+		if diff := cmp.Diff(test.expected, result); diff != "" {
+			t.Errorf("generateSplitKeyMap() mismatch for the case of %s (-want +got):\n%s", test.msg, diff)
+		}
+	}
+}
+
+func TestGenerateSplitKeyAuthJwtClaimSets(t *testing.T) {
+	t.Parallel()
+	route := conf_v1.Route{
+		Path: "/",
+		SplitKey: &conf_v1.SplitKey{
+			JWTClaim: "sub",
+		},
+		Matches: []conf_v1.Match{
+			{
+				SplitKey: &conf_v1.SplitKey{
+					JWTClaim: "user.group",
+				},
+			},
+			{
+				SplitKey: &conf_v1.SplitKey{
+					Cookie: "session",
+				},
+			},
+		},
+	}
+	owner := policyOwnerDetails{
+		vsNamespace: "default",
+		vsName:      "cafe",
+	}
+
+	expected := []version2.AuthJWTClaimSet{
+		{
+			Variable: "$jwt_default_cafe_sub",
+			Claim:    "sub",
+		},
+		{
+			Variable: "$jwt_default_cafe_user_group",
+			Claim:    "user group",
+		},
+	}
+
+	result := generateSplitKeyAuthJwtClaimSets(route, owner)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("generateSplitKeyAuthJwtClaimSets() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestGenerateMatchesConfig(t *testing.T) {
 	t.Parallel()
 	route := conf_v1.Route{
@@ -18836,6 +21674,7 @@ func TestGenerateValueForMatchesRouteMap(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		input              string
+		inputIsRegex       bool
 		expectedValue      string
 		expectedIsNegative bool
 	}{
@@ -18884,10 +21723,22 @@ func TestGenerateValueForMatchesRouteMap(t *testing.T) {
 			expectedValue:      `""`,
 			expectedIsNegative: true,
 		},
+		{
+			input:              "^v[0-9]+$",
+			inputIsRegex:       true,
+			expectedValue:      `"~^v[0-9]+$"`,
+			expectedIsNegative: false,
+		},
+		{
+			input:              "!(?i)^beta",
+			inputIsRegex:       true,
+			expectedValue:      `"~(?i)^beta"`,
+			expectedIsNegative: true,
+		},
 	}
 
 	for _, test := range tests {
-		resultValue, resultIsNegative := generateValueForMatchesRouteMap(test.input)
+		resultValue, resultIsNegative := generateValueForMatchesRouteMap(test.input, test.inputIsRegex)
 		if resultValue != test.expectedValue {
 			t.Errorf("generateValueForMatchesRouteMap(%q) returned %q but expected %q as the value", test.input, resultValue, test.expectedValue)
 		}
@@ -18900,12 +21751,15 @@ func TestGenerateValueForMatchesRouteMap(t *testing.T) {
 func TestGenerateParametersForMatchesRouteMap(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
-		inputMatchedValue     string
+		inputCondition        conf_v1.Condition
 		inputSuccessfulResult string
 		expected              []version2.Parameter
 	}{
 		{
-			inputMatchedValue:     "abc",
+			inputCondition: conf_v1.Condition{
+				Header: "x-version",
+				Value:  "abc",
+			},
 			inputSuccessfulResult: "1",
 			expected: []version2.Parameter{
 				{
@@ -18919,7 +21773,10 @@ func TestGenerateParametersForMatchesRouteMap(t *testing.T) {
 			},
 		},
 		{
-			inputMatchedValue:     "!abc",
+			inputCondition: conf_v1.Condition{
+				Header: "x-version",
+				Value:  "!abc",
+			},
 			inputSuccessfulResult: "1",
 			expected: []version2.Parameter{
 				{
@@ -18932,16 +21789,153 @@ func TestGenerateParametersForMatchesRouteMap(t *testing.T) {
 				},
 			},
 		},
+		{
+			inputCondition: conf_v1.Condition{
+				Header: "x-version",
+				Value:  "^v[0-9]+$",
+				Regex:  true,
+			},
+			inputSuccessfulResult: "1",
+			expected: []version2.Parameter{
+				{
+					Value:  `"~^v[0-9]+$"`,
+					Result: "1",
+				},
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		},
+		{
+			inputCondition: conf_v1.Condition{
+				Methods: []string{"GET", "HEAD"},
+			},
+			inputSuccessfulResult: "$vs_default_cafe_matches_0_match_0_cond_1",
+			expected: []version2.Parameter{
+				{
+					Value:  `"GET"`,
+					Result: "$vs_default_cafe_matches_0_match_0_cond_1",
+				},
+				{
+					Value:  `"HEAD"`,
+					Result: "$vs_default_cafe_matches_0_match_0_cond_1",
+				},
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		},
+		{
+			inputCondition: conf_v1.Condition{
+				SourceCIDRs: []string{"10.0.0.0/8"},
+			},
+			inputSuccessfulResult: "1",
+			expected: []version2.Parameter{
+				{
+					Value:  `"1"`,
+					Result: "1",
+				},
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		},
+		{
+			inputCondition: conf_v1.Condition{
+				Cookie:  "session",
+				Present: createPointerFromBool(true),
+			},
+			inputSuccessfulResult: "1",
+			expected: []version2.Parameter{
+				{
+					Value:  `""`,
+					Result: "0",
+				},
+				{
+					Value:  "default",
+					Result: "1",
+				},
+			},
+		},
+		{
+			inputCondition: conf_v1.Condition{
+				Cookie:  "session",
+				Present: createPointerFromBool(false),
+			},
+			inputSuccessfulResult: "1",
+			expected: []version2.Parameter{
+				{
+					Value:  `""`,
+					Result: "1",
+				},
+				{
+					Value:  "default",
+					Result: "0",
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
-		result := generateParametersForMatchesRouteMap(test.inputMatchedValue, test.inputSuccessfulResult)
+		result := generateParametersForMatchesRouteMap(test.inputCondition, test.inputSuccessfulResult)
 		if !reflect.DeepEqual(result, test.expected) {
-			t.Errorf("generateParametersForMatchesRouteMap(%q, %q) returned %v but expected %v", test.inputMatchedValue, test.inputSuccessfulResult, result, test.expected)
+			t.Errorf("generateParametersForMatchesRouteMap(%v, %q) returned %v but expected %v", test.inputCondition, test.inputSuccessfulResult, result, test.expected)
 		}
 	}
 }
 
+func TestGenerateConditionConfigForSourceCIDRs(t *testing.T) {
+	t.Parallel()
+	condition := conf_v1.Condition{
+		SourceCIDRs: []string{"10.0.0.0/8", "192.168.1.1"},
+	}
+	variable := "$vs_default_cafe_matches_0_match_0_cond_0"
+
+	expectedMap := version2.Map{
+		Source:   "$vs_default_cafe_matches_0_match_0_cond_0_geo",
+		Variable: variable,
+		Parameters: []version2.Parameter{
+			{
+				Value:  `"1"`,
+				Result: "1",
+			},
+			{
+				Value:  "default",
+				Result: "0",
+			},
+		},
+	}
+	expectedGeo := &version2.Geo{
+		Source:   "$remote_addr",
+		Variable: "$vs_default_cafe_matches_0_match_0_cond_0_geo",
+		Parameters: []version2.Parameter{
+			{
+				Value:  "default",
+				Result: "0",
+			},
+			{
+				Value:  "10.0.0.0/8",
+				Result: "1",
+			},
+			{
+				Value:  "192.168.1.1",
+				Result: "1",
+			},
+		},
+	}
+
+	resultMap, resultGeo := generateConditionConfig(condition, variable, "1")
+	if !cmp.Equal(expectedMap, resultMap) {
+		t.Errorf("generateConditionConfig() returned unexpected map (-want +got):\n%s", cmp.Diff(expectedMap, resultMap))
+	}
+	if !cmp.Equal(expectedGeo, resultGeo) {
+		t.Errorf("generateConditionConfig() returned unexpected geo (-want +got):\n%s", cmp.Diff(expectedGeo, resultGeo))
+	}
+}
+
 func TestGetNameForSourceForMatchesRouteMapFromCondition(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -18972,6 +21966,18 @@ func TestGetNameForSourceForMatchesRouteMapFromCondition(t *testing.T) {
 			},
 			expected: "$request_method",
 		},
+		{
+			input: conf_v1.Condition{
+				Methods: []string{"GET"},
+			},
+			expected: "$request_method",
+		},
+		{
+			input: conf_v1.Condition{
+				SourceCIDRs: []string{"10.0.0.0/8"},
+			},
+			expected: "$remote_addr",
+		},
 	}
 
 	for _, test := range tests {
@@ -19539,6 +22545,44 @@ func TestGenerateEndpointsForUpstream(t *testing.T) {
 			expected:             []string{nginx502Server},
 			msg:                  "Upstream with subselector, without a matching endpoint",
 		},
+		{
+			upstream: conf_v1.Upstream{
+				Servers: []string{"10.0.0.1:8080", "legacy.example.com:80"},
+			},
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+					},
+				},
+				Endpoints: map[string][]string{},
+			},
+			isPlus:               false,
+			isResolverConfigured: false,
+			expected:             []string{"10.0.0.1:8080", "legacy.example.com:80"},
+			msg:                  "Upstream with static servers",
+		},
+		{
+			upstream: conf_v1.Upstream{
+				Servers: []string{"legacy.example.com:80"},
+				Resolve: true,
+			},
+			vsEx: &VirtualServerEx{
+				VirtualServer: &conf_v1.VirtualServer{
+					ObjectMeta: meta_v1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+					},
+				},
+				Endpoints: map[string][]string{},
+			},
+			isPlus:               true,
+			isResolverConfigured: false,
+			warningsExpected:     true,
+			expected:             []string{"legacy.example.com:80"},
+			msg:                  "Upstream with static servers to re-resolve without resolver configured",
+		},
 	}
 
 	for _, test := range tests {
@@ -19835,6 +22879,50 @@ func TestGeneratePath(t *testing.T) {
 	}
 }
 
+func TestGenerateRoutePath(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		route    conf_v1.Route
+		expected string
+	}{
+		{
+			route:    conf_v1.Route{Path: "=/exact/match"},
+			expected: "=/exact/match",
+		},
+		{
+			route:    conf_v1.Route{Path: "/exact/match", PathType: conf_v1.PathTypeExact},
+			expected: "=/exact/match",
+		},
+		{
+			route:    conf_v1.Route{Path: "/exact/match.html", PathType: conf_v1.PathTypeExact, CaseInsensitive: true},
+			expected: `~*^/exact/match\.html$`,
+		},
+		{
+			route:    conf_v1.Route{Path: "/prefix", PathType: conf_v1.PathTypePrefix},
+			expected: "/prefix",
+		},
+		{
+			route:    conf_v1.Route{Path: "/prefix", PathType: conf_v1.PathTypePrefix, CaseInsensitive: true},
+			expected: "~*^/prefix",
+		},
+		{
+			route:    conf_v1.Route{Path: `^/images/.*\.jpg$`, PathType: conf_v1.PathTypeRegularExpression},
+			expected: `~^/images/.*\.jpg$`,
+		},
+		{
+			route:    conf_v1.Route{Path: `^/images/.*\.jpg$`, PathType: conf_v1.PathTypeRegularExpression, CaseInsensitive: true},
+			expected: `~*^/images/.*\.jpg$`,
+		},
+	}
+
+	for _, test := range tests {
+		result := GenerateRoutePath(test.route)
+		if result != test.expected {
+			t.Errorf("GenerateRoutePath(%+v) returned %v, but expected %v.", test.route, result, test.expected)
+		}
+	}
+}
+
 func TestGenerateErrorPageName(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -20594,6 +23682,40 @@ func TestGenerateProxyIgnoreHeaders(t *testing.T) {
 	}
 }
 
+func TestAddCacheIgnoreHeaders(t *testing.T) {
+	t.Parallel()
+	specCache := &version2.Cache{ZoneName: "spec_cache", IgnoreCacheControl: true}
+	routeCache := &version2.Cache{ZoneName: "route_cache"}
+
+	locations := []version2.Location{
+		{
+			Path:               "/tea",
+			ProxyIgnoreHeaders: "X-Accel-Expires Expires",
+		},
+		{
+			Path: "/coffee",
+		},
+		{
+			Path:               "/juice",
+			ProxyIgnoreHeaders: "Set-Cookie",
+			Cache:              routeCache,
+		},
+		{
+			Path:     "/grpc",
+			GRPCPass: "grpc://grpc-upstream",
+		},
+	}
+	expected := []string{"X-Accel-Expires Expires Cache-Control", "Cache-Control Expires", "Set-Cookie", ""}
+
+	addCacheIgnoreHeaders(locations, specCache)
+
+	for i, loc := range locations {
+		if loc.ProxyIgnoreHeaders != expected[i] {
+			t.Errorf("addCacheIgnoreHeaders() set %q for location %s but expected %q", loc.ProxyIgnoreHeaders, loc.Path, expected[i])
+		}
+	}
+}
+
 func TestGenerateProxyAddHeaders(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -3,6 +3,7 @@ package k8s
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -215,6 +216,14 @@ type VirtualServerConfiguration struct {
 	HTTPIPv6            string
 	HTTPSIPv4           string
 	HTTPSIPv6           string
+	HTTPProxyProtocol   *bool
+	HTTPSProxyProtocol  *bool
+	HTTPSetRealIPFrom   []string
+	HTTPSSetRealIPFrom  []string
+	HTTPRealIPHeader    string
+	HTTPSRealIPHeader   string
+	HTTP3               bool
+	HTTP3ReusePort      bool
 }
 
 // NewVirtualServerConfiguration creates a VirtualServerConfiguration.
@@ -277,6 +286,8 @@ type TransportServerConfiguration struct {
 	ListenerPort    int
 	IPv4            string
 	IPv6            string
+	ProxyProtocol   bool
+	SetRealIPFrom   []string
 	TransportServer *conf_v1.TransportServer
 	Warnings        []string
 }
@@ -317,7 +328,10 @@ func (tsc *TransportServerConfiguration) IsEqual(resource Resource) bool {
 		return false
 	}
 
-	return compareObjectMetas(tsc.GetObjectMeta(), resource.GetObjectMeta()) && tsc.ListenerPort == tsConfig.ListenerPort
+	return compareObjectMetas(tsc.GetObjectMeta(), resource.GetObjectMeta()) &&
+		tsc.ListenerPort == tsConfig.ListenerPort &&
+		tsc.ProxyProtocol == tsConfig.ProxyProtocol &&
+		slices.Equal(tsc.SetRealIPFrom, tsConfig.SetRealIPFrom)
 }
 
 func compareObjectMetas(meta1 *metav1.ObjectMeta, meta2 *metav1.ObjectMeta) bool {
@@ -809,6 +823,8 @@ func (c *Configuration) buildListenerHostsAndTSConfigurations() (map[listenerHos
 		tsc.ListenerPort = listener.Port
 		tsc.IPv4 = listener.IPv4
 		tsc.IPv6 = listener.IPv6
+		tsc.ProxyProtocol = listener.ProxyProtocol != nil && *listener.ProxyProtocol
+		tsc.SetRealIPFrom = listener.SetRealIPFrom
 
 		host := ts.Spec.Host
 		listenerKey := listenerHostKey{ListenerName: listener.Name, Host: host}
@@ -839,16 +855,30 @@ func (c *Configuration) buildListenersForVSConfiguration(vsc *VirtualServerConfi
 		return
 	}
 
-	assignListener := func(listenerName string, isSSL bool, port *int, ipv4 *string, ipv6 *string) {
+	getListener := func(listenerName string, isSSL bool) *conf_v1.Listener {
 		if gcListener, ok := c.listenerMap[listenerName]; ok && gcListener.Protocol == conf_v1.HTTPProtocol && gcListener.Ssl == isSSL {
-			*port = gcListener.Port
-			*ipv4 = gcListener.IPv4
-			*ipv6 = gcListener.IPv6
+			return &gcListener
 		}
+		return nil
 	}
 
-	assignListener(vs.Spec.Listener.HTTP, false, &vsc.HTTPPort, &vsc.HTTPIPv4, &vsc.HTTPIPv6)
-	assignListener(vs.Spec.Listener.HTTPS, true, &vsc.HTTPSPort, &vsc.HTTPSIPv4, &vsc.HTTPSIPv6)
+	if l := getListener(vs.Spec.Listener.HTTP, false); l != nil {
+		vsc.HTTPPort = l.Port
+		vsc.HTTPIPv4 = l.IPv4
+		vsc.HTTPIPv6 = l.IPv6
+		vsc.HTTPProxyProtocol = l.ProxyProtocol
+		vsc.HTTPSetRealIPFrom = l.SetRealIPFrom
+		vsc.HTTPRealIPHeader = l.RealIPHeader
+	}
+	if l := getListener(vs.Spec.Listener.HTTPS, true); l != nil {
+		vsc.HTTPSPort = l.Port
+		vsc.HTTPSIPv4 = l.IPv4
+		vsc.HTTPSIPv6 = l.IPv6
+		vsc.HTTPSProxyProtocol = l.ProxyProtocol
+		vsc.HTTPSSetRealIPFrom = l.SetRealIPFrom
+		vsc.HTTPSRealIPHeader = l.RealIPHeader
+		vsc.HTTP3 = l.HTTP3
+	}
 }
 
 // GetResources returns all configuration resources.
@@ -1530,9 +1560,39 @@ func (c *Configuration) buildHostsAndResources() (newHosts map[string]Resource,
 		}
 	}
 
+	// Step 4 - Choose the VirtualServers that set the reuseport parameter of the HTTP/3 listeners
+
+	setHTTP3ReusePort(newHosts)
+
 	return newHosts, newResources
 }
 
+// setHTTP3ReusePort chooses, for every custom HTTP/3 listener, the VirtualServer whose QUIC listen directives include
+// the reuseport parameter. NGINX allows the parameter only once per address and port. The QUIC listen directives
+// of the default HTTPS listener get the parameter from the default server.
+func setHTTP3ReusePort(hosts map[string]Resource) {
+	var hostNames []string
+	for h := range hosts {
+		hostNames = append(hostNames, h)
+	}
+	sort.Strings(hostNames)
+
+	listeners := make(map[string]bool)
+	for _, h := range hostNames {
+		vsc, ok := hosts[h].(*VirtualServerConfiguration)
+		if !ok || !vsc.HTTP3 {
+			continue
+		}
+		// a VirtualServer without a TLS secret might not listen on the HTTPS listener
+		vs := vsc.VirtualServer
+		if vs.Spec.TLS == nil || vs.Spec.TLS.Secret == "" || listeners[vs.Spec.Listener.HTTPS] {
+			continue
+		}
+		listeners[vs.Spec.Listener.HTTPS] = true
+		vsc.HTTP3ReusePort = true
+	}
+}
+
 func (c *Configuration) isChallengeIngress(ing *networking.Ingress) bool {
 	if !c.isCertManagerEnabled {
 		return false
@@ -1636,6 +1696,14 @@ func (c *Configuration) buildVirtualServerRoutes(vs *conf_v1.VirtualServer) ([]*
 	var vsrs []*conf_v1.VirtualServerRoute
 	var warnings []string
 
+	// routes holds the routes that generate locations, used to detect the conflicts between VirtualServerRoutes.
+	var routes []conf_v1.Route
+	for _, r := range vs.Spec.Routes {
+		if r.Route == "" {
+			routes = append(routes, r)
+		}
+	}
+
 	for _, r := range vs.Spec.Routes {
 		if r.Route == "" {
 			continue
@@ -1655,14 +1723,22 @@ func (c *Configuration) buildVirtualServerRoutes(vs *conf_v1.VirtualServer) ([]*
 			continue
 		}
 
-		err := c.virtualServerValidator.ValidateVirtualServerRouteForVirtualServer(vsr, vs.Spec.Host, r.Path)
+		err := c.virtualServerValidator.ValidateVirtualServerRouteForVirtualServer(vsr, vs.Spec.Host, configs.GenerateRoutePath(r))
 		if err != nil {
 			warning := fmt.Sprintf("VirtualServerRoute %s is invalid: %v", vsrKey, err)
 			warnings = append(warnings, warning)
 			continue
 		}
 
+		err = c.virtualServerValidator.ValidateVirtualServerRouteConflicts(vsr, routes)
+		if err != nil {
+			warning := fmt.Sprintf("VirtualServerRoute %s conflicts with other routes: %v", vsrKey, err)
+			warnings = append(warnings, warning)
+			continue
+		}
+
 		vsrs = append(vsrs, vsr)
+		routes = append(routes, vsr.Spec.Subroutes...)
 	}
 
 	return vsrs, warnings
@@ -1831,11 +1907,29 @@ func detectChangesInHosts(oldHosts map[string]Resource, newHosts map[string]Reso
 			updatedHosts = append(updatedHosts, h)
 		}
 
+		if !listenerRealIPSettingsEqual(newVsc, oldVsc) {
+			updatedHosts = append(updatedHosts, h)
+		}
+
+		if newVsc.HTTP3 != oldVsc.HTTP3 || newVsc.HTTP3ReusePort != oldVsc.HTTP3ReusePort {
+			updatedHosts = append(updatedHosts, h)
+		}
+
 	}
 
 	return removedHosts, updatedHosts, addedHosts
 }
 
+// listenerRealIPSettingsEqual checks if the PROXY protocol and real IP settings of the listeners of two VirtualServerConfigurations are equal.
+func listenerRealIPSettingsEqual(vsc1 *VirtualServerConfiguration, vsc2 *VirtualServerConfiguration) bool {
+	return reflect.DeepEqual(vsc1.HTTPProxyProtocol, vsc2.HTTPProxyProtocol) &&
+		reflect.DeepEqual(vsc1.HTTPSProxyProtocol, vsc2.HTTPSProxyProtocol) &&
+		slices.Equal(vsc1.HTTPSetRealIPFrom, vsc2.HTTPSetRealIPFrom) &&
+		slices.Equal(vsc1.HTTPSSetRealIPFrom, vsc2.HTTPSSetRealIPFrom) &&
+		vsc1.HTTPRealIPHeader == vsc2.HTTPRealIPHeader &&
+		vsc1.HTTPSRealIPHeader == vsc2.HTTPSRealIPHeader
+}
+
 func detectChangesInListenerHosts(
 	oldListenerHosts map[listenerHostKey]*TransportServerConfiguration,
 	newListenerHosts map[listenerHostKey]*TransportServerConfiguration,
@@ -2779,6 +2779,91 @@ func TestAddVirtualServerWithValidCustomListenersFirstThenAddGlobalConfiguration
 	addOrUpdateGlobalConfiguration(t, configuration, customHTTPAndHTTPSListeners, expectedChanges, noProblems)
 }
 
+func TestHTTP3ListenerReusePort(t *testing.T) {
+	t.Parallel()
+	configuration := createTestConfiguration()
+
+	http3Listeners := []conf_v1.Listener{
+		{
+			Name:     "http-8082",
+			Port:     8082,
+			Protocol: "HTTP",
+		},
+		{
+			Name:     "https-8442",
+			Port:     8442,
+			Protocol: "HTTP",
+			Ssl:      true,
+			HTTP3:    true,
+		},
+	}
+	addOrUpdateGlobalConfiguration(t, configuration, http3Listeners, noChanges, noProblems)
+
+	cafe := createTestVirtualServerWithListeners("cafe", "cafe.example.com", "http-8082", "https-8442")
+	cafe.Spec.TLS = &conf_v1.TLS{Secret: "cafe-secret"}
+	tea := createTestVirtualServerWithListeners("tea", "tea.example.com", "http-8082", "https-8442")
+	tea.Spec.TLS = &conf_v1.TLS{Secret: "tea-secret"}
+
+	expectedChanges := []ResourceChange{
+		{
+			Op: AddOrUpdate,
+			Resource: &VirtualServerConfiguration{
+				VirtualServer:  cafe,
+				HTTPPort:       8082,
+				HTTPSPort:      8442,
+				HTTP3:          true,
+				HTTP3ReusePort: true,
+			},
+		},
+	}
+	addOrUpdateVirtualServer(t, configuration, cafe, expectedChanges, noProblems)
+
+	expectedChanges = []ResourceChange{
+		{
+			Op: AddOrUpdate,
+			Resource: &VirtualServerConfiguration{
+				VirtualServer: tea,
+				HTTPPort:      8082,
+				HTTPSPort:     8442,
+				HTTP3:         true,
+			},
+		},
+	}
+	addOrUpdateVirtualServer(t, configuration, tea, expectedChanges, noProblems)
+
+	// the reuseport parameter moves to the remaining VirtualServer
+	expectedChanges = []ResourceChange{
+		{
+			Op: Delete,
+			Resource: &VirtualServerConfiguration{
+				VirtualServer:  cafe,
+				HTTPPort:       8082,
+				HTTPSPort:      8442,
+				HTTP3:          true,
+				HTTP3ReusePort: true,
+			},
+		},
+		{
+			Op: AddOrUpdate,
+			Resource: &VirtualServerConfiguration{
+				VirtualServer:  tea,
+				HTTPPort:       8082,
+				HTTPSPort:      8442,
+				HTTP3:          true,
+				HTTP3ReusePort: true,
+			},
+		},
+	}
+
+	changes, problems := configuration.DeleteVirtualServer("default/cafe")
+	if diff := cmp.Diff(expectedChanges, changes); diff != "" {
+		t.Errorf("DeleteVirtualServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(noProblems, problems); diff != "" {
+		t.Errorf("DeleteVirtualServer() returned unexpected result (-want +got):\n%s", diff)
+	}
+}
+
 func TestAddVirtualServerWithValidCustomListenersAndNoGlobalConfiguration(t *testing.T) {
 	t.Parallel()
 	configuration := createTestConfiguration()
@@ -4232,6 +4317,48 @@ func TestFindResourcesForResourceReference(t *testing.T) {
 	}
 }
 
+func TestFindResourcesForPolicy(t *testing.T) {
+	t.Parallel()
+	vs := createTestVirtualServer("virtualserver", "foo.example.com")
+	vs.Spec.Policies = []conf_v1.PolicyReference{
+		{
+			Name: "conn-limit",
+		},
+	}
+	listeners := []conf_v1.Listener{
+		{
+			Name:     "tcp-7777",
+			Port:     7777,
+			Protocol: "TCP",
+		},
+	}
+	gc := createTestGlobalConfiguration(listeners)
+	ts := createTestTransportServer("transportserver-tcp", "tcp-7777", "TCP")
+	ts.Spec.Policies = []conf_v1.PolicyReference{
+		{
+			Name: "conn-limit",
+		},
+	}
+	tsWithoutPolicies := createTestTLSPassthroughTransportServer("transportserver-passthrough", "ts.example.com")
+
+	configuration := createTestConfiguration()
+
+	configuration.AddOrUpdateVirtualServer(vs)
+	_, _, _ = configuration.AddOrUpdateGlobalConfiguration(gc)
+	configuration.AddOrUpdateTransportServer(ts)
+	configuration.AddOrUpdateTransportServer(tsWithoutPolicies)
+
+	expected := []Resource{
+		configuration.hosts["foo.example.com"],
+		configuration.listenerHosts[listenerHostKey{ListenerName: "tcp-7777", Host: ""}],
+	}
+
+	result := configuration.FindResourcesForPolicy("default", "conn-limit")
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("FindResourcesForPolicy() returned unexpected result (-want +got):\n%s", diff)
+	}
+}
+
 func TestGetResources(t *testing.T) {
 	t.Parallel()
 	ing := createTestIngress("ingress", "foo.example.com", "bar.example.com")
@@ -802,6 +802,12 @@ func (lbc *LoadBalancerController) virtualServerRequiresEndpointsUpdate(vsEx *co
 		}
 	}
 
+	for _, pol := range vsEx.Policies {
+		if isServiceReferencedByPolicy(pol, pol.Namespace, serviceName) {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -874,7 +880,7 @@ func (lbc *LoadBalancerController) createExtendedResources(resources []Resource)
 				result.IngressExes = append(result.IngressExes, ingEx)
 			}
 		case *TransportServerConfiguration:
-			tsEx := lbc.createTransportServerEx(impl.TransportServer, impl.ListenerPort, impl.IPv4, impl.IPv6)
+			tsEx := lbc.createTransportServerEx(impl)
 			result.TransportServerExes = append(result.TransportServerExes, tsEx)
 		}
 	}
@@ -1297,7 +1303,7 @@ func (lbc *LoadBalancerController) processChanges(changes []ResourceChange) {
 					lbc.updateRegularIngressStatusAndEvents(impl, warnings, addOrUpdateErr)
 				}
 			case *TransportServerConfiguration:
-				tsEx := lbc.createTransportServerEx(impl.TransportServer, impl.ListenerPort, impl.IPv4, impl.IPv6)
+				tsEx := lbc.createTransportServerEx(impl)
 				warnings, addOrUpdateErr := lbc.configurator.AddOrUpdateTransportServer(tsEx)
 				lbc.updateTransportServerStatusAndEvents(impl, warnings, addOrUpdateErr)
 			}
@@ -2379,6 +2385,14 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 		virtualServerEx.HTTPIPv6 = vsc.HTTPIPv6
 		virtualServerEx.HTTPSIPv4 = vsc.HTTPSIPv4
 		virtualServerEx.HTTPSIPv6 = vsc.HTTPSIPv6
+		virtualServerEx.HTTPProxyProtocol = vsc.HTTPProxyProtocol
+		virtualServerEx.HTTPSProxyProtocol = vsc.HTTPSProxyProtocol
+		virtualServerEx.HTTPSetRealIPFrom = vsc.HTTPSetRealIPFrom
+		virtualServerEx.HTTPSSetRealIPFrom = vsc.HTTPSSetRealIPFrom
+		virtualServerEx.HTTPRealIPHeader = vsc.HTTPRealIPHeader
+		virtualServerEx.HTTPSRealIPHeader = vsc.HTTPSRealIPHeader
+		virtualServerEx.HTTP3 = vsc.HTTP3
+		virtualServerEx.HTTP3ReusePort = vsc.HTTP3ReusePort
 	}
 
 	if virtualServer.Spec.TLS != nil && virtualServer.Spec.TLS.Secret != "" {
@@ -2421,6 +2435,10 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 	if err != nil {
 		nl.Warnf(lbc.Logger, "Error getting APIKey secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
 	}
+	err = lbc.addSignatureValidationSecretRefs(virtualServerEx.SecretRefs, policies)
+	if err != nil {
+		nl.Warnf(lbc.Logger, "Error getting Signature Validation secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
+	}
 
 	err = lbc.addWAFPolicyRefs(virtualServerEx.ApPolRefs, virtualServerEx.LogConfRefs, policies)
 	if err != nil {
@@ -2461,6 +2479,12 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 	}
 
 	for _, u := range virtualServer.Spec.Upstreams {
+		// static upstreams don't depend on the endpoints of a service
+		if len(u.Servers) > 0 {
+			generateBackupEndpoints(endpoints, u)
+			continue
+		}
+
 		endpointsKey := configs.GenerateEndpointsKey(virtualServer.Namespace, u.Service, u.Subselector, u.Port)
 
 		var endps []string
@@ -2549,6 +2573,10 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 		if err != nil {
 			nl.Warnf(lbc.Logger, "Error getting APIKey secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
 		}
+		err = lbc.addSignatureValidationSecretRefs(virtualServerEx.SecretRefs, vsRoutePolicies)
+		if err != nil {
+			nl.Warnf(lbc.Logger, "Error getting Signature Validation secrets for VirtualServer %v/%v: %v", virtualServer.Namespace, virtualServer.Name, err)
+		}
 
 	}
 
@@ -2584,6 +2612,10 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 			if err != nil {
 				nl.Warnf(lbc.Logger, "Error getting APIKey secrets for VirtualServerRoute %v/%v: %v", vsr.Namespace, vsr.Name, err)
 			}
+			err = lbc.addSignatureValidationSecretRefs(virtualServerEx.SecretRefs, vsrSubroutePolicies)
+			if err != nil {
+				nl.Warnf(lbc.Logger, "Error getting Signature Validation secrets for VirtualServerRoute %v/%v: %v", vsr.Namespace, vsr.Name, err)
+			}
 
 			err = lbc.addWAFPolicyRefs(virtualServerEx.ApPolRefs, virtualServerEx.LogConfRefs, vsrSubroutePolicies)
 			if err != nil {
@@ -2600,6 +2632,12 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 		}
 
 		for _, u := range vsr.Spec.Upstreams {
+			// static upstreams don't depend on the endpoints of a service
+			if len(u.Servers) > 0 {
+				generateBackupEndpoints(endpoints, u)
+				continue
+			}
+
 			endpointsKey := configs.GenerateEndpointsKey(vsr.Namespace, u.Service, u.Subselector, u.Port)
 
 			var endps []string
@@ -2645,6 +2683,8 @@ func (lbc *LoadBalancerController) createVirtualServerEx(virtualServer *conf_v1.
 		}
 	}
 
+	lbc.addExternalAuthEndpoints(endpoints, externalNameSvcs, policies)
+
 	virtualServerEx.Endpoints = endpoints
 	virtualServerEx.VirtualServerRoutes = virtualServerRoutes
 	virtualServerEx.ExternalNameSvcs = externalNameSvcs
@@ -2867,6 +2907,53 @@ func (lbc *LoadBalancerController) addAPIKeySecretRefs(secretRefs map[string]*se
 	return nil
 }
 
+func (lbc *LoadBalancerController) addSignatureValidationSecretRefs(secretRefs map[string]*secrets.SecretReference, policies []*conf_v1.Policy) error {
+	for _, pol := range policies {
+		if pol.Spec.SignatureValidation == nil {
+			continue
+		}
+
+		secretKey := fmt.Sprintf("%v/%v", pol.Namespace, pol.Spec.SignatureValidation.Secret)
+		secretRef := lbc.secretStore.GetSecret(secretKey)
+
+		secretRefs[secretKey] = secretRef
+
+		if secretRef.Error != nil {
+			return secretRef.Error
+		}
+	}
+	return nil
+}
+
+func (lbc *LoadBalancerController) addExternalAuthEndpoints(endpoints map[string][]string, externalNameSvcs map[string]bool, policies []*conf_v1.Policy) {
+	for _, pol := range policies {
+		if pol.Spec.ExternalAuth == nil {
+			continue
+		}
+
+		svcName, svcPort, err := configs.ParseExternalAuthService(pol.Spec.ExternalAuth.AuthService)
+		if err != nil {
+			nl.Warnf(lbc.Logger, "Error parsing auth service of Policy %v/%v: %v", pol.Namespace, pol.Name, err)
+			continue
+		}
+
+		endpointsKey := configs.GenerateEndpointsKey(pol.Namespace, svcName, nil, svcPort)
+		podEndps, external, err := lbc.getEndpointsForUpstream(pol.Namespace, svcName, svcPort)
+		if err != nil {
+			nl.Warnf(lbc.Logger, "Error getting Endpoints for auth service of Policy %v/%v: %v", pol.Namespace, pol.Name, err)
+		}
+		if err == nil && external && lbc.isNginxPlus {
+			externalNameSvcs[configs.GenerateExternalNameSvcKey(pol.Namespace, svcName)] = true
+		}
+
+		endpoints[endpointsKey] = getIPAddressesFromEndpoints(podEndps)
+	}
+}
+
+func (lbc *LoadBalancerController) getPoliciesForService(svcNamespace string, svcName string) []*conf_v1.Policy {
+	return findPoliciesForService(lbc.getAllPolicies(), svcNamespace, svcName)
+}
+
 func (lbc *LoadBalancerController) getPoliciesForSecret(secretNamespace string, secretName string) []*conf_v1.Policy {
 	return findPoliciesForSecret(lbc.getAllPolicies(), secretNamespace, secretName)
 }
@@ -2889,6 +2976,8 @@ func findPoliciesForSecret(policies []*conf_v1.Policy, secretNamespace string, s
 			res = append(res, pol)
 		} else if pol.Spec.APIKey != nil && pol.Spec.APIKey.ClientSecret == secretName && pol.Namespace == secretNamespace {
 			res = append(res, pol)
+		} else if pol.Spec.SignatureValidation != nil && pol.Spec.SignatureValidation.Secret == secretName && pol.Namespace == secretNamespace {
+			res = append(res, pol)
 		}
 	}
 
@@ -74,6 +74,12 @@ func (lbc *LoadBalancerController) syncEndpointSlices(task task) bool {
 	endpointSlice := obj.(*discovery_v1.EndpointSlice)
 	svcName := endpointSlice.Labels["kubernetes.io/service-name"]
 	svcResource := lbc.configuration.FindResourcesForService(endpointSlice.Namespace, svcName)
+	if lbc.areCustomResourcesEnabled {
+		for _, pol := range lbc.getPoliciesForService(endpointSlice.Namespace, svcName) {
+			svcResource = append(svcResource, lbc.configuration.FindResourcesForPolicy(pol.Namespace, pol.Name)...)
+		}
+		svcResource = removeDuplicateResources(svcResource)
+	}
 
 	// check if this is the endpointslice for the controller's own service
 	if lbc.statusUpdater.namespace == endpointSlice.Namespace && lbc.statusUpdater.externalServiceName == svcName {
@@ -101,12 +101,12 @@ func (lbc *LoadBalancerController) syncPolicy(task task) {
 	resources := lbc.configuration.FindResourcesForPolicy(namespace, name)
 	resourceExes := lbc.createExtendedResources(resources)
 
-	// Only VirtualServers support policies
-	if len(resourceExes.VirtualServerExes) == 0 {
+	// Only VirtualServers and TransportServers support policies
+	if len(resourceExes.VirtualServerExes) == 0 && len(resourceExes.TransportServerExes) == 0 {
 		return
 	}
 
-	warnings, updateErr := lbc.configurator.AddOrUpdateVirtualServers(resourceExes.VirtualServerExes)
+	warnings, updateErr := lbc.configurator.AddOrUpdateResources(resourceExes, true)
 	lbc.updateResourcesStatusAndEvents(resources, warnings, updateErr)
 
 	// Note: updating the status of a policy based on a reload is not needed.
@@ -99,6 +99,12 @@ func (rc *secretReferenceChecker) IsReferencedByTransportServer(secretNamespace
 		return true
 	}
 
+	for _, u := range ts.Spec.Upstreams {
+		if u.TLS != nil && (u.TLS.TLSSecret == secretName || u.TLS.TrustedCertSecret == secretName) {
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -147,7 +153,11 @@ func (rc *serviceReferenceChecker) IsReferencedByVirtualServer(svcNamespace stri
 		if rc.hasClusterIP && u.UseClusterIP {
 			continue
 		}
-		if u.Service == svcName || u.Backup == svcName {
+		if u.Backup == svcName {
+			return true
+		}
+		// static upstreams don't reference a service
+		if len(u.Servers) == 0 && u.Service == svcName {
 			return true
 		}
 	}
@@ -164,7 +174,8 @@ func (rc *serviceReferenceChecker) IsReferencedByVirtualServerRoute(svcNamespace
 		if rc.hasClusterIP && u.UseClusterIP {
 			continue
 		}
-		if u.Service == svcName {
+		// static upstreams don't reference a service
+		if len(u.Servers) == 0 && u.Service == svcName {
 			return true
 		}
 	}
@@ -186,6 +197,28 @@ func (rc *serviceReferenceChecker) IsReferencedByTransportServer(svcNamespace st
 	return false
 }
 
+// findPoliciesForService finds the policies that reference the specified service.
+func findPoliciesForService(policies []*conf_v1.Policy, svcNamespace string, svcName string) []*conf_v1.Policy {
+	var res []*conf_v1.Policy
+
+	for _, pol := range policies {
+		if isServiceReferencedByPolicy(pol, svcNamespace, svcName) {
+			res = append(res, pol)
+		}
+	}
+
+	return res
+}
+
+func isServiceReferencedByPolicy(pol *conf_v1.Policy, svcNamespace string, svcName string) bool {
+	if pol.Namespace != svcNamespace || pol.Spec.ExternalAuth == nil {
+		return false
+	}
+
+	name, _, err := configs.ParseExternalAuthService(pol.Spec.ExternalAuth.AuthService)
+	return err == nil && name == svcName
+}
+
 type policyReferenceChecker struct{}
 
 func newPolicyReferenceChecker() *policyReferenceChecker {
@@ -224,8 +257,8 @@ func (rc *policyReferenceChecker) IsReferencedByVirtualServerRoute(policyNamespa
 	return false
 }
 
-func (rc *policyReferenceChecker) IsReferencedByTransportServer(_ string, _ string, _ *conf_v1.TransportServer) bool {
-	return false
+func (rc *policyReferenceChecker) IsReferencedByTransportServer(policyNamespace string, policyName string, ts *conf_v1.TransportServer) bool {
+	return isPolicyReferenced(ts.Spec.Policies, ts.Namespace, policyNamespace, policyName)
 }
 
 // appProtectResourceReferenceChecker is a reference checker for AppProtect related resources.
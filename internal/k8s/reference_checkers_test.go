@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/nginx/kubernetes-ingress/internal/configs"
@@ -364,6 +365,69 @@ func TestSecretIsReferencedByTransportServer(t *testing.T) {
 			expected:        false,
 			msg:             "tls secret is not but in another namespace",
 		},
+		{
+			ts: &conf_v1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					Upstreams: []conf_v1.TransportServerUpstream{
+						{
+							TLS: &conf_v1.TransportServerUpstreamTLS{
+								Enable:    true,
+								TLSSecret: "test-secret",
+							},
+						},
+					},
+				},
+			},
+			secretNamespace: "default",
+			secretName:      "test-secret",
+			expected:        true,
+			msg:             "upstream tls secret is referenced",
+		},
+		{
+			ts: &conf_v1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					Upstreams: []conf_v1.TransportServerUpstream{
+						{
+							TLS: &conf_v1.TransportServerUpstreamTLS{
+								Enable:            true,
+								TrustedCertSecret: "test-secret",
+							},
+						},
+					},
+				},
+			},
+			secretNamespace: "default",
+			secretName:      "test-secret",
+			expected:        true,
+			msg:             "upstream trusted cert secret is referenced",
+		},
+		{
+			ts: &conf_v1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					Upstreams: []conf_v1.TransportServerUpstream{
+						{
+							TLS: &conf_v1.TransportServerUpstreamTLS{
+								Enable:    true,
+								TLSSecret: "test-secret",
+							},
+						},
+					},
+				},
+			},
+			secretNamespace: "other-namespace",
+			secretName:      "test-secret",
+			expected:        false,
+			msg:             "upstream tls secret is referenced but in another namespace",
+		},
 	}
 
 	for _, test := range tests {
@@ -687,6 +751,38 @@ func TestServiceIsReferencedByVirtualServerAndVirtualServerRoutes(t *testing.T)
 			expected:         false,
 			msg:              "wrong name for service in an upstream",
 		},
+		{
+			vs: &conf_v1.VirtualServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerSpec{
+					Upstreams: []conf_v1.Upstream{
+						{
+							Service: "test-service",
+							Servers: []string{"10.0.0.1:80"},
+						},
+					},
+				},
+			},
+			vsr: &conf_v1.VirtualServerRoute{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.VirtualServerRouteSpec{
+					Upstreams: []conf_v1.Upstream{
+						{
+							Service: "test-service",
+							Servers: []string{"10.0.0.1:80"},
+						},
+					},
+				},
+			},
+			serviceNamespace: "default",
+			serviceName:      "test-service",
+			expected:         false,
+			msg:              "upstream with static servers",
+		},
 	}
 
 	for _, test := range tests {
@@ -854,7 +950,7 @@ func TestBackupServiceIsReferencedByTransportServer(t *testing.T) {
 	}
 }
 
-func TestPolicyIsReferencedByIngressesAndTransportServers(t *testing.T) {
+func TestPolicyIsReferencedByIngresses(t *testing.T) {
 	t.Parallel()
 	rc := newPolicyReferenceChecker()
 
@@ -867,10 +963,93 @@ func TestPolicyIsReferencedByIngressesAndTransportServers(t *testing.T) {
 	if result {
 		t.Error("IsReferencedByMinion() returned true but expected false")
 	}
+}
 
-	result = rc.IsReferencedByTransportServer("", "", nil)
-	if result {
-		t.Error("IsReferencedByTransportServer() returned true but expected false")
+func TestPolicyIsReferencedByTransportServer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		ts              *conf_v1.TransportServer
+		policyNamespace string
+		policyName      string
+		expected        bool
+		msg             string
+	}{
+		{
+			ts: &conf_v1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					Policies: []conf_v1.PolicyReference{
+						{
+							Name: "test-policy",
+						},
+					},
+				},
+			},
+			policyNamespace: "default",
+			policyName:      "test-policy",
+			expected:        true,
+			msg:             "policy is referenced in the same namespace",
+		},
+		{
+			ts: &conf_v1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					Policies: []conf_v1.PolicyReference{
+						{
+							Name:      "test-policy",
+							Namespace: "nginx-ingress",
+						},
+					},
+				},
+			},
+			policyNamespace: "nginx-ingress",
+			policyName:      "test-policy",
+			expected:        true,
+			msg:             "policy is referenced in another namespace",
+		},
+		{
+			ts: &conf_v1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+				Spec: conf_v1.TransportServerSpec{
+					Policies: []conf_v1.PolicyReference{
+						{
+							Name: "test-policy",
+						},
+					},
+				},
+			},
+			policyNamespace: "nginx-ingress",
+			policyName:      "test-policy",
+			expected:        false,
+			msg:             "policy is not referenced in another namespace",
+		},
+		{
+			ts: &conf_v1.TransportServer{
+				ObjectMeta: v1.ObjectMeta{
+					Namespace: "default",
+				},
+			},
+			policyNamespace: "default",
+			policyName:      "test-policy",
+			expected:        false,
+			msg:             "no policies",
+		},
+	}
+
+	rc := newPolicyReferenceChecker()
+
+	for _, test := range tests {
+		result := rc.IsReferencedByTransportServer(test.policyNamespace, test.policyName, test.ts)
+		if result != test.expected {
+			t.Errorf("IsReferencedByTransportServer() returned %v but expected %v for the case of %s", result,
+				test.expected, test.msg)
+		}
 	}
 }
 
@@ -1684,3 +1863,72 @@ func TestReplicaReferenceChecker(t *testing.T) {
 		}
 	}
 }
+
+func TestFindPoliciesForService(t *testing.T) {
+	t.Parallel()
+	extAuthPol := &conf_v1.Policy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "ext-auth-policy",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			ExternalAuth: &conf_v1.ExternalAuth{
+				AuthService: "auth-svc:8080",
+			},
+		},
+	}
+
+	extAuthPol2 := &conf_v1.Policy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "ext-auth-policy",
+			Namespace: "ns-1",
+		},
+		Spec: conf_v1.PolicySpec{
+			ExternalAuth: &conf_v1.ExternalAuth{
+				AuthService: "auth-svc:8080",
+			},
+		},
+	}
+
+	corsPol := &conf_v1.Policy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "cors-policy",
+			Namespace: "default",
+		},
+		Spec: conf_v1.PolicySpec{
+			CORS: &conf_v1.CORS{
+				AllowOrigins: []string{"*"},
+			},
+		},
+	}
+
+	tests := []struct {
+		policies     []*conf_v1.Policy
+		svcNamespace string
+		svcName      string
+		expected     []*conf_v1.Policy
+		msg          string
+	}{
+		{
+			policies:     []*conf_v1.Policy{extAuthPol, extAuthPol2, corsPol},
+			svcNamespace: "default",
+			svcName:      "auth-svc",
+			expected:     []*conf_v1.Policy{extAuthPol},
+			msg:          "find policy in default ns, ignore other namespaces and types",
+		},
+		{
+			policies:     []*conf_v1.Policy{extAuthPol, extAuthPol2},
+			svcNamespace: "default",
+			svcName:      "other-svc",
+			expected:     nil,
+			msg:          "ignore policies referencing other services",
+		},
+	}
+
+	for _, test := range tests {
+		result := findPoliciesForService(test.policies, test.svcNamespace, test.svcName)
+		if !reflect.DeepEqual(test.expected, result) {
+			t.Errorf("findPoliciesForService() returned %v but expected %v for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
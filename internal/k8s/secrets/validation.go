@@ -1,11 +1,14 @@
 package secrets
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
 	"regexp"
+	"strings"
 
 	api_v1 "k8s.io/api/core/v1"
 )
@@ -19,9 +22,23 @@ const CAKey = "ca.crt"
 // ClientSecretKey is the key of the data field of a Secret where the OIDC client secret must be stored.
 const ClientSecretKey = "client-secret"
 
+// SessionKeyKey is the key of the data field of an OIDC Secret where the key used to encrypt the session cookies in NGINX must be stored.
+const SessionKeyKey = "session-key"
+
+// sessionKeyMinLength is the minimum length of the OIDC session key.
+const sessionKeyMinLength = 32
+
+// APIKeyHashPrefix is the prefix of the keys of an APIKey Secret which are stored as their hex-encoded SHA-256 hash.
+const APIKeyHashPrefix = "sha256:"
+
+var apiKeyHashRegexp = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
 // HtpasswdFileKey is the key of the data field of a Secret where the HTTP basic authorization list must be stored
 const HtpasswdFileKey = "htpasswd"
 
+// HMACKeyKey is the key of the data field of a Secret where the HMAC key for signature validation must be stored.
+const HMACKeyKey = "key"
+
 // SecretTypeCA contains a certificate authority for TLS certificate verification. #nosec G101
 const SecretTypeCA api_v1.SecretType = "nginx.org/ca" //nolint:gosec // G101: Potential hardcoded credentials - false positive
 
@@ -37,6 +54,9 @@ const SecretTypeHtpasswd api_v1.SecretType = "nginx.org/htpasswd" // #nosec G101
 // SecretTypeAPIKey contains a list of client ID and key for API key authorization.. #nosec G101
 const SecretTypeAPIKey api_v1.SecretType = "nginx.org/apikey" // #nosec G101
 
+// SecretTypeHMAC contains a key for validating HMAC request signatures. #nosec G101
+const SecretTypeHMAC api_v1.SecretType = "nginx.org/hmac" // #nosec G101
+
 // SecretTypeLicense contains the license.jwt required for NGINX Plus. #nosec G101
 const SecretTypeLicense api_v1.SecretType = "nginx.com/license" // #nosec G101
 
@@ -112,6 +132,15 @@ func ValidateOIDCSecret(secret *api_v1.Secret) error {
 	if msg, ok := isValidClientSecretValue(string(clientSecret)); !ok {
 		return fmt.Errorf("OIDC client secret is invalid: %s", msg)
 	}
+
+	if sessionKey, exists := secret.Data[SessionKeyKey]; exists {
+		if len(sessionKey) < sessionKeyMinLength {
+			return fmt.Errorf("OIDC session key must be at least %d characters long", sessionKeyMinLength)
+		}
+		if msg, ok := isValidClientSecretValue(string(sessionKey)); !ok {
+			return fmt.Errorf("OIDC session key is invalid: %s", msg)
+		}
+	}
 	return nil
 }
 
@@ -122,16 +151,47 @@ func ValidateAPIKeySecret(secret *api_v1.Secret) error {
 	}
 
 	uniqueKeys := make(map[string]bool)
-	for _, key := range secret.Data {
-		if uniqueKeys[string(key)] {
+	for clientID, key := range secret.Data {
+		hashedKey, err := HashAPIKey(key)
+		if err != nil {
+			return fmt.Errorf("invalid API Key of the client %s: %w", clientID, err)
+		}
+		if uniqueKeys[hashedKey] {
 			return fmt.Errorf("API Keys cannot be repeated")
 		}
-		uniqueKeys[string(key)] = true
+		uniqueKeys[hashedKey] = true
 	}
 
 	return nil
 }
 
+// ValidateHMACSecret validates the secret. If it is valid, the function returns nil.
+func ValidateHMACSecret(secret *api_v1.Secret) error {
+	if secret.Type != SecretTypeHMAC {
+		return fmt.Errorf("HMAC secret must be of the type %v", SecretTypeHMAC)
+	}
+
+	if len(secret.Data[HMACKeyKey]) == 0 {
+		return fmt.Errorf("HMAC secret must have the non-empty data field %v", HMACKeyKey)
+	}
+
+	return nil
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 hash of a key of an APIKey Secret.
+// Keys with the APIKeyHashPrefix are already hashed, so the Secret does not need to store the keys in plain text.
+func HashAPIKey(key []byte) (string, error) {
+	if hashedKey, found := strings.CutPrefix(string(key), APIKeyHashPrefix); found {
+		if !apiKeyHashRegexp.MatchString(hashedKey) {
+			return "", fmt.Errorf("a hashed API Key must be %s followed by 64 hexadecimal characters", APIKeyHashPrefix)
+		}
+		return strings.ToLower(hashedKey), nil
+	}
+
+	hash := sha256.Sum256(key)
+	return hex.EncodeToString(hash[:]), nil
+}
+
 // ValidateHtpasswdSecret validates the secret. If it is valid, the function returns nil.
 func ValidateHtpasswdSecret(secret *api_v1.Secret) error {
 	if secret.Type != SecretTypeHtpasswd {
@@ -169,6 +229,7 @@ func IsSupportedSecretType(secretType api_v1.SecretType) bool {
 		secretType == SecretTypeOIDC ||
 		secretType == SecretTypeHtpasswd ||
 		secretType == SecretTypeAPIKey ||
+		secretType == SecretTypeHMAC ||
 		secretType == SecretTypeLicense
 }
 
@@ -187,6 +248,8 @@ func ValidateSecret(secret *api_v1.Secret) error {
 		return ValidateHtpasswdSecret(secret)
 	case SecretTypeAPIKey:
 		return ValidateAPIKeySecret(secret)
+	case SecretTypeHMAC:
+		return ValidateHMACSecret(secret)
 	case SecretTypeLicense:
 		return ValidateLicenseSecret(secret)
 	}
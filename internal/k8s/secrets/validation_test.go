@@ -66,6 +66,77 @@ func TestValidateJWKSecretFails(t *testing.T) {
 	}
 }
 
+func TestValidateHMACSecret(t *testing.T) {
+	t.Parallel()
+	secret := &v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "hmac-secret",
+			Namespace: "default",
+		},
+		Type: SecretTypeHMAC,
+		Data: map[string][]byte{
+			"key": []byte("webhook-key"),
+		},
+	}
+
+	err := ValidateHMACSecret(secret)
+	if err != nil {
+		t.Errorf("ValidateHMACSecret() returned error %v", err)
+	}
+}
+
+func TestValidateHMACSecretFails(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		secret *v1.Secret
+		msg    string
+	}{
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "hmac-secret",
+					Namespace: "default",
+				},
+				Type: "some-type",
+				Data: map[string][]byte{
+					"key": []byte("webhook-key"),
+				},
+			},
+			msg: "Incorrect type for HMAC secret",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "hmac-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeHMAC,
+			},
+			msg: "Missing key for HMAC secret",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "hmac-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeHMAC,
+				Data: map[string][]byte{
+					"key": nil,
+				},
+			},
+			msg: "Empty key for HMAC secret",
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateHMACSecret(test.secret)
+		if err == nil {
+			t.Errorf("ValidateHMACSecret() returned no error for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateValidateAPIKeySecret(t *testing.T) {
 	t.Parallel()
 	secret := &v1.Secret{
@@ -77,6 +148,7 @@ func TestValidateValidateAPIKeySecret(t *testing.T) {
 		Data: map[string][]byte{
 			"client1": []byte("cGFzc3dvcmQ="),
 			"client2": []byte("N2ViNDMwOGItY2Q1Yi00NDEzLWI0NTUtYjMyZmQ4OTg2MmZk"),
+			"client3": []byte("sha256:2FDF0A61DC1ACB33CB4DC6D0EC4C3C3E19F2E3C0E2F1B8A3F9D7E5B4C3A2D1E0"),
 		},
 	}
 
@@ -134,6 +206,33 @@ func TestValidateValidateAPIKeyFails(t *testing.T) {
 			},
 			msg: "repeated empty API Keys for API Key secret",
 		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeAPIKey,
+				Data: map[string][]byte{
+					"client1": []byte("password"),
+					"client2": []byte("sha256:5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"),
+				},
+			},
+			msg: "repeated plain and hashed API Keys for API Key secret",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "api-key-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeAPIKey,
+				Data: map[string][]byte{
+					"client1": []byte("sha256:not-a-hash"),
+				},
+			},
+			msg: "invalid hashed API Key for API Key secret",
+		},
 	}
 
 	for _, test := range tests {
@@ -145,6 +244,36 @@ func TestValidateValidateAPIKeyFails(t *testing.T) {
 	}
 }
 
+func TestHashAPIKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		key      string
+		expected string
+		msg      string
+	}{
+		{
+			key:      "password",
+			expected: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+			msg:      "plain API Key",
+		},
+		{
+			key:      "sha256:5E884898DA28047151D0E56F8DC6292773603D0D6AABBDD62A11EF721D1542D8",
+			expected: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+			msg:      "hashed API Key",
+		},
+	}
+
+	for _, test := range tests {
+		result, err := HashAPIKey([]byte(test.key))
+		if err != nil {
+			t.Errorf("HashAPIKey() returned error %v for the case of %s", err, test.msg)
+		}
+		if result != test.expected {
+			t.Errorf("HashAPIKey() returned %q but expected %q for the case of %s", result, test.expected, test.msg)
+		}
+	}
+}
+
 func TestValidateHtpasswdSecret(t *testing.T) {
 	t.Parallel()
 	secret := &v1.Secret{
@@ -384,6 +513,7 @@ func TestValidateOIDCSecret(t *testing.T) {
 		Type: SecretTypeOIDC,
 		Data: map[string][]byte{
 			"client-secret": nil,
+			"session-key":   []byte("8f2b1c6e0d9a4f7b3e5c2a1d6f8b0e4c"),
 		},
 	}
 
@@ -435,6 +565,34 @@ func TestValidateOIDCSecretFails(t *testing.T) {
 			},
 			msg: "Invalid characters in OIDC client secret",
 		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "oidc-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeOIDC,
+				Data: map[string][]byte{
+					"client-secret": []byte("secret"),
+					"session-key":   []byte("too-short"),
+				},
+			},
+			msg: "Too short OIDC session key",
+		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "oidc-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeOIDC,
+				Data: map[string][]byte{
+					"client-secret": []byte("secret"),
+					"session-key":   []byte("a-session-key-that-is-long-enough-but-has-$"),
+				},
+			},
+			msg: "Invalid characters in OIDC session key",
+		},
 		{
 			secret: &v1.Secret{
 				ObjectMeta: meta_v1.ObjectMeta{
@@ -601,6 +759,19 @@ func TestValidateSecret(t *testing.T) {
 			},
 			msg: "Valid API Key secret",
 		},
+		{
+			secret: &v1.Secret{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      "hmac-secret",
+					Namespace: "default",
+				},
+				Type: SecretTypeHMAC,
+				Data: map[string][]byte{
+					"key": []byte("webhook-key"),
+				},
+			},
+			msg: "Valid HMAC secret",
+		},
 	}
 
 	for _, test := range tests {
@@ -714,6 +885,10 @@ func TestHasCorrectSecretType(t *testing.T) {
 			secretType: SecretTypeAPIKey,
 			expected:   true,
 		},
+		{
+			secretType: SecretTypeHMAC,
+			expected:   true,
+		},
 		{
 			secretType: "some-type",
 			expected:   false,
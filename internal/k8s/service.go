@@ -251,6 +251,12 @@ func (lbc *LoadBalancerController) syncService(task task) {
 	namespace, name, _ := ParseNamespaceName(key)
 
 	resources := lbc.configuration.FindResourcesForService(namespace, name)
+	if lbc.areCustomResourcesEnabled {
+		for _, pol := range lbc.getPoliciesForService(namespace, name) {
+			resources = append(resources, lbc.configuration.FindResourcesForPolicy(pol.Namespace, pol.Name)...)
+		}
+		resources = removeDuplicateResources(resources)
+	}
 
 	if len(resources) == 0 {
 		return
@@ -254,7 +254,13 @@ func getExternalServicePorts(svc *api_v1.Service) string {
 	}
 
 	for _, port := range svc.Spec.Ports {
-		if isRequiredPort(port.TargetPort) {
+		if !isRequiredPort(port.TargetPort) {
+			continue
+		}
+		// the UDP ports, which accept HTTP/3 connections, are distinguished from the TCP ports with the same number
+		if port.Protocol == api_v1.ProtocolUDP {
+			ports = append(ports, fmt.Sprintf("%d/UDP", port.Port))
+		} else {
 			ports = append(ports, strconv.Itoa(int(port.Port)))
 		}
 	}
@@ -655,6 +655,47 @@ func TestGetExternalServicePorts(t *testing.T) {
 	}
 }
 
+func TestGetExternalServicePortsWithUDP(t *testing.T) {
+	t.Parallel()
+	svc := v1.Service{
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{
+					Port:     int32(80),
+					Protocol: v1.ProtocolTCP,
+					TargetPort: intstr.IntOrString{
+						Type:   intstr.Int,
+						IntVal: 80,
+					},
+				},
+				{
+					Port:     int32(443),
+					Protocol: v1.ProtocolTCP,
+					TargetPort: intstr.IntOrString{
+						Type:   intstr.Int,
+						IntVal: 443,
+					},
+				},
+				{
+					Port:     int32(443),
+					Protocol: v1.ProtocolUDP,
+					TargetPort: intstr.IntOrString{
+						Type:   intstr.Int,
+						IntVal: 443,
+					},
+				},
+			},
+		},
+	}
+
+	expected := "[80,443,443/UDP]"
+	ports := getExternalServicePorts(&svc)
+
+	if ports != expected {
+		t.Errorf("getExternalServicePorts(%v) returned %v but expected %v", svc, ports, expected)
+	}
+}
+
 func TestIsRequiredPort(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -41,6 +41,20 @@ func createTransportServerHandlers(lbc *LoadBalancerController) cache.ResourceEv
 		},
 		UpdateFunc: func(old, cur interface{}) {
 			curTs := cur.(*conf_v1.TransportServer)
+			oldTs := old.(*conf_v1.TransportServer)
+
+			if lbc.weightChangesDynamicReload && !reflect.DeepEqual(oldTs.Spec, curTs.Spec) && reflect.DeepEqual(oldTs.Annotations, curTs.Annotations) {
+				curTsCopy := curTs.DeepCopy()
+				oldTsCopy := oldTs.DeepCopy()
+				zeroOutTransportServerSplitWeights(curTsCopy)
+				zeroOutTransportServerSplitWeights(oldTsCopy)
+
+				if reflect.DeepEqual(oldTsCopy.Spec, curTsCopy.Spec) {
+					lbc.processTSWeightChangesDynamicReload(oldTs, curTs)
+					return
+				}
+			}
+
 			if !reflect.DeepEqual(old, cur) {
 				nl.Debugf(lbc.Logger, "TransportServer %v changed, syncing", curTs.Name)
 				lbc.AddSyncQueue(curTs)
@@ -49,6 +63,25 @@ func createTransportServerHandlers(lbc *LoadBalancerController) cache.ResourceEv
 	}
 }
 
+// transportServerActions returns the actions of the matches and the default action of a TransportServer,
+// in the order in which the configuration of their splits is generated.
+func transportServerActions(ts *conf_v1.TransportServer) []*conf_v1.TransportServerAction {
+	var actions []*conf_v1.TransportServerAction
+	for _, m := range ts.Spec.Matches {
+		actions = append(actions, m.Action)
+	}
+	return append(actions, ts.Spec.Action)
+}
+
+func zeroOutTransportServerSplitWeights(ts *conf_v1.TransportServer) {
+	for _, action := range transportServerActions(ts) {
+		if action != nil && len(action.Splits) == 2 {
+			action.Splits[0].Weight = 0
+			action.Splits[1].Weight = 0
+		}
+	}
+}
+
 func (nsi *namespacedInformer) addTransportServerHandler(handlers cache.ResourceEventHandlerFuncs) {
 	informer := nsi.confSharedInformerFactory.K8s().V1().TransportServers().Informer()
 	informer.AddEventHandler(handlers) //nolint:errcheck,gosec
@@ -86,6 +119,88 @@ func (lbc *LoadBalancerController) syncTransportServer(task task) {
 	lbc.processProblems(problems)
 }
 
+func (lbc *LoadBalancerController) processTSWeightChangesDynamicReload(tsOld *conf_v1.TransportServer, tsNew *conf_v1.TransportServer) {
+	var weightUpdates []configs.WeightUpdate
+	var splitClientsIndex int
+	variableNamer := configs.NewTSVariableNamer(tsNew)
+
+	actionsOld := transportServerActions(tsOld)
+	for i, actionNew := range transportServerActions(tsNew) {
+		if actionNew == nil {
+			continue
+		}
+		actionOld := actionsOld[i]
+		if len(actionNew.Splits) == 2 {
+			if actionNew.Splits[0].Weight != actionOld.Splits[0].Weight || actionNew.Splits[1].Weight != actionOld.Splits[1].Weight {
+				weightUpdates = append(weightUpdates, configs.WeightUpdate{
+					Zone:  variableNamer.GetNameOfKeyvalZoneForSplitClientIndex(splitClientsIndex),
+					Key:   variableNamer.GetNameOfKeyvalKeyForSplitClientIndex(splitClientsIndex),
+					Value: variableNamer.GetNameOfKeyOfMapForWeights(splitClientsIndex, actionNew.Splits[0].Weight, actionNew.Splits[1].Weight),
+				})
+			}
+			splitClientsIndex += splitClientAmountWhenWeightChangesDynamicReload
+		} else if len(actionNew.Splits) > 0 {
+			splitClientsIndex++
+		}
+	}
+
+	if len(weightUpdates) == 0 {
+		return
+	}
+
+	if tsOld.Status.State == conf_v1.StateInvalid {
+		lbc.AddSyncQueue(tsNew)
+		return
+	}
+
+	if lbc.haltIfTSConfigInvalid(tsNew) {
+		return
+	}
+
+	for _, weight := range weightUpdates {
+		lbc.configurator.UpsertStreamSplitClientsKeyVal(weight.Zone, weight.Key, weight.Value)
+	}
+}
+
+// haltIfTSConfigInvalid stores a TransportServer whose weights changed in the configuration and reports whether
+// the weights must not be updated in NGINX. In that case, the TransportServer is synced as usual.
+func (lbc *LoadBalancerController) haltIfTSConfigInvalid(tsNew *conf_v1.TransportServer) bool {
+	lbc.configuration.lock.Lock()
+	defer lbc.configuration.lock.Unlock()
+	key := getResourceKey(&tsNew.ObjectMeta)
+
+	// the TransportServer is not in the configuration if it was invalid or is handled by another Ingress Controller
+	if _, exists := lbc.configuration.transportServers[key]; !exists {
+		lbc.AddSyncQueue(tsNew)
+		return true
+	}
+
+	if err := lbc.configuration.transportServerValidator.ValidateTransportServer(tsNew); err != nil {
+		lbc.AddSyncQueue(tsNew)
+		return true
+	}
+	lbc.configuration.transportServers[key] = tsNew
+
+	changes, problems := lbc.configuration.rebuildListenerHosts()
+	if lbc.configuration.isTLSPassthroughEnabled {
+		hostChanges, hostProblems := lbc.configuration.rebuildHosts()
+		changes = append(changes, hostChanges...)
+		problems = append(problems, hostProblems...)
+	}
+
+	if len(problems) > 0 {
+		lbc.processProblems(problems)
+	}
+
+	for _, c := range changes {
+		if impl, ok := c.Resource.(*TransportServerConfiguration); ok && c.Op == AddOrUpdate {
+			lbc.updateTransportServerStatusAndEvents(impl, configs.Warnings{}, nil)
+		}
+	}
+
+	return false
+}
+
 func (lbc *LoadBalancerController) updateTransportServerStatusAndEventsOnDelete(tsConfig *TransportServerConfiguration, changeError string, deleteErr error) {
 	eventType := api_v1.EventTypeWarning
 	eventTitle := nl.EventReasonRejected
@@ -202,7 +317,8 @@ func (lbc *LoadBalancerController) updateTransportServersStatusFromEvents() erro
 	return nil
 }
 
-func (lbc *LoadBalancerController) createTransportServerEx(transportServer *conf_v1.TransportServer, listenerPort int, ipv4 string, ipv6 string) *configs.TransportServerEx {
+func (lbc *LoadBalancerController) createTransportServerEx(tsc *TransportServerConfiguration) *configs.TransportServerEx {
+	transportServer := tsc.TransportServer
 	endpoints := make(map[string][]string)
 	externalNameSvcs := make(map[string]bool)
 	podsByIP := make(map[string]string)
@@ -248,16 +364,48 @@ func (lbc *LoadBalancerController) createTransportServerEx(transportServer *conf
 		scrtRefs[scrtKey] = scrtRef
 	}
 
+	for _, u := range transportServer.Spec.Upstreams {
+		if u.TLS == nil || !u.TLS.Enable {
+			continue
+		}
+		for _, secret := range []string{u.TLS.TLSSecret, u.TLS.TrustedCertSecret} {
+			if secret == "" {
+				continue
+			}
+			scrtKey := transportServer.Namespace + "/" + secret
+
+			scrtRef := lbc.secretStore.GetSecret(scrtKey)
+			if scrtRef.Error != nil {
+				nl.Warnf(lbc.Logger, "Error trying to get the secret %v for the upstream %v of TransportServer %v: %v", scrtKey, u.Name, transportServer.Name, scrtRef.Error)
+			}
+
+			scrtRefs[scrtKey] = scrtRef
+		}
+	}
+
+	policies, policyErrors := lbc.getPolicies(transportServer.Spec.Policies, transportServer.Namespace)
+	for _, err := range policyErrors {
+		nl.Warnf(lbc.Logger, "Error getting policy for TransportServer %s/%s: %v", transportServer.Namespace, transportServer.Name, err)
+	}
+
+	err := lbc.addIngressMTLSSecretRefs(scrtRefs, policies)
+	if err != nil {
+		nl.Warnf(lbc.Logger, "Error getting IngressMTLS secret for TransportServer %v/%v: %v", transportServer.Namespace, transportServer.Name, err)
+	}
+
 	return &configs.TransportServerEx{
-		ListenerPort:     listenerPort,
-		IPv4:             ipv4,
-		IPv6:             ipv6,
+		ListenerPort:     tsc.ListenerPort,
+		IPv4:             tsc.IPv4,
+		IPv6:             tsc.IPv6,
+		ProxyProtocol:    tsc.ProxyProtocol,
+		SetRealIPFrom:    tsc.SetRealIPFrom,
 		TransportServer:  transportServer,
 		Endpoints:        endpoints,
 		PodsByIP:         podsByIP,
 		ExternalNameSvcs: externalNameSvcs,
 		DisableIPV6:      disableIPV6,
 		SecretRefs:       scrtRefs,
+		Policies:         createPolicyMap(policies),
 	}
 }
 
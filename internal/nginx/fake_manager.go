@@ -193,6 +193,11 @@ func (fm *FakeManager) UpsertSplitClientsKeyVal(_ string, _ string, _ string) {
 	nl.Debugf(fm.logger, "Creating split clients key")
 }
 
+// UpsertStreamSplitClientsKeyVal is a fake implementation of UpsertStreamSplitClientsKeyVal
+func (fm *FakeManager) UpsertStreamSplitClientsKeyVal(_ string, _ string, _ string) {
+	nl.Debugf(fm.logger, "Creating stream split clients key")
+}
+
 // DeleteKeyValStateFiles is a fake implementation of DeleteKeyValStateFiles
 func (fm *FakeManager) DeleteKeyValStateFiles(_ string) {
 	nl.Debugf(fm.logger, "Deleting keyval state files")
@@ -34,6 +34,8 @@ const (
 	JWKSecretFileMode = 0o644
 	// HtpasswdSecretFileMode defines the default filemode for HTTP basic auth user files.
 	HtpasswdSecretFileMode = 0o644
+	// HMACSecretFileMode defines the default filemode for files with HMAC keys for signature validation.
+	HMACSecretFileMode = 0o644
 
 	configFileMode       = 0o644
 	nginxBinaryPath      = "/usr/sbin/nginx"
@@ -99,7 +101,8 @@ type Manager interface {
 	AgentVersion() string
 	GetSecretsDir() string
 	UpsertSplitClientsKeyVal(zoneName string, key string, value string)
-	DeleteKeyValStateFiles(virtualServerName string)
+	UpsertStreamSplitClientsKeyVal(zoneName string, key string, value string)
+	DeleteKeyValStateFiles(resourceName string)
 }
 
 // LocalManager updates NGINX configuration, starts, reloads and quits NGINX, updates License Reporting and the Deployment Metadata file
@@ -701,24 +704,43 @@ func configContentsChanged(filename string, content []byte) bool {
 
 // UpsertSplitClientsKeyVal upserts a key value pair in the split clients zone.
 func (lm *LocalManager) UpsertSplitClientsKeyVal(zoneName, key, value string) {
+	lm.upsertSplitClientsKeyVal(zoneName, key, value, false)
+}
+
+// UpsertStreamSplitClientsKeyVal upserts a key value pair in the split clients zone of the stream context.
+func (lm *LocalManager) UpsertStreamSplitClientsKeyVal(zoneName, key, value string) {
+	lm.upsertSplitClientsKeyVal(zoneName, key, value, true)
+}
+
+func (lm *LocalManager) upsertSplitClientsKeyVal(zoneName, key, value string, stream bool) {
 	key = strings.Trim(key, "\"")
 	value = strings.Trim(value, "\"")
 
-	keyValPairs, err := lm.plusClient.GetKeyValPairs(context.Background(), zoneName)
+	getKeyValPairs := lm.plusClient.GetKeyValPairs
+	if stream {
+		getKeyValPairs = lm.plusClient.GetStreamKeyValPairs
+	}
+
+	keyValPairs, err := getKeyValPairs(context.Background(), zoneName)
 	if err != nil {
-		lm.tryAddKeyValPair(zoneName, key, value)
+		lm.tryAddKeyValPair(zoneName, key, value, stream)
 		return
 	}
 
 	if _, ok := keyValPairs[key]; ok {
-		lm.tryModifyKeyValPair(zoneName, key, value)
+		lm.tryModifyKeyValPair(zoneName, key, value, stream)
 	} else {
-		lm.tryAddKeyValPair(zoneName, key, value)
+		lm.tryAddKeyValPair(zoneName, key, value, stream)
 	}
 }
 
-func (lm *LocalManager) tryAddKeyValPair(zoneName, key, value string) {
-	err := lm.plusClient.AddKeyValPair(context.Background(), zoneName, key, value)
+func (lm *LocalManager) tryAddKeyValPair(zoneName, key, value string, stream bool) {
+	addKeyValPair := lm.plusClient.AddKeyValPair
+	if stream {
+		addKeyValPair = lm.plusClient.AddStreamKeyValPair
+	}
+
+	err := addKeyValPair(context.Background(), zoneName, key, value)
 	if err != nil {
 		nl.Warnf(lm.logger, "Failed to add key value pair: %v", err)
 	} else {
@@ -726,8 +748,13 @@ func (lm *LocalManager) tryAddKeyValPair(zoneName, key, value string) {
 	}
 }
 
-func (lm *LocalManager) tryModifyKeyValPair(zoneName, key, value string) {
-	err := lm.plusClient.ModifyKeyValPair(context.Background(), zoneName, key, value)
+func (lm *LocalManager) tryModifyKeyValPair(zoneName, key, value string, stream bool) {
+	modifyKeyValPair := lm.plusClient.ModifyKeyValPair
+	if stream {
+		modifyKeyValPair = lm.plusClient.ModifyStreamKeyValPair
+	}
+
+	err := modifyKeyValPair(context.Background(), zoneName, key, value)
 	if err != nil {
 		nl.Warnf(lm.logger, "Failed to modify key value pair: %v", err)
 	} else {
@@ -735,14 +762,14 @@ func (lm *LocalManager) tryModifyKeyValPair(zoneName, key, value string) {
 	}
 }
 
-// DeleteKeyValStateFiles deletes the state files in the /etc/nginx/state_files folder for the given virtual server.
-func (lm *LocalManager) DeleteKeyValStateFiles(virtualServerName string) {
+// DeleteKeyValStateFiles deletes the state files in the /etc/nginx/state_files folder for the given VirtualServer or TransportServer.
+func (lm *LocalManager) DeleteKeyValStateFiles(resourceName string) {
 	files, err := os.ReadDir(lm.stateFilesPath)
 	if err != nil {
 		nl.Warnf(lm.logger, "Failed to read the state files directory %s: %v", lm.stateFilesPath, err)
 	}
 	for _, file := range files {
-		if strings.HasPrefix(file.Name(), virtualServerName+"_keyval_zone_split_clients") {
+		if strings.HasPrefix(file.Name(), resourceName+"_keyval_zone_split_clients") {
 			if err := os.Remove(path.Join(lm.stateFilesPath, file.Name())); err != nil {
 				nl.Warnf(lm.logger, "Failed to delete the state file %s: %v", file.Name(), err)
 			}
@@ -17,6 +17,12 @@ const (
 	TLSPassthroughListenerName = "tls-passthrough"
 	// TLSPassthroughListenerProtocol is the protocol of a built-in TLS Passthrough listener.
 	TLSPassthroughListenerProtocol = "TLS_PASSTHROUGH"
+	// PathTypeExact is the path type of a route that matches the URI of a request exactly.
+	PathTypeExact = "Exact"
+	// PathTypePrefix is the path type of a route that matches the URIs of requests that start with the path.
+	PathTypePrefix = "Prefix"
+	// PathTypeRegularExpression is the path type of a route that matches the URIs of requests against a regular expression.
+	PathTypeRegularExpression = "RegularExpression"
 )
 
 // +genclient
@@ -124,6 +130,10 @@ type Upstream struct {
 	Subselector map[string]string `json:"subselector"`
 	// The port of the service. If the service doesn’t define that port, NGINX will assume the service has zero endpoints and return a 502 response for requests for this upstream. The port must fall into the range 1..65535.
 	Port uint16 `json:"port"`
+	// A list of static upstream servers in the host:port format, where the host is an IP address or a hostname. Use it for backends that run outside of the cluster. The servers are used instead of the endpoints of a service, so service, port, subselector and use-cluster-ip must not be specified.
+	Servers []string `json:"servers"`
+	// Enables periodic re-resolution of the hostnames of the static servers, so that the changes of the DNS records are picked up without a reload. The records are re-resolved according to the resolver-valid ConfigMap key. Requires a resolver configured in the ConfigMap. Note: this feature is supported only in NGINX Plus.
+	Resolve bool `json:"resolve"`
 	// The load balancing method. To use the round-robin method, specify round_robin. The default is specified in the lb-method ConfigMap key.
 	LBMethod string `json:"lb-method"`
 	// The time during which the specified number of unsuccessful attempts to communicate with an upstream server should happen to consider the server unavailable. The default is set in the fail-timeout ConfigMap key.
@@ -262,6 +272,10 @@ type SessionCookie struct {
 type Route struct {
 	// The path of the route. NGINX will match it against the URI of a request. Possible values are: a prefix ( / , /path ), an exact match ( =/exact/match ), a case insensitive regular expression ( ~*^/Bar.*\.jpg ) or a case sensitive regular expression ( ~^/foo.*\.jpg ). In the case of a prefix (must start with / ) or an exact match (must start with = ), the path must not include any whitespace characters, { , } or ;. In the case of the regex matches, all double quotes " must be escaped and the match can’t end in an unescaped backslash \. The path must be unique among the paths of all routes of the VirtualServer. Check the location directive for more information.
 	Path string `json:"path"`
+	// The type of the path match. Possible values are Exact, Prefix and RegularExpression. When set, the path must not include the =, ~ or ~* modifiers: an Exact or Prefix path must start with / , and a RegularExpression path is a regular expression. When not set, the type of the match is defined by the modifier of the path.
+	PathType string `json:"pathType"`
+	// Matches the path case-insensitively. Requires the pathType.
+	CaseInsensitive bool `json:"caseInsensitive"`
 	// A list of policies. The policies override the policies of the same type defined in the spec of the VirtualServer.
 	Policies []PolicyReference `json:"policies"`
 	// The name of a VirtualServerRoute resource that defines this route. If the VirtualServerRoute belongs to a different namespace than the VirtualServer, you need to include the namespace. For example, tea-namespace/tea.
@@ -270,6 +284,8 @@ type Route struct {
 	Action *Action `json:"action"`
 	// The default splits configuration for traffic splitting. Must include at least 2 splits.
 	Splits []Split `json:"splits"`
+	// The request attribute used to consistently distribute requests between the splits, so that requests with the same value of the attribute are always handled by the same split. Also applies to the splits of the matches that don't define their own splitKey. Requests without the attribute are distributed randomly. By default, all requests are distributed randomly.
+	SplitKey *SplitKey `json:"splitKey"`
 	// The matching rules for advanced content-based routing. Requires the default Action or Splits. Unmatched requests will be handled by the default Action or Splits.
 	Matches []Match `json:"matches"`
 	// The custom responses for error codes. NGINX will use those responses instead of returning the error responses from the upstream servers or the default responses generated by NGINX. A custom response can be a redirect or a canned response. For example, a redirect to another URL if an upstream server responded with a 404 status code.
@@ -322,6 +338,32 @@ type ActionProxy struct {
 	RequestHeaders *ProxyRequestHeaders `json:"requestHeaders"`
 	// The response headers modifications.
 	ResponseHeaders *ProxyResponseHeaders `json:"responseHeaders"`
+	// Mirrors (copies) requests to an additional upstream. The responses from the mirror upstream are ignored.
+	Mirror *ProxyMirror `json:"mirror"`
+	// The timeout for establishing a connection with an upstream server. Overrides the connect-timeout of the upstream.
+	ConnectTimeout string `json:"connectTimeout"`
+	// The timeout for reading a response from an upstream server. Overrides the read-timeout of the upstream.
+	ReadTimeout string `json:"readTimeout"`
+	// The timeout for transmitting a request to an upstream server. Overrides the send-timeout of the upstream.
+	SendTimeout string `json:"sendTimeout"`
+	// Enables buffering of responses from the upstream server. Overrides the buffering of the upstream.
+	Buffering *bool `json:"buffering"`
+	// Configures the buffers used for reading a response from the upstream server for a single connection. Overrides the buffers of the upstream.
+	Buffers *UpstreamBuffers `json:"buffers"`
+	// Sets the size of the buffer used for reading the first part of a response received from the upstream server. Overrides the buffer-size of the upstream.
+	BufferSize string `json:"bufferSize"`
+	// Sets the maximum allowed size of the client request body. Overrides the client-max-body-size of the upstream.
+	ClientMaxBodySize string `json:"clientMaxBodySize"`
+}
+
+// ProxyMirror defines the mirroring of requests in an ActionProxy.
+type ProxyMirror struct {
+	// The name of the upstream which the mirrored requests will be sent to. The upstream with that name must be defined in the resource.
+	Upstream string `json:"upstream"`
+	// The percentage of requests to mirror. Must fall into the range 1..100. The default is 100.
+	Percentage *int `json:"percentage"`
+	// Mirrors the client request body. The default is true.
+	RequestBody *bool `json:"requestBody"`
 }
 
 // ProxyRequestHeaders defines the request headers manipulation in an ActionProxy.
@@ -369,8 +411,16 @@ type Condition struct {
 	Argument string `json:"argument"`
 	// The name of an NGINX variable. Must start with $.
 	Variable string `json:"variable"`
+	// A list of HTTP methods, for example, GET or POST. The condition is satisfied if the method of a request is one of them.
+	Methods []string `json:"methods"`
+	// A list of IP addresses or ranges in the CIDR notation. The condition is satisfied if the client IP address of a request belongs to one of them.
+	SourceCIDRs []string `json:"sourceCIDRs"`
 	// The value to match the condition against.
 	Value string `json:"value"`
+	// Matches the value as a regular expression. The match is case-sensitive, use the (?i) modifier for a case-insensitive match.
+	Regex bool `json:"regex"`
+	// Checks whether the header, cookie, argument or variable is present (true) or absent (false) instead of matching a value. An empty value is considered absent.
+	Present *bool `json:"present"`
 }
 
 // Match defines a match.
@@ -381,6 +431,20 @@ type Match struct {
 	Action *Action `json:"action"`
 	// The splits configuration for traffic splitting. Must include at least 2 splits.
 	Splits []Split `json:"splits"`
+	// The request attribute used to consistently distribute requests between the splits. Overrides the splitKey of the route.
+	SplitKey *SplitKey `json:"splitKey"`
+}
+
+// SplitKey defines the request attribute used to distribute requests between splits. Exactly one field must be set.
+type SplitKey struct {
+	// The name of a header. Must consist of alphanumeric characters or -.
+	Header string `json:"header"`
+	// The name of a cookie. Must consist of alphanumeric characters or _.
+	Cookie string `json:"cookie"`
+	// The name of an argument. Must consist of alphanumeric characters or _.
+	Argument string `json:"argument"`
+	// The name of a JWT claim. Nested claims must be separated by ".", for example, user.id. The claim is only available when the request is authenticated by a JWT policy. Supported in NGINX Plus only.
+	JWTClaim string `json:"jwtClaim"`
 }
 
 // ErrorPage defines an ErrorPage in a Route.
@@ -411,6 +475,30 @@ type TLS struct {
 	Redirect *TLSRedirect `json:"redirect"`
 	// The cert-manager configuration of the TLS for a VirtualServer.
 	CertManager *CertManager `json:"cert-manager"`
+	// The OCSP configuration of the TLS for a VirtualServer.
+	OCSP *TLSOCSP `json:"ocsp"`
+}
+
+// TLSOCSP defines the OCSP configuration of the TLS for a VirtualServer.
+type TLSOCSP struct {
+	// Enables OCSP stapling of the server certificate. The TLS secret must include the certificate of the issuer after the server certificate. The default is False.
+	Stapling bool `json:"stapling"`
+	// Overrides the URL of the OCSP responder specified in the Authority Information Access extension of the server certificate. Only http:// responders are supported.
+	StaplingResponder string `json:"staplingResponder"`
+	// The DNS resolver for the hostnames of the OCSP responders, used for OCSP stapling and for the OCSP checks of an IngressMTLS policy. If not set, the resolver configured in the ConfigMap is used. Because NGINX uses the resolver for the whole server, it is ignored when the ConfigMap configures a resolver.
+	Resolver *OCSPResolver `json:"resolver"`
+}
+
+// OCSPResolver defines the DNS resolver for the hostnames of the OCSP responders.
+type OCSPResolver struct {
+	// The addresses of the DNS servers in the host[:port] format, for example 10.0.0.10 or kube-dns.kube-system.svc.cluster.local:53.
+	Addresses []string `json:"addresses"`
+	// Overrides the TTL of the DNS responses, for example 30s.
+	Valid string `json:"valid"`
+	// The timeout for the DNS resolution, for example 5s.
+	Timeout string `json:"timeout"`
+	// Enables lookups of IPv6 addresses. The default is True.
+	IPv6 *bool `json:"ipv6"`
 }
 
 // TLSRedirect defines a redirect for a TLS.
@@ -568,6 +656,14 @@ type Listener struct {
 	IPv6 string `json:"ipv6"`
 	// Whether the listener will be listening for SSL connections
 	Ssl bool `json:"ssl"`
+	// Enables the PROXY protocol (v1 and v2) on the listener. If not set, HTTP listeners use the proxy-protocol ConfigMap key, and TCP listeners don't accept the PROXY protocol. Not supported for UDP listeners.
+	ProxyProtocol *bool `json:"proxyProtocol"`
+	// The trusted addresses or CIDRs of the load balancers in front of the listener. For HTTP listeners, it overrides the set-real-ip-from ConfigMap key; the HTTP and HTTPS listeners of a VirtualServer must have the same setRealIPFrom and realIPHeader, otherwise the ConfigMap keys are used. For TCP listeners, the client address from the PROXY protocol is used for the connections from these addresses, which requires proxyProtocol.
+	SetRealIPFrom []string `json:"setRealIPFrom"`
+	// The request header, or proxy_protocol, whose value is used as the client address. Overrides the real-ip-header ConfigMap key. Only supported for HTTP listeners.
+	RealIPHeader string `json:"realIPHeader"`
+	// Enables HTTP/3 on the listener. NGINX accepts QUIC connections on the same port using UDP and advertises HTTP/3 to the clients with the Alt-Svc header. Only supported for HTTP listeners with ssl.
+	HTTP3 bool `json:"http3"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -622,6 +718,10 @@ type TransportServerSpec struct {
 	SessionParameters *SessionParameters `json:"sessionParameters"`
 	// The action to perform for a request.
 	Action *TransportServerAction `json:"action"`
+	// A list of matches to route connections to different upstreams. The first match whose conditions are satisfied by a connection determines its action. The connections that satisfy no match are handled by the action of the TransportServer.
+	Matches []TransportServerConnectionMatch `json:"matches"`
+	// A list of policies. The accessControl, ingressMTLS and connectionLimit policies are supported in TransportServers.
+	Policies []PolicyReference `json:"policies"`
 }
 
 // TransportServerTLS defines TransportServerTLS configuration for a TransportServer.
@@ -659,6 +759,26 @@ type TransportServerUpstream struct {
 	Backup string `json:"backup"`
 	// The port of the backup service. The backup port is required if the backup service name is provided. The port must fall into the range 1..65535.
 	BackupPort *uint16 `json:"backupPort"`
+	// The TLS configuration for the connections to the upstream servers. Note: TLS is not supported for UDP TransportServers. When the TransportServer has matches or splits, all upstreams that the action, matches and splits pass connections to must have the same TLS configuration.
+	TLS *TransportServerUpstreamTLS `json:"tls"`
+}
+
+// TransportServerUpstreamTLS defines the TLS configuration for the connections to the upstream servers of a TransportServer.
+type TransportServerUpstreamTLS struct {
+	// Enables TLS for the connections to the upstream servers. The default is false.
+	Enable bool `json:"enable"`
+	// The name of the Kubernetes secret that stores the TLS certificate and key that NGINX presents to the upstream servers. It must be in the same namespace as the TransportServer. The secret must be of the type kubernetes.io/tls, the certificate must be stored in the secret under the key tls.crt, and the key must be stored under the key tls.key, otherwise the secret will be rejected as invalid.
+	TLSSecret string `json:"tlsSecret"`
+	// Enables verification of the upstream server certificate. The default is false.
+	VerifyServer bool `json:"verifyServer"`
+	// Sets the verification depth in the upstream server certificates chain. The default is 1.
+	VerifyDepth *int `json:"verifyDepth"`
+	// The name of the Kubernetes secret that stores the CA certificate used to verify the upstream server certificate. It must be in the same namespace as the TransportServer. The secret must be of the type nginx.org/ca, and the certificate must be stored in the secret under the key ca.crt, otherwise the secret will be rejected as invalid.
+	TrustedCertSecret string `json:"trustedCertSecret"`
+	// Enables passing of the server name through Server Name Indication extension. The default is false.
+	ServerName bool `json:"serverName"`
+	// Allows overriding the server name used to verify the certificate of the upstream server and passed through SNI. The default is the DNS name of the service of the upstream, <service>.<namespace>.svc.
+	SSLName string `json:"sslName"`
 }
 
 // TransportServerHealthCheck defines the parameters for active Upstream HealthChecks.
@@ -703,6 +823,8 @@ type UpstreamParameters struct {
 	NextUpstreamTimeout string `json:"nextUpstreamTimeout"`
 	// The number of tries for passing a connection to the next server. The default is 0.
 	NextUpstreamTries int `json:"nextUpstreamTries"`
+	// Enables the PROXY protocol for the connections to the upstreams, which passes the client address to the upstreams. Not supported for UDP listeners. The default is false.
+	ProxyProtocol bool `json:"proxyProtocol"`
 }
 
 // SessionParameters defines session parameters.
@@ -715,6 +837,34 @@ type SessionParameters struct {
 type TransportServerAction struct {
 	// Passes connections/datagrams to an upstream. The upstream with that name must be defined in the resource.
 	Pass string `json:"pass"`
+	// Distributes connections/datagrams between two or more upstreams by weight, based on the client IP address. Must include at least 2 splits. Cannot be used with pass.
+	Splits []TransportServerSplit `json:"splits"`
+}
+
+// TransportServerSplit defines a split of a TransportServer action.
+type TransportServerSplit struct {
+	// The weight of the split. Must fall into the range 0..100. The sum of the weights of all splits must be equal to 100.
+	Weight int `json:"weight"`
+	// Passes connections/datagrams to an upstream. The upstream with that name must be defined in the resource.
+	Pass string `json:"pass"`
+}
+
+// TransportServerConnectionMatch defines a match for the connections of a TransportServer.
+type TransportServerConnectionMatch struct {
+	// A list of conditions. Must include at least 1 condition. A connection matches if it satisfies all conditions.
+	Conditions []TransportServerCondition `json:"conditions"`
+	// The action to perform for the matching connections.
+	Action *TransportServerAction `json:"action"`
+}
+
+// TransportServerCondition defines a condition of a TransportServer match. Exactly one of serverName, alpn or sourceCIDRs must be specified.
+type TransportServerCondition struct {
+	// The server name (SNI) that the client sends in the TLS handshake, for example, db.example.com or *.example.com. Not supported for UDP TransportServers or with TLS termination.
+	ServerName string `json:"serverName"`
+	// An ALPN protocol, for example, h2 or mqtt. The condition is satisfied if the client offers the protocol in the TLS handshake. Not supported for UDP TransportServers or with TLS termination.
+	ALPN string `json:"alpn"`
+	// A list of IP addresses or ranges in the CIDR notation. The condition is satisfied if the client IP address belongs to one of them.
+	SourceCIDRs []string `json:"sourceCIDRs"`
 }
 
 // TransportServerStatus defines the status for the TransportServer resource.
@@ -788,6 +938,20 @@ type PolicySpec struct {
 	WAF *WAF `json:"waf"`
 	// The API Key policy configures NGINX to authorize requests which provide a valid API Key in a specified header or query param.
 	APIKey *APIKey `json:"apiKey"`
+	// The CORS policy configures NGINX to handle Cross-Origin Resource Sharing preflight requests and to add the CORS headers to responses.
+	CORS *CORS `json:"cors"`
+	// The External Auth policy configures NGINX to authorize requests by sending a subrequest to an external auth service before proxying them.
+	ExternalAuth *ExternalAuth `json:"externalAuth"`
+	// The Cache policy configures NGINX to cache the responses of the upstreams.
+	Cache *Cache `json:"cache"`
+	// The Fault Injection policy configures NGINX to delay or abort a percentage of requests before they reach the upstream.
+	FaultInjection *FaultInjection `json:"faultInjection"`
+	// The connection limit policy limits the number of concurrent connections per a defined key.
+	ConnectionLimit *ConnectionLimit `json:"connectionLimit"`
+	// The Authorization policy configures NGINX to authorize requests based on the claims of their JSON Web Token.
+	Authorization *Authorization `json:"authorization"`
+	// The Signature Validation policy configures NGINX to reject requests without a valid HMAC signature of their body, such as webhooks, before they reach the upstream.
+	SignatureValidation *SignatureValidation `json:"signatureValidation"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -836,6 +1000,57 @@ type RateLimit struct {
 	Condition *RateLimitCondition `json:"condition"`
 }
 
+// ConnectionLimit defines a connection limit policy.
+type ConnectionLimit struct {
+	// The key to which the connection limit is applied. Can contain text, variables, or a combination of them.
+	// Variables must be surrounded by ${}. For example: ${binary_remote_addr}. Accepted variables are
+	// $binary_remote_addr, $remote_addr, $server_addr, $server_port.
+	Key string `json:"key"`
+	// Size of the shared memory zone. Only positive values are allowed. Allowed suffixes are k or m, if none are present k is assumed.
+	ZoneSize string `json:"zoneSize"`
+	// The maximum number of concurrent connections allowed per key. Only positive values are allowed.
+	Connections int `json:"connections"`
+	// Enables the dry run mode. In this mode, the number of connections is not limited, but the number of excessive connections is accounted as usual in the shared memory zone.
+	DryRun *bool `json:"dryRun"`
+	// Sets the status code to return in response to rejected requests. Must fall into the range 400..599. Default is 503. Ignored in TransportServers.
+	RejectCode *int `json:"rejectCode"`
+}
+
+// Authorization defines an authorization policy based on the claims of the JSON Web Token of a request.
+// In NGINX Plus, the token is the one validated by a jwt or an oidc policy. In NGINX, the token is the one validated by a jwt policy.
+type Authorization struct {
+	// A list of claim requirements that must all be met.
+	AllOf []ClaimRequirement `json:"allOf"`
+	// A list of claim requirements of which at least one must be met.
+	AnyOf []ClaimRequirement `json:"anyOf"`
+	// Sets the status code to return in response to unauthorized requests. Allowed values are 401 and 403. Default is 403.
+	RejectCode *int `json:"rejectCode"`
+}
+
+// SignatureValidation defines a Signature Validation policy.
+type SignatureValidation struct {
+	// The name of the Kubernetes secret that stores the HMAC key. It must be in the same namespace as the Policy resource. The secret must be of the type nginx.org/hmac, and the key must be stored in the key field.
+	Secret string `json:"secret"`
+	// The format of the signature. Allowed values are plain, stripe and slack. With plain, the header contains the hex-encoded signature of the request body, optionally prefixed with the algorithm, for example sha256=, as sent by GitHub. With stripe, the header contains a timestamp and one or more signatures of the timestamp and the body, for example t=1700000000,v1=abc. With slack, the header contains the signature prefixed with v0= of the timestamp from the X-Slack-Request-Timestamp header and the body. Default is plain.
+	Format string `json:"format"`
+	// The hash algorithm of the HMAC. Allowed values are sha1, sha256 and sha512. Default is sha256.
+	Algorithm string `json:"algorithm"`
+	// The request header that contains the signature. Default is X-Hub-Signature-256 for plain, Stripe-Signature for stripe and X-Slack-Signature for slack.
+	Header string `json:"header"`
+	// The maximum difference in seconds between the signature timestamp and the current time for the stripe and slack formats. 0 disables the check. Default is 300.
+	TimestampTolerance *int `json:"timestampTolerance"`
+	// Sets the status code to return in response to requests without a valid signature. Allowed values are 401 and 403. Default is 401.
+	RejectCode *int `json:"rejectCode"`
+}
+
+// ClaimRequirement defines a requirement on the value of a JWT claim.
+type ClaimRequirement struct {
+	// The JWT claim. Nested claims should be separated by ".".
+	Claim string `json:"claim"`
+	// The accepted values of the claim. The requirement is met if the claim, an element of an array claim or a space-separated part of a claim, such as scope, equals one of the values.
+	Values []string `json:"values"`
+}
+
 // RateLimitCondition defines a condition for a rate limit policy.
 type RateLimitCondition struct {
 	// defines a JWT condition to rate limit against.
@@ -872,7 +1087,7 @@ type VariableCondition struct {
 	Match string `json:"match"`
 }
 
-// JWTAuth holds JWT authentication configuration.
+// JWTAuth holds JWT authentication configuration. In NGINX, the JWT is validated by njs and must be signed with HS256, RS256 or ES256. The top-level claims of the validated JWT are available as $jwt_claim_ variables.
 type JWTAuth struct {
 	// The realm of the JWT.
 	Realm string `json:"realm"`
@@ -888,6 +1103,10 @@ type JWTAuth struct {
 	SNIEnabled bool `json:"sniEnabled"`
 	// The SNI name to use when connecting to the remote server. If not set, the hostname from the ``jwksURI`` will be used.
 	SNIName string `json:"sniName"`
+	// The required value of the iss claim.
+	Issuer string `json:"issuer"`
+	// The list of accepted values of the aud claim. The JWT must contain at least one of them.
+	Audience []string `json:"audience"`
 }
 
 // BasicAuth holds HTTP Basic authentication configuration
@@ -908,6 +1127,31 @@ type IngressMTLS struct {
 	VerifyClient string `json:"verifyClient"`
 	// Sets the verification depth in the client certificates chain. The default is 1.
 	VerifyDepth *int `json:"verifyDepth"`
+	// Forwards the identity of the verified client certificate to the upstreams in request headers. Not supported in TransportServers.
+	ForwardCertificate *ForwardCertificate `json:"forwardCertificate"`
+	// Checks the revocation status of the client certificates with OCSP, as an alternative to crlFileName. The resolver for the OCSP responders is configured in the TLS of the VirtualServer. Not supported in TransportServers.
+	OCSP *IngressMTLSOCSP `json:"ocsp"`
+}
+
+// IngressMTLSOCSP defines the OCSP checks of the client certificates.
+type IngressMTLSOCSP struct {
+	// Checks only the client certificate, and not the intermediate CA certificates of the chain. The default is False.
+	LeafOnly bool `json:"leafOnly"`
+	// Overrides the URL of the OCSP responder specified in the Authority Information Access extension of the client certificates. Only http:// responders are supported.
+	Responder string `json:"responder"`
+}
+
+// ForwardCertificate defines the request headers that forward the identity of the verified client certificate to the upstreams.
+// The headers are only set for clients with a successfully verified certificate. Headers with the same names sent by clients are removed.
+type ForwardCertificate struct {
+	// The header for the subject distinguished name of the client certificate, for example X-Client-Subject-DN.
+	SubjectHeader string `json:"subjectHeader"`
+	// The header for the subject alternative names of the client certificate, as a comma-separated list of entries such as DNS:client.example.com, URI:spiffe://example.com/client, IP:10.0.0.1 and email:client@example.com.
+	SANsHeader string `json:"sansHeader"`
+	// The header for the SHA1 fingerprint of the client certificate.
+	FingerprintHeader string `json:"fingerprintHeader"`
+	// The header for the client certificate in the PEM format, URL-encoded.
+	CertificateHeader string `json:"certificateHeader"`
 }
 
 // The EgressMTLS policy configures upstreams authentication and certificate verification.
@@ -932,7 +1176,9 @@ type EgressMTLS struct {
 	SSLName string `json:"sslName"`
 }
 
-// The OIDC policy configures NGINX Plus as a relying party for OpenID Connect authentication.
+// The OIDC policy configures NGINX as a relying party for OpenID Connect authentication.
+// NGINX Plus stores the tokens in key-value zones, while NGINX stores them in encrypted session cookies.
+// In NGINX, a resolver for the endpoints of the OpenID Connect provider must be configured with the http-snippets ConfigMap key.
 type OIDC struct {
 	// URL for the authorization endpoint provided by your OpenID Connect provider.
 	AuthEndpoint string `json:"authEndpoint"`
@@ -942,7 +1188,7 @@ type OIDC struct {
 	JWKSURI string `json:"jwksURI"`
 	// The client ID provided by your OpenID Connect provider.
 	ClientID string `json:"clientID"`
-	// The name of the Kubernetes secret that stores the client secret provided by your OpenID Connect provider. It must be in the same namespace as the Policy resource. The secret must be of the type nginx.org/oidc, and the secret under the key client-secret, otherwise the secret will be rejected as invalid. If PKCE is enabled, this should be not configured.
+	// The name of the Kubernetes secret that stores the client secret provided by your OpenID Connect provider. It must be in the same namespace as the Policy resource. The secret must be of the type nginx.org/oidc, and the secret under the key client-secret, otherwise the secret will be rejected as invalid. In NGINX, the secret must also store a key of at least 32 characters under the key session-key, which is used to encrypt the session cookies. If PKCE is enabled, this should be not configured.
 	ClientSecret string `json:"clientSecret"`
 	// List of OpenID Connect scopes. The scope openid always needs to be present and others can be added concatenating them with a + sign, for example openid+profile+email, openid+email+userDefinedScope. The default is openid.
 	Scope string `json:"scope"`
@@ -952,14 +1198,28 @@ type OIDC struct {
 	EndSessionEndpoint string `json:"endSessionEndpoint"`
 	// URI to redirect to after the logout has been performed. Requires endSessionEndpoint. The default is /_logout.
 	PostLogoutRedirectURI string `json:"postLogoutRedirectURI"`
-	// Specifies the maximum timeout in milliseconds for synchronizing ID/access tokens and shared values between Ingress Controller pods. The default is 200.
+	// Specifies the maximum timeout in milliseconds for synchronizing ID/access tokens and shared values between Ingress Controller pods. The default is 200. Supported in NGINX Plus only, as NGINX stores the tokens in encrypted session cookies.
 	ZoneSyncLeeway *int `json:"zoneSyncLeeway"`
 	// A list of extra URL arguments to pass to the authorization endpoint provided by your OpenID Connect provider. Arguments must be URL encoded, multiple arguments may be included in the list, for example [ arg1=value1, arg2=value2 ]
 	AuthExtraArgs []string `json:"authExtraArgs"`
 	// Option of whether Bearer token is used to authorize NGINX to access protected backend.
 	AccessTokenEnable bool `json:"accessTokenEnable"`
-	// Switches Proof Key for Code Exchange on. The OpenID client needs to be in public mode. clientSecret is not used in this mode.
+	// Switches Proof Key for Code Exchange on. The OpenID client needs to be in public mode. clientSecret is not used in this mode. Supported in NGINX Plus only.
 	PKCEEnable bool `json:"pkceEnable"`
+	// A list of ID token claims to pass to the upstream in request headers. Headers with the same names sent by the client are replaced.
+	ClaimHeaders []ClaimHeader `json:"claimHeaders"`
+	// Specifies how many seconds before the ID and access tokens expire NGINX refreshes them with the refresh token, so the tokens passed to the upstream are never expired. Requires the OpenID Connect provider to issue refresh tokens. The default is 0, which refreshes the tokens once they have expired.
+	RefreshLeeway *int `json:"refreshLeeway"`
+	// Enables OpenID Connect back-channel logout on the given path, for example /back_channel_logout. The path must be registered as the back-channel logout URI of the client at your OpenID Connect provider. The sessions of the logout tokens received are terminated. In NGINX, the terminated sessions are kept in the memory of each Ingress Controller pod and are not shared between pods.
+	BackchannelLogoutURI string `json:"backchannelLogoutURI"`
+}
+
+// ClaimHeader defines a request header set from an ID token claim.
+type ClaimHeader struct {
+	// The name of the claim, for example email. Nested claims are separated by '.', for example address.country. The elements of array claims are separated by commas.
+	Claim string `json:"claim"`
+	// The name of the request header, for example X-User-Email.
+	Header string `json:"header"`
 }
 
 // The WAF policy configures NGINX Plus to secure client requests using App Protect WAF policies.
@@ -994,6 +1254,89 @@ type APIKey struct {
 	SuppliedIn *SuppliedIn `json:"suppliedIn"`
 	// The key to which the API key is applied. Can contain text, variables, or a combination of them. Accepted variables are $http_, $arg_, $cookie_.
 	ClientSecret string `json:"clientSecret"`
+	// A list of rate-limit tiers of the clients. The tier of the client of a request is available in the $apikey_tier variable, which rateLimit policies can match in a variables condition, for example to apply different rates to free and paid clients. Clients not assigned to a tier have an empty tier.
+	Tiers []APIKeyTier `json:"tiers"`
+}
+
+// APIKeyTier assigns API key clients to a rate-limit tier.
+type APIKeyTier struct {
+	// The name of the tier, for example premium.
+	Name string `json:"name"`
+	// The IDs of the clients in the tier. The client IDs are the keys of the data of the API key secret.
+	ClientIDs []string `json:"clientIDs"`
+}
+
+// The CORS policy configures NGINX to handle Cross-Origin Resource Sharing preflight requests and to add the CORS headers to responses.
+type CORS struct {
+	// The origins allowed to make cross-origin requests. An origin is either an exact value, for example, https://example.com, or a regular expression that starts with ~, for example, ~^https://.+\.example\.com$. The value * allows all origins and must be the only value.
+	AllowOrigins []string `json:"allowOrigins"`
+	// The HTTP methods allowed in cross-origin requests. The default is GET, HEAD and POST.
+	AllowMethods []string `json:"allowMethods"`
+	// The request headers allowed in cross-origin requests.
+	AllowHeaders []string `json:"allowHeaders"`
+	// The response headers exposed to the client scripts.
+	ExposeHeaders []string `json:"exposeHeaders"`
+	// Allows cross-origin requests to include credentials, such as cookies. Cannot be used together with the * origin. The default is false.
+	AllowCredentials bool `json:"allowCredentials"`
+	// The time in seconds the result of a preflight request can be cached by the client.
+	MaxAge *int `json:"maxAge"`
+}
+
+// The External Auth policy configures NGINX to authorize requests by sending a subrequest to an external auth service before proxying them.
+// A request is allowed if the auth service returns a 2xx response code, and rejected if the auth service returns 401 or 403.
+type ExternalAuth struct {
+	// The name and port of the Kubernetes service of the auth service, in the format service:port, for example, auth-svc:8080. The service must be in the same namespace as the Policy resource.
+	AuthService string `json:"authService"`
+	// The path of the auth service endpoint that the subrequests are sent to. The default is /.
+	AuthPath string `json:"authPath"`
+	// The request headers forwarded to the auth service. If not set, all request headers are forwarded.
+	ForwardRequestHeaders []string `json:"forwardRequestHeaders"`
+	// The headers of the auth service response that are added to the request proxied to the upstream.
+	ResponseHeaders []string `json:"responseHeaders"`
+	// The URL clients are redirected to when the auth service returns 401, for example, https://sso.example.com/signin.
+	SigninURL string `json:"signinURL"`
+}
+
+// The Cache policy configures NGINX to cache the responses of the upstreams.
+type Cache struct {
+	// The size of the shared memory zone that stores the cache keys, for example, 10m. The default is 10m.
+	CacheZoneSize string `json:"cacheZoneSize"`
+	// The maximum size of the cache on disk, for example, 1g. By default, the size of the cache is not limited.
+	MaxSize string `json:"maxSize"`
+	// The time after which the cached responses that are not accessed are removed from the cache, for example, 1h. The default is 10m.
+	Inactive string `json:"inactive"`
+	// The key for caching. The key can contain text and the variables ${scheme}, ${host}, ${proxy_host}, ${request_uri}, ${uri}, ${args}, ${request_method}, ${arg_*}, ${http_*} and ${cookie_*}, for example, ${scheme}${host}${request_uri}. The default is ${scheme}${proxy_host}${request_uri}.
+	Key string `json:"key"`
+	// The request methods whose responses are cached. Accepted values are GET, HEAD and POST. The default is GET and HEAD.
+	AllowedMethods []string `json:"allowedMethods"`
+	// The caching times for the response codes. By default, only the caching time set in the Cache-Control or Expires headers of the upstream responses is used.
+	Valid []CacheValid `json:"valid"`
+	// The variables that, if at least one of them is not empty and is not equal to 0, make NGINX bypass the cache, for example, $http_pragma or $cookie_nocache. Accepted variables are $arg_*, $http_* and $cookie_*.
+	Bypass []string `json:"bypass"`
+	// Ignores the Cache-Control and Expires headers of the upstream responses, so that only the caching times from valid are used. The default is false.
+	IgnoreCacheControl bool `json:"ignoreCacheControl"`
+	// The client IP addresses or ranges in the CIDR notation that are allowed to remove responses from the cache with the PURGE method. Supported only in NGINX Plus.
+	PurgeAllowedCIDRs []string `json:"purgeAllowedCIDRs"`
+}
+
+// CacheValid defines the caching time for response codes.
+type CacheValid struct {
+	// The response codes, for example, 200 or 404. The value any caches all response codes.
+	Codes []string `json:"codes"`
+	// The caching time, for example, 10m.
+	Time string `json:"time"`
+}
+
+// The FaultInjection policy configures NGINX to delay or abort a percentage of requests before they reach the upstream.
+type FaultInjection struct {
+	// The percentage of requests the fault is injected into. Must fall into the range 1..100.
+	Percentage int `json:"percentage"`
+	// The fixed delay added to the requests before they are proxied to the upstream, for example, 2s or 500ms. Supported units are h, m, s and ms.
+	Delay string `json:"delay"`
+	// The status code returned to the requests instead of proxying them to the upstream. Must fall into the range 400..599.
+	Status int `json:"status"`
+	// The condition that a request must match for the fault to be injected, for example, a header that marks test traffic. If not set, the fault is injected into all requests.
+	Condition *Condition `json:"condition"`
 }
 
 // SuppliedIn defines the locations API Key should be supplied in.
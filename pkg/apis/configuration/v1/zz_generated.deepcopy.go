@@ -17,6 +17,13 @@ func (in *APIKey) DeepCopyInto(out *APIKey) {
 		*out = new(SuppliedIn)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Tiers != nil {
+		in, out := &in.Tiers, &out.Tiers
+		*out = make([]APIKeyTier, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -30,6 +37,27 @@ func (in *APIKey) DeepCopy() *APIKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIKeyTier) DeepCopyInto(out *APIKeyTier) {
+	*out = *in
+	if in.ClientIDs != nil {
+		in, out := &in.ClientIDs, &out.ClientIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIKeyTier.
+func (in *APIKeyTier) DeepCopy() *APIKeyTier {
+	if in == nil {
+		return nil
+	}
+	out := new(APIKeyTier)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AccessControl) DeepCopyInto(out *AccessControl) {
 	*out = *in
@@ -100,6 +128,21 @@ func (in *ActionProxy) DeepCopyInto(out *ActionProxy) {
 		*out = new(ProxyResponseHeaders)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(ProxyMirror)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Buffering != nil {
+		in, out := &in.Buffering, &out.Buffering
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Buffers != nil {
+		in, out := &in.Buffers, &out.Buffers
+		*out = new(UpstreamBuffers)
+		**out = **in
+	}
 	return
 }
 
@@ -167,6 +210,41 @@ func (in *AddHeader) DeepCopy() *AddHeader {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Authorization) DeepCopyInto(out *Authorization) {
+	*out = *in
+	if in.AllOf != nil {
+		in, out := &in.AllOf, &out.AllOf
+		*out = make([]ClaimRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AnyOf != nil {
+		in, out := &in.AnyOf, &out.AnyOf
+		*out = make([]ClaimRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RejectCode != nil {
+		in, out := &in.RejectCode, &out.RejectCode
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Authorization.
+func (in *Authorization) DeepCopy() *Authorization {
+	if in == nil {
+		return nil
+	}
+	out := new(Authorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BasicAuth) DeepCopyInto(out *BasicAuth) {
 	*out = *in
@@ -183,6 +261,106 @@ func (in *BasicAuth) DeepCopy() *BasicAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CORS) DeepCopyInto(out *CORS) {
+	*out = *in
+	if in.AllowOrigins != nil {
+		in, out := &in.AllowOrigins, &out.AllowOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowMethods != nil {
+		in, out := &in.AllowMethods, &out.AllowMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowHeaders != nil {
+		in, out := &in.AllowHeaders, &out.AllowHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExposeHeaders != nil {
+		in, out := &in.ExposeHeaders, &out.ExposeHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CORS.
+func (in *CORS) DeepCopy() *CORS {
+	if in == nil {
+		return nil
+	}
+	out := new(CORS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Cache) DeepCopyInto(out *Cache) {
+	*out = *in
+	if in.AllowedMethods != nil {
+		in, out := &in.AllowedMethods, &out.AllowedMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Valid != nil {
+		in, out := &in.Valid, &out.Valid
+		*out = make([]CacheValid, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bypass != nil {
+		in, out := &in.Bypass, &out.Bypass
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PurgeAllowedCIDRs != nil {
+		in, out := &in.PurgeAllowedCIDRs, &out.PurgeAllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Cache.
+func (in *Cache) DeepCopy() *Cache {
+	if in == nil {
+		return nil
+	}
+	out := new(Cache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CacheValid) DeepCopyInto(out *CacheValid) {
+	*out = *in
+	if in.Codes != nil {
+		in, out := &in.Codes, &out.Codes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CacheValid.
+func (in *CacheValid) DeepCopy() *CacheValid {
+	if in == nil {
+		return nil
+	}
+	out := new(CacheValid)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManager) DeepCopyInto(out *CertManager) {
 	*out = *in
@@ -199,9 +377,61 @@ func (in *CertManager) DeepCopy() *CertManager {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClaimHeader) DeepCopyInto(out *ClaimHeader) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimHeader.
+func (in *ClaimHeader) DeepCopy() *ClaimHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(ClaimHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClaimRequirement) DeepCopyInto(out *ClaimRequirement) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimRequirement.
+func (in *ClaimRequirement) DeepCopy() *ClaimRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(ClaimRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
 	*out = *in
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SourceCIDRs != nil {
+		in, out := &in.SourceCIDRs, &out.SourceCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Present != nil {
+		in, out := &in.Present, &out.Present
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -215,6 +445,32 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionLimit) DeepCopyInto(out *ConnectionLimit) {
+	*out = *in
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RejectCode != nil {
+		in, out := &in.RejectCode, &out.RejectCode
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionLimit.
+func (in *ConnectionLimit) DeepCopy() *ConnectionLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EgressMTLS) DeepCopyInto(out *EgressMTLS) {
 	*out = *in
@@ -306,6 +562,32 @@ func (in *ErrorPageReturn) DeepCopy() *ErrorPageReturn {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalAuth) DeepCopyInto(out *ExternalAuth) {
+	*out = *in
+	if in.ForwardRequestHeaders != nil {
+		in, out := &in.ForwardRequestHeaders, &out.ForwardRequestHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResponseHeaders != nil {
+		in, out := &in.ResponseHeaders, &out.ResponseHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAuth.
+func (in *ExternalAuth) DeepCopy() *ExternalAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalDNS) DeepCopyInto(out *ExternalDNS) {
 	*out = *in
@@ -350,6 +632,43 @@ func (in *ExternalEndpoint) DeepCopy() *ExternalEndpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FaultInjection) DeepCopyInto(out *FaultInjection) {
+	*out = *in
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(Condition)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FaultInjection.
+func (in *FaultInjection) DeepCopy() *FaultInjection {
+	if in == nil {
+		return nil
+	}
+	out := new(FaultInjection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForwardCertificate) DeepCopyInto(out *ForwardCertificate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForwardCertificate.
+func (in *ForwardCertificate) DeepCopy() *ForwardCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(ForwardCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GlobalConfiguration) DeepCopyInto(out *GlobalConfiguration) {
 	*out = *in
@@ -416,7 +735,9 @@ func (in *GlobalConfigurationSpec) DeepCopyInto(out *GlobalConfigurationSpec) {
 	if in.Listeners != nil {
 		in, out := &in.Listeners, &out.Listeners
 		*out = make([]Listener, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -486,6 +807,16 @@ func (in *IngressMTLS) DeepCopyInto(out *IngressMTLS) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.ForwardCertificate != nil {
+		in, out := &in.ForwardCertificate, &out.ForwardCertificate
+		*out = new(ForwardCertificate)
+		**out = **in
+	}
+	if in.OCSP != nil {
+		in, out := &in.OCSP, &out.OCSP
+		*out = new(IngressMTLSOCSP)
+		**out = **in
+	}
 	return
 }
 
@@ -499,9 +830,30 @@ func (in *IngressMTLS) DeepCopy() *IngressMTLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressMTLSOCSP) DeepCopyInto(out *IngressMTLSOCSP) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressMTLSOCSP.
+func (in *IngressMTLSOCSP) DeepCopy() *IngressMTLSOCSP {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressMTLSOCSP)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JWTAuth) DeepCopyInto(out *JWTAuth) {
 	*out = *in
+	if in.Audience != nil {
+		in, out := &in.Audience, &out.Audience
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -534,6 +886,16 @@ func (in *JWTCondition) DeepCopy() *JWTCondition {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Listener) DeepCopyInto(out *Listener) {
 	*out = *in
+	if in.ProxyProtocol != nil {
+		in, out := &in.ProxyProtocol, &out.ProxyProtocol
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SetRealIPFrom != nil {
+		in, out := &in.SetRealIPFrom, &out.SetRealIPFrom
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -553,7 +915,9 @@ func (in *Match) DeepCopyInto(out *Match) {
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Action != nil {
 		in, out := &in.Action, &out.Action
@@ -567,6 +931,11 @@ func (in *Match) DeepCopyInto(out *Match) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SplitKey != nil {
+		in, out := &in.SplitKey, &out.SplitKey
+		*out = new(SplitKey)
+		**out = **in
+	}
 	return
 }
 
@@ -580,6 +949,32 @@ func (in *Match) DeepCopy() *Match {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCSPResolver) DeepCopyInto(out *OCSPResolver) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6 != nil {
+		in, out := &in.IPv6, &out.IPv6
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCSPResolver.
+func (in *OCSPResolver) DeepCopy() *OCSPResolver {
+	if in == nil {
+		return nil
+	}
+	out := new(OCSPResolver)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OIDC) DeepCopyInto(out *OIDC) {
 	*out = *in
@@ -593,6 +988,16 @@ func (in *OIDC) DeepCopyInto(out *OIDC) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClaimHeaders != nil {
+		in, out := &in.ClaimHeaders, &out.ClaimHeaders
+		*out = make([]ClaimHeader, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshLeeway != nil {
+		in, out := &in.RefreshLeeway, &out.RefreshLeeway
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -699,7 +1104,7 @@ func (in *PolicySpec) DeepCopyInto(out *PolicySpec) {
 	if in.JWTAuth != nil {
 		in, out := &in.JWTAuth, &out.JWTAuth
 		*out = new(JWTAuth)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.BasicAuth != nil {
 		in, out := &in.BasicAuth, &out.BasicAuth
@@ -731,6 +1136,41 @@ func (in *PolicySpec) DeepCopyInto(out *PolicySpec) {
 		*out = new(APIKey)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CORS != nil {
+		in, out := &in.CORS, &out.CORS
+		*out = new(CORS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalAuth != nil {
+		in, out := &in.ExternalAuth, &out.ExternalAuth
+		*out = new(ExternalAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(Cache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FaultInjection != nil {
+		in, out := &in.FaultInjection, &out.FaultInjection
+		*out = new(FaultInjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionLimit != nil {
+		in, out := &in.ConnectionLimit, &out.ConnectionLimit
+		*out = new(ConnectionLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(Authorization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SignatureValidation != nil {
+		in, out := &in.SignatureValidation, &out.SignatureValidation
+		*out = new(SignatureValidation)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -796,6 +1236,32 @@ func (in *ProviderSpecificProperty) DeepCopy() *ProviderSpecificProperty {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyMirror) DeepCopyInto(out *ProxyMirror) {
+	*out = *in
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int)
+		**out = **in
+	}
+	if in.RequestBody != nil {
+		in, out := &in.RequestBody, &out.RequestBody
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyMirror.
+func (in *ProxyMirror) DeepCopy() *ProxyMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProxyRequestHeaders) DeepCopyInto(out *ProxyRequestHeaders) {
 	*out = *in
@@ -954,6 +1420,11 @@ func (in *Route) DeepCopyInto(out *Route) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SplitKey != nil {
+		in, out := &in.SplitKey, &out.SplitKey
+		*out = new(SplitKey)
+		**out = **in
+	}
 	if in.Matches != nil {
 		in, out := &in.Matches, &out.Matches
 		*out = make([]Match, len(*in))
@@ -1029,6 +1500,32 @@ func (in *SessionParameters) DeepCopy() *SessionParameters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SignatureValidation) DeepCopyInto(out *SignatureValidation) {
+	*out = *in
+	if in.TimestampTolerance != nil {
+		in, out := &in.TimestampTolerance, &out.TimestampTolerance
+		*out = new(int)
+		**out = **in
+	}
+	if in.RejectCode != nil {
+		in, out := &in.RejectCode, &out.RejectCode
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SignatureValidation.
+func (in *SignatureValidation) DeepCopy() *SignatureValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(SignatureValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Split) DeepCopyInto(out *Split) {
 	*out = *in
@@ -1050,6 +1547,22 @@ func (in *Split) DeepCopy() *Split {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SplitKey) DeepCopyInto(out *SplitKey) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplitKey.
+func (in *SplitKey) DeepCopy() *SplitKey {
+	if in == nil {
+		return nil
+	}
+	out := new(SplitKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SuppliedIn) DeepCopyInto(out *SuppliedIn) {
 	*out = *in
@@ -1089,6 +1602,11 @@ func (in *TLS) DeepCopyInto(out *TLS) {
 		*out = new(CertManager)
 		**out = **in
 	}
+	if in.OCSP != nil {
+		in, out := &in.OCSP, &out.OCSP
+		*out = new(TLSOCSP)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1102,6 +1620,27 @@ func (in *TLS) DeepCopy() *TLS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSOCSP) DeepCopyInto(out *TLSOCSP) {
+	*out = *in
+	if in.Resolver != nil {
+		in, out := &in.Resolver, &out.Resolver
+		*out = new(OCSPResolver)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSOCSP.
+func (in *TLSOCSP) DeepCopy() *TLSOCSP {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSOCSP)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSRedirect) DeepCopyInto(out *TLSRedirect) {
 	*out = *in
@@ -1154,6 +1693,11 @@ func (in *TransportServer) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransportServerAction) DeepCopyInto(out *TransportServerAction) {
 	*out = *in
+	if in.Splits != nil {
+		in, out := &in.Splits, &out.Splits
+		*out = make([]TransportServerSplit, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1167,6 +1711,55 @@ func (in *TransportServerAction) DeepCopy() *TransportServerAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportServerCondition) DeepCopyInto(out *TransportServerCondition) {
+	*out = *in
+	if in.SourceCIDRs != nil {
+		in, out := &in.SourceCIDRs, &out.SourceCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportServerCondition.
+func (in *TransportServerCondition) DeepCopy() *TransportServerCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportServerCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportServerConnectionMatch) DeepCopyInto(out *TransportServerConnectionMatch) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TransportServerCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Action != nil {
+		in, out := &in.Action, &out.Action
+		*out = new(TransportServerAction)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportServerConnectionMatch.
+func (in *TransportServerConnectionMatch) DeepCopy() *TransportServerConnectionMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportServerConnectionMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransportServerHealthCheck) DeepCopyInto(out *TransportServerHealthCheck) {
 	*out = *in
@@ -1282,7 +1875,19 @@ func (in *TransportServerSpec) DeepCopyInto(out *TransportServerSpec) {
 	if in.Action != nil {
 		in, out := &in.Action, &out.Action
 		*out = new(TransportServerAction)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Matches != nil {
+		in, out := &in.Matches, &out.Matches
+		*out = make([]TransportServerConnectionMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]PolicyReference, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
@@ -1297,6 +1902,22 @@ func (in *TransportServerSpec) DeepCopy() *TransportServerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportServerSplit) DeepCopyInto(out *TransportServerSplit) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportServerSplit.
+func (in *TransportServerSplit) DeepCopy() *TransportServerSplit {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportServerSplit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransportServerStatus) DeepCopyInto(out *TransportServerStatus) {
 	*out = *in
@@ -1352,6 +1973,11 @@ func (in *TransportServerUpstream) DeepCopyInto(out *TransportServerUpstream) {
 		*out = new(uint16)
 		**out = **in
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TransportServerUpstreamTLS)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1365,6 +1991,27 @@ func (in *TransportServerUpstream) DeepCopy() *TransportServerUpstream {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransportServerUpstreamTLS) DeepCopyInto(out *TransportServerUpstreamTLS) {
+	*out = *in
+	if in.VerifyDepth != nil {
+		in, out := &in.VerifyDepth, &out.VerifyDepth
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransportServerUpstreamTLS.
+func (in *TransportServerUpstreamTLS) DeepCopy() *TransportServerUpstreamTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(TransportServerUpstreamTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Upstream) DeepCopyInto(out *Upstream) {
 	*out = *in
@@ -1375,6 +2022,11 @@ func (in *Upstream) DeepCopyInto(out *Upstream) {
 			(*out)[key] = val
 		}
 	}
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.MaxFails != nil {
 		in, out := &in.MaxFails, &out.MaxFails
 		*out = new(int)
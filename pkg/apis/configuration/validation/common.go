@@ -88,7 +88,9 @@ func validateSpecialVariable(nVar string, fieldPath *field.Path, isPlus bool) fi
 		addErrors(isValidSpecialHeaderLikeVariable(value))
 	case "cookie":
 		addErrors(isCookieName(value))
-	case "jwt_header", "jwt_claim":
+	case "jwt_claim":
+		addErrors(isValidSpecialHeaderLikeVariable(value))
+	case "jwt_header":
 		if !isPlus {
 			allErrs = append(allErrs, field.Forbidden(fieldPath, "is only supported in NGINX Plus"))
 		} else {
@@ -10,6 +10,10 @@ func createPointerFromInt(n int) *int {
 	return &n
 }
 
+func createPointerFromBool(b bool) *bool {
+	return &b
+}
+
 func TestValidateVariable(t *testing.T) {
 	t.Parallel()
 	validVars := map[string]bool{
@@ -130,6 +134,7 @@ func TestValidateSpecialVariable(t *testing.T) {
 		"arg_user_name",
 		"http_header_name",
 		"cookie_cookie_name",
+		"jwt_claim_user",
 	}
 
 	isPlus := false
@@ -171,7 +176,6 @@ func TestValidateSpecialVariableFails(t *testing.T) {
 		"http_header+invalid",
 		"cookie_cookie_name?invalid",
 		"jwt_header_alg",
-		"jwt_claim_user",
 		"some_var",
 	}
 
@@ -108,6 +108,9 @@ func (gcv *GlobalConfigurationValidator) checkIPPortProtocolConflicts(combinatio
 			if existingProtocol == "HTTP" || existingProtocol == "TCP" {
 				return field.Invalid(fieldPath.Child("protocol"), listener.Protocol, fmt.Sprintf("Listener %s: Duplicated ip:port protocol combination %s:%d %s", listener.Name, ip, listener.Port, listener.Protocol))
 			}
+			if listener.HTTP3 && existingProtocol == "UDP" {
+				return field.Invalid(fieldPath.Child("http3"), listener.HTTP3, fmt.Sprintf("Listener %s: Duplicated ip:port protocol combination %s:%d UDP", listener.Name, ip, listener.Port))
+			}
 		case "UDP":
 			if existingProtocol == "UDP" {
 				return field.Invalid(fieldPath.Child("protocol"), listener.Protocol, fmt.Sprintf("Listener %s: Duplicated ip:port protocol combination %s:%d %s", listener.Name, ip, listener.Port, listener.Protocol))
@@ -124,6 +127,10 @@ func (gcv *GlobalConfigurationValidator) updatePortProtocolCombinations(combinat
 		combinations[ip] = make(map[int][]string)
 	}
 	combinations[ip][listener.Port] = append(combinations[ip][listener.Port], listener.Protocol)
+	// HTTP/3 uses the port of the listener for UDP
+	if listener.HTTP3 {
+		combinations[ip][listener.Port] = append(combinations[ip][listener.Port], "UDP")
+	}
 }
 
 // getIP returns the appropriate IP address for the given ipType and listener.
@@ -150,10 +157,52 @@ func (gcv *GlobalConfigurationValidator) validateListener(listener conf_v1.Liste
 	allErrs = append(allErrs, validateListenerProtocol(listener.Protocol, fieldPath.Child("protocol"))...)
 	allErrs = append(allErrs, validateListenerIPv4(listener.IPv4, fieldPath.Child("ipv4"))...)
 	allErrs = append(allErrs, validateListenerIPv6(listener.IPv6, fieldPath.Child("ipv6"))...)
+	allErrs = append(allErrs, validateListenerRealIP(listener, fieldPath)...)
+	allErrs = append(allErrs, validateListenerHTTP3(listener, fieldPath.Child("http3"))...)
 
 	return allErrs
 }
 
+func validateListenerRealIP(listener conf_v1.Listener, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	proxyProtocol := listener.ProxyProtocol != nil && *listener.ProxyProtocol
+
+	if proxyProtocol && listener.Protocol == "UDP" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("proxyProtocol"), "is not supported for UDP listeners"))
+	}
+
+	for i, address := range listener.SetRealIPFrom {
+		allErrs = append(allErrs, validateIPorCIDR(address, fieldPath.Child("setRealIPFrom").Index(i))...)
+	}
+	if len(listener.SetRealIPFrom) > 0 && listener.Protocol != conf_v1.HTTPProtocol && !proxyProtocol {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("setRealIPFrom"), "requires proxyProtocol for TCP and UDP listeners"))
+	}
+
+	if listener.RealIPHeader == "" {
+		return allErrs
+	}
+	if listener.Protocol != conf_v1.HTTPProtocol {
+		return append(allErrs, field.Forbidden(fieldPath.Child("realIPHeader"), "is only supported for HTTP listeners"))
+	}
+	if listener.RealIPHeader == "proxy_protocol" {
+		if listener.ProxyProtocol != nil && !*listener.ProxyProtocol {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("realIPHeader"), "proxy_protocol requires proxyProtocol to be enabled"))
+		}
+		return allErrs
+	}
+	for _, msg := range validation.IsHTTPHeaderName(listener.RealIPHeader) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("realIPHeader"), listener.RealIPHeader, msg))
+	}
+	return allErrs
+}
+
+func validateListenerHTTP3(listener conf_v1.Listener, fieldPath *field.Path) field.ErrorList {
+	if listener.HTTP3 && (listener.Protocol != conf_v1.HTTPProtocol || !listener.Ssl) {
+		return field.ErrorList{field.Forbidden(fieldPath, "is only supported for HTTP listeners with ssl")}
+	}
+	return nil
+}
+
 func validateGlobalConfigurationListenerName(name string, fieldPath *field.Path) field.ErrorList {
 	if name == conf_v1.TLSPassthroughListenerName {
 		return field.ErrorList{field.Forbidden(fieldPath, "is the name of a built-in listener")}
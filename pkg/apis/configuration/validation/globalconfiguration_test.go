@@ -423,6 +423,66 @@ func TestValidateListener(t *testing.T) {
 	}
 }
 
+func TestValidateListener_PassesOnValidRealIPSettings(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		Listener conf_v1.Listener
+		msg      string
+	}{
+		{
+			Listener: conf_v1.Listener{
+				Name:          "http-listener",
+				Port:          8080,
+				Protocol:      "HTTP",
+				ProxyProtocol: createPointerFromBool(true),
+				SetRealIPFrom: []string{"10.0.0.0/8", "192.168.1.1"},
+				RealIPHeader:  "proxy_protocol",
+			},
+			msg: "http listener with proxy protocol",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:          "http-listener",
+				Port:          8080,
+				Protocol:      "HTTP",
+				ProxyProtocol: createPointerFromBool(false),
+				SetRealIPFrom: []string{"10.0.0.0/8"},
+				RealIPHeader:  "X-Forwarded-For",
+			},
+			msg: "http listener with real ip header",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:          "tcp-listener",
+				Port:          5353,
+				Protocol:      "TCP",
+				ProxyProtocol: createPointerFromBool(true),
+				SetRealIPFrom: []string{"10.0.0.0/8"},
+			},
+			msg: "tcp listener with proxy protocol",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:     "https-listener",
+				Port:     8443,
+				Protocol: "HTTP",
+				Ssl:      true,
+				HTTP3:    true,
+			},
+			msg: "https listener with http3",
+		},
+	}
+
+	gcv := createGlobalConfigurationValidator()
+
+	for _, test := range tests {
+		allErrs := gcv.validateListener(test.Listener, field.NewPath("listener"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateListener() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
 func TestValidateListenerFails(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -461,6 +521,81 @@ func TestValidateListenerFails(t *testing.T) {
 			},
 			msg: "name of a built-in listener",
 		},
+		{
+			Listener: conf_v1.Listener{
+				Name:          "udp-listener",
+				Port:          5353,
+				Protocol:      "UDP",
+				ProxyProtocol: createPointerFromBool(true),
+			},
+			msg: "proxy protocol on udp listener",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:          "tcp-listener",
+				Port:          5353,
+				Protocol:      "TCP",
+				SetRealIPFrom: []string{"10.0.0.0/8"},
+			},
+			msg: "set real ip from on tcp listener without proxy protocol",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:          "http-listener",
+				Port:          8080,
+				Protocol:      "HTTP",
+				SetRealIPFrom: []string{"10.0.0.0/33"},
+			},
+			msg: "invalid set real ip from",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:          "tcp-listener",
+				Port:          5353,
+				Protocol:      "TCP",
+				ProxyProtocol: createPointerFromBool(true),
+				RealIPHeader:  "proxy_protocol",
+			},
+			msg: "real ip header on tcp listener",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:         "http-listener",
+				Port:         8080,
+				Protocol:     "HTTP",
+				RealIPHeader: "X Forwarded For",
+			},
+			msg: "invalid real ip header",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:          "http-listener",
+				Port:          8080,
+				Protocol:      "HTTP",
+				ProxyProtocol: createPointerFromBool(false),
+				RealIPHeader:  "proxy_protocol",
+			},
+			msg: "proxy_protocol real ip header with disabled proxy protocol",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:     "http-listener",
+				Port:     8080,
+				Protocol: "HTTP",
+				HTTP3:    true,
+			},
+			msg: "http3 on listener without ssl",
+		},
+		{
+			Listener: conf_v1.Listener{
+				Name:     "tcp-listener",
+				Port:     5353,
+				Protocol: "TCP",
+				Ssl:      true,
+				HTTP3:    true,
+			},
+			msg: "http3 on tcp listener",
+		},
 	}
 
 	gcv := createGlobalConfigurationValidator()
@@ -755,3 +890,75 @@ func TestValidateListenerProtocol_PassesOnUDPListenerUsingSamePortAsHTTPListener
 		t.Errorf("validateListeners() returned errors %v for valid input", allErrs)
 	}
 }
+
+func TestValidateListenerProtocol_FailsOnUDPListenerUsingSamePortAsHTTP3Listener(t *testing.T) {
+	t.Parallel()
+	listeners := []conf_v1.Listener{
+		{
+			Name:     "https-listener",
+			Port:     8443,
+			Protocol: "HTTP",
+			Ssl:      true,
+			HTTP3:    true,
+		},
+		{
+			Name:     "udp-listener",
+			Port:     8443,
+			Protocol: "UDP",
+		},
+	}
+	wantListeners := []conf_v1.Listener{
+		{
+			Name:     "https-listener",
+			Port:     8443,
+			Protocol: "HTTP",
+			Ssl:      true,
+			HTTP3:    true,
+		},
+	}
+
+	gcv := createGlobalConfigurationValidator()
+
+	listeners, allErrs := gcv.getValidListeners(listeners, field.NewPath("listeners"))
+	if diff := cmp.Diff(listeners, wantListeners); diff != "" {
+		t.Errorf("getValidListeners() returned unexpected result: (-want +got):\n%s", diff)
+	}
+	if len(allErrs) == 0 {
+		t.Errorf("validateListeners() returned no errors %v for invalid input", allErrs)
+	}
+}
+
+func TestValidateListenerProtocol_FailsOnHTTP3ListenerUsingSamePortAsUDPListener(t *testing.T) {
+	t.Parallel()
+	listeners := []conf_v1.Listener{
+		{
+			Name:     "udp-listener",
+			Port:     8443,
+			Protocol: "UDP",
+		},
+		{
+			Name:     "https-listener",
+			Port:     8443,
+			Protocol: "HTTP",
+			Ssl:      true,
+			HTTP3:    true,
+		},
+	}
+	wantListeners := []conf_v1.Listener{
+		{
+			Name:     "udp-listener",
+			Port:     8443,
+			Protocol: "UDP",
+		},
+	}
+
+	gcv := createGlobalConfigurationValidator()
+
+	listeners, allErrs := gcv.getValidListeners(listeners, field.NewPath("listeners"))
+	if diff := cmp.Diff(listeners, wantListeners); diff != "" {
+		t.Errorf("getValidListeners() returned unexpected result: (-want +got):\n%s", diff)
+	}
+	if len(allErrs) == 0 {
+		t.Errorf("validateListeners() returned no errors %v for invalid input", allErrs)
+	}
+}
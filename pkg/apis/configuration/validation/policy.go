@@ -7,10 +7,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
+	"github.com/dlclark/regexp2"
+	"github.com/nginx/kubernetes-ingress/internal/configs"
 	validation2 "github.com/nginx/kubernetes-ingress/internal/validation"
 	v1 "github.com/nginx/kubernetes-ingress/pkg/apis/configuration/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
@@ -37,10 +41,6 @@ func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enab
 	}
 
 	if spec.JWTAuth != nil {
-		if !isPlus {
-			return append(allErrs, field.Forbidden(fieldPath.Child("jwt"), "jwt secrets are only supported in NGINX Plus"))
-		}
-
 		allErrs = append(allErrs, validateJWT(spec.JWTAuth, fieldPath.Child("jwt"))...)
 		fieldCount++
 	}
@@ -65,11 +65,7 @@ func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enab
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("oidc"),
 				"OIDC must be enabled via cli argument -enable-oidc to use OIDC policy"))
 		}
-		if !isPlus {
-			return append(allErrs, field.Forbidden(fieldPath.Child("oidc"), "OIDC is only supported in NGINX Plus"))
-		}
-
-		allErrs = append(allErrs, validateOIDC(spec.OIDC, fieldPath.Child("oidc"))...)
+		allErrs = append(allErrs, validateOIDC(spec.OIDC, fieldPath.Child("oidc"), isPlus)...)
 		fieldCount++
 	}
 
@@ -78,6 +74,41 @@ func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enab
 		fieldCount++
 	}
 
+	if spec.CORS != nil {
+		allErrs = append(allErrs, validateCORS(spec.CORS, fieldPath.Child("cors"))...)
+		fieldCount++
+	}
+
+	if spec.ExternalAuth != nil {
+		allErrs = append(allErrs, validateExternalAuth(spec.ExternalAuth, fieldPath.Child("externalAuth"))...)
+		fieldCount++
+	}
+
+	if spec.Cache != nil {
+		allErrs = append(allErrs, validateCache(spec.Cache, fieldPath.Child("cache"), isPlus)...)
+		fieldCount++
+	}
+
+	if spec.FaultInjection != nil {
+		allErrs = append(allErrs, validateFaultInjection(spec.FaultInjection, fieldPath.Child("faultInjection"))...)
+		fieldCount++
+	}
+
+	if spec.ConnectionLimit != nil {
+		allErrs = append(allErrs, validateConnectionLimit(spec.ConnectionLimit, fieldPath.Child("connectionLimit"), isPlus)...)
+		fieldCount++
+	}
+
+	if spec.Authorization != nil {
+		allErrs = append(allErrs, validateAuthorization(spec.Authorization, fieldPath.Child("authorization"))...)
+		fieldCount++
+	}
+
+	if spec.SignatureValidation != nil {
+		allErrs = append(allErrs, validateSignatureValidation(spec.SignatureValidation, fieldPath.Child("signatureValidation"))...)
+		fieldCount++
+	}
+
 	if spec.WAF != nil {
 		if !isPlus {
 			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("waf"), "WAF is only supported in NGINX Plus"))
@@ -92,9 +123,9 @@ func validatePolicySpec(spec *v1.PolicySpec, fieldPath *field.Path, isPlus, enab
 	}
 
 	if fieldCount != 1 {
-		msg := "must specify exactly one of: `accessControl`, `rateLimit`, `ingressMTLS`, `egressMTLS`, `basicAuth`, `apiKey`"
+		msg := "must specify exactly one of: `accessControl`, `rateLimit`, `ingressMTLS`, `egressMTLS`, `basicAuth`, `apiKey`, `cors`, `externalAuth`, `cache`, `faultInjection`, `connectionLimit`, `authorization`, `signatureValidation`, `jwt`, `oidc`"
 		if isPlus {
-			msg = fmt.Sprint(msg, ", `jwt`, `oidc`, `waf`")
+			msg = fmt.Sprint(msg, ", `waf`")
 		}
 		allErrs = append(allErrs, field.Invalid(fieldPath, "", msg))
 	}
@@ -167,6 +198,132 @@ func validateRateLimit(rateLimit *v1.RateLimit, fieldPath *field.Path, isPlus bo
 	return allErrs
 }
 
+func validateConnectionLimit(connLimit *v1.ConnectionLimit, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := validateRateLimitZoneSize(connLimit.ZoneSize, fieldPath.Child("zoneSize"))
+	allErrs = append(allErrs, validateConnectionLimitKey(connLimit.Key, fieldPath.Child("key"), isPlus)...)
+
+	if connLimit.Connections <= 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("connections"), connLimit.Connections, "must be positive"))
+	}
+
+	if connLimit.RejectCode != nil {
+		if *connLimit.RejectCode < 400 || *connLimit.RejectCode > 599 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("rejectCode"), connLimit.RejectCode,
+				"must be within the range [400-599]"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateJWTIssuerAndAudience validates the iss and aud claim requirements, checked by njs in NGINX and by
+// auth_jwt_require in NGINX Plus.
+func validateJWTIssuerAndAudience(jwt *v1.JWTAuth, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if jwt.Issuer != "" && !claimValueRegexp.MatchString(jwt.Issuer) {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("issuer"), jwt.Issuer,
+			`must not contain whitespace, '$', '"', "'", '\\' or ','`))
+	}
+
+	for i, aud := range jwt.Audience {
+		if !claimValueRegexp.MatchString(aud) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("audience").Index(i), aud,
+				`must not be empty or contain whitespace, '$', '"', "'", '\\' or ','`))
+		}
+	}
+
+	return allErrs
+}
+
+func validateAuthorization(authz *v1.Authorization, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(authz.AllOf) == 0 && len(authz.AnyOf) == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath, "at least one of `allOf` or `anyOf` must be specified"))
+	}
+
+	for i, req := range authz.AllOf {
+		allErrs = append(allErrs, validateClaimRequirement(req, fieldPath.Child("allOf").Index(i))...)
+	}
+
+	for i, req := range authz.AnyOf {
+		allErrs = append(allErrs, validateClaimRequirement(req, fieldPath.Child("anyOf").Index(i))...)
+	}
+
+	if authz.RejectCode != nil && *authz.RejectCode != 401 && *authz.RejectCode != 403 {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("rejectCode"), *authz.RejectCode, []string{"401", "403"}))
+	}
+
+	return allErrs
+}
+
+var (
+	signatureFormats    = map[string]bool{"plain": true, "stripe": true, "slack": true}
+	signatureAlgorithms = map[string]bool{"sha1": true, "sha256": true, "sha512": true}
+)
+
+func validateSignatureValidation(sv *v1.SignatureValidation, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if sv.Secret == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("secret"), ""))
+	} else {
+		allErrs = append(allErrs, validateSecretName(sv.Secret, fieldPath.Child("secret"))...)
+	}
+
+	if sv.Format != "" && !signatureFormats[sv.Format] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("format"), sv.Format, []string{"plain", "stripe", "slack"}))
+	}
+
+	if sv.Algorithm != "" && !signatureAlgorithms[sv.Algorithm] {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("algorithm"), sv.Algorithm, []string{"sha1", "sha256", "sha512"}))
+	}
+
+	if sv.Header != "" {
+		for _, msg := range validation.IsHTTPHeaderName(sv.Header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("header"), sv.Header, msg))
+		}
+	}
+
+	if sv.TimestampTolerance != nil && *sv.TimestampTolerance < 0 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("timestampTolerance"), *sv.TimestampTolerance, "must not be negative"))
+	}
+
+	if sv.RejectCode != nil && *sv.RejectCode != 401 && *sv.RejectCode != 403 {
+		allErrs = append(allErrs, field.NotSupported(fieldPath.Child("rejectCode"), *sv.RejectCode, []string{"401", "403"}))
+	}
+
+	return allErrs
+}
+
+var claimValueRegexp = regexp.MustCompile(`^[^\s$"'\\,]+$`)
+
+func validateClaimRequirement(req v1.ClaimRequirement, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if req.Claim == "" {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("claim"), ""))
+	} else {
+		for _, msg := range isJWTClaimName(req.Claim) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("claim"), req.Claim, msg))
+		}
+	}
+
+	if len(req.Values) == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("values"), ""))
+	}
+
+	for i, v := range req.Values {
+		if !claimValueRegexp.MatchString(v) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("values").Index(i), v,
+				`must not be empty or contain whitespace, '$', '"', "'", '\\' or ','`))
+		}
+	}
+
+	return allErrs
+}
+
 // validateJWT validates JWT Policy according the rules specified in documentation
 // for using [jwt] local k8s secrets and using [jwks] from remote location.
 //
@@ -178,6 +335,7 @@ func validateJWT(jwt *v1.JWTAuth, fieldPath *field.Path) field.ErrorList {
 		return field.ErrorList{field.Required(fieldPath.Child("realm"), "realm field must be present")}
 	}
 	allErrs := validateRealm(jwt.Realm, fieldPath.Child("realm"))
+	allErrs = append(allErrs, validateJWTIssuerAndAudience(jwt, fieldPath)...)
 
 	// Use either JWT Secret or JWKS URI, they are mutually exclusive.
 	if jwt.Secret == "" && jwt.JwksURI == "" {
@@ -265,6 +423,56 @@ func validateIngressMTLS(ingressMTLS *v1.IngressMTLS, fieldPath *field.Path) fie
 	if ingressMTLS.VerifyDepth != nil {
 		allErrs = append(allErrs, validatePositiveIntOrZero(*ingressMTLS.VerifyDepth, fieldPath.Child("verifyDepth"))...)
 	}
+	if ingressMTLS.ForwardCertificate != nil {
+		allErrs = append(allErrs, validateForwardCertificate(ingressMTLS.ForwardCertificate, fieldPath.Child("forwardCertificate"))...)
+	}
+	if ingressMTLS.OCSP != nil {
+		allErrs = append(allErrs, validateIngressMTLSOCSP(ingressMTLS.OCSP, ingressMTLS.VerifyClient, fieldPath.Child("ocsp"))...)
+	}
+	return allErrs
+}
+
+func validateIngressMTLSOCSP(ocsp *v1.IngressMTLSOCSP, verifyClient string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if verifyClient == "off" || verifyClient == "optional_no_ca" {
+		msg := fmt.Sprintf("cannot be used with verifyClient %q", verifyClient)
+		allErrs = append(allErrs, field.Forbidden(fieldPath, msg))
+	}
+	return append(allErrs, validateOCSPResponder(ocsp.Responder, fieldPath.Child("responder"))...)
+}
+
+func validateForwardCertificate(fc *v1.ForwardCertificate, fieldPath *field.Path) field.ErrorList {
+	headers := []struct {
+		field string
+		name  string
+	}{
+		{"subjectHeader", fc.SubjectHeader},
+		{"sansHeader", fc.SANsHeader},
+		{"fingerprintHeader", fc.FingerprintHeader},
+		{"certificateHeader", fc.CertificateHeader},
+	}
+
+	allErrs := field.ErrorList{}
+	used := sets.Set[string]{}
+
+	for _, h := range headers {
+		if h.name == "" {
+			continue
+		}
+		for _, msg := range validation.IsHTTPHeaderName(h.name) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child(h.field), h.name, msg))
+		}
+		if used.Has(strings.ToLower(h.name)) {
+			allErrs = append(allErrs, field.Duplicate(fieldPath.Child(h.field), h.name))
+		}
+		used.Insert(strings.ToLower(h.name))
+	}
+
+	if used.Len() == 0 {
+		msg := "at least one of subjectHeader, sansHeader, fingerprintHeader or certificateHeader must be set"
+		allErrs = append(allErrs, field.Required(fieldPath, msg))
+	}
+
 	return allErrs
 }
 
@@ -282,7 +490,7 @@ func validateEgressMTLS(egressMTLS *v1.EgressMTLS, fieldPath *field.Path) field.
 	return append(allErrs, validateSSLName(egressMTLS.SSLName, fieldPath.Child("sslName"))...)
 }
 
-func validateOIDC(oidc *v1.OIDC, fieldPath *field.Path) field.ErrorList {
+func validateOIDC(oidc *v1.OIDC, fieldPath *field.Path, isPlus bool) field.ErrorList {
 	if oidc.AuthEndpoint == "" {
 		return field.ErrorList{field.Required(fieldPath.Child("authEndpoint"), "")}
 	}
@@ -302,6 +510,10 @@ func validateOIDC(oidc *v1.OIDC, fieldPath *field.Path) field.ErrorList {
 
 	allErrs := field.ErrorList{}
 
+	if oidc.PKCEEnable && !isPlus {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("pkceEnable"), "PKCE is only supported in NGINX Plus"))
+	}
+
 	allErrs = append(allErrs, validatePKCE(oidc.PKCEEnable, oidc.ClientSecret, fieldPath.Child("clientSecret"))...)
 
 	if oidc.Scope != "" {
@@ -322,6 +534,14 @@ func validateOIDC(oidc *v1.OIDC, fieldPath *field.Path) field.ErrorList {
 	if oidc.AuthExtraArgs != nil {
 		allErrs = append(allErrs, validateQueryString(strings.Join(oidc.AuthExtraArgs, "&"), fieldPath.Child("authExtraArgs"))...)
 	}
+	if oidc.RefreshLeeway != nil {
+		allErrs = append(allErrs, validatePositiveIntOrZero(*oidc.RefreshLeeway, fieldPath.Child("refreshLeeway"))...)
+	}
+	if oidc.BackchannelLogoutURI != "" {
+		allErrs = append(allErrs, validateBackchannelLogoutURI(oidc.BackchannelLogoutURI, oidc.RedirectURI, fieldPath.Child("backchannelLogoutURI"))...)
+	}
+
+	allErrs = append(allErrs, validateClaimHeaders(oidc.ClaimHeaders, fieldPath.Child("claimHeaders"))...)
 
 	allErrs = append(allErrs, validateURL(oidc.AuthEndpoint, fieldPath.Child("authEndpoint"))...)
 	allErrs = append(allErrs, validateURL(oidc.TokenEndpoint, fieldPath.Child("tokenEndpoint"))...)
@@ -370,9 +590,378 @@ func validateAPIKey(apiKey *v1.APIKey, fieldPath *field.Path) field.ErrorList {
 		allErrs = append(allErrs, validateSecretName(apiKey.ClientSecret, fieldPath.Child("clientSecret"))...)
 	}
 
+	allErrs = append(allErrs, validateAPIKeyTiers(apiKey.Tiers, fieldPath.Child("tiers"))...)
+
 	return allErrs
 }
 
+func validateAPIKeyTiers(tiers []v1.APIKeyTier, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.Set[string]{}
+	clientIDs := sets.Set[string]{}
+
+	for i, tier := range tiers {
+		idxPath := fieldPath.Index(i)
+
+		if tier.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(tier.Name) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), tier.Name, msg))
+			}
+			if names.Has(tier.Name) {
+				allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), tier.Name))
+			}
+			names.Insert(tier.Name)
+		}
+
+		if len(tier.ClientIDs) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("clientIDs"), "at least one client must be provided"))
+		}
+		for j, clientID := range tier.ClientIDs {
+			clientPath := idxPath.Child("clientIDs").Index(j)
+			for _, msg := range validation.IsConfigMapKey(clientID) {
+				allErrs = append(allErrs, field.Invalid(clientPath, clientID, msg))
+			}
+			if clientIDs.Has(clientID) {
+				allErrs = append(allErrs, field.Invalid(clientPath, clientID, "a client can only be assigned to one tier"))
+			}
+			clientIDs.Insert(clientID)
+		}
+	}
+
+	return allErrs
+}
+
+func validateCORS(cors *v1.CORS, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(cors.AllowOrigins) == 0 {
+		return append(allErrs, field.Required(fieldPath.Child("allowOrigins"), "at least one origin must be provided"))
+	}
+
+	for i, origin := range cors.AllowOrigins {
+		idxPath := fieldPath.Child("allowOrigins").Index(i)
+		if origin == "*" {
+			if len(cors.AllowOrigins) > 1 {
+				allErrs = append(allErrs, field.Invalid(idxPath, origin, "* must be the only origin"))
+			}
+			if cors.AllowCredentials {
+				allErrs = append(allErrs, field.Forbidden(idxPath, "* cannot be used together with allowCredentials"))
+			}
+			continue
+		}
+		allErrs = append(allErrs, validateCORSOrigin(origin, idxPath)...)
+	}
+
+	for i, method := range cors.AllowMethods {
+		if !corsMethodRegexp.MatchString(method) {
+			msg := validation.RegexError(corsMethodErrMsg, corsMethodFmt, "GET", "PATCH")
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("allowMethods").Index(i), method, msg))
+		}
+	}
+
+	for i, header := range cors.AllowHeaders {
+		for _, msg := range validation.IsHTTPHeaderName(header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("allowHeaders").Index(i), header, msg))
+		}
+	}
+
+	for i, header := range cors.ExposeHeaders {
+		for _, msg := range validation.IsHTTPHeaderName(header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("exposeHeaders").Index(i), header, msg))
+		}
+	}
+
+	if cors.MaxAge != nil {
+		allErrs = append(allErrs, validatePositiveInt(*cors.MaxAge, fieldPath.Child("maxAge"))...)
+	}
+
+	return allErrs
+}
+
+const (
+	corsMethodFmt    = `[A-Z]+`
+	corsMethodErrMsg = "must consist of uppercase letters"
+)
+
+var corsMethodRegexp = regexp.MustCompile("^" + corsMethodFmt + "$")
+
+// validateCORSOrigin validates an origin of the CORS policy. An origin is either a regular expression
+// that starts with ~ or an exact origin in the format scheme://host[:port].
+func validateCORSOrigin(origin string, fieldPath *field.Path) field.ErrorList {
+	if strings.HasPrefix(origin, "~") {
+		regex := strings.TrimPrefix(origin, "~")
+		if regex == "" {
+			return field.ErrorList{field.Invalid(fieldPath, origin, "regular expression cannot be empty")}
+		}
+		if _, err := regexp2.Compile(regex, 0); err != nil {
+			return field.ErrorList{field.Invalid(fieldPath, origin, fmt.Sprintf("must be a valid regular expression: %v", err))}
+		}
+		if err := ValidateEscapedString(origin, `~^https://.+\.example\.com$`); err != nil {
+			return field.ErrorList{field.Invalid(fieldPath, origin, err.Error())}
+		}
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fieldPath, origin, err.Error())}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return field.ErrorList{field.Invalid(fieldPath, origin, "scheme must be http or https")}
+	}
+	if u.Host == "" {
+		return field.ErrorList{field.Invalid(fieldPath, origin, "hostname required")}
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return field.ErrorList{field.Invalid(fieldPath, origin, "must be in the format scheme://host[:port]")}
+	}
+
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host = u.Host
+	}
+
+	allErrs := validateSSLName(host, fieldPath)
+	if port != "" {
+		allErrs = append(allErrs, validatePortNumber(port, fieldPath)...)
+	}
+	return allErrs
+}
+
+func validateExternalAuth(extAuth *v1.ExternalAuth, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	svcPath := fieldPath.Child("authService")
+	if extAuth.AuthService == "" {
+		allErrs = append(allErrs, field.Required(svcPath, ""))
+	} else if name, port, err := net.SplitHostPort(extAuth.AuthService); err != nil {
+		allErrs = append(allErrs, field.Invalid(svcPath, extAuth.AuthService, "must be in the format service:port"))
+	} else {
+		allErrs = append(allErrs, validateServiceName(name, svcPath)...)
+		allErrs = append(allErrs, validatePortNumber(port, svcPath)...)
+	}
+
+	if extAuth.AuthPath != "" {
+		allErrs = append(allErrs, validatePath(extAuth.AuthPath, fieldPath.Child("authPath"))...)
+	}
+
+	for i, header := range extAuth.ForwardRequestHeaders {
+		for _, msg := range validation.IsHTTPHeaderName(header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("forwardRequestHeaders").Index(i), header, msg))
+		}
+	}
+
+	for i, header := range extAuth.ResponseHeaders {
+		for _, msg := range validation.IsHTTPHeaderName(header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("responseHeaders").Index(i), header, msg))
+		}
+	}
+
+	if extAuth.SigninURL != "" {
+		allErrs = append(allErrs, validateSigninURL(extAuth.SigninURL, fieldPath.Child("signinURL"))...)
+	}
+
+	return allErrs
+}
+
+// validateSigninURL validates the URL clients are redirected to when they are not authorized.
+// Unlike validateURL, the path of the URL is optional.
+func validateSigninURL(signinURL string, fieldPath *field.Path) field.ErrorList {
+	if strings.ContainsAny(signinURL, " \t\n\"';{}\\") {
+		return field.ErrorList{field.Invalid(fieldPath, signinURL, "must not include any whitespace character, quotes, `;`, `{`, `}` or `\\`")}
+	}
+
+	u, err := url.Parse(signinURL)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fieldPath, signinURL, err.Error())}
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return field.ErrorList{field.Invalid(fieldPath, signinURL, "scheme required, please use the prefix http(s)://")}
+	}
+	if u.Host == "" {
+		return field.ErrorList{field.Invalid(fieldPath, signinURL, "hostname required")}
+	}
+
+	return nil
+}
+
+// validateOCSPResponder validates the URL of an OCSP responder. NGINX supports only http:// responders.
+func validateOCSPResponder(responder string, fieldPath *field.Path) field.ErrorList {
+	if responder == "" {
+		return nil
+	}
+	if strings.ContainsAny(responder, " \t\n\"';{}\\") {
+		return field.ErrorList{field.Invalid(fieldPath, responder, "must not include any whitespace character, quotes, `;`, `{`, `}` or `\\`")}
+	}
+
+	u, err := url.Parse(responder)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fieldPath, responder, err.Error())}
+	}
+	if u.Scheme != "http" {
+		return field.ErrorList{field.Invalid(fieldPath, responder, "scheme required, please use the prefix http://")}
+	}
+	if u.Hostname() == "" {
+		return field.ErrorList{field.Invalid(fieldPath, responder, "hostname required")}
+	}
+
+	allErrs := field.ErrorList{}
+	if net.ParseIP(u.Hostname()) == nil {
+		allErrs = append(allErrs, validateSSLName(u.Hostname(), fieldPath)...)
+	}
+	if u.Port() != "" {
+		allErrs = append(allErrs, validatePortNumber(u.Port(), fieldPath)...)
+	}
+	return allErrs
+}
+
+// cacheKeySpecialVariables includes NGINX variable prefixes allowed to be used in a cache policy key.
+var cacheKeySpecialVariables = []string{"arg_", "http_", "cookie_"}
+
+// cacheKeyVariables includes NGINX variables allowed to be used in a cache policy key.
+var cacheKeyVariables = map[string]bool{
+	"scheme":         true,
+	"host":           true,
+	"proxy_host":     true,
+	"request_uri":    true,
+	"uri":            true,
+	"args":           true,
+	"request_method": true,
+}
+
+var cacheAllowedMethods = map[string]bool{
+	"GET":  true,
+	"HEAD": true,
+	"POST": true,
+}
+
+var cacheBypassVariableRegexp = regexp.MustCompile(`^\$(arg|http|cookie)_[a-zA-Z0-9_]+$`)
+
+func validateCache(cache *v1.Cache, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cache.CacheZoneSize != "" {
+		allErrs = append(allErrs, validateSize(cache.CacheZoneSize, fieldPath.Child("cacheZoneSize"))...)
+	}
+
+	if cache.MaxSize != "" {
+		allErrs = append(allErrs, validateOffset(cache.MaxSize, fieldPath.Child("maxSize"))...)
+	}
+
+	if cache.Inactive != "" {
+		allErrs = append(allErrs, validateTime(cache.Inactive, fieldPath.Child("inactive"))...)
+	}
+
+	if cache.Key != "" {
+		keyPath := fieldPath.Child("key")
+		if err := ValidateEscapedString(cache.Key, `${scheme}${host}${request_uri}`); err != nil {
+			allErrs = append(allErrs, field.Invalid(keyPath, cache.Key, err.Error()))
+		}
+		allErrs = append(allErrs, validateStringWithVariables(cache.Key, keyPath, cacheKeySpecialVariables, cacheKeyVariables, isPlus)...)
+	}
+
+	for i, method := range cache.AllowedMethods {
+		if !cacheAllowedMethods[method] {
+			allErrs = append(allErrs, field.NotSupported(fieldPath.Child("allowedMethods").Index(i), method, []string{"GET", "HEAD", "POST"}))
+		}
+	}
+
+	for i, v := range cache.Valid {
+		validPath := fieldPath.Child("valid").Index(i)
+		if len(v.Codes) == 0 {
+			allErrs = append(allErrs, field.Required(validPath.Child("codes"), ""))
+		}
+		for j, code := range v.Codes {
+			allErrs = append(allErrs, validateCacheValidCode(code, validPath.Child("codes").Index(j))...)
+		}
+		if v.Time == "" {
+			allErrs = append(allErrs, field.Required(validPath.Child("time"), ""))
+		} else {
+			allErrs = append(allErrs, validateTime(v.Time, validPath.Child("time"))...)
+		}
+	}
+
+	for i, bypass := range cache.Bypass {
+		if !cacheBypassVariableRegexp.MatchString(bypass) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("bypass").Index(i), bypass, "must be a variable starting with $arg_, $http_ or $cookie_"))
+		}
+	}
+
+	if len(cache.PurgeAllowedCIDRs) > 0 {
+		if !isPlus {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("purgeAllowedCIDRs"), "purge is only supported in NGINX Plus"))
+		}
+		for i, ipOrCIDR := range cache.PurgeAllowedCIDRs {
+			allErrs = append(allErrs, validateIPorCIDR(ipOrCIDR, fieldPath.Child("purgeAllowedCIDRs").Index(i))...)
+		}
+	}
+
+	return allErrs
+}
+
+func validateCacheValidCode(code string, fieldPath *field.Path) field.ErrorList {
+	if code == "any" {
+		return nil
+	}
+
+	c, err := strconv.Atoi(code)
+	if err != nil || c < 100 || c > 599 {
+		return field.ErrorList{field.Invalid(fieldPath, code, "must be a status code in the range 100-599 or any")}
+	}
+
+	return nil
+}
+
+func validateFaultInjection(fault *v1.FaultInjection, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if fault.Percentage < 1 || fault.Percentage > 100 {
+		allErrs = append(allErrs, field.Invalid(fieldPath.Child("percentage"), fault.Percentage, "must be in the range 1..100"))
+	}
+
+	fieldCount := 0
+
+	if fault.Delay != "" {
+		allErrs = append(allErrs, validateFaultInjectionDelay(fault.Delay, fieldPath.Child("delay"))...)
+		fieldCount++
+	}
+
+	if fault.Status != 0 {
+		if fault.Status < 400 || fault.Status > 599 {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("status"), fault.Status, "must be in the range 400..599"))
+		}
+		fieldCount++
+	}
+
+	if fieldCount != 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `delay` or `status`"))
+	}
+
+	if fault.Condition != nil {
+		allErrs = append(allErrs, validateCondition(*fault.Condition, fieldPath.Child("condition"))...)
+	}
+
+	return allErrs
+}
+
+// validateFaultInjectionDelay validates the delay of a FaultInjection policy.
+// Unlike validateTime, it only accepts the units up to hours, since the delay is converted to milliseconds.
+func validateFaultInjectionDelay(delay string, fieldPath *field.Path) field.ErrorList {
+	t, err := configs.ParseTime(delay)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fieldPath, delay, err.Error())}
+	}
+
+	d, err := time.ParseDuration(t)
+	if err != nil || d <= 0 {
+		return field.ErrorList{field.Invalid(fieldPath, delay, "must be a positive time with the units h, m, s or ms")}
+	}
+
+	return nil
+}
+
 func validateWAF(waf *v1.WAF, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	bundleMode := waf.ApBundle != ""
@@ -500,6 +1089,59 @@ func validateOIDCScope(scope string, fieldPath *field.Path) field.ErrorList {
 	return nil
 }
 
+// oidcLocationPaths are the paths of the locations generated for an OIDC policy.
+var oidcLocationPaths = map[string]bool{
+	"/_codexch":          true,
+	"/_token":            true,
+	"/_refresh":          true,
+	"/_jwks_uri":         true,
+	"/_token_validation": true,
+	"/_oidc_session":     true,
+	"/logout":            true,
+	"/_logout":           true,
+}
+
+func validateBackchannelLogoutURI(uri string, redirectURI string, fieldPath *field.Path) field.ErrorList {
+	allErrs := validatePath(uri, fieldPath)
+	if oidcLocationPaths[uri] || uri == redirectURI {
+		allErrs = append(allErrs, field.Invalid(fieldPath, uri, "must not be the path of another OIDC location or the redirectURI"))
+	}
+	return allErrs
+}
+
+func validateClaimHeaders(claimHeaders []v1.ClaimHeader, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	headers := sets.Set[string]{}
+
+	for i, h := range claimHeaders {
+		idxPath := fieldPath.Index(i)
+
+		if h.Claim == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("claim"), ""))
+		} else {
+			for _, msg := range isJWTClaimName(h.Claim) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("claim"), h.Claim, msg))
+			}
+		}
+
+		if h.Header == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("header"), ""))
+			continue
+		}
+		for _, msg := range validation.IsHTTPHeaderName(h.Header) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("header"), h.Header, msg))
+		}
+
+		header := strings.ToLower(h.Header)
+		if headers.Has(header) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("header"), h.Header))
+		}
+		headers.Insert(header)
+	}
+
+	return allErrs
+}
+
 // validatePKCE checks for the duo of PKCEEnable and clientSecret settings.
 //
 //   - yes PKCE and not empty client secret is bad, because PKCE does not use
@@ -646,9 +1288,37 @@ func validateRateLimitKey(key string, fieldPath *field.Path, isPlus bool) field.
 	if err := ValidateEscapedString(key, `Hello World! \n`, `\"${request_uri}\" is unavailable. \n`); err != nil {
 		allErrs = append(allErrs, field.Invalid(fieldPath, key, err.Error()))
 	}
+	if !isPlus {
+		// in NGINX, the claims are set when the JWT is validated, which happens after the rate limit is applied
+		for _, nVar := range captureVariables(key) {
+			if strings.HasPrefix(nVar, "jwt_claim_") {
+				allErrs = append(allErrs, field.Forbidden(fieldPath, fmt.Sprintf("$%s is only supported in NGINX Plus", nVar)))
+			}
+		}
+	}
 	return append(allErrs, validateStringWithVariables(key, fieldPath, rateLimitKeySpecialVariables, rateLimitKeyVariables, isPlus)...)
 }
 
+// connectionLimitKeyVariables includes NGINX variables allowed to be used in a connectionLimit policy key.
+// The variables must be available in both the http and the stream contexts.
+var connectionLimitKeyVariables = map[string]bool{
+	"binary_remote_addr": true,
+	"remote_addr":        true,
+	"server_addr":        true,
+	"server_port":        true,
+}
+
+func validateConnectionLimitKey(key string, fieldPath *field.Path, isPlus bool) field.ErrorList {
+	if key == "" {
+		return field.ErrorList{field.Required(fieldPath, "")}
+	}
+	allErrs := field.ErrorList{}
+	if err := ValidateEscapedString(key, `${binary_remote_addr}`); err != nil {
+		allErrs = append(allErrs, field.Invalid(fieldPath, key, err.Error()))
+	}
+	return append(allErrs, validateStringWithVariables(key, fieldPath, nil, connectionLimitKeyVariables, isPlus)...)
+}
+
 var jwtTokenSpecialVariables = []string{"arg_", "http_", "cookie_"}
 
 func validateJWTToken(token string, fieldPath *field.Path) field.ErrorList {
@@ -400,7 +400,39 @@ func TestValidatePolicy_PassesOnValidInput(t *testing.T) {
 			isPlus:           true,
 			enableOIDC:       false,
 			enableAppProtect: false,
-			msg:              "use jwt(plus only) policy",
+			msg:              "use jwt policy",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					JWTAuth: &v1.JWTAuth{
+						Realm:    "My Product API",
+						Secret:   "my-jwk",
+						Issuer:   "https://idp.com",
+						Audience: []string{"orders"},
+					},
+				},
+			},
+			isPlus:           false,
+			enableOIDC:       false,
+			enableAppProtect: false,
+			msg:              "use jwt policy with issuer and audience on NGINX",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					JWTAuth: &v1.JWTAuth{
+						Realm:    "My Product API",
+						Secret:   "my-jwk",
+						Issuer:   "https://idp.com",
+						Audience: []string{"orders"},
+					},
+				},
+			},
+			isPlus:           true,
+			enableOIDC:       false,
+			enableAppProtect: false,
+			msg:              "use jwt policy with issuer and audience on NGINX Plus",
 		},
 		{
 			policy: &v1.Policy{
@@ -423,7 +455,25 @@ func TestValidatePolicy_PassesOnValidInput(t *testing.T) {
 			},
 			isPlus:     true,
 			enableOIDC: true,
-			msg:        "use OIDC (plus only)",
+			msg:        "use OIDC",
+		},
+		{
+			policy: &v1.Policy{
+				Spec: v1.PolicySpec{
+					OIDC: &v1.OIDC{
+						AuthEndpoint:      "https://foo.bar/auth",
+						TokenEndpoint:     "https://foo.bar/token",
+						JWKSURI:           "https://foo.bar/certs",
+						ClientID:          "random-string",
+						ClientSecret:      "random-secret",
+						Scope:             "openid",
+						AccessTokenEnable: true,
+					},
+				},
+			},
+			isPlus:     false,
+			enableOIDC: true,
+			msg:        "use OIDC in OSS",
 		},
 		{
 			policy: &v1.Policy{
@@ -489,13 +539,14 @@ func TestValidatePolicy_FailsOnInvalidInput(t *testing.T) {
 					JWTAuth: &v1.JWTAuth{
 						Realm:  "My Product API",
 						Secret: "my-jwk",
+						Issuer: "https://idp.com extra",
 					},
 				},
 			},
-			isPlus:           false,
+			isPlus:           true,
 			enableOIDC:       false,
 			enableAppProtect: false,
-			msg:              "jwt(plus only) policy on OSS",
+			msg:              "jwt policy with invalid issuer",
 		},
 		{
 			policy: &v1.Policy{
@@ -542,15 +593,15 @@ func TestValidatePolicy_FailsOnInvalidInput(t *testing.T) {
 						EndSessionEndpoint:    "https://foo.bar/logout",
 						PostLogoutRedirectURI: "/_logout",
 						ClientID:              "random-string",
-						ClientSecret:          "random-secret",
 						Scope:                 "openid",
 						AccessTokenEnable:     true,
+						PKCEEnable:            true,
 					},
 				},
 			},
 			isPlus:     false,
 			enableOIDC: true,
-			msg:        "OIDC policy in OSS",
+			msg:        "OIDC policy with PKCE in OSS",
 		},
 		{
 			policy: &v1.Policy{
@@ -874,6 +925,17 @@ func TestValidateRateLimitKey(t *testing.T) {
 			t.Errorf("validateRateLimitKey %q returned no errors for an empty key", emptyKey)
 		}
 	})
+
+	t.Run("jwt claim key", func(t *testing.T) {
+		t.Parallel()
+		claimKey := "${jwt_claim_sub}"
+		if allErrs := validateRateLimitKey(claimKey, field.NewPath("key"), false); len(allErrs) == 0 {
+			t.Errorf("validateRateLimitKey %q returned no errors for a JWT claim key in NGINX", claimKey)
+		}
+		if allErrs := validateRateLimitKey(claimKey, field.NewPath("key"), true); len(allErrs) > 0 {
+			t.Errorf("validateRateLimitKey %q returned errors for a JWT claim key in NGINX Plus: %v", claimKey, allErrs)
+		}
+	})
 }
 
 func TestValidateJWT_PassesOnValidInput(t *testing.T) {
@@ -927,6 +989,16 @@ func TestValidateJWT_PassesOnValidInput(t *testing.T) {
 			},
 			msg: "SNI enabled and no server name passed",
 		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:    "My Product API",
+				JwksURI:  "https://idp.com/token",
+				KeyCache: "1h",
+				Issuer:   "https://idp.com",
+				Audience: []string{"orders", "api://orders"},
+			},
+			msg: "issuer and audience",
+		},
 	}
 	for _, test := range tests {
 		allErrs := validateJWT(test.jwt, field.NewPath("jwt"))
@@ -1059,6 +1131,24 @@ func TestValidateJWT_FailsOnInvalidInput(t *testing.T) {
 			},
 			msg: "SNI server name passed, SNI not passed",
 		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:    "My Product api",
+				JwksURI:  "https://idp.com/token",
+				KeyCache: "1h",
+				Issuer:   "https://idp.com extra",
+			},
+			msg: "issuer with whitespace",
+		},
+		{
+			jwt: &v1.JWTAuth{
+				Realm:    "My Product api",
+				JwksURI:  "https://idp.com/token",
+				KeyCache: "1h",
+				Audience: []string{"orders,billing"},
+			},
+			msg: "audience with comma",
+		},
 	}
 	for _, test := range tests {
 		test := test
@@ -1326,6 +1416,45 @@ func TestValidateIngressMTLS_PassesOnValidInput(t *testing.T) {
 			},
 			msg: "optional parameters",
 		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				ForwardCertificate: &v1.ForwardCertificate{
+					SubjectHeader:     "X-Client-Subject-DN",
+					SANsHeader:        "X-Client-SANs",
+					FingerprintHeader: "X-Client-Fingerprint",
+					CertificateHeader: "X-Client-Cert",
+				},
+			},
+			msg: "forward all certificate headers",
+		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				ForwardCertificate: &v1.ForwardCertificate{
+					SANsHeader: "X-Client-SANs",
+				},
+			},
+			msg: "forward one certificate header",
+		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				OCSP:             &v1.IngressMTLSOCSP{},
+			},
+			msg: "ocsp with default parameters",
+		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				VerifyClient:     "optional",
+				OCSP: &v1.IngressMTLSOCSP{
+					LeafOnly:  true,
+					Responder: "http://10.0.0.20:2560",
+				},
+			},
+			msg: "ocsp with responder",
+		},
 	}
 	for _, test := range tests {
 		allErrs := validateIngressMTLS(test.ing, field.NewPath("ingressMTLS"))
@@ -1368,6 +1497,49 @@ func TestValidateIngressMTLS_FailsOnInvalidInput(t *testing.T) {
 			},
 			msg: "invalid depth",
 		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret:   "ingress-mtls-secret",
+				ForwardCertificate: &v1.ForwardCertificate{},
+			},
+			msg: "no forwarded certificate headers",
+		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				ForwardCertificate: &v1.ForwardCertificate{
+					SubjectHeader: "X Client Subject",
+				},
+			},
+			msg: "invalid forwarded certificate header name",
+		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				ForwardCertificate: &v1.ForwardCertificate{
+					SubjectHeader:     "X-Client-Identity",
+					FingerprintHeader: "x-client-identity",
+				},
+			},
+			msg: "duplicate forwarded certificate header names",
+		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				VerifyClient:     "optional_no_ca",
+				OCSP:             &v1.IngressMTLSOCSP{},
+			},
+			msg: "ocsp with verify client optional_no_ca",
+		},
+		{
+			ing: &v1.IngressMTLS{
+				ClientCertSecret: "ingress-mtls-secret",
+				OCSP: &v1.IngressMTLSOCSP{
+					Responder: "ocsp.example.com",
+				},
+			},
+			msg: "ocsp responder without scheme",
+		},
 	}
 	for _, test := range tests {
 		allErrs := validateIngressMTLS(test.ing, field.NewPath("ingressMTLS"))
@@ -1596,6 +1768,22 @@ func TestValidateOIDC_PassesOnValidOIDC(t *testing.T) {
 			},
 			msg: "no end-session endpoint or post logout redirect URI",
 		},
+		{
+			oidc: &v1.OIDC{
+				AuthEndpoint:  "http://127.0.0.1:8080/realms/master/protocol/openid-connect/auth",
+				TokenEndpoint: "http://127.0.0.1:8080/realms/master/protocol/openid-connect/token",
+				JWKSURI:       "http://127.0.0.1:8080/realms/master/protocol/openid-connect/certs",
+				ClientID:      "client",
+				ClientSecret:  "secret",
+				ClaimHeaders: []v1.ClaimHeader{
+					{Claim: "email", Header: "X-User-Email"},
+					{Claim: "realm_access.roles", Header: "X-User-Roles"},
+				},
+				RefreshLeeway:        createPointerFromInt(60),
+				BackchannelLogoutURI: "/back_channel_logout",
+			},
+			msg: "claim headers, refresh leeway and back-channel logout",
+		},
 		{
 			oidc: &v1.OIDC{
 				AuthEndpoint:       "http://127.0.0.1:8080/realms/master/protocol/openid-connect/auth",
@@ -1613,7 +1801,7 @@ func TestValidateOIDC_PassesOnValidOIDC(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		allErrs := validateOIDC(test.oidc, field.NewPath("oidc"))
+		allErrs := validateOIDC(test.oidc, field.NewPath("oidc"), true)
 		if len(allErrs) != 0 {
 			t.Errorf("validateOIDC() returned errors %v for valid input for the case of %v", allErrs, test.msg)
 		}
@@ -1830,12 +2018,79 @@ func TestValidateOIDC_FailsOnInvalidOIDC(t *testing.T) {
 			fieldPath: "oidc.zoneSyncLeeway",
 			msg:       "invalid zoneSyncLeeway value",
 		},
+		{
+			oidc: &v1.OIDC{
+				AuthEndpoint:  "http://127.0.0.1:8080/realms/master/protocol/openid-connect/auth",
+				TokenEndpoint: "http://127.0.0.1:8080/realms/master/protocol/openid-connect/token",
+				JWKSURI:       "http://127.0.0.1:8080/realms/master/protocol/openid-connect/certs",
+				ClientID:      "client",
+				ClientSecret:  "secret",
+				ClaimHeaders: []v1.ClaimHeader{
+					{Claim: "email$", Header: "X-User-Email"},
+				},
+			},
+			fieldPath: "oidc.claimHeaders[0].claim",
+			msg:       "invalid claim",
+		},
+		{
+			oidc: &v1.OIDC{
+				AuthEndpoint:  "http://127.0.0.1:8080/realms/master/protocol/openid-connect/auth",
+				TokenEndpoint: "http://127.0.0.1:8080/realms/master/protocol/openid-connect/token",
+				JWKSURI:       "http://127.0.0.1:8080/realms/master/protocol/openid-connect/certs",
+				ClientID:      "client",
+				ClientSecret:  "secret",
+				ClaimHeaders: []v1.ClaimHeader{
+					{Claim: "email", Header: "X User Email"},
+				},
+			},
+			fieldPath: "oidc.claimHeaders[0].header",
+			msg:       "invalid header",
+		},
+		{
+			oidc: &v1.OIDC{
+				AuthEndpoint:  "http://127.0.0.1:8080/realms/master/protocol/openid-connect/auth",
+				TokenEndpoint: "http://127.0.0.1:8080/realms/master/protocol/openid-connect/token",
+				JWKSURI:       "http://127.0.0.1:8080/realms/master/protocol/openid-connect/certs",
+				ClientID:      "client",
+				ClientSecret:  "secret",
+				ClaimHeaders: []v1.ClaimHeader{
+					{Claim: "email", Header: "X-User"},
+					{Claim: "sub", Header: "x-user"},
+				},
+			},
+			fieldPath: "oidc.claimHeaders[1].header",
+			msg:       "duplicate header",
+		},
+		{
+			oidc: &v1.OIDC{
+				AuthEndpoint:  "http://127.0.0.1:8080/realms/master/protocol/openid-connect/auth",
+				TokenEndpoint: "http://127.0.0.1:8080/realms/master/protocol/openid-connect/token",
+				JWKSURI:       "http://127.0.0.1:8080/realms/master/protocol/openid-connect/certs",
+				ClientID:      "client",
+				ClientSecret:  "secret",
+				RefreshLeeway: createPointerFromInt(-1),
+			},
+			fieldPath: "oidc.refreshLeeway",
+			msg:       "invalid refreshLeeway value",
+		},
+		{
+			oidc: &v1.OIDC{
+				AuthEndpoint:         "http://127.0.0.1:8080/realms/master/protocol/openid-connect/auth",
+				TokenEndpoint:        "http://127.0.0.1:8080/realms/master/protocol/openid-connect/token",
+				JWKSURI:              "http://127.0.0.1:8080/realms/master/protocol/openid-connect/certs",
+				ClientID:             "client",
+				ClientSecret:         "secret",
+				BackchannelLogoutURI: "/logout",
+			},
+			fieldPath: "oidc.backchannelLogoutURI",
+			msg:       "backchannelLogoutURI is an OIDC location",
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.msg, func(t *testing.T) {
 			t.Parallel()
-			allErrs := validateOIDC(test.oidc, field.NewPath("oidc"))
+			allErrs := validateOIDC(test.oidc, field.NewPath("oidc"), true)
 			if len(allErrs) == 0 {
 				t.Errorf("validateOIDC() returned no errors for invalid input for the case of %v", test.msg)
 			} else if allErrs[0].Field != test.fieldPath {
@@ -1862,6 +2117,27 @@ func TestValidateAPIKeyPolicy_PassOnValidInput(t *testing.T) {
 				ClientSecret: "secret",
 			},
 		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{
+						"X-API-Key",
+					},
+				},
+				ClientSecret: "secret",
+				Tiers: []v1.APIKeyTier{
+					{
+						Name:      "premium",
+						ClientIDs: []string{"client1", "client2"},
+					},
+					{
+						Name:      "basic",
+						ClientIDs: []string{"client3"},
+					},
+				},
+			},
+			msg: "tiers",
+		},
 	}
 
 	for _, test := range tests {
@@ -1938,6 +2214,115 @@ func TestValidateAPIKeyPolicy_FailsOnInvalidInput(t *testing.T) {
 		{
 			apiKey: nil, msg: "no apikey provided",
 		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{
+						"X-API-Key",
+					},
+				},
+				ClientSecret: "secret",
+				Tiers: []v1.APIKeyTier{
+					{
+						Name:      "",
+						ClientIDs: []string{"client1"},
+					},
+				},
+			},
+			msg: "missing tier name",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{
+						"X-API-Key",
+					},
+				},
+				ClientSecret: "secret",
+				Tiers: []v1.APIKeyTier{
+					{
+						Name:      "Premium Tier",
+						ClientIDs: []string{"client1"},
+					},
+				},
+			},
+			msg: "invalid tier name",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{
+						"X-API-Key",
+					},
+				},
+				ClientSecret: "secret",
+				Tiers: []v1.APIKeyTier{
+					{
+						Name:      "premium",
+						ClientIDs: []string{"client1"},
+					},
+					{
+						Name:      "premium",
+						ClientIDs: []string{"client2"},
+					},
+				},
+			},
+			msg: "duplicate tier names",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{
+						"X-API-Key",
+					},
+				},
+				ClientSecret: "secret",
+				Tiers: []v1.APIKeyTier{
+					{
+						Name: "premium",
+					},
+				},
+			},
+			msg: "missing tier clients",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{
+						"X-API-Key",
+					},
+				},
+				ClientSecret: "secret",
+				Tiers: []v1.APIKeyTier{
+					{
+						Name:      "premium",
+						ClientIDs: []string{"client 1"},
+					},
+				},
+			},
+			msg: "invalid tier client",
+		},
+		{
+			apiKey: &v1.APIKey{
+				SuppliedIn: &v1.SuppliedIn{
+					Header: []string{
+						"X-API-Key",
+					},
+				},
+				ClientSecret: "secret",
+				Tiers: []v1.APIKeyTier{
+					{
+						Name:      "premium",
+						ClientIDs: []string{"client1"},
+					},
+					{
+						Name:      "basic",
+						ClientIDs: []string{"client1"},
+					},
+				},
+			},
+			msg: "client in several tiers",
+		},
 	}
 
 	for _, test := range tests {
@@ -1948,13 +2333,610 @@ func TestValidateAPIKeyPolicy_FailsOnInvalidInput(t *testing.T) {
 	}
 }
 
-func TestValidateOIDCScope_ErrorsOnInvalidInput(t *testing.T) {
+func TestValidateCORSPolicy_PassesOnValidInput(t *testing.T) {
 	t.Parallel()
+	tests := []struct {
+		cors *v1.CORS
+		msg  string
+	}{
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"*"},
+			},
+			msg: "all origins",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins:     []string{"https://example.com", "http://localhost:8080", `~^https://.+\.example\.com$`},
+				AllowMethods:     []string{"GET", "POST", "PATCH"},
+				AllowHeaders:     []string{"Content-Type", "X-Request-ID"},
+				ExposeHeaders:    []string{"X-Total-Count"},
+				AllowCredentials: true,
+				MaxAge:           createPointerFromInt(3600),
+			},
+			msg: "exact and regex origins with all fields",
+		},
+	}
 
-	invalidInput := []string{
-		"",
-		" ",
-		"openid+scope\x5c",
+	for _, test := range tests {
+		allErrs := validateCORS(test.cors, field.NewPath("cors"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateCORS() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateCORSPolicy_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		cors *v1.CORS
+		msg  string
+	}{
+		{
+			cors: &v1.CORS{},
+			msg:  "missing origins",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"*", "https://example.com"},
+			},
+			msg: "* combined with other origins",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins:     []string{"*"},
+				AllowCredentials: true,
+			},
+			msg: "* combined with credentials",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"example.com"},
+			},
+			msg: "origin without scheme",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"ftp://example.com"},
+			},
+			msg: "origin with invalid scheme",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"https://example.com/path"},
+			},
+			msg: "origin with path",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"https://example.com:99999"},
+			},
+			msg: "origin with invalid port",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"~^https://(example.com$"},
+			},
+			msg: "invalid regex origin",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{`~^https://"example.com$`},
+			},
+			msg: "regex origin with unescaped quote",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"~"},
+			},
+			msg: "empty regex origin",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"https://example.com"},
+				AllowMethods: []string{"get"},
+			},
+			msg: "invalid method",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"https://example.com"},
+				AllowHeaders: []string{"Content Type"},
+			},
+			msg: "invalid allowed header",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins:  []string{"https://example.com"},
+				ExposeHeaders: []string{`X-Count"`},
+			},
+			msg: "invalid exposed header",
+		},
+		{
+			cors: &v1.CORS{
+				AllowOrigins: []string{"https://example.com"},
+				MaxAge:       createPointerFromInt(0),
+			},
+			msg: "invalid max age",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCORS(test.cors, field.NewPath("cors"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateCORS() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateExternalAuthPolicy_PassesOnValidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		extAuth *v1.ExternalAuth
+		msg     string
+	}{
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth-svc:8080",
+			},
+			msg: "only auth service",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:           "auth-svc:8080",
+				AuthPath:              "/api/v1/verify",
+				ForwardRequestHeaders: []string{"Authorization", "Cookie"},
+				ResponseHeaders:       []string{"X-User", "X-Groups"},
+				SigninURL:             "https://sso.example.com/signin?rd=$scheme://$host$request_uri",
+			},
+			msg: "all fields",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateExternalAuth(test.extAuth, field.NewPath("externalAuth"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateExternalAuth() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateExternalAuthPolicy_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		extAuth *v1.ExternalAuth
+		msg     string
+	}{
+		{
+			extAuth: &v1.ExternalAuth{},
+			msg:     "missing auth service",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth-svc",
+			},
+			msg: "auth service without port",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth_svc:8080",
+			},
+			msg: "invalid service name",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth-svc:80800",
+			},
+			msg: "invalid port",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth-svc:8080",
+				AuthPath:    "verify",
+			},
+			msg: "path without leading slash",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:           "auth-svc:8080",
+				ForwardRequestHeaders: []string{"Authorization;"},
+			},
+			msg: "invalid forwarded header",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService:     "auth-svc:8080",
+				ResponseHeaders: []string{"X User"},
+			},
+			msg: "invalid response header",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth-svc:8080",
+				SigninURL:   "sso.example.com/signin",
+			},
+			msg: "sign-in URL without scheme",
+		},
+		{
+			extAuth: &v1.ExternalAuth{
+				AuthService: "auth-svc:8080",
+				SigninURL:   "https://sso.example.com/signin;",
+			},
+			msg: "sign-in URL with invalid character",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateExternalAuth(test.extAuth, field.NewPath("externalAuth"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateExternalAuth() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateCachePolicy_PassesOnValidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		cache  *v1.Cache
+		isPlus bool
+		msg    string
+	}{
+		{
+			cache: &v1.Cache{},
+			msg:   "no fields",
+		},
+		{
+			cache: &v1.Cache{
+				CacheZoneSize:  "20m",
+				MaxSize:        "1g",
+				Inactive:       "1h",
+				Key:            "${scheme}${host}${request_uri}${cookie_session}",
+				AllowedMethods: []string{"GET", "HEAD", "POST"},
+				Valid: []v1.CacheValid{
+					{Codes: []string{"200", "302"}, Time: "10m"},
+					{Codes: []string{"any"}, Time: "1m"},
+				},
+				Bypass:             []string{"$http_pragma", "$cookie_nocache", "$arg_nocache"},
+				IgnoreCacheControl: true,
+			},
+			msg: "all fields except purge",
+		},
+		{
+			cache: &v1.Cache{
+				PurgeAllowedCIDRs: []string{"10.0.0.0/8", "127.0.0.1"},
+			},
+			isPlus: true,
+			msg:    "purge in NGINX Plus",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCache(test.cache, field.NewPath("cache"), test.isPlus)
+		if len(allErrs) != 0 {
+			t.Errorf("validateCache() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateCachePolicy_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		cache  *v1.Cache
+		isPlus bool
+		msg    string
+	}{
+		{
+			cache: &v1.Cache{
+				CacheZoneSize: "10 m",
+			},
+			msg: "invalid zone size",
+		},
+		{
+			cache: &v1.Cache{
+				MaxSize: "1t",
+			},
+			msg: "invalid max size",
+		},
+		{
+			cache: &v1.Cache{
+				Inactive: "one hour",
+			},
+			msg: "invalid inactive time",
+		},
+		{
+			cache: &v1.Cache{
+				Key: "${remote_addr}",
+			},
+			msg: "unsupported variable in key",
+		},
+		{
+			cache: &v1.Cache{
+				Key: `${request_uri}"`,
+			},
+			msg: "unescaped quote in key",
+		},
+		{
+			cache: &v1.Cache{
+				AllowedMethods: []string{"PUT"},
+			},
+			msg: "unsupported method",
+		},
+		{
+			cache: &v1.Cache{
+				Valid: []v1.CacheValid{{Codes: []string{"200"}}},
+			},
+			msg: "valid without time",
+		},
+		{
+			cache: &v1.Cache{
+				Valid: []v1.CacheValid{{Time: "10m"}},
+			},
+			msg: "valid without codes",
+		},
+		{
+			cache: &v1.Cache{
+				Valid: []v1.CacheValid{{Codes: []string{"600"}, Time: "10m"}},
+			},
+			msg: "invalid status code",
+		},
+		{
+			cache: &v1.Cache{
+				Bypass: []string{"$remote_addr"},
+			},
+			msg: "unsupported bypass variable",
+		},
+		{
+			cache: &v1.Cache{
+				PurgeAllowedCIDRs: []string{"10.0.0.0/8"},
+			},
+			msg: "purge in NGINX OSS",
+		},
+		{
+			cache: &v1.Cache{
+				PurgeAllowedCIDRs: []string{"10.0.0.0/33"},
+			},
+			isPlus: true,
+			msg:    "invalid purge CIDR",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateCache(test.cache, field.NewPath("cache"), test.isPlus)
+		if len(allErrs) == 0 {
+			t.Errorf("validateCache() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateFaultInjectionPolicy_PassesOnValidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fault *v1.FaultInjection
+		msg   string
+	}{
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 10,
+				Delay:      "2s",
+			},
+			msg: "delay",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 100,
+				Status:     503,
+			},
+			msg: "status",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 50,
+				Delay:      "1m500ms",
+				Condition: &v1.Condition{
+					Header: "X-Chaos",
+					Value:  "on",
+				},
+			},
+			msg: "delay with header condition",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 1,
+				Status:     500,
+				Condition: &v1.Condition{
+					Cookie: "chaos",
+					Value:  "!off",
+				},
+			},
+			msg: "status with negated cookie condition",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateFaultInjection(test.fault, field.NewPath("faultInjection"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateFaultInjection() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateFaultInjectionPolicy_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		fault *v1.FaultInjection
+		msg   string
+	}{
+		{
+			fault: &v1.FaultInjection{
+				Status: 503,
+			},
+			msg: "missing percentage",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 101,
+				Status:     503,
+			},
+			msg: "percentage above 100",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 10,
+			},
+			msg: "neither delay nor status",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 10,
+				Delay:      "2s",
+				Status:     503,
+			},
+			msg: "both delay and status",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 10,
+				Delay:      "two seconds",
+			},
+			msg: "invalid delay",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 10,
+				Delay:      "1d",
+			},
+			msg: "delay in days",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 10,
+				Status:     200,
+			},
+			msg: "status below 400",
+		},
+		{
+			fault: &v1.FaultInjection{
+				Percentage: 10,
+				Status:     503,
+				Condition: &v1.Condition{
+					Value: "on",
+				},
+			},
+			msg: "condition without header or cookie",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateFaultInjection(test.fault, field.NewPath("faultInjection"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateFaultInjection() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateConnectionLimitPolicy_PassesOnValidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		connLimit *v1.ConnectionLimit
+		msg       string
+	}{
+		{
+			connLimit: &v1.ConnectionLimit{
+				Key:         "${binary_remote_addr}",
+				ZoneSize:    "10M",
+				Connections: 10,
+			},
+			msg: "only required fields",
+		},
+		{
+			connLimit: &v1.ConnectionLimit{
+				Key:         "${server_addr}:${server_port}",
+				ZoneSize:    "10M",
+				Connections: 1,
+				DryRun:      createPointerFromBool(true),
+				RejectCode:  createPointerFromInt(429),
+			},
+			msg: "all fields",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateConnectionLimit(test.connLimit, field.NewPath("connectionLimit"), false)
+		if len(allErrs) > 0 {
+			t.Errorf("validateConnectionLimit() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateConnectionLimitPolicy_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		connLimit *v1.ConnectionLimit
+		msg       string
+	}{
+		{
+			connLimit: &v1.ConnectionLimit{
+				ZoneSize:    "10M",
+				Connections: 10,
+			},
+			msg: "missing key",
+		},
+		{
+			connLimit: &v1.ConnectionLimit{
+				Key:         "${request_uri}",
+				ZoneSize:    "10M",
+				Connections: 10,
+			},
+			msg: "key with a variable not available in the stream context",
+		},
+		{
+			connLimit: &v1.ConnectionLimit{
+				Key:         "${binary_remote_addr}",
+				Connections: 10,
+			},
+			msg: "missing zone size",
+		},
+		{
+			connLimit: &v1.ConnectionLimit{
+				Key:      "${binary_remote_addr}",
+				ZoneSize: "10M",
+			},
+			msg: "missing connections",
+		},
+		{
+			connLimit: &v1.ConnectionLimit{
+				Key:         "${binary_remote_addr}",
+				ZoneSize:    "10M",
+				Connections: -1,
+			},
+			msg: "negative connections",
+		},
+		{
+			connLimit: &v1.ConnectionLimit{
+				Key:         "${binary_remote_addr}",
+				ZoneSize:    "10M",
+				Connections: 10,
+				RejectCode:  createPointerFromInt(600),
+			},
+			msg: "invalid reject code",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateConnectionLimit(test.connLimit, field.NewPath("connectionLimit"), false)
+		if len(allErrs) == 0 {
+			t.Errorf("validateConnectionLimit() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateOIDCScope_ErrorsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	invalidInput := []string{
+		"",
+		" ",
+		"openid+scope\x5c",
 		"mycustom\x7fscope",
 		"openid+myscope\x20",
 		"openid+cus\x19tom",
@@ -2424,3 +3406,227 @@ func TestValidateWAF_FailsOnInvalidApLogBundle(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAuthorizationPolicy_PassesOnValidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		authz *v1.Authorization
+		msg   string
+	}{
+		{
+			authz: &v1.Authorization{
+				AllOf: []v1.ClaimRequirement{
+					{Claim: "scope", Values: []string{"read:orders"}},
+				},
+			},
+			msg: "only allOf",
+		},
+		{
+			authz: &v1.Authorization{
+				AnyOf: []v1.ClaimRequirement{
+					{Claim: "groups", Values: []string{"admins", "support"}},
+					{Claim: "realm_access.roles", Values: []string{"orders-admin"}},
+				},
+			},
+			msg: "only anyOf with a nested claim",
+		},
+		{
+			authz: &v1.Authorization{
+				AllOf: []v1.ClaimRequirement{
+					{Claim: "aud", Values: []string{"https://api.example.com"}},
+				},
+				AnyOf: []v1.ClaimRequirement{
+					{Claim: "scope", Values: []string{"orders:write"}},
+				},
+				RejectCode: createPointerFromInt(401),
+			},
+			msg: "all fields",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateAuthorization(test.authz, field.NewPath("authorization"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateAuthorization() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateAuthorizationPolicy_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		authz *v1.Authorization
+		msg   string
+	}{
+		{
+			authz: &v1.Authorization{},
+			msg:   "no requirements",
+		},
+		{
+			authz: &v1.Authorization{
+				AllOf: []v1.ClaimRequirement{
+					{Values: []string{"admins"}},
+				},
+			},
+			msg: "missing claim",
+		},
+		{
+			authz: &v1.Authorization{
+				AnyOf: []v1.ClaimRequirement{
+					{Claim: "realm_access..roles", Values: []string{"admins"}},
+				},
+			},
+			msg: "invalid claim",
+		},
+		{
+			authz: &v1.Authorization{
+				AllOf: []v1.ClaimRequirement{
+					{Claim: "groups"},
+				},
+			},
+			msg: "missing values",
+		},
+		{
+			authz: &v1.Authorization{
+				AllOf: []v1.ClaimRequirement{
+					{Claim: "scope", Values: []string{"read write"}},
+				},
+			},
+			msg: "value with a space",
+		},
+		{
+			authz: &v1.Authorization{
+				AnyOf: []v1.ClaimRequirement{
+					{Claim: "groups", Values: []string{"${arg_group}"}},
+				},
+			},
+			msg: "value with a variable",
+		},
+		{
+			authz: &v1.Authorization{
+				AllOf: []v1.ClaimRequirement{
+					{Claim: "groups", Values: []string{"admins"}},
+				},
+				RejectCode: createPointerFromInt(404),
+			},
+			msg: "invalid reject code",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateAuthorization(test.authz, field.NewPath("authorization"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateAuthorization() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateSignatureValidationPolicy_PassesOnValidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		sv  *v1.SignatureValidation
+		msg string
+	}{
+		{
+			sv: &v1.SignatureValidation{
+				Secret: "webhook-key",
+			},
+			msg: "only secret",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret:    "webhook-key",
+				Format:    "plain",
+				Algorithm: "sha1",
+				Header:    "X-Hub-Signature",
+			},
+			msg: "plain format with sha1",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret:             "webhook-key",
+				Format:             "stripe",
+				TimestampTolerance: createPointerFromInt(600),
+				RejectCode:         createPointerFromInt(403),
+			},
+			msg: "stripe format",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret:             "webhook-key",
+				Format:             "slack",
+				Algorithm:          "sha256",
+				TimestampTolerance: createPointerFromInt(0),
+			},
+			msg: "slack format without timestamp check",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateSignatureValidation(test.sv, field.NewPath("signatureValidation"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateSignatureValidation() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateSignatureValidationPolicy_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		sv  *v1.SignatureValidation
+		msg string
+	}{
+		{
+			sv:  &v1.SignatureValidation{},
+			msg: "missing secret",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret: "webhook_key",
+			},
+			msg: "invalid secret name",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret: "webhook-key",
+				Format: "github",
+			},
+			msg: "invalid format",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret:    "webhook-key",
+				Algorithm: "md5",
+			},
+			msg: "invalid algorithm",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret: "webhook-key",
+				Header: "X-Signature:",
+			},
+			msg: "invalid header",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret:             "webhook-key",
+				TimestampTolerance: createPointerFromInt(-1),
+			},
+			msg: "negative timestamp tolerance",
+		},
+		{
+			sv: &v1.SignatureValidation{
+				Secret:     "webhook-key",
+				RejectCode: createPointerFromInt(400),
+			},
+			msg: "invalid reject code",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateSignatureValidation(test.sv, field.NewPath("signatureValidation"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateSignatureValidation() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package validation
 import (
 	"encoding/hex"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -30,11 +31,11 @@ func NewTransportServerValidator(tlsPassthrough bool, snippetsEnabled bool, isPl
 
 // ValidateTransportServer validates a TransportServer.
 func (tsv *TransportServerValidator) ValidateTransportServer(transportServer *conf_v1.TransportServer) error {
-	allErrs := tsv.validateTransportServerSpec(&transportServer.Spec, field.NewPath("spec"))
+	allErrs := tsv.validateTransportServerSpec(&transportServer.Spec, field.NewPath("spec"), transportServer.Namespace)
 	return allErrs.ToAggregate()
 }
 
-func (tsv *TransportServerValidator) validateTransportServerSpec(spec *conf_v1.TransportServerSpec, fieldPath *field.Path) field.ErrorList {
+func (tsv *TransportServerValidator) validateTransportServerSpec(spec *conf_v1.TransportServerSpec, fieldPath *field.Path, namespace string) field.ErrorList {
 	allErrs := tsv.validateTransportListener(&spec.Listener, fieldPath.Child("listener"))
 
 	isTLSPassthroughListener := isPotentialTLSPassthroughListener(&spec.Listener)
@@ -53,6 +54,12 @@ func (tsv *TransportServerValidator) validateTransportServerSpec(spec *conf_v1.T
 		allErrs = append(allErrs, validateTransportServerAction(spec.Action, fieldPath.Child("action"), upstreamNames)...)
 	}
 
+	allErrs = append(allErrs, validateTransportServerMatches(spec, fieldPath.Child("matches"), upstreamNames)...)
+
+	allErrs = append(allErrs, validateHealthChecksWithMatchesOrSplits(spec, fieldPath.Child("upstreams"))...)
+
+	allErrs = append(allErrs, validateTransportServerUpstreamsTLS(spec, fieldPath.Child("upstreams"))...)
+
 	allErrs = append(allErrs, validateSnippets(spec.ServerSnippets, fieldPath.Child("serverSnippets"), tsv.snippetsEnabled)...)
 
 	allErrs = append(allErrs, validateSnippets(spec.StreamSnippets, fieldPath.Child("streamSnippets"), tsv.snippetsEnabled)...)
@@ -60,6 +67,8 @@ func (tsv *TransportServerValidator) validateTransportServerSpec(spec *conf_v1.T
 	hostSpecified := spec.Host != ""
 	allErrs = append(allErrs, validateTLS(spec.TLS, isTLSPassthroughListener, fieldPath.Child("tls"), hostSpecified)...)
 
+	allErrs = append(allErrs, validatePolicies(spec.Policies, fieldPath.Child("policies"), namespace)...)
+
 	return allErrs
 }
 
@@ -190,6 +199,10 @@ func validateTransportServerUpstreams(upstreams []conf_v1.TransportServerUpstrea
 		allErrs = append(allErrs, validateTSUpstreamHealthChecks(u.HealthCheck, idxPath.Child("healthChecks"))...)
 		allErrs = append(allErrs, validateLoadBalancingMethod(u.LoadBalancingMethod, idxPath.Child("loadBalancingMethod"), isPlus)...)
 		allErrs = append(allErrs, validateBackup(u.Backup, u.BackupPort, u.LoadBalancingMethod, idxPath)...)
+
+		if u.TLS != nil {
+			allErrs = append(allErrs, validateTransportServerUpstreamTLS(u.TLS, idxPath.Child("tls"))...)
+		}
 	}
 
 	return allErrs, upstreamNames
@@ -366,6 +379,9 @@ func validateTransportServerUpstreamParameters(upstreamParameters *conf_v1.Upstr
 	allErrs = append(allErrs, validateTime(upstreamParameters.ConnectTimeout, fieldPath.Child("connectTimeout"))...)
 	allErrs = append(allErrs, validateTime(upstreamParameters.NextUpstreamTimeout, fieldPath.Child("nextUpstreamTimeout"))...)
 	allErrs = append(allErrs, validatePositiveIntOrZero(upstreamParameters.NextUpstreamTries, fieldPath.Child("nextUpstreamTries"))...)
+	if upstreamParameters.ProxyProtocol && protocol == "UDP" {
+		allErrs = append(allErrs, field.Forbidden(fieldPath.Child("proxyProtocol"), "is not allowed for UDP TransportServers"))
+	}
 	return allErrs
 }
 
@@ -384,8 +400,238 @@ func validateUDPUpstreamParameter(parameter *int, fieldPath *field.Path, protoco
 }
 
 func validateTransportServerAction(action *conf_v1.TransportServerAction, fieldPath *field.Path, upstreamNames sets.Set[string]) field.ErrorList {
+	if len(action.Splits) > 0 {
+		if action.Pass != "" {
+			return field.ErrorList{field.Invalid(fieldPath, "", "must specify exactly one of `pass` or `splits`")}
+		}
+		return validateTransportServerSplits(action.Splits, fieldPath.Child("splits"), upstreamNames)
+	}
 	if action.Pass == "" {
-		return field.ErrorList{field.Required(fieldPath, "must specify pass")}
+		return field.ErrorList{field.Required(fieldPath, "must specify pass or splits")}
 	}
 	return validateReferencedUpstream(action.Pass, fieldPath.Child("pass"), upstreamNames)
 }
+
+func validateTransportServerSplits(splits []conf_v1.TransportServerSplit, fieldPath *field.Path, upstreamNames sets.Set[string]) field.ErrorList {
+	if len(splits) < 2 {
+		return field.ErrorList{field.Invalid(fieldPath, "", "must include at least 2 splits")}
+	}
+
+	allErrs := field.ErrorList{}
+	totalWeight := 0
+	for i, s := range splits {
+		idxPath := fieldPath.Index(i)
+
+		for _, msg := range validation.IsInRange(s.Weight, 0, 100) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("weight"), s.Weight, msg))
+		}
+
+		if s.Pass == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("pass"), ""))
+		} else {
+			allErrs = append(allErrs, validateReferencedUpstream(s.Pass, idxPath.Child("pass"), upstreamNames)...)
+		}
+
+		totalWeight += s.Weight
+	}
+
+	if totalWeight != 100 {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "the sum of the weights of all splits must be equal to 100"))
+	}
+
+	return allErrs
+}
+
+// validateHealthChecksWithMatchesOrSplits forbids active health checks when the connections are passed to the upstreams
+// using a variable, which is the case for matches and splits.
+// hasMatchesOrSplits reports whether the upstream of a connection is selected by matches or splits.
+func hasMatchesOrSplits(spec *conf_v1.TransportServerSpec) bool {
+	if len(spec.Matches) > 0 {
+		return true
+	}
+	return spec.Action != nil && len(spec.Action.Splits) > 0
+}
+
+func validateHealthChecksWithMatchesOrSplits(spec *conf_v1.TransportServerSpec, fieldPath *field.Path) field.ErrorList {
+	if !hasMatchesOrSplits(spec) {
+		return nil
+	}
+
+	allErrs := field.ErrorList{}
+	for i, u := range spec.Upstreams {
+		if u.HealthCheck != nil && u.HealthCheck.Enabled {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("healthCheck"), "is not supported with matches or splits"))
+		}
+	}
+	return allErrs
+}
+
+func validateTransportServerUpstreamTLS(tls *conf_v1.TransportServerUpstreamTLS, fieldPath *field.Path) field.ErrorList {
+	allErrs := validateSecretName(tls.TLSSecret, fieldPath.Child("tlsSecret"))
+
+	if tls.VerifyServer && tls.TrustedCertSecret == "" {
+		return append(allErrs, field.Required(fieldPath.Child("trustedCertSecret"), "must be set when verifyServer is 'true'"))
+	}
+	allErrs = append(allErrs, validateSecretName(tls.TrustedCertSecret, fieldPath.Child("trustedCertSecret"))...)
+
+	if tls.VerifyDepth != nil {
+		allErrs = append(allErrs, validatePositiveIntOrZero(*tls.VerifyDepth, fieldPath.Child("verifyDepth"))...)
+	}
+	return append(allErrs, validateSSLName(tls.SSLName, fieldPath.Child("sslName"))...)
+}
+
+// validateTransportServerUpstreamsTLS validates the TLS of the upstreams against the listener and the actions.
+// The TLS of the connections to the upstreams is configured for the whole server, so all upstreams a connection
+// can be passed to by the action, matches or splits must have the same TLS configuration.
+func validateTransportServerUpstreamsTLS(spec *conf_v1.TransportServerSpec, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.Listener.Protocol == "UDP" {
+		for i, u := range spec.Upstreams {
+			if u.TLS != nil && u.TLS.Enable {
+				allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("tls"), "is not supported for UDP TransportServers"))
+			}
+		}
+		return allErrs
+	}
+
+	if !hasMatchesOrSplits(spec) {
+		return nil
+	}
+
+	passes := getTransportServerActionPasses(spec)
+
+	first := -1
+	for i, u := range spec.Upstreams {
+		if !passes.Has(u.Name) {
+			continue
+		}
+		if first == -1 {
+			first = i
+			continue
+		}
+		if !reflect.DeepEqual(normalizeTransportServerUpstreamTLS(u.TLS), normalizeTransportServerUpstreamTLS(spec.Upstreams[first].TLS)) {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Index(i).Child("tls"),
+				fmt.Sprintf("must be the same as the tls of the upstream %s when matches or splits are used", spec.Upstreams[first].Name)))
+		}
+	}
+	return allErrs
+}
+
+// normalizeTransportServerUpstreamTLS returns nil for a disabled TLS, whose other fields are ignored.
+func normalizeTransportServerUpstreamTLS(tls *conf_v1.TransportServerUpstreamTLS) *conf_v1.TransportServerUpstreamTLS {
+	if tls == nil || !tls.Enable {
+		return nil
+	}
+	return tls
+}
+
+// getTransportServerActionPasses returns the names of the upstreams the action and the actions of the matches pass to.
+func getTransportServerActionPasses(spec *conf_v1.TransportServerSpec) sets.Set[string] {
+	passes := sets.New[string]()
+
+	actions := []*conf_v1.TransportServerAction{spec.Action}
+	for _, m := range spec.Matches {
+		actions = append(actions, m.Action)
+	}
+
+	for _, a := range actions {
+		if a == nil {
+			continue
+		}
+		if a.Pass != "" {
+			passes.Insert(a.Pass)
+		}
+		for _, s := range a.Splits {
+			passes.Insert(s.Pass)
+		}
+	}
+	return passes
+}
+
+func validateTransportServerMatches(spec *conf_v1.TransportServerSpec, fieldPath *field.Path, upstreamNames sets.Set[string]) field.ErrorList {
+	if len(spec.Matches) == 0 {
+		return nil
+	}
+
+	allErrs := field.ErrorList{}
+
+	isUDP := spec.Listener.Protocol == "UDP"
+	isTLSTermination := spec.TLS != nil
+
+	for i, m := range spec.Matches {
+		idxPath := fieldPath.Index(i)
+
+		if len(m.Conditions) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("conditions"), "must specify at least one condition"))
+		}
+		for j, c := range m.Conditions {
+			allErrs = append(allErrs, validateTransportServerCondition(c, idxPath.Child("conditions").Index(j), isUDP, isTLSTermination)...)
+		}
+
+		if m.Action == nil {
+			allErrs = append(allErrs, field.Required(idxPath.Child("action"), "must specify action"))
+		} else {
+			allErrs = append(allErrs, validateTransportServerAction(m.Action, idxPath.Child("action"), upstreamNames)...)
+		}
+	}
+
+	return allErrs
+}
+
+func validateTransportServerCondition(condition conf_v1.TransportServerCondition, fieldPath *field.Path, isUDP bool, isTLSTermination bool) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	fieldCount := 0
+
+	if condition.ServerName != "" {
+		allErrs = append(allErrs, validatePrereadCondition(fieldPath.Child("serverName"), isUDP, isTLSTermination)...)
+		allErrs = append(allErrs, validateHost(condition.ServerName, fieldPath.Child("serverName"))...)
+		fieldCount++
+	}
+
+	if condition.ALPN != "" {
+		allErrs = append(allErrs, validatePrereadCondition(fieldPath.Child("alpn"), isUDP, isTLSTermination)...)
+		allErrs = append(allErrs, validateALPNProtocol(condition.ALPN, fieldPath.Child("alpn"))...)
+		fieldCount++
+	}
+
+	if len(condition.SourceCIDRs) > 0 {
+		for i, ipOrCIDR := range condition.SourceCIDRs {
+			allErrs = append(allErrs, validateIPorCIDR(ipOrCIDR, fieldPath.Child("sourceCIDRs").Index(i))...)
+		}
+		fieldCount++
+	}
+
+	if fieldCount != 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `serverName`, `alpn` or `sourceCIDRs`"))
+	}
+
+	return allErrs
+}
+
+// validatePrereadCondition validates a condition on the ClientHello of a connection. The ClientHello is not
+// available for UDP and is consumed by the TLS handshake when TLS is terminated.
+func validatePrereadCondition(fieldPath *field.Path, isUDP bool, isTLSTermination bool) field.ErrorList {
+	if isUDP {
+		return field.ErrorList{field.Forbidden(fieldPath, "is not allowed for UDP TransportServers")}
+	}
+	if isTLSTermination {
+		return field.ErrorList{field.Forbidden(fieldPath, "is not allowed with TLS termination")}
+	}
+	return nil
+}
+
+const (
+	alpnProtocolFmt    = `[A-Za-z0-9._/\-]+`
+	alpnProtocolErrMsg = "a valid ALPN protocol must consist of alphanumeric characters, '.', '_', '/' or '-'"
+)
+
+var alpnProtocolRegexp = regexp.MustCompile("^" + alpnProtocolFmt + "$")
+
+func validateALPNProtocol(protocol string, fieldPath *field.Path) field.ErrorList {
+	if !alpnProtocolRegexp.MatchString(protocol) {
+		return field.ErrorList{field.Invalid(fieldPath, protocol, validation.RegexError(alpnProtocolErrMsg, alpnProtocolFmt, "h2", "mqtt"))}
+	}
+	return nil
+}
@@ -857,6 +857,23 @@ func TestValidateUpstreamParameters(t *testing.T) {
 	}
 }
 
+func TestValidateUpstreamParameters_ProxyProtocol(t *testing.T) {
+	t.Parallel()
+	parameters := &conf_v1.UpstreamParameters{
+		ProxyProtocol: true,
+	}
+
+	allErrs := validateTransportServerUpstreamParameters(parameters, field.NewPath("upstreamParameters"), "TCP")
+	if len(allErrs) > 0 {
+		t.Errorf("validateTransportServerUpstreamParameters() returned errors %v for valid input", allErrs)
+	}
+
+	allErrs = validateTransportServerUpstreamParameters(parameters, field.NewPath("upstreamParameters"), "UDP")
+	if len(allErrs) == 0 {
+		t.Error("validateTransportServerUpstreamParameters() returned no errors for proxyProtocol in a UDP TransportServer")
+	}
+}
+
 func TestValidateSessionParameters(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1001,6 +1018,16 @@ func TestValidateTransportServerAction_FailsOnInvalidInput(t *testing.T) {
 			},
 			msg: "pass references a non-existing upstream",
 		},
+		{
+			action: &conf_v1.TransportServerAction{
+				Pass: "test",
+				Splits: []conf_v1.TransportServerSplit{
+					{Weight: 50, Pass: "test"},
+					{Weight: 50, Pass: "test"},
+				},
+			},
+			msg: "both pass and splits",
+		},
 	}
 
 	for _, test := range tests {
@@ -1011,6 +1038,520 @@ func TestValidateTransportServerAction_FailsOnInvalidInput(t *testing.T) {
 	}
 }
 
+func TestValidateTransportServerSplits(t *testing.T) {
+	t.Parallel()
+	upstreamNames := sets.New("test-1", "test-2", "test-3")
+
+	tests := [][]conf_v1.TransportServerSplit{
+		{
+			{Weight: 90, Pass: "test-1"},
+			{Weight: 10, Pass: "test-2"},
+		},
+		{
+			{Weight: 100, Pass: "test-1"},
+			{Weight: 0, Pass: "test-2"},
+		},
+		{
+			{Weight: 33, Pass: "test-1"},
+			{Weight: 33, Pass: "test-2"},
+			{Weight: 34, Pass: "test-3"},
+		},
+	}
+
+	for _, splits := range tests {
+		allErrs := validateTransportServerAction(&conf_v1.TransportServerAction{Splits: splits}, field.NewPath("action"), upstreamNames)
+		if len(allErrs) > 0 {
+			t.Errorf("validateTransportServerAction() returned errors %v for valid input %v", allErrs, splits)
+		}
+	}
+}
+
+func TestValidateTransportServerSplits_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	upstreamNames := sets.New("test-1", "test-2")
+
+	tests := []struct {
+		splits []conf_v1.TransportServerSplit
+		msg    string
+	}{
+		{
+			splits: []conf_v1.TransportServerSplit{
+				{Weight: 100, Pass: "test-1"},
+			},
+			msg: "only one split",
+		},
+		{
+			splits: []conf_v1.TransportServerSplit{
+				{Weight: 50, Pass: "test-1"},
+				{Weight: 40, Pass: "test-2"},
+			},
+			msg: "the sum of the weights is not 100",
+		},
+		{
+			splits: []conf_v1.TransportServerSplit{
+				{Weight: 101, Pass: "test-1"},
+				{Weight: -1, Pass: "test-2"},
+			},
+			msg: "weights out of range",
+		},
+		{
+			splits: []conf_v1.TransportServerSplit{
+				{Weight: 50},
+				{Weight: 50, Pass: "test-2"},
+			},
+			msg: "missing pass",
+		},
+		{
+			splits: []conf_v1.TransportServerSplit{
+				{Weight: 50, Pass: "test-1"},
+				{Weight: 50, Pass: "non-existing"},
+			},
+			msg: "pass references a non-existing upstream",
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateTransportServerSplits(test.splits, field.NewPath("splits"), upstreamNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateTransportServerSplits() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
+func TestValidateHealthChecksWithMatchesOrSplits(t *testing.T) {
+	t.Parallel()
+
+	splits := []conf_v1.TransportServerSplit{
+		{Weight: 50, Pass: "upstream1"},
+		{Weight: 50, Pass: "upstream1"},
+	}
+
+	tests := []struct {
+		spec      func(spec *conf_v1.TransportServerSpec)
+		wantError bool
+		msg       string
+	}{
+		{
+			spec:      func(_ *conf_v1.TransportServerSpec) {},
+			wantError: false,
+			msg:       "health check with pass",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Action = &conf_v1.TransportServerAction{Splits: splits}
+			},
+			wantError: true,
+			msg:       "health check with splits",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Matches = []conf_v1.TransportServerConnectionMatch{
+					{
+						Conditions: []conf_v1.TransportServerCondition{{SourceCIDRs: []string{"10.0.0.0/8"}}},
+						Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+					},
+				}
+			},
+			wantError: true,
+			msg:       "health check with matches",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Matches = []conf_v1.TransportServerConnectionMatch{
+					{
+						Conditions: []conf_v1.TransportServerCondition{{SourceCIDRs: []string{"10.0.0.0/8"}}},
+						Action:     &conf_v1.TransportServerAction{Splits: splits},
+					},
+				}
+			},
+			wantError: true,
+			msg:       "health check with splits in a match",
+		},
+	}
+
+	for _, test := range tests {
+		ts := makeTransportServer()
+		ts.Spec.Upstreams[0].HealthCheck = &conf_v1.TransportServerHealthCheck{Enabled: true}
+		test.spec(&ts.Spec)
+
+		allErrs := validateHealthChecksWithMatchesOrSplits(&ts.Spec, field.NewPath("spec").Child("upstreams"))
+		if test.wantError != (len(allErrs) > 0) {
+			t.Errorf("validateHealthChecksWithMatchesOrSplits() returned errors %v for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateTransportServerUpstreamTLS_PassesOnValidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls *conf_v1.TransportServerUpstreamTLS
+		msg string
+	}{
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable: true,
+			},
+			msg: "tls enabled",
+		},
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable:    true,
+				TLSSecret: "mtls-secret",
+			},
+			msg: "tls secret",
+		},
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable:            true,
+				TrustedCertSecret: "ca-secret",
+				VerifyServer:      true,
+				VerifyDepth:       createPointerFromInt(2),
+				ServerName:        true,
+				SSLName:           "postgres.default.svc",
+			},
+			msg: "verify server",
+		},
+	}
+	for _, test := range tests {
+		allErrs := validateTransportServerUpstreamTLS(test.tls, field.NewPath("tls"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateTransportServerUpstreamTLS() returned errors %v for valid input for the case of %v", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateTransportServerUpstreamTLS_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tls *conf_v1.TransportServerUpstreamTLS
+		msg string
+	}{
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable:       true,
+				VerifyServer: true,
+			},
+			msg: "verify server without trusted cert secret",
+		},
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable:    true,
+				TLSSecret: "-foo-",
+			},
+			msg: "invalid tls secret name",
+		},
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable:            true,
+				TrustedCertSecret: "-foo-",
+			},
+			msg: "invalid trusted cert secret name",
+		},
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable:      true,
+				VerifyDepth: createPointerFromInt(-1),
+			},
+			msg: "negative verify depth",
+		},
+		{
+			tls: &conf_v1.TransportServerUpstreamTLS{
+				Enable:  true,
+				SSLName: "foo.com;",
+			},
+			msg: "invalid ssl name",
+		},
+	}
+	for _, test := range tests {
+		allErrs := validateTransportServerUpstreamTLS(test.tls, field.NewPath("tls"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateTransportServerUpstreamTLS() returned no errors for invalid input for the case of %v", test.msg)
+		}
+	}
+}
+
+func TestValidateTransportServerUpstreamsTLS(t *testing.T) {
+	t.Parallel()
+
+	tls := &conf_v1.TransportServerUpstreamTLS{Enable: true, TLSSecret: "mtls-secret"}
+	splits := []conf_v1.TransportServerSplit{
+		{Weight: 90, Pass: "upstream1"},
+		{Weight: 10, Pass: "upstream2"},
+	}
+
+	tests := []struct {
+		spec      func(spec *conf_v1.TransportServerSpec)
+		wantError bool
+		msg       string
+	}{
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[0].TLS = tls
+			},
+			wantError: false,
+			msg:       "tls with pass",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[0].TLS = tls
+				spec.Upstreams[1].TLS = tls
+				spec.Action = &conf_v1.TransportServerAction{Splits: splits}
+			},
+			wantError: false,
+			msg:       "same tls with splits",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[0].TLS = tls
+				spec.Action = &conf_v1.TransportServerAction{Splits: splits}
+			},
+			wantError: true,
+			msg:       "different tls with splits",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[1].TLS = &conf_v1.TransportServerUpstreamTLS{Enable: false}
+				spec.Action = &conf_v1.TransportServerAction{Splits: splits}
+			},
+			wantError: false,
+			msg:       "no tls and disabled tls with splits",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[1].TLS = tls
+				spec.Matches = []conf_v1.TransportServerConnectionMatch{
+					{
+						Conditions: []conf_v1.TransportServerCondition{{SourceCIDRs: []string{"10.0.0.0/8"}}},
+						Action:     &conf_v1.TransportServerAction{Pass: "upstream2"},
+					},
+				}
+			},
+			wantError: true,
+			msg:       "different tls with matches",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[0].TLS = tls
+				spec.Upstreams[1].TLS = tls
+				spec.Upstreams = append(spec.Upstreams, conf_v1.TransportServerUpstream{Name: "upstream3", Service: "test-3", Port: 5503})
+				spec.Action = &conf_v1.TransportServerAction{Splits: splits}
+			},
+			wantError: false,
+			msg:       "different tls of an upstream that is not passed to with splits",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[0].TLS = tls
+				spec.Upstreams = append(spec.Upstreams, conf_v1.TransportServerUpstream{Name: "upstream3", Service: "test-3", Port: 5503, TLS: tls})
+				spec.Matches = []conf_v1.TransportServerConnectionMatch{
+					{
+						Conditions: []conf_v1.TransportServerCondition{{SourceCIDRs: []string{"10.0.0.0/8"}}},
+						Action: &conf_v1.TransportServerAction{
+							Splits: []conf_v1.TransportServerSplit{
+								{Weight: 50, Pass: "upstream1"},
+								{Weight: 50, Pass: "upstream3"},
+							},
+						},
+					},
+				}
+			},
+			wantError: false,
+			msg:       "different tls of an upstream that is not passed to with matches",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Upstreams[0].TLS = tls
+				spec.Upstreams = append(spec.Upstreams, conf_v1.TransportServerUpstream{Name: "upstream3", Service: "test-3", Port: 5503})
+				spec.Matches = []conf_v1.TransportServerConnectionMatch{
+					{
+						Conditions: []conf_v1.TransportServerCondition{{SourceCIDRs: []string{"10.0.0.0/8"}}},
+						Action: &conf_v1.TransportServerAction{
+							Splits: []conf_v1.TransportServerSplit{
+								{Weight: 50, Pass: "upstream1"},
+								{Weight: 50, Pass: "upstream3"},
+							},
+						},
+					},
+				}
+			},
+			wantError: true,
+			msg:       "different tls of an upstream of the splits of a match",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Listener.Protocol = "UDP"
+				spec.Upstreams[0].TLS = tls
+			},
+			wantError: true,
+			msg:       "tls with UDP",
+		},
+		{
+			spec: func(spec *conf_v1.TransportServerSpec) {
+				spec.Listener.Protocol = "UDP"
+				spec.Upstreams[0].TLS = &conf_v1.TransportServerUpstreamTLS{Enable: false}
+			},
+			wantError: false,
+			msg:       "disabled tls with UDP",
+		},
+	}
+
+	for _, test := range tests {
+		ts := makeTransportServer()
+		ts.Spec.Upstreams = append(ts.Spec.Upstreams, conf_v1.TransportServerUpstream{Name: "upstream2", Service: "test-2", Port: 5502})
+		test.spec(&ts.Spec)
+
+		allErrs := validateTransportServerUpstreamsTLS(&ts.Spec, field.NewPath("spec").Child("upstreams"))
+		if test.wantError != (len(allErrs) > 0) {
+			t.Errorf("validateTransportServerUpstreamsTLS() returned errors %v for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateTransportServerMatches(t *testing.T) {
+	t.Parallel()
+
+	ts := makeTransportServer()
+	ts.Spec.Upstreams = append(ts.Spec.Upstreams,
+		conf_v1.TransportServerUpstream{Name: "postgres", Service: "postgres", Port: 5432},
+		conf_v1.TransportServerUpstream{Name: "mqtt", Service: "mqtt", Port: 8883},
+	)
+	ts.Spec.Matches = []conf_v1.TransportServerConnectionMatch{
+		{
+			Conditions: []conf_v1.TransportServerCondition{
+				{ServerName: "db.example.com"},
+				{ALPN: "postgresql"},
+			},
+			Action: &conf_v1.TransportServerAction{Pass: "postgres"},
+		},
+		{
+			Conditions: []conf_v1.TransportServerCondition{
+				{ServerName: "*.mqtt.example.com"},
+				{SourceCIDRs: []string{"10.0.0.0/8", "192.168.1.1"}},
+			},
+			Action: &conf_v1.TransportServerAction{Pass: "mqtt"},
+		},
+	}
+
+	tsv := createTransportServerValidator()
+
+	err := tsv.ValidateTransportServer(&ts)
+	if err != nil {
+		t.Errorf("ValidateTransportServer() returned error %v for valid input", err)
+	}
+}
+
+func TestValidateTransportServerMatches_FailsOnInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		matches  []conf_v1.TransportServerConnectionMatch
+		protocol string
+		tls      *conf_v1.TransportServerTLS
+		msg      string
+	}{
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{Action: &conf_v1.TransportServerAction{Pass: "upstream1"}},
+			},
+			msg: "no conditions",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{Conditions: []conf_v1.TransportServerCondition{{ServerName: "example.com"}}},
+			},
+			msg: "no action",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{ServerName: "example.com"}},
+					Action:     &conf_v1.TransportServerAction{Pass: "non-existing"},
+				},
+			},
+			msg: "action references a non-existing upstream",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{}},
+					Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+				},
+			},
+			msg: "empty condition",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{ServerName: "example.com", ALPN: "h2"}},
+					Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+				},
+			},
+			msg: "condition with multiple fields",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{ServerName: "example_com"}},
+					Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+				},
+			},
+			msg: "invalid server name",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{ALPN: "h2,http/1.1"}},
+					Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+				},
+			},
+			msg: "invalid alpn",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{SourceCIDRs: []string{"10.0.0.0/33"}}},
+					Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+				},
+			},
+			msg: "invalid source CIDR",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{ServerName: "example.com"}},
+					Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+				},
+			},
+			protocol: "UDP",
+			msg:      "server name for UDP",
+		},
+		{
+			matches: []conf_v1.TransportServerConnectionMatch{
+				{
+					Conditions: []conf_v1.TransportServerCondition{{ALPN: "h2"}},
+					Action:     &conf_v1.TransportServerAction{Pass: "upstream1"},
+				},
+			},
+			tls: &conf_v1.TransportServerTLS{Secret: "my-secret"},
+			msg: "alpn with TLS termination",
+		},
+	}
+
+	for _, test := range tests {
+		ts := makeTransportServer()
+		ts.Spec.Matches = test.matches
+		ts.Spec.TLS = test.tls
+		if test.protocol != "" {
+			ts.Spec.Listener.Protocol = test.protocol
+		}
+
+		allErrs := validateTransportServerMatches(&ts.Spec, field.NewPath("spec").Child("matches"), sets.New("upstream1"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateTransportServerMatches() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateMatchSend(t *testing.T) {
 	t.Parallel()
 	validInput := []string{
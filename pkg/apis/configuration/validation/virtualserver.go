@@ -2,7 +2,9 @@ package validation
 
 import (
 	"fmt"
+	"net"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -161,6 +163,66 @@ func (vsv *VirtualServerValidator) validateTLS(tls *v1.TLS, fieldPath *field.Pat
 	allErrs := validateSecretName(tls.Secret, fieldPath.Child("secret"))
 	allErrs = append(allErrs, validateTLSRedirect(tls.Redirect, fieldPath.Child("redirect"))...)
 	allErrs = append(allErrs, validateTLSCmFields(tls.CertManager, vsv.isCertManagerEnabled, tls.Secret, fieldPath.Child("cert-manager"))...)
+	allErrs = append(allErrs, validateTLSOCSP(tls.OCSP, fieldPath.Child("ocsp"))...)
+	return allErrs
+}
+
+func validateTLSOCSP(ocsp *v1.TLSOCSP, fieldPath *field.Path) field.ErrorList {
+	if ocsp == nil {
+		return nil
+	}
+
+	allErrs := field.ErrorList{}
+	if ocsp.StaplingResponder != "" {
+		if !ocsp.Stapling {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("staplingResponder"), "requires stapling to be enabled"))
+		}
+		allErrs = append(allErrs, validateOCSPResponder(ocsp.StaplingResponder, fieldPath.Child("staplingResponder"))...)
+	}
+	if ocsp.Resolver != nil {
+		allErrs = append(allErrs, validateOCSPResolver(ocsp.Resolver, fieldPath.Child("resolver"))...)
+	}
+	return allErrs
+}
+
+func validateOCSPResolver(resolver *v1.OCSPResolver, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(resolver.Addresses) == 0 {
+		allErrs = append(allErrs, field.Required(fieldPath.Child("addresses"), ""))
+	}
+	for i, address := range resolver.Addresses {
+		allErrs = append(allErrs, validateResolverAddress(address, fieldPath.Child("addresses").Index(i))...)
+	}
+	allErrs = append(allErrs, validateTime(resolver.Valid, fieldPath.Child("valid"))...)
+	return append(allErrs, validateTime(resolver.Timeout, fieldPath.Child("timeout"))...)
+}
+
+// validateResolverAddress validates the address of a DNS server in the host[:port] format.
+// IPv6 addresses must be enclosed in square brackets.
+func validateResolverAddress(address string, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	host := address
+	if h, port, err := net.SplitHostPort(address); err == nil {
+		host = h
+		allErrs = append(allErrs, validatePortNumber(port, fieldPath)...)
+	} else if strings.HasPrefix(address, "[") && strings.HasSuffix(address, "]") {
+		host = strings.Trim(address, "[]")
+		if ip := net.ParseIP(host); ip == nil || ip.To4() != nil {
+			return append(allErrs, field.Invalid(fieldPath, address, "must be a valid IPv6 address"))
+		}
+		return allErrs
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() == nil && host == address {
+			allErrs = append(allErrs, field.Invalid(fieldPath, address, "IPv6 addresses must be enclosed in square brackets"))
+		}
+		return allErrs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(host) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, address, msg))
+	}
 	return allErrs
 }
 
@@ -589,7 +651,18 @@ func (vsv *VirtualServerValidator) validateUpstreams(upstreams []v1.Upstream, fi
 			allErrs = append(allErrs, validateLabels(u.Subselector, idxPath.Child("subselector"))...)
 		}
 
-		allErrs = append(allErrs, validateServiceName(u.Service, idxPath.Child("service"))...)
+		if len(u.Servers) > 0 {
+			allErrs = append(allErrs, validateStaticUpstream(u, idxPath)...)
+		} else {
+			allErrs = append(allErrs, validateServiceName(u.Service, idxPath.Child("service"))...)
+			for _, msg := range validation.IsValidPortNum(int(u.Port)) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), u.Port, msg))
+			}
+			if u.Resolve {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("resolve"), "requires `servers`"))
+			}
+		}
+
 		allErrs = append(allErrs, validateTime(u.ProxyConnectTimeout, idxPath.Child("connect-timeout"))...)
 		allErrs = append(allErrs, validateTime(u.ProxyReadTimeout, idxPath.Child("read-timeout"))...)
 		allErrs = append(allErrs, validateTime(u.ProxySendTimeout, idxPath.Child("send-timeout"))...)
@@ -609,11 +682,6 @@ func (vsv *VirtualServerValidator) validateUpstreams(upstreams []v1.Upstream, fi
 		allErrs = append(allErrs, validateQueue(u.Queue, idxPath.Child("queue"))...)
 		allErrs = append(allErrs, validateSessionCookie(u.SessionCookie, idxPath.Child("sessionCookie"))...)
 		allErrs = append(allErrs, validateUpstreamType(u.Type, idxPath.Child("type"))...)
-
-		for _, msg := range validation.IsValidPortNum(int(u.Port)) {
-			allErrs = append(allErrs, field.Invalid(idxPath.Child("port"), u.Port, msg))
-		}
-
 		allErrs = append(allErrs, validateBackup(u.Backup, u.BackupPort, u.LBMethod, idxPath)...)
 
 		allErrs = append(allErrs, rejectPlusResourcesInOSS(u, idxPath, vsv.isPlus)...)
@@ -621,6 +689,55 @@ func (vsv *VirtualServerValidator) validateUpstreams(upstreams []v1.Upstream, fi
 	return allErrs, upstreamNames
 }
 
+// validateStaticUpstream validates an upstream with static servers, which can't reference a service.
+func validateStaticUpstream(u v1.Upstream, idxPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if u.Service != "" {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("service"), "can't be used with `servers`"))
+	}
+	if u.Port != 0 {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("port"), "can't be used with `servers`"))
+	}
+	if u.Subselector != nil {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("subselector"), "can't be used with `servers`"))
+	}
+	if u.UseClusterIP {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("use-cluster-ip"), "can't be used with `servers`"))
+	}
+
+	servers := sets.Set[string]{}
+	for i, s := range u.Servers {
+		serverPath := idxPath.Child("servers").Index(i)
+		if servers.Has(s) {
+			allErrs = append(allErrs, field.Duplicate(serverPath, s))
+			continue
+		}
+		servers.Insert(s)
+		allErrs = append(allErrs, validateUpstreamServer(s, serverPath)...)
+	}
+
+	return allErrs
+}
+
+// validateUpstreamServer validates a static upstream server in the host:port format.
+func validateUpstreamServer(server string, fieldPath *field.Path) field.ErrorList {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fieldPath, server, "must be in the format host:port")}
+	}
+
+	allErrs := validatePortNumber(port, fieldPath)
+	if net.ParseIP(host) != nil {
+		return allErrs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(host) {
+		allErrs = append(allErrs, field.Invalid(fieldPath, server, msg))
+	}
+
+	return allErrs
+}
+
 // validateBackup validates backup service name and port semantics and business logic.
 //
 // Backup can't be used with load balancing methods: 'hash', 'hash_ip' and 'random'.
@@ -717,45 +834,127 @@ func (vsv *VirtualServerValidator) validateVirtualServerRoutes(routes []v1.Route
 	allErrs := field.ErrorList{}
 
 	allPaths := sets.Set[string]{}
+	var validRoutes []v1.Route
 
 	for i, r := range routes {
 		idxPath := fieldPath.Index(i)
 
 		isRouteFieldForbidden := false
 		routeErrs := vsv.validateRoute(r, idxPath, upstreamNames, isRouteFieldForbidden, namespace)
+		path := configs.GenerateRoutePath(r)
 		if len(routeErrs) > 0 {
 			allErrs = append(allErrs, routeErrs...)
-		} else if allPaths.Has(r.Path) {
+		} else if allPaths.Has(path) {
 			allErrs = append(allErrs, field.Duplicate(idxPath.Child("path"), r.Path))
 		} else {
-			allPaths.Insert(r.Path)
+			allErrs = append(allErrs, validateRouteConflicts(r, validRoutes, idxPath.Child("path"))...)
+			allPaths.Insert(path)
+			validRoutes = append(validRoutes, r)
 		}
 	}
 
 	return allErrs
 }
 
+// validateRouteConflicts validates that the route doesn't shadow and isn't shadowed by any of the other routes.
+// To keep the existing configurations working, only pairs of routes where at least one route specifies the path type are validated.
+func validateRouteConflicts(route v1.Route, otherRoutes []v1.Route, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, other := range otherRoutes {
+		if route.PathType == "" && other.PathType == "" {
+			continue
+		}
+		if msg := findRouteConflict(route, other); msg != "" {
+			allErrs = append(allErrs, field.Invalid(fieldPath, route.Path, msg))
+		}
+	}
+
+	return allErrs
+}
+
+// findRouteConflict returns a message describing the conflict between two routes or an empty string if there is no conflict.
+// NGINX checks regular expression locations before prefix locations, so a regular expression that matches the path of
+// a prefix route silently takes over its requests. Exact locations are checked first, so they can't be shadowed.
+func findRouteConflict(route v1.Route, other v1.Route) string {
+	path := configs.GenerateRoutePath(route)
+	otherPath := configs.GenerateRoutePath(other)
+
+	switch {
+	case isRegexPath(path) && isRegexPath(otherPath):
+		if trimRegexModifier(path) == trimRegexModifier(otherPath) {
+			return fmt.Sprintf("conflicts with the route with path '%s' that uses the same regular expression", other.Path)
+		}
+	case isRegexPath(path) && isPrefixPath(otherPath):
+		if regexPathMatches(path, otherPath) {
+			return fmt.Sprintf("shadows the route with path '%s'", other.Path)
+		}
+	case isPrefixPath(path) && isRegexPath(otherPath):
+		if regexPathMatches(otherPath, path) {
+			return fmt.Sprintf("is shadowed by the route with path '%s'", other.Path)
+		}
+	}
+
+	return ""
+}
+
+func isRegexPath(path string) bool {
+	return strings.HasPrefix(path, "~")
+}
+
+func isPrefixPath(path string) bool {
+	return strings.HasPrefix(path, "/")
+}
+
+func trimRegexModifier(path string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(path, "~"), "*"), " ")
+}
+
+// regexPathMatches reports whether the regular expression of the location path matches the prefix path.
+func regexPathMatches(regexPath string, prefixPath string) bool {
+	var opts regexp2.RegexOptions
+	if strings.HasPrefix(regexPath, "~*") {
+		opts = regexp2.IgnoreCase
+	}
+
+	re, err := regexp2.Compile(trimRegexModifier(regexPath), opts)
+	if err != nil {
+		return false
+	}
+
+	matched, err := re.MatchString(prefixPath)
+	return err == nil && matched
+}
+
 func (vsv *VirtualServerValidator) validateRoute(route v1.Route, fieldPath *field.Path, upstreamNames sets.Set[string], isRouteFieldForbidden bool, namespace string) field.ErrorList {
-	allErrs := validateRoutePath(route.Path, fieldPath.Child("path"))
+	allErrs := validateRoutePathWithType(route, fieldPath)
 	allErrs = append(allErrs, validatePolicies(route.Policies, fieldPath.Child("policies"), namespace)...)
 
+	path := configs.GenerateRoutePath(route)
 	fieldCount := 0
 
 	if route.Action != nil {
-		allErrs = append(allErrs, vsv.validateAction(route.Action, fieldPath.Child("action"), upstreamNames, route.Path, false)...)
+		allErrs = append(allErrs, vsv.validateAction(route.Action, fieldPath.Child("action"), upstreamNames, path, false)...)
 		fieldCount++
 	}
 
 	if len(route.Splits) > 0 {
-		allErrs = append(allErrs, vsv.validateSplits(route.Splits, fieldPath.Child("splits"), upstreamNames, route.Path)...)
+		allErrs = append(allErrs, vsv.validateSplits(route.Splits, fieldPath.Child("splits"), upstreamNames, path)...)
 		fieldCount++
 	}
 
 	// Matches are optional. that's why we don't do fieldCount++
 	if len(route.Matches) > 0 {
 		for i, m := range route.Matches {
-			allErrs = append(allErrs, vsv.validateMatch(m, fieldPath.Child("matches").Index(i), upstreamNames, route.Path)...)
+			allErrs = append(allErrs, vsv.validateMatch(m, fieldPath.Child("matches").Index(i), upstreamNames, path)...)
+		}
+	}
+
+	if route.SplitKey != nil {
+		if !routeHasSplits(route) {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("splitKey"), "requires `splits` in the route or in one of its matches"))
 		}
+		allErrs = append(allErrs, vsv.validateSplitKey(route.SplitKey, fieldPath.Child("splitKey"))...)
 	}
 
 	for i, e := range route.ErrorPages {
@@ -1059,6 +1258,13 @@ func (vsv *VirtualServerValidator) validateActionProxy(p *v1.ActionProxy, fieldP
 	allErrs := validateReferencedUpstream(p.Upstream, fieldPath.Child("upstream"), upstreamNames)
 	allErrs = append(allErrs, vsv.validateActionProxyRequestHeaders(p.RequestHeaders, fieldPath.Child("requestHeaders"))...)
 	allErrs = append(allErrs, vsv.validateActionProxyResponseHeaders(p.ResponseHeaders, fieldPath.Child("responseHeaders"))...)
+	allErrs = append(allErrs, validateActionProxyMirror(p.Mirror, fieldPath.Child("mirror"), upstreamNames)...)
+	allErrs = append(allErrs, validateTime(p.ConnectTimeout, fieldPath.Child("connectTimeout"))...)
+	allErrs = append(allErrs, validateTime(p.ReadTimeout, fieldPath.Child("readTimeout"))...)
+	allErrs = append(allErrs, validateTime(p.SendTimeout, fieldPath.Child("sendTimeout"))...)
+	allErrs = append(allErrs, validateBuffer(p.Buffers, fieldPath.Child("buffers"))...)
+	allErrs = append(allErrs, validateSize(p.BufferSize, fieldPath.Child("bufferSize"))...)
+	allErrs = append(allErrs, validateOffset(p.ClientMaxBodySize, fieldPath.Child("clientMaxBodySize"))...)
 
 	if strings.HasPrefix(path, "~") || internal {
 		allErrs = append(allErrs, validateActionProxyRewritePathForRegexp(p.RewritePath, fieldPath.Child("rewritePath"))...)
@@ -1069,6 +1275,22 @@ func (vsv *VirtualServerValidator) validateActionProxy(p *v1.ActionProxy, fieldP
 	return allErrs
 }
 
+func validateActionProxyMirror(m *v1.ProxyMirror, fieldPath *field.Path, upstreamNames sets.Set[string]) field.ErrorList {
+	if m == nil {
+		return nil
+	}
+
+	allErrs := validateReferencedUpstream(m.Upstream, fieldPath.Child("upstream"), upstreamNames)
+
+	if m.Percentage != nil {
+		for _, msg := range validation.IsInRange(*m.Percentage, 1, 100) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("percentage"), *m.Percentage, msg))
+		}
+	}
+
+	return allErrs
+}
+
 func validateStringNoVariables(s string, fieldPath *field.Path) field.ErrorList {
 	for i, char := range s {
 		charLen := len(string(char))
@@ -1263,6 +1485,43 @@ func (vsv *VirtualServerValidator) validateSplits(splits []v1.Split, fieldPath *
 	return allErrs
 }
 
+var validPathTypes = []string{v1.PathTypeExact, v1.PathTypePrefix, v1.PathTypeRegularExpression}
+
+// validateRoutePathWithType validates the path of a route according to its path type.
+// When the path type is set, the path must not include the modifier of the NGINX location.
+func validateRoutePathWithType(route v1.Route, fieldPath *field.Path) field.ErrorList {
+	pathPath := fieldPath.Child("path")
+
+	if route.PathType == "" {
+		allErrs := validateRoutePath(route.Path, pathPath)
+		if route.CaseInsensitive {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("caseInsensitive"), "requires `pathType`"))
+		}
+		return allErrs
+	}
+
+	if route.Path == "" {
+		return field.ErrorList{field.Required(pathPath, "")}
+	}
+
+	switch route.PathType {
+	case v1.PathTypeExact, v1.PathTypePrefix:
+		allErrs := validatePath(route.Path, pathPath)
+		if len(allErrs) == 0 && route.CaseInsensitive {
+			// case-insensitive matching is implemented with a regular expression
+			allErrs = append(allErrs, validateRegexPath(trimRegexModifier(configs.GenerateRoutePath(route)), pathPath)...)
+		}
+		return allErrs
+	case v1.PathTypeRegularExpression:
+		if strings.HasPrefix(route.Path, "~") || strings.HasPrefix(route.Path, "=") {
+			return field.ErrorList{field.Invalid(pathPath, route.Path, "must not start with ~ or = when `pathType` is specified")}
+		}
+		return validateRegexPath(route.Path, pathPath)
+	}
+
+	return field.ErrorList{field.NotSupported(fieldPath.Child("pathType"), route.PathType, validPathTypes)}
+}
+
 // We support prefix-based NGINX locations, positive case-sensitive/insensitive regular expressions matches and exact matches.
 // More info http://nginx.org/en/docs/http/ngx_http_core_module.html#location
 func validateRoutePath(path string, fieldPath *field.Path) field.ErrorList {
@@ -1359,6 +1618,70 @@ func (vsv *VirtualServerValidator) validateMatch(match v1.Match, fieldPath *fiel
 		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of `action` or `splits`"))
 	}
 
+	if match.SplitKey != nil {
+		if len(match.Splits) == 0 {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("splitKey"), "requires `splits`"))
+		}
+		allErrs = append(allErrs, vsv.validateSplitKey(match.SplitKey, fieldPath.Child("splitKey"))...)
+	}
+
+	return allErrs
+}
+
+func routeHasSplits(route v1.Route) bool {
+	if len(route.Splits) > 0 {
+		return true
+	}
+
+	for _, m := range route.Matches {
+		if len(m.Splits) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (vsv *VirtualServerValidator) validateSplitKey(splitKey *v1.SplitKey, fieldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	fieldCount := 0
+
+	if splitKey.Header != "" {
+		for _, msg := range validation.IsHTTPHeaderName(splitKey.Header) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("header"), splitKey.Header, msg))
+		}
+		fieldCount++
+	}
+
+	if splitKey.Cookie != "" {
+		for _, msg := range isCookieName(splitKey.Cookie) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("cookie"), splitKey.Cookie, msg))
+		}
+		fieldCount++
+	}
+
+	if splitKey.Argument != "" {
+		for _, msg := range isArgumentName(splitKey.Argument) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("argument"), splitKey.Argument, msg))
+		}
+		fieldCount++
+	}
+
+	if splitKey.JWTClaim != "" {
+		if !vsv.isPlus {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("jwtClaim"), "is only supported in NGINX Plus"))
+		}
+		for _, msg := range isJWTClaimName(splitKey.JWTClaim) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("jwtClaim"), splitKey.JWTClaim, msg))
+		}
+		fieldCount++
+	}
+
+	if fieldCount != 1 {
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `header`, `cookie`, `argument` or `jwtClaim`"))
+	}
+
 	return allErrs
 }
 
@@ -1393,17 +1716,72 @@ func validateCondition(condition v1.Condition, fieldPath *field.Path) field.Erro
 		fieldCount++
 	}
 
+	if len(condition.Methods) > 0 {
+		for i, method := range condition.Methods {
+			if !slices.Contains(conditionMethods, method) {
+				allErrs = append(allErrs, field.NotSupported(fieldPath.Child("methods").Index(i), method, conditionMethods))
+			}
+		}
+		fieldCount++
+	}
+
+	if len(condition.SourceCIDRs) > 0 {
+		for i, ipOrCIDR := range condition.SourceCIDRs {
+			allErrs = append(allErrs, validateIPorCIDR(ipOrCIDR, fieldPath.Child("sourceCIDRs").Index(i))...)
+		}
+		fieldCount++
+	}
+
 	if fieldCount != 1 {
-		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `header`, `cookie`, `argument` or `variable`"))
+		allErrs = append(allErrs, field.Invalid(fieldPath, "", "must specify exactly one of: `header`, `cookie`, `argument`, `variable`, `methods` or `sourceCIDRs`"))
 	}
 
-	for _, msg := range isValidMatchValue(condition.Value) {
-		allErrs = append(allErrs, field.Invalid(fieldPath.Child("value"), condition.Value, msg))
+	switch {
+	case len(condition.Methods) > 0 || len(condition.SourceCIDRs) > 0:
+		msg := "cannot be used with `methods` or `sourceCIDRs`"
+		if condition.Value != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("value"), msg))
+		}
+		if condition.Regex {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("regex"), msg))
+		}
+		if condition.Present != nil {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("present"), msg))
+		}
+	case condition.Present != nil:
+		if condition.Value != "" {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("value"), "cannot be used with `present`"))
+		}
+		if condition.Regex {
+			allErrs = append(allErrs, field.Forbidden(fieldPath.Child("regex"), "cannot be used with `present`"))
+		}
+	default:
+		for _, msg := range isValidMatchValue(condition.Value) {
+			allErrs = append(allErrs, field.Invalid(fieldPath.Child("value"), condition.Value, msg))
+		}
+		if condition.Regex {
+			allErrs = append(allErrs, validateConditionRegex(condition.Value, fieldPath.Child("value"))...)
+		}
 	}
 
 	return allErrs
 }
 
+// conditionMethods includes the HTTP methods allowed in a condition.
+var conditionMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH"}
+
+// validateConditionRegex validates the value of a regex condition, which can be negated with the ! prefix.
+func validateConditionRegex(value string, fieldPath *field.Path) field.ErrorList {
+	regex := strings.TrimPrefix(value, "!")
+	if regex == "" {
+		return field.ErrorList{field.Required(fieldPath, "a regular expression is required when `regex` is set")}
+	}
+	if _, err := regexp2.Compile(regex, 0); err != nil {
+		return field.ErrorList{field.Invalid(fieldPath, value, fmt.Sprintf("must be a valid regular expression: %v", err))}
+	}
+	return nil
+}
+
 const (
 	cookieNameFmt    string = "[_A-Za-z0-9]+"
 	cookieNameErrMsg string = "a valid cookie name must consist of alphanumeric characters or '_'"
@@ -1432,6 +1810,20 @@ func isArgumentName(value string) []string {
 	return nil
 }
 
+const (
+	jwtClaimNameFmt    string = `[_A-Za-z0-9\-]+(\.[_A-Za-z0-9\-]+)*`
+	jwtClaimNameErrMsg string = "a valid JWT claim name must consist of alphanumeric characters, '_' or '-', with nested claims separated by '.'"
+)
+
+var jwtClaimNameRegexp = regexp.MustCompile("^" + jwtClaimNameFmt + "$")
+
+func isJWTClaimName(value string) []string {
+	if !jwtClaimNameRegexp.MatchString(value) {
+		return []string{validation.RegexError(jwtClaimNameErrMsg, jwtClaimNameFmt, "sub", "user.id")}
+	}
+	return nil
+}
+
 // validVariableNames includes NGINX variables allowed to be used in conditions.
 // Not all NGINX variables are allowed. The full list of NGINX variables is at https://nginx.org/en/docs/varindex.html
 var validVariableNames = map[string]bool{
@@ -1471,6 +1863,29 @@ func (vsv *VirtualServerValidator) ValidateVirtualServerRoute(virtualServerRoute
 	return allErrs.ToAggregate()
 }
 
+// ValidateVirtualServerRouteConflicts validates that the subroutes of a VirtualServerRoute neither duplicate nor shadow
+// the routes that are already configured for the VirtualServer, including the subroutes of other VirtualServerRoutes.
+func (vsv *VirtualServerValidator) ValidateVirtualServerRouteConflicts(virtualServerRoute *v1.VirtualServerRoute, routes []v1.Route) error {
+	allErrs := field.ErrorList{}
+	fieldPath := field.NewPath("spec").Child("subroutes")
+
+	paths := sets.Set[string]{}
+	for _, r := range routes {
+		paths.Insert(configs.GenerateRoutePath(r))
+	}
+
+	for i, r := range virtualServerRoute.Spec.Subroutes {
+		idxPath := fieldPath.Index(i).Child("path")
+		if paths.Has(configs.GenerateRoutePath(r)) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, r.Path))
+			continue
+		}
+		allErrs = append(allErrs, validateRouteConflicts(r, routes, idxPath)...)
+	}
+
+	return allErrs.ToAggregate()
+}
+
 // ValidateVirtualServerRouteForVirtualServer validates a VirtualServerRoute for a VirtualServer represented by its host and path prefix.
 func (vsv *VirtualServerValidator) ValidateVirtualServerRouteForVirtualServer(virtualServerRoute *v1.VirtualServerRoute, virtualServerHost string, vsPath string) error {
 	allErrs := vsv.validateVirtualServerRouteSpec(&virtualServerRoute.Spec, field.NewPath("spec"), virtualServerHost, vsPath,
@@ -1508,6 +1923,7 @@ func (vsv *VirtualServerValidator) validateVirtualServerRouteSubroutes(routes []
 	allErrs := field.ErrorList{}
 
 	allPaths := sets.Set[string]{}
+	var validRoutes []v1.Route
 
 	if isRegexOrExactMatch(vsPath) {
 		if len(routes) != 1 {
@@ -1515,7 +1931,7 @@ func (vsv *VirtualServerValidator) validateVirtualServerRouteSubroutes(routes []
 		}
 
 		idxPath := fieldPath.Index(0)
-		if routes[0].Path != vsPath {
+		if configs.GenerateRoutePath(routes[0]) != vsPath {
 			return append(allErrs, field.Invalid(idxPath.Child("path"), routes[0].Path, "must have the same path as the referenced VirtualServer route path"))
 		}
 
@@ -1528,17 +1944,20 @@ func (vsv *VirtualServerValidator) validateVirtualServerRouteSubroutes(routes []
 		isRouteFieldForbidden := true
 		routeErrs := vsv.validateRoute(r, idxPath, upstreamNames, isRouteFieldForbidden, namespace)
 
-		if vsPath != "" && !strings.HasPrefix(r.Path, vsPath) {
+		path := configs.GenerateRoutePath(r)
+		if vsPath != "" && !strings.HasPrefix(path, vsPath) {
 			msg := fmt.Sprintf("must start with '%s'", vsPath)
 			routeErrs = append(routeErrs, field.Invalid(idxPath, r.Path, msg))
 		}
 
 		if len(routeErrs) > 0 {
 			allErrs = append(allErrs, routeErrs...)
-		} else if allPaths.Has(r.Path) {
+		} else if allPaths.Has(path) {
 			allErrs = append(allErrs, field.Duplicate(idxPath.Child("path"), r.Path))
 		} else {
-			allPaths.Insert(r.Path)
+			allErrs = append(allErrs, validateRouteConflicts(r, validRoutes, idxPath.Child("path"))...)
+			allPaths.Insert(path)
+			validRoutes = append(validRoutes, r)
 		}
 	}
 
@@ -1571,6 +1990,10 @@ func rejectPlusResourcesInOSS(upstream v1.Upstream, idxPath *field.Path, isPlus
 		allErrs = append(allErrs, field.Forbidden(idxPath.Child("ntlm"), "NTLM is only supported in NGINX Plus"))
 	}
 
+	if upstream.Resolve {
+		allErrs = append(allErrs, field.Forbidden(idxPath.Child("resolve"), "re-resolving of servers is only supported in NGINX Plus"))
+	}
+
 	return allErrs
 }
 
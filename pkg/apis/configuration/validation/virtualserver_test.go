@@ -430,6 +430,27 @@ func TestValidateTLS(t *testing.T) {
 				Issuer: "my-issuer",
 			},
 		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Stapling:          true,
+				StaplingResponder: "http://ocsp.example.com:8080/",
+				Resolver: &v1.OCSPResolver{
+					Addresses: []string{"10.0.0.10", "kube-dns.kube-system.svc.cluster.local:53", "[2001:db8::10]", "[2001:db8::10]:5353"},
+					Valid:     "30s",
+					Timeout:   "5s",
+					IPv6:      createPointerFromBool(false),
+				},
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Resolver: &v1.OCSPResolver{
+					Addresses: []string{"10.0.0.10"},
+				},
+			},
+		},
 	}
 
 	vsv := &VirtualServerValidator{isPlus: false, isCertManagerEnabled: true}
@@ -469,6 +490,66 @@ func TestValidateTLS(t *testing.T) {
 				Issuer: "my-issuer",
 			},
 		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				StaplingResponder: "http://ocsp.example.com",
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Stapling:          true,
+				StaplingResponder: "https://ocsp.example.com",
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Stapling:          true,
+				StaplingResponder: "http://ocsp.example.com/;return 200",
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Stapling: true,
+				Resolver: &v1.OCSPResolver{},
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Resolver: &v1.OCSPResolver{
+					Addresses: []string{"2001:db8::10"},
+				},
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Resolver: &v1.OCSPResolver{
+					Addresses: []string{"10.0.0.10:99999"},
+				},
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Resolver: &v1.OCSPResolver{
+					Addresses: []string{"dns_server"},
+				},
+			},
+		},
+		{
+			Secret: "my-secret",
+			OCSP: &v1.TLSOCSP{
+				Resolver: &v1.OCSPResolver{
+					Addresses: []string{"10.0.0.10"},
+					Valid:     "30x",
+				},
+			},
+		},
 	}
 
 	for _, tls := range invalidTLSes {
@@ -561,6 +642,18 @@ func TestValidateUpstreams(t *testing.T) {
 			},
 			msg: "2 valid upstreams",
 		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:    "upstream1",
+					Servers: []string{"10.0.0.1:8080", "[2001:db8::1]:80", "legacy.example.com:443"},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "upstream with static servers",
+		},
 	}
 
 	vsv := &VirtualServerValidator{isPlus: false}
@@ -583,6 +676,70 @@ func TestValidateUpstreamsFails(t *testing.T) {
 		expectedUpstreamNames sets.Set[string]
 		msg                   string
 	}{
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:    "upstream1",
+					Service: "test-1",
+					Port:    80,
+					Servers: []string{"10.0.0.1:8080"},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "static servers with service",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:    "upstream1",
+					Servers: []string{"10.0.0.1"},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "static server without port",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:    "upstream1",
+					Servers: []string{"legacy_host.example.com:80"},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "static server with invalid hostname",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:    "upstream1",
+					Servers: []string{"10.0.0.1:80", "10.0.0.1:80"},
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "duplicated static servers",
+		},
+		{
+			upstreams: []v1.Upstream{
+				{
+					Name:    "upstream1",
+					Service: "test-1",
+					Port:    80,
+					Resolve: true,
+				},
+			},
+			expectedUpstreamNames: map[string]sets.Empty{
+				"upstream1": {},
+			},
+			msg: "resolve without static servers",
+		},
 		{
 			upstreams: []v1.Upstream{
 				{
@@ -927,6 +1084,36 @@ func TestValidateVirtualServerRoutes(t *testing.T) {
 			},
 			msg: "valid route",
 		},
+		{
+			routes: []v1.Route{
+				{
+					Path:     "/images",
+					PathType: v1.PathTypePrefix,
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Path:     `\.jpg$`,
+					PathType: v1.PathTypeRegularExpression,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+				{
+					Path:     "/images",
+					PathType: v1.PathTypeExact,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "routes with path types that don't conflict",
+		},
 	}
 
 	vsv := &VirtualServerValidator{isPlus: false}
@@ -967,7 +1154,97 @@ func TestValidateVirtualServerRoutesFails(t *testing.T) {
 			},
 			msg: "duplicated paths",
 		},
-
+		{
+			routes: []v1.Route{
+				{
+					Path: "=/test",
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Path:     "/test",
+					PathType: v1.PathTypeExact,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "duplicated paths with path type",
+		},
+		{
+			routes: []v1.Route{
+				{
+					Path:     "/api/v1",
+					PathType: v1.PathTypePrefix,
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Path:     "^/api",
+					PathType: v1.PathTypeRegularExpression,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "prefix route shadowed by regular expression route",
+		},
+		{
+			routes: []v1.Route{
+				{
+					Path:            "/api",
+					PathType:        v1.PathTypePrefix,
+					CaseInsensitive: true,
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Path: "/API/v1",
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "prefix route shadowed by case-insensitive prefix route",
+		},
+		{
+			routes: []v1.Route{
+				{
+					Path: "~ ^/api",
+					Action: &v1.Action{
+						Pass: "test-1",
+					},
+				},
+				{
+					Path:            "^/api",
+					PathType:        v1.PathTypeRegularExpression,
+					CaseInsensitive: true,
+					Action: &v1.Action{
+						Pass: "test-2",
+					},
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			msg: "regular expression routes that differ only in case sensitivity",
+		},
 		{
 			routes: []v1.Route{
 				{
@@ -1036,6 +1313,34 @@ func TestValidateRoute(t *testing.T) {
 			isRouteFieldForbidden: false,
 			msg:                   "valid upstream with splits",
 		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Splits: []v1.Split{
+					{
+						Weight: 90,
+						Action: &v1.Action{
+							Pass: "test-1",
+						},
+					},
+					{
+						Weight: 10,
+						Action: &v1.Action{
+							Pass: "test-2",
+						},
+					},
+				},
+				SplitKey: &v1.SplitKey{
+					Cookie: "session",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test-1": {},
+				"test-2": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "valid splits with split key",
+		},
 		{
 			route: v1.Route{
 				Path: "/",
@@ -1116,6 +1421,22 @@ func TestValidateRouteFails(t *testing.T) {
 			isRouteFieldForbidden: false,
 			msg:                   "invalid pass action",
 		},
+		{
+			route: v1.Route{
+				Path: "/",
+				Action: &v1.Action{
+					Pass: "test",
+				},
+				SplitKey: &v1.SplitKey{
+					Cookie: "session",
+				},
+			},
+			upstreamNames: map[string]sets.Empty{
+				"test": {},
+			},
+			isRouteFieldForbidden: false,
+			msg:                   "split key without splits",
+		},
 		{
 			route: v1.Route{
 				Path: "/",
@@ -1668,6 +1989,46 @@ func TestValidateRoutePath(t *testing.T) {
 	}
 }
 
+func TestValidateRoutePathWithType(t *testing.T) {
+	t.Parallel()
+	validRoutes := []v1.Route{
+		{Path: "/"},
+		{Path: "=/exact/match"},
+		{Path: "/exact/match", PathType: v1.PathTypeExact},
+		{Path: "/exact/match", PathType: v1.PathTypeExact, CaseInsensitive: true},
+		{Path: "/prefix", PathType: v1.PathTypePrefix},
+		{Path: "/prefix", PathType: v1.PathTypePrefix, CaseInsensitive: true},
+		{Path: "^/foo.*\\.jpg", PathType: v1.PathTypeRegularExpression},
+		{Path: "^/Bar.*\\.jpg", PathType: v1.PathTypeRegularExpression, CaseInsensitive: true},
+	}
+
+	for _, route := range validRoutes {
+		allErrs := validateRoutePathWithType(route, field.NewPath("route"))
+		if len(allErrs) != 0 {
+			t.Errorf("validateRoutePathWithType(%+v) returned errors %v for valid input", route, allErrs)
+		}
+	}
+
+	invalidRoutes := []v1.Route{
+		{Path: "", PathType: v1.PathTypePrefix},
+		{Path: "/prefix", CaseInsensitive: true},
+		{Path: "/prefix", PathType: "Invalid"},
+		{Path: "=/exact/match", PathType: v1.PathTypeExact},
+		{Path: "~/prefix", PathType: v1.PathTypePrefix},
+		{Path: "~ ^/foo", PathType: v1.PathTypeRegularExpression},
+		{Path: "=/exact", PathType: v1.PathTypeRegularExpression},
+		{Path: "^/foo(", PathType: v1.PathTypeRegularExpression},
+		{Path: `/path"`, PathType: v1.PathTypePrefix, CaseInsensitive: true},
+	}
+
+	for _, route := range invalidRoutes {
+		allErrs := validateRoutePathWithType(route, field.NewPath("route"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateRoutePathWithType(%+v) returned no errors for invalid input", route)
+		}
+	}
+}
+
 func TestValidatePath(t *testing.T) {
 	t.Parallel()
 	validPaths := []string{
@@ -1962,6 +2323,41 @@ func TestValidateCondition(t *testing.T) {
 			},
 			msg: "valid variable",
 		},
+		{
+			condition: v1.Condition{
+				Methods: []string{"GET", "HEAD"},
+			},
+			msg: "valid methods",
+		},
+		{
+			condition: v1.Condition{
+				SourceCIDRs: []string{"10.0.0.0/8", "192.168.1.1"},
+			},
+			msg: "valid source CIDRs",
+		},
+		{
+			condition: v1.Condition{
+				Header: "x-version",
+				Value:  "^v[0-9]+$",
+				Regex:  true,
+			},
+			msg: "valid regex",
+		},
+		{
+			condition: v1.Condition{
+				Header: "x-version",
+				Value:  "!(?i)^beta",
+				Regex:  true,
+			},
+			msg: "valid negated regex",
+		},
+		{
+			condition: v1.Condition{
+				Cookie:  "session",
+				Present: createPointerFromBool(true),
+			},
+			msg: "valid present",
+		},
 	}
 
 	for _, test := range tests {
@@ -2016,6 +2412,55 @@ func TestValidateConditionFails(t *testing.T) {
 			},
 			msg: "invalid variable",
 		},
+		{
+			condition: v1.Condition{
+				Methods: []string{"get"},
+			},
+			msg: "invalid method",
+		},
+		{
+			condition: v1.Condition{
+				Header:  "x-version",
+				Methods: []string{"GET"},
+			},
+			msg: "header and methods",
+		},
+		{
+			condition: v1.Condition{
+				SourceCIDRs: []string{"10.0.0.0/33"},
+			},
+			msg: "invalid source CIDR",
+		},
+		{
+			condition: v1.Condition{
+				SourceCIDRs: []string{"10.0.0.0/8"},
+				Value:       "1",
+			},
+			msg: "source CIDRs with value",
+		},
+		{
+			condition: v1.Condition{
+				Header: "x-version",
+				Value:  "v[0-9",
+				Regex:  true,
+			},
+			msg: "invalid regex",
+		},
+		{
+			condition: v1.Condition{
+				Header: "x-version",
+				Regex:  true,
+			},
+			msg: "regex without value",
+		},
+		{
+			condition: v1.Condition{
+				Cookie:  "session",
+				Value:   "abc",
+				Present: createPointerFromBool(true),
+			},
+			msg: "present with value",
+		},
 	}
 
 	for _, test := range tests {
@@ -2113,6 +2558,104 @@ func TestValidateVariableName(t *testing.T) {
 	}
 }
 
+func TestValidateSplitKey(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		splitKey *v1.SplitKey
+		isPlus   bool
+		msg      string
+	}{
+		{
+			splitKey: &v1.SplitKey{
+				Header: "x-user-id",
+			},
+			msg: "valid header",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				Cookie: "session_id",
+			},
+			msg: "valid cookie",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				Argument: "user",
+			},
+			msg: "valid argument",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				JWTClaim: "user.id",
+			},
+			isPlus: true,
+			msg:    "valid jwt claim",
+		},
+	}
+
+	for _, test := range tests {
+		vsv := &VirtualServerValidator{isPlus: test.isPlus}
+		allErrs := vsv.validateSplitKey(test.splitKey, field.NewPath("splitKey"))
+		if len(allErrs) > 0 {
+			t.Errorf("validateSplitKey() returned errors %v for valid input for the case of %s", allErrs, test.msg)
+		}
+	}
+}
+
+func TestValidateSplitKeyFails(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		splitKey *v1.SplitKey
+		isPlus   bool
+		msg      string
+	}{
+		{
+			splitKey: &v1.SplitKey{},
+			msg:      "empty split key",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				Header:   "x-user-id",
+				Argument: "user",
+			},
+			msg: "more than one field",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				Cookie: "session-id",
+			},
+			msg: "invalid cookie",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				Header: "x user",
+			},
+			msg: "invalid header",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				JWTClaim: "sub",
+			},
+			isPlus: false,
+			msg:    "jwt claim in OSS",
+		},
+		{
+			splitKey: &v1.SplitKey{
+				JWTClaim: "user..id",
+			},
+			isPlus: true,
+			msg:    "invalid jwt claim",
+		},
+	}
+
+	for _, test := range tests {
+		vsv := &VirtualServerValidator{isPlus: test.isPlus}
+		allErrs := vsv.validateSplitKey(test.splitKey, field.NewPath("splitKey"))
+		if len(allErrs) == 0 {
+			t.Errorf("validateSplitKey() returned no errors for invalid input for the case of %s", test.msg)
+		}
+	}
+}
+
 func TestValidateMatch(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -2398,6 +2941,68 @@ func TestValidateVirtualServerRouteForVirtualServer(t *testing.T) {
 	}
 }
 
+func TestValidateVirtualServerRouteConflicts(t *testing.T) {
+	t.Parallel()
+	virtualServerRoute := v1.VirtualServerRoute{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "coffee",
+			Namespace: "default",
+		},
+		Spec: v1.VirtualServerRouteSpec{
+			Host: "example.com",
+			Subroutes: []v1.Route{
+				{
+					Path:     "/coffee/latte",
+					PathType: v1.PathTypePrefix,
+					Action: &v1.Action{
+						Pass: "latte",
+					},
+				},
+			},
+		},
+	}
+
+	vsv := &VirtualServerValidator{isPlus: false}
+
+	validRoutes := [][]v1.Route{
+		nil,
+		{
+			{Path: "/tea"},
+			{Path: "^/tea/.*\\.png$", PathType: v1.PathTypeRegularExpression},
+		},
+		{
+			// NGINX picks the longest matching prefix
+			{Path: "/coffee"},
+		},
+	}
+
+	for _, routes := range validRoutes {
+		err := vsv.ValidateVirtualServerRouteConflicts(&virtualServerRoute, routes)
+		if err != nil {
+			t.Errorf("ValidateVirtualServerRouteConflicts() returned error %v for valid routes %v", err, routes)
+		}
+	}
+
+	invalidRoutes := [][]v1.Route{
+		{
+			{Path: "/coffee/latte", PathType: v1.PathTypePrefix},
+		},
+		{
+			{Path: "^/coffee", PathType: v1.PathTypeRegularExpression},
+		},
+		{
+			{Path: "/COFFEE", PathType: v1.PathTypePrefix, CaseInsensitive: true},
+		},
+	}
+
+	for _, routes := range invalidRoutes {
+		err := vsv.ValidateVirtualServerRouteConflicts(&virtualServerRoute, routes)
+		if err == nil {
+			t.Errorf("ValidateVirtualServerRouteConflicts() returned no error for invalid routes %v", routes)
+		}
+	}
+}
+
 func TestValidateVirtualServerRouteHost(t *testing.T) {
 	t.Parallel()
 	virtualServerHost := "example.com"
@@ -3269,6 +3874,11 @@ func TestRejectPlusResourcesInOSS(t *testing.T) {
 				NTLM: true,
 			},
 		},
+		{
+			upstream: &v1.Upstream{
+				Resolve: true,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -3710,8 +4320,15 @@ func TestValidateActionProxy(t *testing.T) {
 	}
 	path := "/path"
 	actionProxy := &v1.ActionProxy{
-		Upstream:    "upstream1",
-		RewritePath: "/test",
+		Upstream:          "upstream1",
+		RewritePath:       "/test",
+		ConnectTimeout:    "10s",
+		ReadTimeout:       "120s",
+		SendTimeout:       "1m",
+		Buffering:         createPointerFromBool(false),
+		Buffers:           &v1.UpstreamBuffers{Number: 16, Size: "8k"},
+		BufferSize:        "8k",
+		ClientMaxBodySize: "100m",
 	}
 
 	vsv := &VirtualServerValidator{isPlus: false}
@@ -3742,6 +4359,108 @@ func TestValidateActionProxyFails(t *testing.T) {
 	}
 }
 
+func TestValidateActionProxyOverridesFails(t *testing.T) {
+	t.Parallel()
+	upstreamNames := map[string]sets.Empty{
+		"upstream1": {},
+	}
+	path := "/path"
+	tests := []*v1.ActionProxy{
+		{
+			Upstream:       "upstream1",
+			ConnectTimeout: "10 s",
+		},
+		{
+			Upstream:    "upstream1",
+			ReadTimeout: "abc",
+		},
+		{
+			Upstream:    "upstream1",
+			SendTimeout: "-1s",
+		},
+		{
+			Upstream: "upstream1",
+			Buffers:  &v1.UpstreamBuffers{Number: -1, Size: "8k"},
+		},
+		{
+			Upstream:   "upstream1",
+			BufferSize: "8 k",
+		},
+		{
+			Upstream:          "upstream1",
+			ClientMaxBodySize: "100x",
+		},
+	}
+
+	vsv := &VirtualServerValidator{isPlus: false}
+
+	for _, test := range tests {
+		allErrs := vsv.validateActionProxy(test, field.NewPath("proxy"), upstreamNames, path, false)
+		if len(allErrs) == 0 {
+			t.Errorf("validateActionProxy(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
+func TestValidateActionProxyMirror(t *testing.T) {
+	t.Parallel()
+	upstreamNames := map[string]sets.Empty{
+		"upstream1": {},
+	}
+	tests := []*v1.ProxyMirror{
+		nil,
+		{
+			Upstream: "upstream1",
+		},
+		{
+			Upstream:    "upstream1",
+			Percentage:  createPointerFromInt(1),
+			RequestBody: createPointerFromBool(false),
+		},
+		{
+			Upstream:   "upstream1",
+			Percentage: createPointerFromInt(100),
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxyMirror(test, field.NewPath("mirror"), upstreamNames)
+		if len(allErrs) != 0 {
+			t.Errorf("validateActionProxyMirror(%+v) returned errors for valid input: %v", test, allErrs)
+		}
+	}
+}
+
+func TestValidateActionProxyMirrorFails(t *testing.T) {
+	t.Parallel()
+	upstreamNames := map[string]sets.Empty{
+		"upstream1": {},
+	}
+	tests := []*v1.ProxyMirror{
+		{
+			Upstream: "",
+		},
+		{
+			Upstream: "upstream2",
+		},
+		{
+			Upstream:   "upstream1",
+			Percentage: createPointerFromInt(0),
+		},
+		{
+			Upstream:   "upstream1",
+			Percentage: createPointerFromInt(101),
+		},
+	}
+
+	for _, test := range tests {
+		allErrs := validateActionProxyMirror(test, field.NewPath("mirror"), upstreamNames)
+		if len(allErrs) == 0 {
+			t.Errorf("validateActionProxyMirror(%+v) returned no errors for invalid input", test)
+		}
+	}
+}
+
 func TestValidateActionProxyRewritePath(t *testing.T) {
 	t.Parallel()
 	tests := []string{"/rewrite", "/rewrite", `/$2`}
@@ -23,6 +23,8 @@ jwt_vs_override_spec_route_1 = f"{TEST_DATA}/jwt-policy/route-subroute/virtual-s
 jwt_vs_override_spec_route_2 = f"{TEST_DATA}/jwt-policy/route-subroute/virtual-server-override-spec-route-2.yaml"
 valid_token = f"{TEST_DATA}/jwt-policy/token.jwt"
 invalid_token = f"{TEST_DATA}/jwt-policy/invalid-token.jwt"
+string_exp_token = f"{TEST_DATA}/jwt-policy/token-string-exp.jwt"
+string_nbf_token = f"{TEST_DATA}/jwt-policy/token-string-nbf.jwt"
 
 
 @pytest.mark.skip_for_nginx_oss
@@ -503,3 +505,82 @@ class TestJWTPolicies:
 
         assert resp1.status_code == 401  # 401 unauthorized, since no token is attached to policy
         assert resp2.status_code == 200
+
+
+@pytest.mark.skip_for_nginx_plus
+@pytest.mark.policies
+@pytest.mark.policies_jwt
+@pytest.mark.parametrize(
+    "crd_ingress_controller, virtual_server_setup",
+    [
+        (
+            {
+                "type": "complete",
+                "extra_args": [
+                    f"-enable-custom-resources",
+                    f"-enable-leader-election=false",
+                ],
+            },
+            {
+                "example": "virtual-server",
+                "app_type": "simple",
+            },
+        )
+    ],
+    indirect=True,
+)
+class TestJWTPoliciesNGINX:
+    @pytest.mark.parametrize(
+        "token, expected_status",
+        [
+            (valid_token, 200),
+            (invalid_token, 401),
+            (string_exp_token, 401),
+            (string_nbf_token, 401),
+        ],
+    )
+    def test_jwt_policy_token_claims(
+        self,
+        kube_apis,
+        crd_ingress_controller,
+        virtual_server_setup,
+        test_namespace,
+        token,
+        expected_status,
+    ):
+        """
+        Test that njs rejects tokens with an invalid signature or with non-numeric exp or nbf claims
+        """
+        print(f"Create jwk secret")
+        secret = create_secret_from_yaml(kube_apis.v1, test_namespace, jwk_sec_valid_src)
+        print(f"Create jwt policy")
+        pol_name = create_policy_from_yaml(kube_apis.custom_objects, jwt_pol_valid_src, test_namespace)
+        wait_before_test()
+
+        with open(token) as file:
+            data = file.readline().strip()
+        headers = {"host": virtual_server_setup.vs_host, "token": data}
+
+        print(f"Patch vs with policy: {jwt_vs_single_src}")
+        delete_and_create_vs_from_yaml(
+            kube_apis.custom_objects,
+            virtual_server_setup.vs_name,
+            jwt_vs_single_src,
+            virtual_server_setup.namespace,
+        )
+        wait_before_test()
+
+        resp = requests.get(virtual_server_setup.backend_1_url, headers=headers)
+        print(resp.status_code)
+
+        delete_policy(kube_apis.custom_objects, pol_name, test_namespace)
+        delete_secret(kube_apis.v1, secret, test_namespace)
+
+        delete_and_create_vs_from_yaml(
+            kube_apis.custom_objects,
+            virtual_server_setup.vs_name,
+            std_vs_src,
+            virtual_server_setup.namespace,
+        )
+
+        assert resp.status_code == expected_status
@@ -38,6 +38,11 @@ cm_src = f"{TEST_DATA}/oidc/nginx-config.yaml"
 cm_zs_src = f"{TEST_DATA}/oidc/nginx-config-zs.yaml"
 orig_cm_src = f"{DEPLOYMENTS}/common/nginx-config.yaml"
 svc_src = f"{TEST_DATA}/oidc/nginx-ingress-headless.yaml"
+oidc_secret_nginx_src = f"{TEST_DATA}/oidc/client-secret-nginx.yaml"
+oidc_pol_nginx_src = f"{TEST_DATA}/oidc/oidc-nginx.yaml"
+oidc_vs_nginx_src = f"{TEST_DATA}/oidc/virtual-server-nginx.yaml"
+cm_idp_src = f"{TEST_DATA}/oidc/nginx-config-idp.yaml"
+std_vs_src = f"{TEST_DATA}/virtual-server/standard/virtual-server.yaml"
 
 
 class KeycloakSetup:
@@ -216,6 +221,100 @@ class TestOIDC:
             delete_service(kube_apis.v1, headless_name, ingress_controller_prerequisites.namespace)
 
 
+@pytest.mark.oidc
+@pytest.mark.skip_for_nginx_plus
+@pytest.mark.parametrize(
+    "crd_ingress_controller, virtual_server_setup",
+    [
+        (
+            {
+                "type": "complete",
+                "extra_args": [
+                    f"-enable-oidc",
+                    f"-enable-snippets",
+                ],
+            },
+            {"example": "virtual-server", "app_type": "simple"},
+        )
+    ],
+    indirect=True,
+)
+class TestOIDCNGINX:
+    @pytest.mark.parametrize(
+        "token_endpoint, expected_status",
+        [
+            ("/token/valid", 302),
+            ("/token/forged", 500),
+            ("/token/unsigned", 500),
+        ],
+    )
+    def test_oidc_id_token_signature(
+        self,
+        kube_apis,
+        ingress_controller_prerequisites,
+        crd_ingress_controller,
+        test_namespace,
+        virtual_server_setup,
+        token_endpoint,
+        expected_status,
+    ):
+        """
+        Test that njs only creates a session for an ID token signed with a key of the JWKS of the IdP.
+        The IdP is served by the http-snippets of the ConfigMap.
+        """
+        print(f"Update nginx configmap")
+        replace_configmap_from_yaml(
+            kube_apis.v1,
+            ingress_controller_prerequisites.config_map["metadata"]["name"],
+            ingress_controller_prerequisites.namespace,
+            cm_idp_src,
+        )
+        print(f"Create oidc secret")
+        secret_name = create_secret_from_yaml(kube_apis.v1, test_namespace, oidc_secret_nginx_src)
+
+        print(f"Create oidc policy")
+        with open(oidc_pol_nginx_src) as f:
+            doc = yaml.safe_load(f)
+        pol = doc["metadata"]["name"]
+        doc["spec"]["oidc"]["tokenEndpoint"] = f"http://127.0.0.1:8999{token_endpoint}"
+        kube_apis.custom_objects.create_namespaced_custom_object("k8s.nginx.org", "v1", test_namespace, "policies", doc)
+        wait_before_test()
+
+        print(f"Patch vs with policy: {oidc_vs_nginx_src}")
+        patch_virtual_server_from_yaml(
+            kube_apis.custom_objects, virtual_server_setup.vs_name, oidc_vs_nginx_src, test_namespace
+        )
+        wait_before_test()
+
+        # The nonce of the ID tokens is the HMAC of the auth_nonce cookie with the name of the VirtualServer
+        endpoint = virtual_server_setup.public_endpoint
+        resp = requests.get(
+            f"http://{endpoint.public_ip}:{endpoint.port}/_codexch?code=test",
+            headers={
+                "host": virtual_server_setup.vs_host,
+                "cookie": "auth_nonce=test-nonce; auth_redir=%2Fbackend1",
+            },
+            allow_redirects=False,
+        )
+        print(resp.status_code)
+
+        patch_virtual_server_from_yaml(
+            kube_apis.custom_objects, virtual_server_setup.vs_name, std_vs_src, test_namespace
+        )
+        delete_policy(kube_apis.custom_objects, pol, test_namespace)
+        delete_secret(kube_apis.v1, secret_name, test_namespace)
+        replace_configmap_from_yaml(
+            kube_apis.v1,
+            ingress_controller_prerequisites.config_map["metadata"]["name"],
+            ingress_controller_prerequisites.namespace,
+            orig_cm_src,
+        )
+
+        assert resp.status_code == expected_status
+        if expected_status == 302:
+            assert "auth_session_0=" in resp.headers["Set-Cookie"]
+
+
 def run_oidc(browser_type, ip_address, port):
 
     browser = browser_type.launch(headless=True, args=[f"--host-resolver-rules=MAP * {ip_address}:{port}"])